@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/report"
+	"github.com/spf13/cobra"
+)
+
+const (
+	diffFormatText = "text"
+	diffFormatJSON = "json"
+)
+
+func diffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff old.zip new.zip",
+		Short: "Compare the results of two exported reports",
+		Long: `Compare the results of two exported reports by API version, listing newly failing,
+newly passing, added and removed test cases, changed failure reasons and response time regressions.
+
+Exit codes:
+  0 - no test case is newly failing
+  1 - at least one test case is newly failing
+  2 - the reports could not be compared`,
+		Args:         cobra.ExactArgs(2),
+		RunE:         diff,
+		SilenceUsage: true,
+	}
+	defaults := report.DefaultDiffOptions()
+	cmd.Flags().String("format", diffFormatText, "Diff format, one of "+diffFormatText+"|"+diffFormatJSON)
+	cmd.Flags().StringP("output", "o", "", "Diff filename, defaults to standard output")
+	cmd.Flags().Float64("response-time-increase", defaults.ResponseTimeIncrease, "Fraction a response time must increase by to be a regression, 0.5 is 50% slower")
+	cmd.Flags().Duration("min-response-time-increase", defaults.MinResponseTimeIncrease, "Smallest response time increase reported as a regression")
+	return cmd
+}
+
+// diff compares the reports exported to the two ZIP archives given as arguments
+func diff(cmd *cobra.Command, args []string) error {
+	formatFlag, err := cmd.Flags().GetString("format")
+	if err != nil || (formatFlag != diffFormatText && formatFlag != diffFormatJSON) {
+		return newRunError("You need to provide a diff format, one of %s, %s.", diffFormatText, diffFormatJSON)
+	}
+
+	outputFlag, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return newRunError("%s", err.Error())
+	}
+
+	options := report.DiffOptions{}
+	if options.ResponseTimeIncrease, err = cmd.Flags().GetFloat64("response-time-increase"); err != nil {
+		return newRunError("%s", err.Error())
+	}
+	if options.MinResponseTimeIncrease, err = cmd.Flags().GetDuration("min-response-time-increase"); err != nil {
+		return newRunError("%s", err.Error())
+	}
+
+	oldReport, err := importReportFile(args[0])
+	if err != nil {
+		return newRunError("%s", err.Error())
+	}
+	newReport, err := importReportFile(args[1])
+	if err != nil {
+		return newRunError("%s", err.Error())
+	}
+
+	reportDiff := report.NewDiff(oldReport, newReport, options)
+
+	var output io.Writer = os.Stdout
+	if outputFlag != "" {
+		file, err := os.Create(outputFlag)
+		if err != nil {
+			return newRunError("%s", err.Error())
+		}
+		defer file.Close()
+		output = file
+	}
+
+	if formatFlag == diffFormatJSON {
+		encoder := json.NewEncoder(output)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(reportDiff)
+	} else {
+		err = reportDiff.WriteText(output)
+	}
+	if err != nil {
+		return newRunError("%s", err.Error())
+	}
+
+	if newlyFailing := reportDiff.NewlyFailing(); newlyFailing > 0 {
+		return newTestFailuresError("%d test cases newly failing", newlyFailing)
+	}
+	return nil
+}
+
+func importReportFile(filename string) (report.Report, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return report.Report{}, err
+	}
+	defer file.Close()
+
+	imported, err := report.NewZipImporter(file).Import()
+	if err != nil {
+		return report.Report{}, fmt.Errorf("%s: %s", filename, err)
+	}
+	return imported, nil
+}
@@ -0,0 +1,28 @@
+package main
+
+import "fmt"
+
+// Exit codes so pipelines can tell a failed conformance run apart from a run that could not complete
+const (
+	exitSuccess      = 0
+	exitTestFailures = 1
+	exitRunError     = 2
+)
+
+// exitError is returned by commands to terminate the CLI with a specific exit code
+type exitError struct {
+	code int
+	msg  string
+}
+
+func (e exitError) Error() string {
+	return e.msg
+}
+
+func newRunError(format string, args ...interface{}) exitError {
+	return exitError{code: exitRunError, msg: fmt.Sprintf(format, args...)}
+}
+
+func newTestFailuresError(format string, args ...interface{}) exitError {
+	return exitError{code: exitTestFailures, msg: fmt.Sprintf(format, args...)}
+}
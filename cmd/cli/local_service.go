@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/resty.v1"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/client"
+	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+	"github.com/OpenBankingUK/conformance-suite/pkg/generation"
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	os2 "github.com/OpenBankingUK/conformance-suite/pkg/os"
+	"github.com/OpenBankingUK/conformance-suite/pkg/server"
+	"github.com/OpenBankingUK/conformance-suite/pkg/server/models"
+	"github.com/OpenBankingUK/conformance-suite/pkg/version"
+)
+
+// localService runs the functional conformance journey in-process
+// so no fcs_server, websocket or browser is required
+type localService struct {
+	reportFile    string
+	reportFormat  string
+	minTLSVersion uint16
+	logger        *logrus.Entry
+}
+
+func newLocalService(reportFile, reportFormat string, minTLSVersion uint16) localService {
+	logger := logrus.StandardLogger()
+	level, err := logrus.ParseLevel(os2.GetEnvOrDefault("FCS_LOG_LEVEL", "WARN"))
+	if err == nil {
+		logger.SetLevel(level)
+	}
+	resty.SetRedirectPolicy(resty.FlexibleRedirectPolicy(15))
+
+	return localService{
+		reportFile:    reportFile,
+		reportFormat:  reportFormat,
+		minTLSVersion: minTLSVersion,
+		logger:        logger.WithField("app", "cli"),
+	}
+}
+
+func (s localService) Version() (client.VersionResponse, error) {
+	return client.VersionResponse{Version: version.FullVersion}, nil
+}
+
+func (s localService) Run(discoveryFile, configFile, exportConfig string) ([]client.TestCase, error) {
+	discoveryModel := &discovery.Model{}
+	if err := readJSONFile(discoveryFile, discoveryModel); err != nil {
+		return nil, errors.Wrap(err, "setting discovery model")
+	}
+
+	config := &server.GlobalConfiguration{}
+	if err := readJSONFile(configFile, config); err != nil {
+		return nil, errors.Wrap(err, "setting config")
+	}
+
+	request := models.ExportRequest{}
+	if err := readJSONFile(exportConfig, &request); err != nil {
+		return nil, errors.Wrap(err, "export report")
+	}
+	if s.reportFormat != "" {
+		request.Format = s.reportFormat
+	}
+
+	// the report is written next to the report file and renamed once the run succeeds,
+	// so a failed run does not leave an empty or partial report behind
+	report, err := ioutil.TempFile(filepath.Dir(s.reportFile), filepath.Base(s.reportFile)+".*.tmp")
+	if err != nil {
+		return nil, errors.Wrap(err, "export report")
+	}
+	defer os.Remove(report.Name())
+	defer report.Close()
+
+	validatorEngine := discovery.NewFuncValidator(model.NewConditionalityChecker())
+	testGenerator := generation.NewGenerator()
+	tlsValidator := discovery.NewStdTLSValidator(s.minTLSVersion)
+	journey := server.NewJourney(s.logger, testGenerator, validatorEngine, tlsValidator, false)
+
+	runner := server.NewHeadlessRunner(journey, s.logger)
+	results, err := runner.Run(discoveryModel, config, request, report)
+	if err != nil {
+		return nil, err
+	}
+	if err := report.Close(); err != nil {
+		return nil, errors.Wrap(err, "export report")
+	}
+	if err := os.Rename(report.Name(), s.reportFile); err != nil {
+		return nil, errors.Wrap(err, "export report")
+	}
+
+	testCases := make([]client.TestCase, 0, len(results))
+	for _, result := range results {
+		testCases = append(testCases, client.TestCase{
+			Id:         result.Id,
+			Pass:       result.Pass,
+			Fail:       result.Fail,
+			Detail:     result.Detail,
+			RefURI:     result.RefURI,
+			Endpoint:   result.Endpoint,
+			HttpStatus: result.HttpStatus,
+			Metrics: client.Metrics{
+				ResponseTime: float64(result.Metrics.ResponseTime) / float64(time.Millisecond),
+				ResponseSize: result.Metrics.ResponseSize,
+				Attempts:     result.Metrics.Attempts,
+			},
+			API:        result.API,
+			APIVersion: result.APIVersion,
+			Skipped:    result.Skipped,
+		})
+	}
+	return testCases, nil
+}
+
+func readJSONFile(filename string, v interface{}) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return json.NewDecoder(file).Decode(v)
+}
@@ -1,8 +1,8 @@
 package main
 
 import (
-	"github.com/OpenBankingUK/conformance-suite/pkg/client"
 	"fmt"
+	"github.com/OpenBankingUK/conformance-suite/pkg/client"
 	"os"
 
 	os2 "github.com/OpenBankingUK/conformance-suite/pkg/os"
@@ -15,14 +15,15 @@ const (
 )
 
 func main() {
-	fmt.Println("Functional Conformance Suite CLI")
+	// results may be written to standard output so everything else goes to standard error
+	fmt.Fprintln(os.Stderr, "Functional Conformance Suite CLI")
 
 	insecureConn, err := client.NewConnection()
 	if err == client.ErrInsecure {
-		fmt.Println("server's certificate chain and host name not verified")
+		fmt.Fprintln(os.Stderr, "server's certificate chain and host name not verified")
 	} else if err != nil {
-		fmt.Println(err.Error())
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(exitRunError)
 	}
 	service := client.NewService(
 		os2.GetEnvOrDefault("FCS_HOST", defaultHostServer),
@@ -32,18 +33,26 @@ func main() {
 
 	rootCmd := newRootCommand(service)
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, err)
+		if exitErr, ok := err.(exitError); ok {
+			os.Exit(exitErr.code)
+		}
+		os.Exit(exitRunError)
 	}
+	os.Exit(exitSuccess)
 }
 
 func newRootCommand(service client.Service) *cobra.Command {
 	rootCmd := &cobra.Command{
-		Use:   "fcs",
-		Short: "Functional Conformance Suite CLI",
-		Long:  `To use with pipelines and reproducible test runs`,
+		Use:           "fcs",
+		Short:         "Functional Conformance Suite CLI",
+		Long:          `To use with pipelines and reproducible test runs`,
+		SilenceErrors: true,
 	}
 	rootCmd.AddCommand(runCmd(service))
 	rootCmd.AddCommand(versionCmd(service))
+	rootCmd.AddCommand(diffCmd())
+	rootCmd.AddCommand(verifyCmd())
+	rootCmd.AddCommand(mockASPSPCmd())
 	return rootCmd
 }
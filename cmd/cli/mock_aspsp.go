@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/mockaspsp"
+	os2 "github.com/OpenBankingUK/conformance-suite/pkg/os"
+)
+
+const defaultMockASPSPAddress = "127.0.0.1:8450"
+
+func mockASPSPCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mock-aspsp",
+		Short: "Serve a mock ASPSP from the bundled OpenAPI specs",
+		Long: `Serve the Account Info, Payment Initiation, Confirmation of Funds and VRP APIs of the bundled
+OpenAPI specs with an OpenID configuration, token endpoint, JWKS and headless consent, so the
+functional conformance journey can run offline. Responses are signed with x-jws-signature.`,
+		RunE:         mockASPSP,
+		SilenceUsage: true,
+	}
+	cmd.Flags().String("address", defaultMockASPSPAddress, "Address to listen on")
+	cmd.Flags().String("base-url", "", "URL the mock is reached at, defaults to https://<address>")
+	cmd.Flags().String("api-version", mockaspsp.DefaultVersion, "Version of the APIs served")
+	cmd.Flags().String("org-id", mockaspsp.DefaultOrgID, "Organisation ID the responses are signed as")
+	cmd.Flags().String("faults", "", "Fault profile filename, the faults injected in the responses")
+	cmd.Flags().String("signing-cert", "", "File the PEM encoded certificate responses are signed with is written to, for the suite to trust")
+	cmd.Flags().Bool("insecure-http", false, "Serve plain HTTP instead of HTTPS with a self-signed certificate")
+	return cmd
+}
+
+// mockASPSP serves the mock ASPSP until the process is stopped
+func mockASPSP(cmd *cobra.Command, _ []string) error {
+	addressFlag, err := cmd.Flags().GetString("address")
+	if err != nil || addressFlag == "" {
+		return newRunError("You need to provide an address to listen on.")
+	}
+	host, _, err := net.SplitHostPort(addressFlag)
+	if err != nil {
+		return newRunError("Invalid address %s: %s", addressFlag, err.Error())
+	}
+
+	insecureFlag, err := cmd.Flags().GetBool("insecure-http")
+	if err != nil {
+		return newRunError("%s", err.Error())
+	}
+	scheme := "https"
+	if insecureFlag {
+		scheme = "http"
+	}
+
+	baseURLFlag, err := cmd.Flags().GetString("base-url")
+	if err != nil {
+		return newRunError("%s", err.Error())
+	}
+	if baseURLFlag == "" {
+		baseURLFlag = scheme + "://" + addressFlag
+	}
+
+	config := mockaspsp.Config{BaseURL: baseURLFlag}
+	if config.Version, err = cmd.Flags().GetString("api-version"); err != nil {
+		return newRunError("%s", err.Error())
+	}
+	if config.OrgID, err = cmd.Flags().GetString("org-id"); err != nil {
+		return newRunError("%s", err.Error())
+	}
+	faultsFlag, err := cmd.Flags().GetString("faults")
+	if err != nil {
+		return newRunError("%s", err.Error())
+	}
+	if faultsFlag != "" {
+		if config.Faults, err = mockaspsp.LoadFaultProfile(faultsFlag); err != nil {
+			return newRunError("%s", err.Error())
+		}
+	}
+
+	logger := logrus.StandardLogger()
+	if level, err := logrus.ParseLevel(os2.GetEnvOrDefault("FCS_LOG_LEVEL", "INFO")); err == nil {
+		logger.SetLevel(level)
+	}
+
+	mock, err := mockaspsp.NewServer(config, logger.WithField("app", "cli"))
+	if err != nil {
+		return newRunError("%s", err.Error())
+	}
+
+	signingCertFlag, err := cmd.Flags().GetString("signing-cert")
+	if err != nil {
+		return newRunError("%s", err.Error())
+	}
+	if signingCertFlag != "" {
+		if err := ioutil.WriteFile(signingCertFlag, mock.SigningCertificatePEM(), 0644); err != nil {
+			return newRunError("%s", err.Error())
+		}
+	}
+
+	server := &http.Server{Addr: addressFlag, Handler: mock}
+	fmt.Fprintf(os.Stderr, "Mock ASPSP listening on %s, OpenID configuration at %s\n", addressFlag, mock.OpenIDConfigurationURL())
+	for name, resourceURL := range mock.ResourceBaseURLs() {
+		fmt.Fprintf(os.Stderr, "  %s: %s\n", name, resourceURL)
+	}
+
+	if insecureFlag {
+		err = server.ListenAndServe()
+	} else {
+		hosts := []string{"localhost", "127.0.0.1"}
+		if baseURL, errParse := url.Parse(baseURLFlag); errParse == nil && baseURL.Hostname() != "" {
+			hosts = append(hosts, baseURL.Hostname())
+		}
+		if host != "" {
+			hosts = append(hosts, host)
+		}
+		certificate, errCert := mockaspsp.NewTLSCertificate(hosts)
+		if errCert != nil {
+			return newRunError("%s", errCert.Error())
+		}
+		server.TLSConfig = config.Faults.TLSConfig(certificate)
+		err = server.ListenAndServeTLS("", "")
+	}
+	return newRunError("%s", err.Error())
+}
@@ -1,52 +1,165 @@
 package main
 
 import (
-	"github.com/OpenBankingUK/conformance-suite/pkg/client"
 	"fmt"
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
+	"github.com/OpenBankingUK/conformance-suite/pkg/client"
+	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+	"github.com/OpenBankingUK/conformance-suite/pkg/server/models"
 	"github.com/spf13/cobra"
 	"os"
+	"strings"
 )
 
 func runCmd(service client.Service) *cobra.Command {
 	generatorCmd := &cobra.Command{
 		Use:   "run",
 		Short: "Run test cases from a discovery model",
-		Long:  "Run test cases will output to standard output.",
-		Run:   run(service),
+		Long: `Run test cases will output to standard output.
+
+Exit codes:
+  0 - run completed and test failures are within the allowed thresholds
+  1 - run completed but test failures exceed the allowed thresholds
+  2 - run could not complete`,
+		RunE:         run(service),
+		SilenceUsage: true,
 	}
 	generatorCmd.Flags().StringP("filename", "f", "", "Discovery filename")
 	generatorCmd.Flags().StringP("config", "c", "", "Config filename")
 	generatorCmd.Flags().StringP("export", "e", "", "Export config filename")
+	generatorCmd.Flags().BoolP("local", "l", false, "Run in-process without a running fcs_server")
+	generatorCmd.Flags().StringP("report", "r", "report.zip", "Report filename, used when running with --local")
+	generatorCmd.Flags().String("report-format", "", "Report format, one of "+models.ExportFormatZIP+"|"+models.ExportFormatHTML+", overrides the export config format, used when running with --local")
+	generatorCmd.Flags().String("tls-min-version", "TLS11", "Minimum TLS version resource servers must require, one of TLS10, TLS11, TLS12 or TLS13, used when running with --local")
+	generatorCmd.Flags().StringSlice("jws-trust-anchor-certs", nil, "Root certificates response signing certificates of a trust anchor may chain to, as <tan>=<PEM file>, can be repeated, used when running with --local")
+	generatorCmd.Flags().String("format", client.FormatText, "Results format, one of "+strings.Join(client.Formats(), "|"))
+	generatorCmd.Flags().StringP("output", "o", "", "Results filename, defaults to standard output")
+	generatorCmd.Flags().Int("max-failures", 0, "Number of test failures tolerated before the run fails")
+	generatorCmd.Flags().StringSlice("fail-on-api", nil, "Only count failures from APIs whose name contains this value, can be repeated")
+	generatorCmd.Flags().StringSlice("allow-fail", nil, "Test ID glob whose failures are not counted, can be repeated")
 	return generatorCmd
 }
 
 // run runs the functional conformance workflow to generate test case run report
-func run(service client.Service) func(cmd *cobra.Command, _ []string) {
-	return func(cmd *cobra.Command, _ []string) {
+func run(service client.Service) func(cmd *cobra.Command, _ []string) error {
+	return func(cmd *cobra.Command, _ []string) error {
 		filenameFlag, err := cmd.Flags().GetString("filename")
 		if err != nil || filenameFlag == "" {
-			fmt.Println("You need to provide a discovery filename.")
-			return
+			return newRunError("You need to provide a discovery filename.")
 		}
 
 		configFlag, err := cmd.Flags().GetString("config")
-		if err != nil || filenameFlag == "" {
-			fmt.Println("You need to provide a config filename.")
-			return
+		if err != nil || configFlag == "" {
+			return newRunError("You need to provide a config filename.")
 		}
 
 		exportFlag, err := cmd.Flags().GetString("export")
-		if err != nil || filenameFlag == "" {
-			fmt.Println("You need to provide a export config filename.")
-			return
+		if err != nil || exportFlag == "" {
+			return newRunError("You need to provide a export config filename.")
+		}
+
+		formatFlag, err := cmd.Flags().GetString("format")
+		if err != nil || !client.IsSupportedFormat(formatFlag) {
+			return newRunError("You need to provide a results format, one of %s.", strings.Join(client.Formats(), ", "))
+		}
+
+		outputFlag, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return newRunError("%s", err.Error())
+		}
+
+		policy, err := failurePolicy(cmd)
+		if err != nil {
+			return newRunError("%s", err.Error())
+		}
+
+		localFlag, err := cmd.Flags().GetBool("local")
+		if err != nil {
+			return newRunError("%s", err.Error())
+		}
+
+		runService := service
+		if localFlag {
+			reportFlag, err := cmd.Flags().GetString("report")
+			if err != nil || reportFlag == "" {
+				return newRunError("You need to provide a report filename.")
+			}
+			reportFormatFlag, err := cmd.Flags().GetString("report-format")
+			if err != nil || (reportFormatFlag != "" && reportFormatFlag != models.ExportFormatZIP && reportFormatFlag != models.ExportFormatHTML) {
+				return newRunError("You need to provide a report format, one of %s, %s.", models.ExportFormatZIP, models.ExportFormatHTML)
+			}
+			if reportFormatFlag == models.ExportFormatHTML && !cmd.Flags().Changed("report") {
+				reportFlag = "report.html"
+			}
+			tlsMinVersionFlag, err := cmd.Flags().GetString("tls-min-version")
+			if err != nil {
+				return newRunError("%s", err.Error())
+			}
+			minTLSVersion, err := discovery.ParseTLSVersion(tlsMinVersionFlag)
+			if err != nil {
+				return newRunError("%s", err.Error())
+			}
+			trustAnchorCertsFlag, err := cmd.Flags().GetStringSlice("jws-trust-anchor-certs")
+			if err != nil {
+				return newRunError("%s", err.Error())
+			}
+			if err := authentication.AddTrustAnchorRootFiles(trustAnchorCertsFlag); err != nil {
+				return newRunError("%s", err.Error())
+			}
+			runService = newLocalService(reportFlag, reportFormatFlag, minTLSVersion)
 		}
 
-		results, err := service.Run(filenameFlag, configFlag, exportFlag)
+		results, err := runService.Run(filenameFlag, configFlag, exportFlag)
 		if err != nil {
-			fmt.Printf("Error running tests: %s\n", err.Error())
-			return
+			return newRunError("Error running tests: %s", err.Error())
 		}
 
-		client.ResultWriter(os.Stdout, results)
+		output := os.Stdout
+		if outputFlag != "" {
+			output, err = os.Create(outputFlag)
+			if err != nil {
+				return newRunError("Error creating results file: %s", err.Error())
+			}
+			defer output.Close()
+		}
+
+		if err := client.WriteResults(output, formatFlag, results); err != nil {
+			return newRunError("Error writing results: %s", err.Error())
+		}
+
+		policyResult, err := policy.Evaluate(results)
+		if err != nil {
+			return newRunError("%s", err.Error())
+		}
+		if !policyResult.Passed {
+			return newTestFailuresError("Conformance failed: %s, %d allowed by --max-failures", policyResult, policy.MaxFailures)
+		}
+
+		fmt.Fprintf(os.Stderr, "Conformance passed: %s\n", policyResult)
+		return nil
+	}
+}
+
+func failurePolicy(cmd *cobra.Command) (client.FailurePolicy, error) {
+	maxFailures, err := cmd.Flags().GetInt("max-failures")
+	if err != nil {
+		return client.FailurePolicy{}, err
+	}
+
+	failOnAPIs, err := cmd.Flags().GetStringSlice("fail-on-api")
+	if err != nil {
+		return client.FailurePolicy{}, err
+	}
+
+	allowFail, err := cmd.Flags().GetStringSlice("allow-fail")
+	if err != nil {
+		return client.FailurePolicy{}, err
+	}
+
+	policy := client.FailurePolicy{
+		MaxFailures: maxFailures,
+		FailOnAPIs:  failOnAPIs,
+		AllowFail:   allowFail,
 	}
+	return policy, policy.Validate()
 }
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/report"
+	"github.com/spf13/cobra"
+)
+
+func verifyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify report.zip",
+		Short: "Verify the signature of an exported report",
+		Long: `Verify the signature.json of a report exported with a digital signature: the PS256 JWT is signed
+by the key of its x5c certificate, the certificate is the --cert certificate and the JWT is not expired,
+and the digests of the report, the discovery model and the other files of the archive match.
+
+The x5c certificate ships inside the archive, so --cert is required: without it anyone could edit the
+report and sign it again with a certificate of their own.
+
+Exit codes:
+  0 - the report signature is valid
+  1 - the report is not signed, is signed by an untrusted certificate or its signature is not valid
+  2 - the report or the certificate could not be opened`,
+		Args:         cobra.ExactArgs(1),
+		RunE:         verify,
+		SilenceUsage: true,
+	}
+	cmd.Flags().String("cert", "", "PEM encoded certificate the report must be signed with (required)")
+	return cmd
+}
+
+// verify verifies the signature of the report exported to the ZIP archive given as argument
+func verify(cmd *cobra.Command, args []string) error {
+	certFlag, err := cmd.Flags().GetString("cert")
+	if err != nil {
+		return newRunError("%s", err.Error())
+	}
+	if certFlag == "" {
+		return newRunError("--cert is required, the report signature cannot be trusted without the certificate it must be signed with")
+	}
+	trusted, err := readCertificateFile(certFlag)
+	if err != nil {
+		return newRunError("%s: %s", certFlag, err.Error())
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		return newRunError("%s", err.Error())
+	}
+	defer file.Close()
+
+	certificate, err := report.VerifyZip(file, trusted)
+	if err != nil {
+		return newTestFailuresError("%s: %s", args[0], err.Error())
+	}
+	fmt.Printf("%s: signature valid, signed by %s\n", args[0], certificate.Subject)
+	return nil
+}
+
+func readCertificateFile(filename string) (*x509.Certificate, error) {
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(contents)
+	if block == nil {
+		return nil, fmt.Errorf("certificate is not PEM encoded")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
@@ -1,17 +1,21 @@
 package main
 
 import (
-	"crypto/tls"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
 	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
 	"github.com/OpenBankingUK/conformance-suite/pkg/generation"
 	"github.com/OpenBankingUK/conformance-suite/pkg/manifest"
+	"github.com/OpenBankingUK/conformance-suite/pkg/report"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/permissions"
+	"github.com/OpenBankingUK/conformance-suite/pkg/runs"
 	"github.com/OpenBankingUK/conformance-suite/pkg/server"
 	"github.com/OpenBankingUK/conformance-suite/pkg/tracer"
 	"github.com/OpenBankingUK/conformance-suite/pkg/version"
@@ -44,12 +48,55 @@ Complete documentation is available at https://github.com/OpenBankingUK/conforma
 
 			printVersionInfo(ver, logger)
 
-			validatorEngine := discovery.NewFuncValidator(model.NewConditionalityChecker())
-			testGenerator := generation.NewGenerator()
-			tlsValidator := discovery.NewStdTLSValidator(tls.VersionTLS11)
-			journey := server.NewJourney(logger, testGenerator, validatorEngine, tlsValidator, viper.GetBool("dynres"))
+			minTLSVersion, err := discovery.ParseTLSVersion(viper.GetString("tls_min_version"))
+			if err != nil {
+				return err
+			}
 
-			echoServer := server.NewServer(journey, logger, ver)
+			consentResolverMode, err := permissions.ParseResolverMode(viper.GetString("consent_resolver"))
+			if err != nil {
+				return err
+			}
+
+			if err := authentication.AddTrustAnchorRootFiles(viper.GetStringSlice("jws_trust_anchor_certs")); err != nil {
+				return errors.Wrap(err, "jws_trust_anchor_certs")
+			}
+
+			if signingKeyFile := viper.GetString("report_signing_key"); signingKeyFile != "" {
+				signer, err := newReportSigner(signingKeyFile, viper.GetString("report_signing_cert"))
+				if err != nil {
+					return err
+				}
+				server.SetReportSigner(signer)
+			}
+
+			runStore := runs.NewMemoryStore()
+			if runsDB := viper.GetString("runs_db"); runsDB != "" {
+				store, err := runs.NewBoltStore(runsDB)
+				if err != nil {
+					return errors.Wrap(err, "runs_db, set it to an empty string to keep runs in memory")
+				}
+				defer store.Close()
+				runStore = store
+			}
+
+			newJourney := func() server.Journey {
+				validatorEngine := discovery.NewFuncValidator(model.NewConditionalityChecker())
+				testGenerator := generation.NewGenerator()
+				tlsValidator := discovery.NewStdTLSValidator(minTLSVersion)
+				journey := server.NewJourney(logger, testGenerator, validatorEngine, tlsValidator, viper.GetBool("dynres"))
+				journey.SetRunStore(runStore)
+				journey.SetDefaultConsentResolver(consentResolverMode)
+				return journey
+			}
+
+			var echoServer *server.Server
+			if viper.GetBool("sessions") {
+				sessions := server.NewSessions(newJourney, viper.GetDuration("session_ttl"), viper.GetInt("max_sessions"), logger)
+				echoServer = server.NewSessionServer(sessions, logger, ver)
+			} else {
+				echoServer = server.NewServer(newJourney(), logger, ver)
+			}
 			address := fmt.Sprintf("%s:%d", server.ListenHost, viper.GetInt("port"))
 			logger.Infof("listening on https://%s", address)
 			return echoServer.StartTLS(address, certFile, keyFile)
@@ -57,6 +104,19 @@ Complete documentation is available at https://github.com/OpenBankingUK/conforma
 	}
 )
 
+// newReportSigner reads the report signing key and its certificate from PEM encoded files
+func newReportSigner(signingKeyFile, signingCertFile string) (*report.Signer, error) {
+	privateKeyPem, err := ioutil.ReadFile(signingKeyFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "report_signing_key")
+	}
+	certificatePem, err := ioutil.ReadFile(signingCertFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "report_signing_cert")
+	}
+	return report.NewSigner(privateKeyPem, certificatePem)
+}
+
 func printVersionInfo(ver version.GitHub, logger *logrus.Entry) {
 	v, err := ver.VersionFormatter(version.FullVersion)
 	if err != nil {
@@ -88,7 +148,16 @@ func init() {
 	rootCmd.PersistentFlags().Bool("dynres", false, "Use Dynamic Resource IDs - accounts")
 	rootCmd.PersistentFlags().Bool("dumpcontexts", false, "Dump contexts when trace enabled")
 	rootCmd.PersistentFlags().Bool("tlscheck", true, "enable tls version checking - default enabled")
+	rootCmd.PersistentFlags().String("report_signing_key", "", "PEM encoded RSA private key file reports exported with a digital signature are signed with")
+	rootCmd.PersistentFlags().String("report_signing_cert", "", "PEM encoded certificate file of the report signing key")
+	rootCmd.PersistentFlags().StringSlice("jws_trust_anchor_certs", nil, "Root certificates response signing certificates of a trust anchor may chain to, as <tan>=<PEM file>, the OB directory roots are trusted for openbanking.org.uk")
+	rootCmd.PersistentFlags().String("tls_min_version", "TLS11", "Minimum TLS version resource servers must require, one of TLS10, TLS11, TLS12 or TLS13")
+	rootCmd.PersistentFlags().String("consent_resolver", string(permissions.ResolverModeGreedy), "How account test cases are grouped into consents when the configuration has no consent_resolver, one of greedy, heuristic or exact - heuristic and exact minimise the consents to authorise")
 	rootCmd.PersistentFlags().Bool("export_testcases", false, "Dump all testcases to console in CSV format")
+	rootCmd.PersistentFlags().String("runs_db", "runs.db", "File the history of runs is stored in, runs are kept in memory when empty")
+	rootCmd.PersistentFlags().Bool("sessions", false, "Give each user of a shared server their own session")
+	rootCmd.PersistentFlags().Duration("session_ttl", server.DefaultSessionTTL, "Time after which an unused session expires")
+	rootCmd.PersistentFlags().Int("max_sessions", server.DefaultMaxSessions, "Number of sessions that can be live at once, requests starting a session beyond it are rejected")
 
 	if err := viper.BindPFlags(rootCmd.PersistentFlags()); err != nil {
 		fmt.Fprint(os.Stderr, err)
@@ -174,17 +243,26 @@ func initConfig() {
 
 func printConfigurationFlags() {
 	logger.WithFields(logrus.Fields{
-		"log_level":        viper.GetString("log_level"),
-		"log_tracer":       viper.GetBool("log_tracer"),
-		"log_http_trace":   viper.GetBool("log_http_trace"),
-		"log_http_file":    viper.GetBool("log_http_file"),
-		"log_to_file":      viper.GetBool("log_to_file"),
-		"port":             viper.GetInt("port"),
-		"tracer.Silent":    tracer.Silent,
-		"disable_jws":      viper.GetBool("disable_jws"),
-		"dynres":           viper.GetBool("dynres"),
-		"dumpcontexts":     viper.GetBool("dumpcontexts"),
-		"tlscheck":         viper.GetBool("tlscheck"),
-		"export_testcases": viper.GetString("export_testcases"),
+		"log_level":              viper.GetString("log_level"),
+		"log_tracer":             viper.GetBool("log_tracer"),
+		"log_http_trace":         viper.GetBool("log_http_trace"),
+		"log_http_file":          viper.GetBool("log_http_file"),
+		"log_to_file":            viper.GetBool("log_to_file"),
+		"port":                   viper.GetInt("port"),
+		"tracer.Silent":          tracer.Silent,
+		"disable_jws":            viper.GetBool("disable_jws"),
+		"dynres":                 viper.GetBool("dynres"),
+		"dumpcontexts":           viper.GetBool("dumpcontexts"),
+		"tlscheck":               viper.GetBool("tlscheck"),
+		"tls_min_version":        viper.GetString("tls_min_version"),
+		"jws_trust_anchor_certs": viper.GetStringSlice("jws_trust_anchor_certs"),
+		"consent_resolver":       viper.GetString("consent_resolver"),
+		"report_signing_key":     viper.GetString("report_signing_key"),
+		"report_signing_cert":    viper.GetString("report_signing_cert"),
+		"export_testcases":       viper.GetString("export_testcases"),
+		"runs_db":                viper.GetString("runs_db"),
+		"sessions":               viper.GetBool("sessions"),
+		"session_ttl":            viper.GetDuration("session_ttl"),
+		"max_sessions":           viper.GetInt("max_sessions"),
 	}).Info("configuration flags")
 }
@@ -17,7 +17,9 @@ import (
 const (
 	TlsClientAuth     = "tls_client_auth"
 	PrivateKeyJwt     = "private_key_jwt"
+	ClientSecretJwt   = "client_secret_jwt"
 	ClientSecretBasic = "client_secret_basic"
+	ClientSecretPost  = "client_secret_post"
 )
 
 // SuiteSupportedAuthMethodsMostSecureFirst -
@@ -27,7 +29,9 @@ func SuiteSupportedAuthMethodsMostSecureFirst() []string {
 	return []string{
 		TlsClientAuth,
 		PrivateKeyJwt,
+		ClientSecretJwt,
 		ClientSecretBasic,
+		ClientSecretPost,
 	}
 }
 
@@ -0,0 +1,72 @@
+package authentication
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultCacheMaxAge - how long OpenID configurations and JWKS are cached when their response has neither
+	// a `Cache-Control` max-age nor an `Expires` header
+	DefaultCacheMaxAge = 5 * time.Minute
+)
+
+// httpStatusError - a document could not be fetched as the response was not 200 OK
+type httpStatusError struct {
+	url        string
+	statusCode int
+	body       []byte
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("GET %s: status code %d", e.url, e.statusCode)
+}
+
+// fetchDocument GETs a document and returns its body with the time it may be cached until
+func fetchDocument(client *http.Client, url string, now time.Time) ([]byte, time.Time, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, time.Time{}, &httpStatusError{url: url, statusCode: resp.StatusCode, body: body}
+	}
+	return body, cacheExpiry(resp.Header, now), nil
+}
+
+// cacheExpiry returns the time a response may be cached until: not at all with `no-store` or `no-cache`,
+// `max-age` seconds, `Expires` or the default max age
+// https://tools.ietf.org/html/rfc7234#section-5.2.2
+func cacheExpiry(header http.Header, now time.Time) time.Time {
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		switch {
+		case directive == "no-store" || directive == "no-cache":
+			return now
+		case strings.HasPrefix(directive, "max-age="):
+			seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age="))
+			if err != nil || seconds < 0 {
+				return now
+			}
+			return now.Add(time.Duration(seconds) * time.Second)
+		}
+	}
+	if expires := header.Get("Expires"); expires != "" {
+		expiresAt, err := http.ParseTime(expires)
+		if err != nil || expiresAt.Before(now) {
+			return now
+		}
+		return expiresAt
+	}
+	return now.Add(DefaultCacheMaxAge)
+}
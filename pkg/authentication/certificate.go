@@ -2,6 +2,9 @@ package authentication
 
 import (
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
@@ -10,15 +13,18 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"reflect"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/sirupsen/logrus"
 )
 
 // Certificate - create new Certificate.
+// Keys are RSA (PS256/RS256), EC P-256 (ES256) or Ed25519 (EdDSA): `*rsa.PublicKey`, `*ecdsa.PublicKey` or
+// `ed25519.PublicKey` and the matching private key.
 type Certificate interface {
-	PublicKey() *rsa.PublicKey
-	PrivateKey() *rsa.PrivateKey
+	PublicKey() crypto.PublicKey
+	PrivateKey() crypto.PrivateKey
 	TLSCert() tls.Certificate
 	DN() (string, string, string, error)
 	SignatureIssuer(bool) (string, error)
@@ -26,8 +32,8 @@ type Certificate interface {
 
 // certificate implements Certificate
 type certificate struct {
-	publicKey     *rsa.PublicKey
-	privateKey    *rsa.PrivateKey
+	publicKey     crypto.PublicKey
+	privateKey    crypto.Signer
 	tlsCert       tls.Certificate
 	publicCertPem []byte
 }
@@ -40,13 +46,13 @@ type certificate struct {
 //
 // Returns Certificate, or nil with error set if something is invalid.
 func NewCertificate(publicKeyPem, privateKeyPem string) (Certificate, error) {
-	publicKey, err := jwt.ParseRSAPublicKeyFromPEM([]byte(publicKeyPem))
+	publicKey, err := parsePublicKeyFromPEM([]byte(publicKeyPem))
 	if err != nil {
 		return nil, fmt.Errorf("error with public key: %w", err)
 	}
 	publicPem := []byte(publicKeyPem)
 
-	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(privateKeyPem))
+	privateKey, err := parsePrivateKeyFromPEM([]byte(privateKeyPem))
 	if err != nil {
 		return nil, fmt.Errorf("error with private key: %w", err)
 	}
@@ -70,7 +76,7 @@ func NewCertificate(publicKeyPem, privateKeyPem string) (Certificate, error) {
 
 // creates a certificate from only the public key, in the case of the aspsp public cert to validate signatures
 func NewPublicCertificate(publicKeyPem string) (Certificate, error) {
-	publicKey, err := jwt.ParseRSAPublicKeyFromPEM([]byte(publicKeyPem))
+	publicKey, err := parsePublicKeyFromPEM([]byte(publicKeyPem))
 	if err != nil {
 		return nil, fmt.Errorf("error with public key: %w", err)
 	}
@@ -82,11 +88,14 @@ func NewPublicCertificate(publicKeyPem string) (Certificate, error) {
 	}, nil
 }
 
-func (c certificate) PublicKey() *rsa.PublicKey {
+func (c certificate) PublicKey() crypto.PublicKey {
 	return c.publicKey
 }
 
-func (c certificate) PrivateKey() *rsa.PrivateKey {
+func (c certificate) PrivateKey() crypto.PrivateKey {
+	if c.privateKey == nil {
+		return nil
+	}
 	return c.privateKey
 }
 
@@ -94,21 +103,101 @@ func (c certificate) TLSCert() tls.Certificate {
 	return c.tlsCert
 }
 
-func validateKeys(publicKey *rsa.PublicKey, privateKey *rsa.PrivateKey) error {
+// parsePublicKeyFromPEM parses the public key of a PEM encoded certificate or public key
+func parsePublicKeyFromPEM(publicKeyPem []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(publicKeyPem)
+	if block == nil {
+		return nil, jwt.ErrKeyMustBePEMEncoded
+	}
+
+	var publicKey crypto.PublicKey
+	if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+		publicKey = cert.PublicKey
+	} else if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		publicKey = key
+	} else if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		publicKey = key
+	} else {
+		return nil, errors.New("not a certificate or a public key")
+	}
+	return publicKey, checkKeyType(publicKey)
+}
+
+// parsePrivateKeyFromPEM parses a PEM encoded PKCS #1, PKCS #8 or SEC 1 private key
+func parsePrivateKeyFromPEM(privateKeyPem []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(privateKeyPem)
+	if block == nil {
+		return nil, jwt.ErrKeyMustBePEMEncoded
+	}
+
+	var privateKey crypto.Signer
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		privateKey = key
+	} else if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported key type %T", key)
+		}
+		privateKey = signer
+	} else if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		privateKey = key
+	} else {
+		return nil, errors.New("not a PKCS #1, PKCS #8 or SEC 1 private key")
+	}
+	return privateKey, checkKeyType(privateKey.Public())
+}
+
+// checkKeyType accepts RSA, EC P-256 and Ed25519 public keys
+func checkKeyType(publicKey crypto.PublicKey) error {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey, ed25519.PublicKey:
+		return nil
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return fmt.Errorf("unsupported EC curve %s, only P-256 is supported", key.Curve.Params().Name)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported key type %T", publicKey)
+	}
+}
+
+func validateKeys(publicKey crypto.PublicKey, privateKey crypto.Signer) error {
 	// validate public and private key pair
 	// see:
 	// * https://stackoverflow.com/questions/20655702/signing-and-decoding-with-rsa-sha-in-go
 	// * http://play.golang.org/p/bzpD7Pa9mr
+	if reflect.TypeOf(publicKey) != reflect.TypeOf(privateKey.Public()) {
+		return fmt.Errorf("public key type %T does not match private key type %T", publicKey, privateKey.Public())
+	}
 	plaintext := []byte(`date: Thu, 05 Jan 2012 21:31:40 GMT`)
 
 	hashed := sha256.Sum256(plaintext)
-	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, hashed[:])
-	if err != nil {
-		return fmt.Errorf("error signing: %w", err)
-	}
-
-	if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, hashed[:], signature); err != nil {
-		return fmt.Errorf("error verifying: %w", err)
+	switch publicKey := publicKey.(type) {
+	case *rsa.PublicKey:
+		signature, err := privateKey.Sign(rand.Reader, hashed[:], crypto.SHA256)
+		if err != nil {
+			return fmt.Errorf("error signing: %w", err)
+		}
+		if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, hashed[:], signature); err != nil {
+			return fmt.Errorf("error verifying: %w", err)
+		}
+	case *ecdsa.PublicKey:
+		signature, err := privateKey.Sign(rand.Reader, hashed[:], crypto.SHA256)
+		if err != nil {
+			return fmt.Errorf("error signing: %w", err)
+		}
+		if !ecdsa.VerifyASN1(publicKey, hashed[:], signature) {
+			return errors.New("error verifying: ecdsa: verification error")
+		}
+	case ed25519.PublicKey:
+		signature, err := privateKey.Sign(rand.Reader, plaintext, crypto.Hash(0))
+		if err != nil {
+			return fmt.Errorf("error signing: %w", err)
+		}
+		if !ed25519.Verify(publicKey, plaintext, signature) {
+			return errors.New("error verifying: ed25519: verification error")
+		}
 	}
 
 	return nil
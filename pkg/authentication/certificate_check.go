@@ -0,0 +1,245 @@
+package authentication
+
+import (
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/client"
+)
+
+// Usages of the certificates of a configuration
+const (
+	CertificateUsageSigning   = "signing"
+	CertificateUsageTransport = "transport"
+)
+
+// Names of the pre-flight checks of a certificate
+const (
+	CertificateCheckCertificate = "certificate"
+	CertificateCheckKeyPair     = "keyPair"
+	CertificateCheckExpiry      = "expiry"
+	CertificateCheckKeyUsage    = "keyUsage"
+	CertificateCheckSubjectDN   = "subjectDN"
+	CertificateCheckKid         = "kid"
+	CertificateCheckJWKS        = "jwks"
+	CertificateCheckMTLS        = "mtlsHandshake"
+)
+
+// CertificateCheck - the outcome of one pre-flight check of the signing or the transport certificate
+type CertificateCheck struct {
+	Certificate string `json:"certificate"` // signing or transport
+	Name        string `json:"name"`
+	Pass        bool   `json:"pass"`
+	Detail      string `json:"detail,omitempty"`
+}
+
+func newCertificateCheck(usage, name string, err error, detail string) CertificateCheck {
+	if err != nil {
+		return CertificateCheck{Certificate: usage, Name: name, Detail: err.Error()}
+	}
+	return CertificateCheck{Certificate: usage, Name: name, Pass: true, Detail: detail}
+}
+
+// CheckCertificate checks the PEM encoded certificate and private key of a `usage` before a run: the certificate
+// parses, matches the key, is valid at `now`, has a key usage allowing it and an OB style subject DN.
+// Checks that depend on a failed check are not made.
+func CheckCertificate(usage, publicKeyPem, privateKeyPem string, now time.Time) []CertificateCheck {
+	cert, err := parseCertificatePEM(publicKeyPem)
+	checks := []CertificateCheck{newCertificateCheck(usage, CertificateCheckCertificate, err, "")}
+	if err != nil {
+		return checks
+	}
+
+	_, err = NewCertificate(publicKeyPem, privateKeyPem)
+	checks = append(checks, newCertificateCheck(usage, CertificateCheckKeyPair, err, ""))
+
+	expiryDetail := fmt.Sprintf("valid until %s", cert.NotAfter.Format(time.RFC3339))
+	return append(checks,
+		newCertificateCheck(usage, CertificateCheckExpiry, checkCertificateValidity(cert, now), expiryDetail),
+		newCertificateCheck(usage, CertificateCheckKeyUsage, checkCertificateKeyUsage(cert, usage), ""),
+		newCertificateCheck(usage, CertificateCheckSubjectDN, checkCertificateSubject(cert), cert.Subject.String()),
+	)
+}
+
+// CheckSigningKid checks the signing certificate has the key `kid` identifies: its key thumbprint must be
+// the kid and, when `jwksURI` is set, the key of the kid in the JWKS must be the certificate key.
+// Returns no checks when no kid is configured.
+func CheckSigningKid(publicKeyPem, kid, jwksURI string) []CertificateCheck {
+	if kid == "" {
+		return []CertificateCheck{}
+	}
+	publicKey, err := parsePublicKeyFromPEM([]byte(publicKeyPem))
+	if err != nil {
+		return []CertificateCheck{newCertificateCheck(CertificateUsageSigning, CertificateCheckKid, err, "")}
+	}
+	thumbprint, err := CalcKidFromPublicKey(publicKey)
+	if err == nil && thumbprint != kid {
+		err = fmt.Errorf("kid %s is not the thumbprint %s of the signing certificate key", kid, thumbprint)
+	}
+	checks := []CertificateCheck{newCertificateCheck(CertificateUsageSigning, CertificateCheckKid, err, "")}
+	if jwksURI == "" {
+		return checks
+	}
+
+	// a cache of its own so the keys of the TPP JWKS are not reported with the keys of a run
+	jwk, err := NewJWKSCache().key(kid, jwksURI)
+	if err == nil {
+		err = checkJWKMatchesKey(jwk, kid, jwksURI, publicKey)
+	}
+	return append(checks, newCertificateCheck(CertificateUsageSigning, CertificateCheckJWKS, err, jwksURI))
+}
+
+// CheckMTLSHandshake makes a request to `uri` with the transport certificate and checks the server asked for a
+// client certificate and accepted it. Any HTTP response, whatever its status, means the handshake succeeded.
+func CheckMTLSHandshake(publicKeyPem, privateKeyPem, uri string, timeout time.Duration) CertificateCheck {
+	tlsCert, err := tls.X509KeyPair([]byte(publicKeyPem), []byte(privateKeyPem))
+	if err != nil {
+		return newCertificateCheck(CertificateUsageTransport, CertificateCheckMTLS, err, "")
+	}
+	parsedURI, err := url.Parse(uri)
+	if err != nil || parsedURI.Scheme != "https" {
+		return newCertificateCheck(CertificateUsageTransport, CertificateCheckMTLS, fmt.Errorf("%s is not an https url", uri), "")
+	}
+
+	requested := false
+	httpClient := client.NewHTTPClientWithTransport(timeout, &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true,
+			GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				requested = true
+				return &tlsCert, nil
+			},
+		},
+	})
+	response, err := httpClient.Post(uri, "application/x-www-form-urlencoded", strings.NewReader(""))
+	if err != nil {
+		return newCertificateCheck(CertificateUsageTransport, CertificateCheckMTLS, fmt.Errorf("handshake with %s failed: %w", parsedURI.Host, err), "")
+	}
+	response.Body.Close()
+	if !requested {
+		err = fmt.Errorf("%s did not request a client certificate", parsedURI.Host)
+	}
+	return newCertificateCheck(CertificateUsageTransport, CertificateCheckMTLS, err, fmt.Sprintf("%s responded %s", parsedURI.Host, response.Status))
+}
+
+// parseCertificatePEM parses a PEM encoded certificate, a public key alone has no subject or validity
+func parseCertificatePEM(publicKeyPem string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(publicKeyPem))
+	if block == nil {
+		return nil, errors.New("certificate is not PEM encoded")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("not a certificate: %w", err)
+	}
+	return cert, nil
+}
+
+func checkCertificateValidity(cert *x509.Certificate, now time.Time) error {
+	if now.Before(cert.NotBefore) {
+		return fmt.Errorf("certificate is not valid before %s", cert.NotBefore.Format(time.RFC3339))
+	}
+	if now.After(cert.NotAfter) {
+		return fmt.Errorf("certificate expired at %s", cert.NotAfter.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// checkCertificateKeyUsage checks the key usages of a certificate allow it to sign or to authenticate a TLS
+// client, a certificate without key usages allows any usage
+func checkCertificateKeyUsage(cert *x509.Certificate, usage string) error {
+	if cert.KeyUsage != 0 && cert.KeyUsage&x509.KeyUsageDigitalSignature == 0 {
+		return fmt.Errorf("%s certificate key usage does not include digitalSignature", usage)
+	}
+	if usage != CertificateUsageTransport || len(cert.ExtKeyUsage) == 0 {
+		return nil
+	}
+	for _, extKeyUsage := range cert.ExtKeyUsage {
+		if extKeyUsage == x509.ExtKeyUsageClientAuth || extKeyUsage == x509.ExtKeyUsageAny {
+			return nil
+		}
+	}
+	return errors.New("transport certificate extended key usage does not include clientAuth")
+}
+
+// checkCertificateSubject checks the subject has the C, O, OU and CN of an OB directory certificate,
+// the OU is the organisation ID and the CN the software statement ID
+func checkCertificateSubject(cert *x509.Certificate) error {
+	missing := []string{}
+	if len(cert.Subject.Country) == 0 {
+		missing = append(missing, "C")
+	}
+	if len(cert.Subject.Organization) == 0 {
+		missing = append(missing, "O")
+	}
+	if len(cert.Subject.OrganizationalUnit) == 0 {
+		missing = append(missing, "OU")
+	}
+	if cert.Subject.CommonName == "" {
+		missing = append(missing, "CN")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("subject %q has no %s", cert.Subject, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// checkJWKMatchesKey checks the JWK of a kid is the public key of a certificate, from its x5c or its members
+func checkJWKMatchesKey(jwk JWK, kid, jwksURI string, publicKey crypto.PublicKey) error {
+	if jwk.Kid == "" {
+		return fmt.Errorf("kid %s not found in %s", kid, jwksURI)
+	}
+	want, err := CalcKidFromPublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	var got string
+	if len(jwk.X5c) > 0 {
+		raw, err := base64.StdEncoding.DecodeString(jwk.X5c[0])
+		if err != nil {
+			return fmt.Errorf("x5c of kid %s: %w", kid, err)
+		}
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return fmt.Errorf("x5c of kid %s: %w", kid, err)
+		}
+		got, err = CalcKidFromPublicKey(cert.PublicKey)
+		if err != nil {
+			return err
+		}
+	} else {
+		got, err = jwkThumbprint(jwk)
+		if err != nil {
+			return err
+		}
+	}
+	if got != want {
+		return fmt.Errorf("key of kid %s in %s is not the signing certificate key", kid, jwksURI)
+	}
+	return nil
+}
+
+// jwkThumbprint returns the SHA-1 thumbprint of the required members of a JWK, as CalcKidFromPublicKey
+func jwkThumbprint(jwk JWK) (string, error) {
+	switch jwk.Kty {
+	case "RSA":
+		return calcThumbprintKid(fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, jwk.E, jwk.N))
+	case "EC":
+		return calcThumbprintKid(fmt.Sprintf(`{"crv":"%s","kty":"EC","x":"%s","y":"%s"}`, jwk.Crv, jwk.X, jwk.Y))
+	case "OKP":
+		return calcThumbprintKid(fmt.Sprintf(`{"crv":"%s","kty":"OKP","x":"%s"}`, jwk.Crv, jwk.X))
+	default:
+		return "", fmt.Errorf("kid %s has no x5c and an unsupported key type %q", jwk.Kid, jwk.Kty)
+	}
+}
@@ -0,0 +1,179 @@
+package authentication
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+)
+
+// obCertificateTemplate - a certificate template with the subject and key usages of an OB directory transport certificate
+func obCertificateTemplate(notAfter time.Time) *x509.Certificate {
+	return &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject: pkix.Name{
+			Country:            []string{"GB"},
+			Organization:       []string{"OpenBanking"},
+			OrganizationalUnit: []string{"0015800001041RbAAI"},
+			CommonName:         "2cY2RN6R9o9pdFYmEAbnVy",
+		},
+		NotBefore:   notAfter.Add(-24 * time.Hour),
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+}
+
+func certificatePEM(t *testing.T, template *x509.Certificate, key crypto.Signer) (string, string) {
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKeyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKeyBytes}))
+}
+
+func failedCertificateChecks(checks []CertificateCheck) []string {
+	failed := []string{}
+	for _, check := range checks {
+		if !check.Pass {
+			failed = append(failed, check.Name)
+		}
+	}
+	return failed
+}
+
+func TestCheckCertificate(t *testing.T) {
+	require := test.NewRequire(t)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(err)
+	publicPem, privatePem := certificatePEM(t, obCertificateTemplate(time.Now().Add(time.Hour)), key)
+
+	checks := CheckCertificate(CertificateUsageTransport, publicPem, privatePem, time.Now())
+
+	names := []string{}
+	for _, check := range checks {
+		require.Equal(CertificateUsageTransport, check.Certificate)
+		names = append(names, check.Name)
+	}
+	require.Equal([]string{CertificateCheckCertificate, CertificateCheckKeyPair, CertificateCheckExpiry,
+		CertificateCheckKeyUsage, CertificateCheckSubjectDN}, names)
+	require.Empty(failedCertificateChecks(checks))
+	require.Equal("CN=2cY2RN6R9o9pdFYmEAbnVy,OU=0015800001041RbAAI,O=OpenBanking,C=GB", checks[4].Detail)
+}
+
+func TestCheckCertificateProblems(t *testing.T) {
+	require := test.NewRequire(t)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(err)
+
+	template := obCertificateTemplate(time.Now().Add(-time.Hour))
+	template.Subject = pkix.Name{CommonName: "2cY2RN6R9o9pdFYmEAbnVy"}
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	publicPem, _ := certificatePEM(t, template, key)
+	_, otherPrivatePem := certificatePEM(t, template, otherKey)
+
+	checks := CheckCertificate(CertificateUsageTransport, publicPem, otherPrivatePem, time.Now())
+
+	require.Equal([]string{CertificateCheckKeyPair, CertificateCheckExpiry, CertificateCheckKeyUsage, CertificateCheckSubjectDN},
+		failedCertificateChecks(checks))
+	require.Contains(checks[2].Detail, "certificate expired at")
+	require.Equal("transport certificate extended key usage does not include clientAuth", checks[3].Detail)
+	require.Equal(`subject "CN=2cY2RN6R9o9pdFYmEAbnVy" has no C, O, OU`, checks[4].Detail)
+
+	// the extended key usage of a signing certificate is not checked
+	checks = CheckCertificate(CertificateUsageSigning, publicPem, otherPrivatePem, time.Now())
+	require.Equal([]string{CertificateCheckKeyPair, CertificateCheckExpiry, CertificateCheckSubjectDN}, failedCertificateChecks(checks))
+
+	checks = CheckCertificate(CertificateUsageSigning, "", "", time.Now())
+	require.Equal([]CertificateCheck{{Certificate: CertificateUsageSigning, Name: CertificateCheckCertificate, Detail: "certificate is not PEM encoded"}}, checks)
+}
+
+func TestCheckSigningKid(t *testing.T) {
+	require := test.NewRequire(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(err)
+	publicPem, _ := certificatePEM(t, obCertificateTemplate(time.Now().Add(time.Hour)), key)
+	kid, err := CalcKidFromPublicKey(key.Public())
+	require.NoError(err)
+
+	require.Empty(CheckSigningKid(publicPem, "", ""))
+
+	checks := CheckSigningKid(publicPem, "another-kid", "")
+	require.Equal([]string{CertificateCheckKid}, failedCertificateChecks(checks))
+	require.Equal("kid another-kid is not the thumbprint "+kid+" of the signing certificate key", checks[0].Detail)
+
+	jwksKeyCache.Reset()
+	server := newSigningJWKSForKey(t, key, kid, verificationIssuer, time.Now().Add(time.Hour))
+	defer server.Close()
+	checks = CheckSigningKid(publicPem, kid, server.URL)
+	require.Len(checks, 2)
+	require.Empty(failedCertificateChecks(checks))
+	require.Equal(CertificateCheckJWKS, checks[1].Name)
+	require.Empty(jwksKeyCache.Keys())
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(err)
+	otherServer := newSigningJWKSForKey(t, otherKey, kid, verificationIssuer, time.Now().Add(time.Hour))
+	defer otherServer.Close()
+	checks = CheckSigningKid(publicPem, kid, otherServer.URL)
+	require.Equal([]string{CertificateCheckJWKS}, failedCertificateChecks(checks))
+	require.Equal("key of kid "+kid+" in "+otherServer.URL+" is not the signing certificate key", checks[1].Detail)
+
+	checks = CheckSigningKid(publicPem, kid, otherServer.URL+"/unknown")
+	require.Equal([]string{CertificateCheckJWKS}, failedCertificateChecks(checks))
+}
+
+func TestCheckMTLSHandshake(t *testing.T) {
+	require := test.NewRequire(t)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(err)
+	publicPem, privatePem := certificatePEM(t, obCertificateTemplate(time.Now().Add(time.Hour)), key)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	check := CheckMTLSHandshake(publicPem, privatePem, server.URL+"/token", time.Second)
+	require.True(check.Pass, check.Detail)
+	require.Equal(CertificateUsageTransport, check.Certificate)
+	require.Contains(check.Detail, "responded 400 Bad Request")
+
+	noClientAuthServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer noClientAuthServer.Close()
+	check = CheckMTLSHandshake(publicPem, privatePem, noClientAuthServer.URL, time.Second)
+	require.False(check.Pass)
+	require.Contains(check.Detail, "did not request a client certificate")
+
+	check = CheckMTLSHandshake(publicPem, privatePem, "http://localhost/token", time.Second)
+	require.Equal("http://localhost/token is not an https url", check.Detail)
+}
@@ -1,11 +1,18 @@
 package authentication
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/pem"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/test"
 )
@@ -192,7 +199,7 @@ yEh6Szz2in47Tv5n52m9dLYyPCbqZkOB5nTSqtscpkQD/HpykCggvx09iQ==
 	cert, err := NewCertificate(publicCert, privateCert)
 
 	require.Nil(cert)
-	require.EqualError(err, `error with public key: Key is not a valid RSA public key`)
+	require.EqualError(err, `public key type *ecdsa.PublicKey does not match private key type *rsa.PublicKey`)
 }
 
 func TestCertificateValidateInvalidPrivateKeyRSA(t *testing.T) {
@@ -207,5 +214,66 @@ VZ4E4JhiGX3fT07ucdN8nDXufQ8WplCX0nWFPh3P3Z7snLq2E3m8yqIQ
 	cert, err := NewCertificate(publicCert, privateCert)
 
 	require.Nil(cert)
-	require.EqualError(err, `error with private key: Key is not a valid RSA private key`)
+	require.EqualError(err, `public key type *rsa.PublicKey does not match private key type *ecdsa.PublicKey`)
+}
+
+// certificateKeyPair returns a PEM encoded self-signed certificate and PKCS #8 private key
+func certificateKeyPair(t *testing.T, key crypto.Signer) (string, string) {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{OrganizationalUnit: []string{"0015800001041RbAAI"}, CommonName: "2cY2RN6R9o9pdFYmEAbnVy"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKeyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKeyBytes}))
+}
+
+func TestCertificateValidateValidKeysECAndEd25519(t *testing.T) {
+	require := test.NewRequire(t)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(err)
+	publicCert, privateCert := certificateKeyPair(t, ecKey)
+	cert, err := NewCertificate(publicCert, privateCert)
+	require.NoError(err)
+	require.Equal(&ecKey.PublicKey, cert.PublicKey())
+	issuer, err := cert.SignatureIssuer(true)
+	require.NoError(err)
+	require.Equal("0015800001041RbAAI/2cY2RN6R9o9pdFYmEAbnVy", issuer)
+
+	// SEC 1 EC private keys are accepted too
+	ecPrivateKeyBytes, err := x509.MarshalECPrivateKey(ecKey)
+	require.NoError(err)
+	cert, err = NewCertificate(publicCert, string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecPrivateKeyBytes})))
+	require.NoError(err)
+	require.NotNil(cert)
+
+	edPublicKey, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(err)
+	publicCert, privateCert = certificateKeyPair(t, edKey)
+	cert, err = NewCertificate(publicCert, privateCert)
+	require.NoError(err)
+	require.Equal(edPublicKey, cert.PublicKey())
+	require.Equal(edKey, cert.PrivateKey())
+}
+
+func TestCertificateValidateUnsupportedCurve(t *testing.T) {
+	require := test.NewRequire(t)
+
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(err)
+	publicCert, privateCert := certificateKeyPair(t, key)
+	cert, err := NewCertificate(publicCert, privateCert)
+
+	require.Nil(cert)
+	require.EqualError(err, `error with public key: unsupported EC curve P-384, only P-256 is supported`)
 }
@@ -0,0 +1,183 @@
+package authentication
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// DefaultClientAssertionLifetime - time after which client assertions expire when not configured
+const DefaultClientAssertionLifetime = 30 * time.Minute
+
+// ClientAssertionClaims - configurable claims of the JWT the client authenticates with using
+// `private_key_jwt` or `client_secret_jwt`
+type ClientAssertionClaims struct {
+	Audience string        // aud, defaults to the token endpoint
+	Lifetime time.Duration // exp is the time the assertion is issued plus the lifetime, defaults to DefaultClientAssertionLifetime
+	JTI      string        // jti, defaults to a new UUID for every assertion
+}
+
+// ClientAuthentication - authenticates the client at the token endpoint with its `token_endpoint_auth_method`
+// https://openid.net/specs/openid-connect-core-1_0.html#ClientAuthentication
+type ClientAuthentication struct {
+	Method        string
+	ClientID      string
+	ClientSecret  string
+	TokenEndpoint string
+	SigningMethod jwt.SigningMethod // signs `private_key_jwt` assertions
+	SigningCert   Certificate       // key of `private_key_jwt` assertions
+	KID           string            // key ID of `private_key_jwt` assertions
+	Claims        ClientAssertionClaims
+}
+
+// TokenRequestAuthentication - headers and form fields authenticating a token endpoint request
+type TokenRequestAuthentication struct {
+	Headers  map[string]string
+	FormData map[string]string
+}
+
+// NewClientAuthentication - client authentication configured in the context, `client_secret_basic` when no
+// `token_endpoint_auth_method` is set
+func NewClientAuthentication(ctx ContextInterface) (ClientAuthentication, error) {
+	method, err := ctx.GetString("token_endpoint_auth_method")
+	if err != nil || method == "" {
+		method = ClientSecretBasic
+	}
+	clientID, err := ctx.GetString("client_id")
+	if err != nil {
+		return ClientAuthentication{}, errors.Wrap(err, "client authentication: cannot get client_id")
+	}
+	tokenEndpoint, err := ctx.GetString("token_endpoint")
+	if err != nil {
+		return ClientAuthentication{}, errors.Wrap(err, "client authentication: cannot get token_endpoint")
+	}
+
+	auth := ClientAuthentication{
+		Method:        method,
+		ClientID:      clientID,
+		TokenEndpoint: tokenEndpoint,
+	}
+	if auth.Claims, err = clientAssertionClaimsFromContext(ctx); err != nil {
+		return ClientAuthentication{}, err
+	}
+
+	switch method {
+	case TlsClientAuth:
+	case ClientSecretBasic, ClientSecretPost, ClientSecretJwt:
+		if auth.ClientSecret, err = ctx.GetString("client_secret"); err != nil {
+			return ClientAuthentication{}, errors.Wrapf(err, "client authentication: cannot get client_secret for %s", method)
+		}
+	case PrivateKeyJwt:
+		alg, err := ctx.GetString("requestObjectSigningAlg")
+		if err != nil {
+			return ClientAuthentication{}, errors.Wrapf(err, "client authentication: cannot get requestObjectSigningAlg for %s", method)
+		}
+		if auth.SigningMethod, err = GetSigningAlg(alg); err != nil {
+			return ClientAuthentication{}, errors.Wrapf(err, "client authentication: %s", method)
+		}
+		if auth.SigningCert, err = SigningCertFromContext(ctx); err != nil {
+			return ClientAuthentication{}, errors.Wrapf(err, "client authentication: %s", method)
+		}
+		if auth.KID, err = ctx.GetString("tpp_signature_kid"); err != nil {
+			return ClientAuthentication{}, errors.Wrapf(err, "client authentication: cannot get tpp_signature_kid for %s", method)
+		}
+	default:
+		return ClientAuthentication{}, fmt.Errorf("client authentication: token_endpoint_auth_method %q unsupported", method)
+	}
+	return auth, nil
+}
+
+// clientAssertionClaimsFromContext reads the optional `client_assertion_audience`, `client_assertion_lifetime`
+// in seconds and `client_assertion_jti`
+func clientAssertionClaimsFromContext(ctx ContextInterface) (ClientAssertionClaims, error) {
+	claims := ClientAssertionClaims{}
+	if audience, err := ctx.GetString("client_assertion_audience"); err == nil {
+		claims.Audience = audience
+	}
+	if jti, err := ctx.GetString("client_assertion_jti"); err == nil {
+		claims.JTI = jti
+	}
+	if lifetime, err := ctx.GetString("client_assertion_lifetime"); err == nil && lifetime != "" {
+		seconds, err := strconv.Atoi(lifetime)
+		if err != nil || seconds < 0 {
+			return ClientAssertionClaims{}, fmt.Errorf("client authentication: invalid client_assertion_lifetime %q", lifetime)
+		}
+		claims.Lifetime = time.Duration(seconds) * time.Second
+	}
+	return claims, nil
+}
+
+// Authenticate returns the headers and form fields authenticating a token request made at `now`
+func (a ClientAuthentication) Authenticate(now time.Time) (TokenRequestAuthentication, error) {
+	auth := TokenRequestAuthentication{Headers: map[string]string{}, FormData: map[string]string{}}
+
+	switch a.Method {
+	case ClientSecretBasic:
+		basic, err := CalculateClientSecretBasicToken(a.ClientID, a.ClientSecret)
+		if err != nil {
+			return TokenRequestAuthentication{}, errors.Wrap(err, "client authentication: client_secret_basic")
+		}
+		auth.Headers["authorization"] = "Basic " + basic
+	case ClientSecretPost:
+		auth.FormData["client_id"] = a.ClientID
+		auth.FormData["client_secret"] = a.ClientSecret
+	case TlsClientAuth:
+		auth.FormData["client_id"] = a.ClientID
+	case ClientSecretJwt:
+		assertion, err := a.clientAssertion(jwt.SigningMethodHS256, []byte(a.ClientSecret), "", now)
+		if err != nil {
+			return TokenRequestAuthentication{}, errors.Wrap(err, "client authentication: client_secret_jwt")
+		}
+		auth.FormData[ClientAssertionType] = ClientAssertionTypeValue
+		auth.FormData[ClientAssertion] = assertion
+	case PrivateKeyJwt:
+		if a.SigningCert == nil || a.SigningMethod == nil {
+			return TokenRequestAuthentication{}, errors.New("client authentication: private_key_jwt needs a signing certificate and algorithm")
+		}
+		assertion, err := a.clientAssertion(a.SigningMethod, a.SigningCert.PrivateKey(), a.KID, now)
+		if err != nil {
+			return TokenRequestAuthentication{}, errors.Wrap(err, "client authentication: private_key_jwt")
+		}
+		auth.FormData[ClientAssertionType] = ClientAssertionTypeValue
+		auth.FormData[ClientAssertion] = assertion
+	default:
+		return TokenRequestAuthentication{}, fmt.Errorf("client authentication: token_endpoint_auth_method %q unsupported", a.Method)
+	}
+	return auth, nil
+}
+
+// clientAssertion returns the signed JWT the client authenticates with
+//
+// iss and sub MUST contain the client_id of the OAuth Client, aud SHOULD be the URL of the
+// Authorization Server's Token Endpoint, jti is a unique identifier preventing the token being reused
+func (a ClientAuthentication) clientAssertion(method jwt.SigningMethod, key interface{}, kid string, now time.Time) (string, error) {
+	audience := a.Claims.Audience
+	if audience == "" {
+		audience = a.TokenEndpoint
+	}
+	lifetime := a.Claims.Lifetime
+	if lifetime == 0 {
+		lifetime = DefaultClientAssertionLifetime
+	}
+	jti := a.Claims.JTI
+	if jti == "" {
+		jti = uuid.New().String()
+	}
+
+	token := jwt.NewWithClaims(method, jwt.MapClaims{
+		"iss": a.ClientID,
+		"sub": a.ClientID,
+		"aud": audience,
+		"iat": now.Unix(),
+		"exp": now.Add(lifetime).Unix(),
+		"jti": jti,
+	})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	return token.SignedString(key)
+}
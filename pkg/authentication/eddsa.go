@@ -0,0 +1,57 @@
+package authentication
+
+import (
+	"crypto/ed25519"
+	"errors"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// SigningMethodEdDSA - EdDSA signatures with Ed25519 keys, jwt-go v3 has no EdDSA signing method
+// https://tools.ietf.org/html/rfc8037#section-3.1
+var SigningMethodEdDSA = &signingMethodEdDSA{}
+
+// ErrEdDSAVerification - an EdDSA signature does not verify
+var ErrEdDSAVerification = errors.New("ed25519: verification error")
+
+type signingMethodEdDSA struct{}
+
+func init() {
+	jwt.RegisterSigningMethod(SigningMethodEdDSA.Alg(), func() jwt.SigningMethod {
+		return SigningMethodEdDSA
+	})
+}
+
+func (m *signingMethodEdDSA) Alg() string {
+	return "EdDSA"
+}
+
+// Verify verifies the base64url encoded `signature` of `signingString` with an `ed25519.PublicKey`
+func (m *signingMethodEdDSA) Verify(signingString, signature string, key interface{}) error {
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return jwt.ErrInvalidKeyType
+	}
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(publicKey, []byte(signingString), sig) {
+		return ErrEdDSAVerification
+	}
+	return nil
+}
+
+// Sign signs `signingString` with an `ed25519.PrivateKey`, returns the base64url encoded signature
+func (m *signingMethodEdDSA) Sign(signingString string, key interface{}) (string, error) {
+	var privateKey ed25519.PrivateKey
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		privateKey = k
+	case *ed25519.PrivateKey:
+		privateKey = *k
+	default:
+		return "", jwt.ErrInvalidKeyType
+	}
+	return jwt.EncodeSegment(ed25519.Sign(privateKey, []byte(signingString))), nil
+}
@@ -0,0 +1,161 @@
+package authentication
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/client"
+)
+
+// jwksRefetchInterval - a cached JWKS is refetched for an unknown kid at most this often, so signatures
+// with an unknown kid do not fetch the JWKS every time
+const jwksRefetchInterval = 10 * time.Second
+
+// JWKSKey - a key seen in a JWKS fetched to verify signatures during a run
+type JWKSKey struct {
+	JwksURI   string     `json:"jwksUri"`
+	Kid       string     `json:"kid"`
+	Alg       string     `json:"alg,omitempty"`
+	Use       string     `json:"use,omitempty"`
+	FirstSeen time.Time  `json:"firstSeen"`
+	Added     bool       `json:"added"`             // not in the first JWKS fetched from the URI, it was rotated in during the run
+	Removed   *time.Time `json:"removed,omitempty"` // when a refetched JWKS no longer had the key, it was rotated out
+}
+
+// JWKSCache - JWKS by URI cached for as long as their `Cache-Control` allows and refetched when a signature
+// has an unknown kid, safe for concurrent use by test cases. Each journey keeps its own, so the keys seen are
+// those of its runs.
+type JWKSCache struct {
+	lock   *sync.Mutex
+	client *http.Client
+	now    func() time.Time
+	sets   map[string]*cachedJWKS
+	seen   map[string]map[string]*JWKSKey // by JWKS URI and kid
+}
+
+type cachedJWKS struct {
+	keys      map[string]JWK // by kid
+	fetchedAt time.Time
+	expiresAt time.Time
+}
+
+// jwksKeyCache - the JWKS signatures are verified with when no JWKSCache is given
+var jwksKeyCache = NewJWKSCache()
+
+// NewJWKSCache - an empty JWKSCache fetching with the default HTTP client
+func NewJWKSCache() *JWKSCache {
+	return newJWKSCache(client.NewHTTPClient(client.DefaultTimeout))
+}
+
+func newJWKSCache(httpClient *http.Client) *JWKSCache {
+	return &JWKSCache{
+		lock:   &sync.Mutex{},
+		client: httpClient,
+		now:    time.Now,
+		sets:   map[string]*cachedJWKS{},
+		seen:   map[string]map[string]*JWKSKey{},
+	}
+}
+
+// Reset drops the cached JWKS and the keys seen, so the keys of a run are reported from its first fetch
+func (c *JWKSCache) Reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.sets = map[string]*cachedJWKS{}
+	c.seen = map[string]map[string]*JWKSKey{}
+}
+
+// key returns the key `kid` of the JWKS at `uri`, an empty JWK when the JWKS does not have it
+func (c *JWKSCache) key(kid, uri string) (JWK, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := c.now()
+	set, ok := c.sets[uri]
+	if ok && now.Before(set.expiresAt) {
+		if key, ok := set.keys[kid]; ok {
+			logrus.Traceln("Using cached jwk")
+			return key, nil
+		}
+		if now.Sub(set.fetchedAt) < jwksRefetchInterval {
+			logrus.Traceln("no matching key found")
+			return JWK{}, nil
+		}
+		logrus.WithField("kid", kid).Trace("unknown kid, refetching JWKS")
+	}
+
+	logrus.Traceln("Retrieving JWKS url: " + uri)
+	body, expiresAt, err := fetchDocument(c.client, uri, now)
+	if err != nil {
+		return JWK{}, fmt.Errorf("GetJwks error retrieving url: %s, %v", uri, err)
+	}
+	fetched := JWKS{}
+	if err := json.Unmarshal(body, &fetched); err != nil {
+		return JWK{}, fmt.Errorf("GetJwks: decoding error %s : %v", uri, err)
+	}
+
+	set = &cachedJWKS{keys: map[string]JWK{}, fetchedAt: now, expiresAt: expiresAt}
+	for _, key := range fetched.Keys {
+		set.keys[key.Kid] = key
+	}
+	c.observe(uri, set, now)
+	c.sets[uri] = set
+
+	key, ok := set.keys[kid]
+	if !ok {
+		logrus.Traceln("no matching key found")
+	}
+	return key, nil
+}
+
+// observe records the keys added to and removed from the JWKS at `uri` since it was last fetched, the lock must be held
+func (c *JWKSCache) observe(uri string, set *cachedJWKS, now time.Time) {
+	seen, refetched := c.seen[uri]
+	if !refetched {
+		seen = map[string]*JWKSKey{}
+		c.seen[uri] = seen
+	}
+	for kid, key := range set.keys {
+		observed, ok := seen[kid]
+		if !ok {
+			seen[kid] = &JWKSKey{JwksURI: uri, Kid: kid, Alg: key.Alg, Use: key.Use, FirstSeen: now, Added: refetched}
+			continue
+		}
+		observed.Removed = nil
+	}
+	for kid, observed := range seen {
+		if _, ok := set.keys[kid]; !ok && observed.Removed == nil {
+			removed := now
+			observed.Removed = &removed
+		}
+	}
+}
+
+// Keys returns the keys seen in the JWKS fetched since the last reset, by JWKS URI and first seen
+func (c *JWKSCache) Keys() []JWKSKey {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	keys := []JWKSKey{}
+	for _, seen := range c.seen {
+		for _, key := range seen {
+			keys = append(keys, *key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].JwksURI != keys[j].JwksURI {
+			return keys[i].JwksURI < keys[j].JwksURI
+		}
+		if !keys[i].FirstSeen.Equal(keys[j].FirstSeen) {
+			return keys[i].FirstSeen.Before(keys[j].FirstSeen)
+		}
+		return keys[i].Kid < keys[j].Kid
+	})
+	return keys
+}
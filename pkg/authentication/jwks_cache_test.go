@@ -0,0 +1,116 @@
+package authentication
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/client"
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+)
+
+// jwksServer - serves a JWKS with the kids it is given, counting fetches
+type jwksServer struct {
+	*httptest.Server
+	lock    sync.Mutex
+	kids    []string
+	fetches int
+}
+
+func newJWKSServer(cacheControl string, kids ...string) *jwksServer {
+	s := &jwksServer{kids: kids}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		s.fetches++
+		keys := make([]string, 0, len(s.kids))
+		for _, kid := range s.kids {
+			keys = append(keys, fmt.Sprintf(`{"kid":%q,"alg":"PS256","use":"sig"}`, kid))
+		}
+		w.Header().Set("Cache-Control", cacheControl)
+		fmt.Fprintf(w, `{"keys":[%s]}`, strings.Join(keys, ","))
+	}))
+	return s
+}
+
+func TestJWKSCacheHonoursCacheControl(t *testing.T) {
+	require := test.NewRequire(t)
+
+	server := newJWKSServer("max-age=60", "kid-001")
+	defer server.Close()
+	now := time.Now()
+	cache := newJWKSCache(client.NewHTTPClient(client.DefaultTimeout))
+	cache.now = func() time.Time { return now }
+
+	key, err := cache.key("kid-001", server.URL)
+	require.NoError(err)
+	require.Equal("PS256", key.Alg)
+	_, err = cache.key("kid-001", server.URL)
+	require.NoError(err)
+	require.Equal(1, server.fetches)
+
+	now = now.Add(time.Minute)
+	_, err = cache.key("kid-001", server.URL)
+	require.NoError(err)
+	require.Equal(2, server.fetches)
+}
+
+func TestJWKSCacheRefetchesUnknownKidAndRecordsRotation(t *testing.T) {
+	require := test.NewRequire(t)
+
+	server := newJWKSServer("max-age=3600", "kid-001")
+	defer server.Close()
+	start := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	cache := newJWKSCache(client.NewHTTPClient(client.DefaultTimeout))
+	cache.now = func() time.Time { return now }
+
+	_, err := cache.key("kid-001", server.URL)
+	require.NoError(err)
+
+	// the ASPSP rotates its signing key, a signature with the new kid refetches the JWKS
+	server.kids = []string{"kid-002"}
+	now = start.Add(time.Minute)
+	key, err := cache.key("kid-002", server.URL)
+	require.NoError(err)
+	require.Equal("kid-002", key.Kid)
+	require.Equal(2, server.fetches)
+
+	// unknown kids do not refetch the JWKS for every signature
+	key, err = cache.key("kid-003", server.URL)
+	require.NoError(err)
+	require.Equal(JWK{}, key)
+	require.Equal(2, server.fetches)
+
+	removed := start.Add(time.Minute)
+	require.Equal([]JWKSKey{
+		{JwksURI: server.URL, Kid: "kid-001", Alg: "PS256", Use: "sig", FirstSeen: start, Removed: &removed},
+		{JwksURI: server.URL, Kid: "kid-002", Alg: "PS256", Use: "sig", FirstSeen: removed, Added: true},
+	}, cache.Keys())
+
+	cache.Reset()
+	require.Empty(cache.Keys())
+}
+
+func TestCacheExpiry(t *testing.T) {
+	require := test.NewRequire(t)
+
+	now := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		header http.Header
+		expiry time.Time
+	}{
+		{header: http.Header{}, expiry: now.Add(DefaultCacheMaxAge)},
+		{header: http.Header{"Cache-Control": {"public, max-age=120"}}, expiry: now.Add(2 * time.Minute)},
+		{header: http.Header{"Cache-Control": {"no-store"}}, expiry: now},
+		{header: http.Header{"Cache-Control": {"max-age=forever"}}, expiry: now},
+		{header: http.Header{"Expires": {"Sat, 01 Jun 2019 13:00:00 GMT"}}, expiry: now.Add(time.Hour)},
+		{header: http.Header{"Cache-Control": {"max-age=0"}, "Expires": {"Sat, 01 Jun 2019 13:00:00 GMT"}}, expiry: now},
+	} {
+		require.True(tc.expiry.Equal(cacheExpiry(tc.header, now)), "%v", tc.header)
+	}
+}
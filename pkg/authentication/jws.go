@@ -34,6 +34,10 @@ func GetSigningAlg(alg string) (jwt.SigningMethod, error) {
 		return SigningMethodPS256, nil
 	case "RS256":
 		return jwt.SigningMethodRS256, nil
+	case "ES256":
+		return jwt.SigningMethodES256, nil
+	case "EDDSA":
+		return SigningMethodEdDSA, nil
 	case "NONE":
 		fallthrough
 	default:
@@ -332,10 +336,7 @@ func minifiyJSONBody(body string) (string, error) {
 }
 
 func getKidFromCertificate(cert Certificate) (string, error) {
-	modulus := cert.PublicKey().N.Bytes()
-	modulusBase64 := base64.RawURLEncoding.EncodeToString(modulus)
-	kid, err := CalcKid(modulusBase64)
-	return kid, err
+	return CalcKidFromPublicKey(cert.PublicKey())
 }
 
 // Gets the payment api version from the context
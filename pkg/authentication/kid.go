@@ -1,17 +1,47 @@
 package authentication
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
 	"crypto/sha1"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"strings"
 )
 
+// CalcKid returns the kid of an RSA key with exponent 65537 from its base64url encoded modulus
 func CalcKid(modulus string) (string, error) {
-	canonicalInput := fmt.Sprintf(`{"e":"AQAB","kty":"RSA","n":"%s"}`, modulus)
+	return calcThumbprintKid(fmt.Sprintf(`{"e":"AQAB","kty":"RSA","n":"%s"}`, modulus))
+}
+
+// CalcKidFromPublicKey returns the kid of an RSA, EC P-256 or Ed25519 public key: the SHA-1 JWK thumbprint
+// of its required members, as the OB directory calculates it
+// https://tools.ietf.org/html/rfc7638#section-3.2
+func CalcKidFromPublicKey(publicKey crypto.PublicKey) (string, error) {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+		n := base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+		return calcThumbprintKid(fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, e, n))
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		x := base64.RawURLEncoding.EncodeToString(padBytes(key.X.Bytes(), size))
+		y := base64.RawURLEncoding.EncodeToString(padBytes(key.Y.Bytes(), size))
+		return calcThumbprintKid(fmt.Sprintf(`{"crv":"%s","kty":"EC","x":"%s","y":"%s"}`, key.Curve.Params().Name, x, y))
+	case ed25519.PublicKey:
+		x := base64.RawURLEncoding.EncodeToString(key)
+		return calcThumbprintKid(fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":"%s"}`, x))
+	default:
+		return "", fmt.Errorf("authentication.CalcKidFromPublicKey: unsupported key type %T", publicKey)
+	}
+}
 
+func calcThumbprintKid(canonicalInput string) (string, error) {
 	sumer := sha1.New()
 	_, err := io.WriteString(sumer, canonicalInput)
 	if err != nil {
@@ -25,6 +55,16 @@ func CalcKid(modulus string) (string, error) {
 	return sumBase64NoTrailingEquals, nil
 }
 
+// padBytes left pads a big-endian EC coordinate to the size of the curve
+func padBytes(b []byte, size int) []byte {
+	if len(b) >= size {
+		return b
+	}
+	padded := make([]byte, size)
+	copy(padded[size-len(b):], b)
+	return padded
+}
+
 // GetKID determines the value of the JWS Key ID
 func GetKID(ctx ContextInterface, modulus []byte) (string, error) {
 	modulusBase64 := base64.RawURLEncoding.EncodeToString(modulus)
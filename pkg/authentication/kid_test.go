@@ -1,6 +1,13 @@
 package authentication
 
 import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -16,3 +23,29 @@ func TestCalcKid(t *testing.T) {
 	expected := "QuFYBRJnWdI6_NHFgamuXNr5R20"
 	assert.Equal(t, expected, kid)
 }
+
+func TestCalcKidFromPublicKey(t *testing.T) {
+	modulus := "tGzvc5H2KLufptikvbL1crtdSaV901mJY4dAxjWK2V-W6hhgNIgdQgusn3k8AW6KKFckDLIs0hYKmIJTVN0MGaruG4USN4sRlRT2kkizJaXU9ZtHZ5yiwP9BMEiaKgY6IGWy4vVxR9ii83HhAXbTo-gI9HaK73i2kLIYUYwiAUG32Oo5Z226dISMBiGxDU7EeLCJ8uhdKPTi05z5fPE0Lw3eszLwaJN8qQ1BIFON_QXCVS7BDMdmWh2XEEljD_h5d6W1SPXikWod2XWK9PbxbKzGkpIJHV_Ty74c48eQE3_0rkUEZ9iCHtuFxgN0SEy1Hj5-5TDMVXkVQO_rGyYv4w"
+	n, err := base64.RawURLEncoding.DecodeString(modulus)
+	require.NoError(t, err)
+
+	kid, err := CalcKidFromPublicKey(&rsa.PublicKey{N: new(big.Int).SetBytes(n), E: 65537})
+	require.NoError(t, err)
+	assert.Equal(t, "QuFYBRJnWdI6_NHFgamuXNr5R20", kid)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecKid, err := CalcKidFromPublicKey(&ecKey.PublicKey)
+	require.NoError(t, err)
+	assert.Len(t, ecKid, 27)
+
+	edPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	edKid, err := CalcKidFromPublicKey(edPublicKey)
+	require.NoError(t, err)
+	assert.Len(t, edKid, 27)
+	assert.NotEqual(t, ecKid, edKid)
+
+	_, err = CalcKidFromPublicKey("not a key")
+	assert.EqualError(t, err, "authentication.CalcKidFromPublicKey: unsupported key type string")
+}
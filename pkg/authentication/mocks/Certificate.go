@@ -3,7 +3,7 @@
 package mocks
 
 import mock "github.com/stretchr/testify/mock"
-import rsa "crypto/rsa"
+import crypto "crypto"
 import tls "crypto/tls"
 
 // Certificate is an autogenerated mock type for the Certificate type
@@ -12,15 +12,15 @@ type Certificate struct {
 }
 
 // PrivateKey provides a mock function with given fields:
-func (_m *Certificate) PrivateKey() *rsa.PrivateKey {
+func (_m *Certificate) PrivateKey() crypto.PrivateKey {
 	ret := _m.Called()
 
-	var r0 *rsa.PrivateKey
-	if rf, ok := ret.Get(0).(func() *rsa.PrivateKey); ok {
+	var r0 crypto.PrivateKey
+	if rf, ok := ret.Get(0).(func() crypto.PrivateKey); ok {
 		r0 = rf()
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*rsa.PrivateKey)
+			r0 = ret.Get(0).(crypto.PrivateKey)
 		}
 	}
 
@@ -28,15 +28,15 @@ func (_m *Certificate) PrivateKey() *rsa.PrivateKey {
 }
 
 // PublicKey provides a mock function with given fields:
-func (_m *Certificate) PublicKey() *rsa.PublicKey {
+func (_m *Certificate) PublicKey() crypto.PublicKey {
 	ret := _m.Called()
 
-	var r0 *rsa.PublicKey
-	if rf, ok := ret.Get(0).(func() *rsa.PublicKey); ok {
+	var r0 crypto.PublicKey
+	if rf, ok := ret.Get(0).(func() crypto.PublicKey); ok {
 		r0 = rf()
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*rsa.PublicKey)
+			r0 = ret.Get(0).(crypto.PublicKey)
 		}
 	}
 
@@ -3,8 +3,9 @@ package authentication
 import (
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/client"
 	"github.com/sirupsen/logrus"
@@ -21,61 +22,66 @@ type OpenIDConfiguration struct {
 	ResponseTypesSupported                 []string `json:"response_types_supported,omitempty"`
 	AcrValuesSupported                     []string `json:"acr_values_supported,omitempty"`
 	JwksURI                                string   `json:"jwks_uri,omitempty"`
+	PushedAuthorizationRequestEndpoint     string   `json:"pushed_authorization_request_endpoint,omitempty"`
+	RequirePushedAuthorizationRequests     bool     `json:"require_pushed_authorization_requests,omitempty"`
 }
 
-var jwks_uri_accessor = ""
-
-func GetJWKSUri() string {
-	return jwks_uri_accessor
-}
+// DefaultOpenIdConfigGetter - OpenID configurations shared by the handlers fetching them
+var DefaultOpenIdConfigGetter = NewOpenIdConfigGetter()
 
+// CachedOpenIdConfigGetter - fetches OpenID configurations and caches them for as long as their
+// `Cache-Control` allows, safe for concurrent use
 type CachedOpenIdConfigGetter struct {
 	client *http.Client
-	cache  map[string]OpenIDConfiguration
+	lock   *sync.Mutex
+	cache  map[string]cachedOpenIDConfiguration
+	now    func() time.Time
+}
+
+type cachedOpenIDConfiguration struct {
+	config    OpenIDConfiguration
+	expiresAt time.Time
 }
 
 func NewOpenIdConfigGetter() *CachedOpenIdConfigGetter {
 	return &CachedOpenIdConfigGetter{
 		client: client.NewHTTPClient(client.DefaultTimeout),
-		cache:  map[string]OpenIDConfiguration{},
+		lock:   &sync.Mutex{},
+		cache:  map[string]cachedOpenIDConfiguration{},
+		now:    time.Now,
 	}
 }
 
-func (g CachedOpenIdConfigGetter) Get(url string) (OpenIDConfiguration, error) {
-	config, ok := g.cache[url]
-	if ok {
-		logrus.Tracef("Cache hit on getting openid config Uri = %s", url)
-		return config, nil
-	}
+func (g *CachedOpenIdConfigGetter) Get(url string) (OpenIDConfiguration, error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
 
-	resp, err := g.client.Get(url)
-	if err != nil {
-		return OpenIDConfiguration{}, fmt.Errorf("Failed to GET OpenIDConfiguration: url=%+v : %w", url, err)
+	now := g.now()
+	cached, ok := g.cache[url]
+	if ok && now.Before(cached.expiresAt) {
+		logrus.Tracef("Cache hit on getting openid config Uri = %s", url)
+		return cached.config, nil
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		responseBody, err := ioutil.ReadAll(resp.Body)
-		defer resp.Body.Close()
-		if err != nil {
-			return OpenIDConfiguration{}, fmt.Errorf("error reading error response from GET OpenIDConfiguration: %w", err)
-		}
-
+	body, expiresAt, err := fetchDocument(g.client, url, now)
+	if statusErr, ok := err.(*httpStatusError); ok {
 		return OpenIDConfiguration{}, fmt.Errorf(
 			"failed to GET OpenIDConfiguration config: url=%+v, StatusCode=%+v, body=%+v",
 			url,
-			resp.StatusCode,
-			string(responseBody),
+			statusErr.statusCode,
+			string(statusErr.body),
 		)
 	}
+	if err != nil {
+		return OpenIDConfiguration{}, fmt.Errorf("Failed to GET OpenIDConfiguration: url=%+v : %w", url, err)
+	}
 
-	defer resp.Body.Close()
-	config = OpenIDConfiguration{}
-	if err := json.NewDecoder(resp.Body).Decode(&config); err != nil {
+	config := OpenIDConfiguration{}
+	if err := json.Unmarshal(body, &config); err != nil {
 		return config, fmt.Errorf("Invalid OpenIDConfiguration: url=%+v: %w", url, err)
 	}
 
 	logrus.Tracef("JWKS Uri = %s", config.JwksURI)
-	jwks_uri_accessor = config.JwksURI
-	g.cache[url] = config
+	g.cache[url] = cachedOpenIDConfiguration{config: config, expiresAt: expiresAt}
 	return config, nil
 }
@@ -3,7 +3,9 @@ package authentication
 import (
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/test"
 )
@@ -30,3 +32,32 @@ func TestOpenIdConfigWhenJsonParseFails(t *testing.T) {
 	expected := fmt.Sprintf("Invalid OpenIDConfiguration: url=%+v: invalid character '<' looking for beginning of value", mockedServerURL)
 	require.EqualError(err, expected)
 }
+
+func TestOpenIdConfigIsCachedUntilExpiry(t *testing.T) {
+	require := test.NewRequire(t)
+
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte(`{"issuer":"https://aspsp.example.com","jwks_uri":"https://aspsp.example.com/jwks"}`))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	getter := NewOpenIdConfigGetter()
+	getter.now = func() time.Time { return now }
+
+	config, err := getter.Get(server.URL)
+	require.NoError(err)
+	require.Equal("https://aspsp.example.com", config.Issuer)
+	config, err = getter.Get(server.URL)
+	require.NoError(err)
+	require.Equal(1, fetches)
+	require.Equal("https://aspsp.example.com/jwks", config.JwksURI)
+
+	now = now.Add(time.Minute)
+	_, err = getter.Get(server.URL)
+	require.NoError(err)
+	require.Equal(2, fetches)
+}
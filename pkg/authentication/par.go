@@ -0,0 +1,72 @@
+package authentication
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/resty.v1"
+)
+
+// PushedAuthorizationResponse - response of a pushed authorization request endpoint
+// https://www.rfc-editor.org/rfc/rfc9126#section-2.2
+type PushedAuthorizationResponse struct {
+	RequestURI string `json:"request_uri"`
+	ExpiresIn  int    `json:"expires_in"`
+}
+
+// PushAuthorizationRequest pushes the parameters of an authorization request, including its signed `request` object,
+// to the pushed authorization request endpoint with the client authentication of the token endpoint, sent with `client`
+// https://www.rfc-editor.org/rfc/rfc9126
+func PushAuthorizationRequest(client *resty.Client, endpoint string, auth ClientAuthentication, params url.Values) (PushedAuthorizationResponse, error) {
+	clientAuth, err := auth.Authenticate(time.Now())
+	if err != nil {
+		return PushedAuthorizationResponse{}, errors.Wrap(err, "pushed authorization request")
+	}
+	form := url.Values{}
+	for key, values := range params {
+		form[key] = values
+	}
+	for key, value := range clientAuth.FormData {
+		form.Set(key, value)
+	}
+
+	resp, err := client.R().
+		SetHeader("content-type", "application/x-www-form-urlencoded").
+		SetHeader("accept", "application/json").
+		SetHeaders(clientAuth.Headers).
+		SetMultiValueFormData(form).
+		Post(endpoint)
+	if err != nil {
+		return PushedAuthorizationResponse{}, errors.Wrap(err, "pushed authorization request")
+	}
+	if resp.StatusCode() != http.StatusCreated {
+		return PushedAuthorizationResponse{}, fmt.Errorf("pushed authorization request: bad status code %d from %q: %.250s", resp.StatusCode(), endpoint, resp.String())
+	}
+
+	pushed := PushedAuthorizationResponse{}
+	if err := json.Unmarshal(resp.Body(), &pushed); err != nil {
+		return PushedAuthorizationResponse{}, errors.Wrap(err, "pushed authorization request")
+	}
+	if pushed.RequestURI == "" {
+		return PushedAuthorizationResponse{}, errors.New("pushed authorization request: no request_uri in response")
+	}
+	return pushed, nil
+}
+
+// PushedAuthorizationURL returns the authorization URL of a pushed authorization request
+// https://www.rfc-editor.org/rfc/rfc9126#section-4
+func PushedAuthorizationURL(authorizationEndpoint, clientID, requestURI string) (string, error) {
+	consentURL, err := url.Parse(authorizationEndpoint)
+	if err != nil {
+		return "", errors.Wrap(err, "pushed authorization URL")
+	}
+	query := consentURL.Query()
+	query.Set("client_id", clientID)
+	query.Set("request_uri", requestURI)
+	consentURL.RawQuery = query.Encode()
+	return consentURL.String(), nil
+}
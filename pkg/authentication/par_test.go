@@ -0,0 +1,48 @@
+package authentication
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"gopkg.in/resty.v1"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+)
+
+func TestPushAuthorizationRequest(t *testing.T) {
+	require := test.NewRequire(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != "client-id" || clientSecret != "client-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.FormValue("request") != "request-object" || r.FormValue("state") != "state-001" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"request_uri":"urn:ietf:params:oauth:request_uri:001","expires_in":90}`))
+	}))
+	defer server.Close()
+
+	auth, err := NewClientAuthentication(clientAuthContext(ClientSecretBasic))
+	require.NoError(err)
+	pushed, err := PushAuthorizationRequest(resty.New(), server.URL, auth, url.Values{"request": {"request-object"}, "state": {"state-001"}})
+	require.NoError(err)
+	require.Equal(PushedAuthorizationResponse{RequestURI: "urn:ietf:params:oauth:request_uri:001", ExpiresIn: 90}, pushed)
+
+	_, err = PushAuthorizationRequest(resty.New(), server.URL, auth, url.Values{"request": {"request-object"}})
+	require.EqualError(err, `pushed authorization request: bad status code 400 from "`+server.URL+`": `)
+}
+
+func TestPushedAuthorizationURL(t *testing.T) {
+	require := test.NewRequire(t)
+
+	consentURL, err := PushedAuthorizationURL("https://aspsp.example.com/auth?tenant=1", "client-id", "urn:ietf:params:oauth:request_uri:001")
+	require.NoError(err)
+	require.Equal("https://aspsp.example.com/auth?client_id=client-id&request_uri=urn%3Aietf%3Aparams%3Aoauth%3Arequest_uri%3A001&tenant=1", consentURL)
+}
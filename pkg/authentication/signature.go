@@ -1,7 +1,8 @@
 package authentication
 
 import (
-	"crypto/rsa"
+	"crypto"
+	"crypto/ed25519"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -17,6 +18,9 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// AlgEdDSA - EdDSA JWS algorithm, jwx has no constant for it
+const AlgEdDSA jwa.SignatureAlgorithm = "EdDSA"
+
 var (
 	// ErrInvalidSignatureHeader is an error indicating that the signature being validated has errors in the header
 	ErrInvalidSignatureHeader = errors.New("invalid signature header")
@@ -38,6 +42,9 @@ type JWK struct {
 	X5c []string `json:"x5c,omitempty"`
 	N   string   `json:"n,omitempty"`
 	E   string   `json:"e,omitempty"`
+	Crv string   `json:"crv,omitempty"`
+	X   string   `json:"x,omitempty"`
+	Y   string   `json:"y,omitempty"`
 	Kid string   `json:"kid,omitempty"`
 	X5t string   `json:"x5t,omitempty"`
 	X5u string   `json:"x5u,omitempty"`
@@ -47,36 +54,12 @@ type JWK struct {
 // ValidateSignature takes the signature JWT
 // and extracts the kid to lookup the public key in the JWKS
 func ValidateSignature(jwtToken, body, jwksURI string, b64 bool) (bool, error) {
-	err := ValidateSignatureHeader(jwtToken, b64)
-	if err != nil {
-		return false, err
-	}
-
-	kid, err := getKidFromToken(jwtToken)
-	if err != nil {
-		return false, err
-	}
-
-	cert, err := getCertForKid(kid, jwksURI)
-	if err != nil {
-		return false, err
-	}
-
-	signature, err := insertBodyIntoJWT(jwtToken, body, b64) // b64claim
-	if err != nil {
-		logrus.Errorf("failed to insert body into signature message: %v", err)
-		return false, err
-	}
-	logrus.Trace("Signature with payload: " + signature)
-
-	verified, err := JWSVerify(signature, jwa.PS256, cert.PublicKey, b64)
-	if err != nil {
+	verification := VerifySignature(jwtToken, body, jwksURI, b64)
+	if err := verification.Err(); err != nil {
 		logrus.Errorf("failed to verify message: %v", err)
 		return false, err
 	}
 
-	logrus.Tracef("signed message verified! -> %s", verified)
-
 	return true, nil
 }
 
@@ -120,7 +103,7 @@ func getKidFromToken(token string) (string, error) {
 
 // buildSignature - takes all the token parameters and assembles a detached header signed token string which is returned
 // Handles api versions v3.1.4 and above, v3.1.3 and prior, plus v3.0 which has a slightly different JWT header
-func buildSignature(b64 bool, kid, issuer, trustAnchor, body string, alg jwt.SigningMethod, privKey *rsa.PrivateKey) (string, error) {
+func buildSignature(b64 bool, kid, issuer, trustAnchor, body string, alg jwt.SigningMethod, privKey crypto.PrivateKey) (string, error) {
 	var token jwt.Token
 
 	if b64 {
@@ -226,6 +209,17 @@ type signatureHeader struct {
 // ValidateSignatureHeader takes a token and performs the header validation
 // taking the b64 parameter value in consideration.
 func ValidateSignatureHeader(token string, b64 bool) error {
+	tokenHeader, err := parseSignatureHeader(token)
+	if err != nil {
+		return err
+	}
+
+	err = tokenHeader.validateSignatureHeader(b64) // validate header depent on b64 setting for api true=3.1.4, false=3.1.3
+
+	return err
+}
+
+func parseSignatureHeader(token string) (signatureHeader, error) {
 	var tokenHeader signatureHeader
 
 	segments := strings.Split(token, ".")
@@ -235,14 +229,11 @@ func ValidateSignatureHeader(token string, b64 bool) error {
 
 	err := json.Unmarshal(decodedPayload, &tokenHeader)
 	if err != nil {
-		return fmt.Errorf("ValidateSignatureHeader: cannot convert header into JSON: " + err.Error())
+		return signatureHeader{}, fmt.Errorf("ValidateSignatureHeader: cannot convert header into JSON: " + err.Error())
 	}
 
 	dumpJSON(tokenHeader)
-
-	err = tokenHeader.validateSignatureHeader(b64) // validate header depent on b64 setting for api true=3.1.4, false=3.1.3
-
-	return err
+	return tokenHeader, nil
 }
 
 // Utility to Dump Json
@@ -258,30 +249,81 @@ func dumpJSON(i interface{}) {
 func (s signatureHeader) validateSignatureHeader(b64 bool) error {
 	dumpJSON(s)
 
+	for _, check := range s.checks(b64) {
+		if !check.Pass {
+			return check.err
+		}
+	}
+	return nil
+}
+
+// checks returns the check of each header claim, in the order they are validated
+func (s signatureHeader) checks(b64 bool) []SignatureCheck {
+	return []SignatureCheck{
+		newSignatureCheck("typ", s.validateType()),
+		newSignatureCheck("alg", s.validateAlg()),
+		newSignatureCheck("kid", s.validateKid()),
+		newSignatureCheck("cty", s.validateContentType()),
+		newSignatureCheck("b64", s.validateB64(b64)),
+		newSignatureCheck("crit", s.validateCritical(b64)),
+		newSignatureCheck("iat", s.validateIssuedAt()),
+		newSignatureCheck("tan", s.validateTrustAnchor()),
+		newSignatureCheck("iss", s.validateIssuer()),
+	}
+}
+
+func (s signatureHeader) validateType() error {
 	if s.Type != "" { // Optional must be "JOSE" if present
 		if s.Type != "JOSE" {
 			return errInvalidSignatureClaim("typ", s.Type, "must equal 'JOSE' if present")
 		}
 	}
+	return nil
+}
 
-	if s.Alg != "PS256" { // Mandatory must be "PS256"
+// validateAlg - the OB signing profile requires ASPSPs to sign with PS256, ES256 and EdDSA are only for TPP signing keys
+func (s signatureHeader) validateAlg() error {
+	if s.Alg != string(jwa.PS256) { // Mandatory must be "PS256"
 		return errInvalidSignatureClaim("alg", s.Alg, "PS256")
 	}
+	return nil
+}
 
+func (s signatureHeader) validateKid() error {
 	if s.Kid == "" { // Mandatory - must be present
 		return fmt.Errorf("%w: kid claim MUST be present", ErrInvalidSignatureHeader)
 	}
+	return nil
+}
 
+func (s signatureHeader) validateContentType() error {
 	if s.Ctype != "" { // Optional - if present must be json or application/json
 		if s.Ctype != "json" && s.Ctype != "application/json" {
 			return errInvalidSignatureClaim("cty", s.Ctype, "'json' or 'application/json'")
 		}
 	}
+	return nil
+}
 
+func (s signatureHeader) validateB64(b64 bool) error {
 	if b64 { // version 3.1.4 and newer
 		if s.B64 != nil {
 			return fmt.Errorf("%w: b64 claim is set - must not be present for v3.1.4 and newer APIs", ErrInvalidSignatureHeader)
 		}
+		return nil
+	}
+	// version 3.1.3 and older
+	if s.B64 == nil {
+		return fmt.Errorf("%w: b64 claim is not set - must be present for v3.1.3 and older APIs", ErrInvalidSignatureHeader)
+	}
+	if *s.B64 == true {
+		return errInvalidSignatureClaim("b64", *s.B64, "value must be false for v3.1.3 and older APIs")
+	}
+	return nil
+}
+
+func (s signatureHeader) validateCritical(b64 bool) error {
+	if b64 { // version 3.1.4 and newer
 		if len(s.Critical) != 3 {
 			return errInvalidSignatureClaim("crit", s.Critical, "must contain 3 elements for v3.1.4 and newer APIs")
 		}
@@ -290,31 +332,35 @@ func (s signatureHeader) validateSignatureHeader(b64 bool) error {
 		if !containsAllElements(s.Critical, requiredElements) {
 			return errInvalidSignatureClaim("crit", s.Critical, requiredElements)
 		}
+		return nil
+	}
+	// version 3.1.3 and older
+	if len(s.Critical) != 4 {
+		return errInvalidSignatureClaim("crit", s.Critical, "must contain 4 elements for v3.1.3 and older APIs")
+	}
 
-	} else { // version 3.1.3 and older
-		if s.B64 == nil {
-			return fmt.Errorf("%w: b64 claim is not set - must be present for v3.1.3 and older APIs", ErrInvalidSignatureHeader)
-		}
-		if *s.B64 == true {
-			return errInvalidSignatureClaim("b64", *s.B64, "value must be false for v3.1.3 and older APIs")
-		}
-		if len(s.Critical) != 4 {
-			return errInvalidSignatureClaim("crit", s.Critical, "must contain 4 elements for v3.1.3 and older APIs")
-		}
-
-		requiredElements := []string{"http://openbanking.org.uk/iss", "http://openbanking.org.uk/iat", "http://openbanking.org.uk/tan", "b64"}
-		if !containsAllElements(s.Critical, requiredElements) {
-			return errInvalidSignatureClaim("crit", s.Critical, requiredElements)
-		}
+	requiredElements := []string{"http://openbanking.org.uk/iss", "http://openbanking.org.uk/iat", "http://openbanking.org.uk/tan", "b64"}
+	if !containsAllElements(s.Critical, requiredElements) {
+		return errInvalidSignatureClaim("crit", s.Critical, requiredElements)
 	}
+	return nil
+}
 
+func (s signatureHeader) validateIssuedAt() error {
 	if s.IssuedAt == decimal.Zero {
 		return errInvalidSignatureClaim("http://openbanking.org.uk/iat", s.IssuedAt.String(), "a JSON number representing time")
 	}
+	return nil
+}
+
+func (s signatureHeader) validateTrustAnchor() error {
 	if s.TrustAnchor != "openbanking.org.uk" && !isHSBCTrustAnchor(s.TrustAnchor) { // allow trust anchors from OBIE HSBC
 		return errInvalidSignatureClaim("http://openbanking.org.uk/tan", s.TrustAnchor, "openbanking.org.uk or ASPSP specific value")
 	}
+	return nil
+}
 
+func (s signatureHeader) validateIssuer() error {
 	if len(s.Issuer) == 0 {
 		return errInvalidSignatureClaim("http://openbanking.org.uk/iss", s.Issuer, "non empty value")
 	}
@@ -362,18 +408,25 @@ func containsAllElements(source, elements []string) bool {
 // If the verification is successful, `err` is nil, and the content of the
 // payload that was signed is returned.
 func JWSVerify(buf string, alg jwa.SignatureAlgorithm, key interface{}, b64 bool) (ret []byte, err error) {
-	verifier, err := verify.New(alg)
-	if err != nil {
-		return nil, errors.New("failed to create verifier")
-	}
 	protected, payload, signature := payloadSplit(buf)
 	verifyBuf := []byte(protected + "." + payload)
 	decodedSignature := make([]byte, base64.RawURLEncoding.DecodedLen(len(signature)))
 	if _, err := base64.RawURLEncoding.Decode(decodedSignature, []byte(signature)); err != nil {
 		return nil, errors.New(`failed to decode signature`)
 	}
-	if err := verifier.Verify(verifyBuf, decodedSignature, key); err != nil {
-		return nil, errors.New(`failed to verify message`)
+	if alg == AlgEdDSA { // not supported by jwx verifiers
+		publicKey, ok := key.(ed25519.PublicKey)
+		if !ok || !ed25519.Verify(publicKey, verifyBuf, decodedSignature) {
+			return nil, errors.New(`failed to verify message`)
+		}
+	} else {
+		verifier, err := verify.New(alg)
+		if err != nil {
+			return nil, errors.New("failed to create verifier")
+		}
+		if err := verifier.Verify(verifyBuf, decodedSignature, key); err != nil {
+			return nil, errors.New(`failed to verify message`)
+		}
 	}
 
 	decodedPayload := make([]byte, base64.RawURLEncoding.DecodedLen(len(payload)))
@@ -114,7 +114,7 @@ func verifySig(t *testing.T, signingMethod jwt.SigningMethod, signingString, sig
 	kid, err := getKidFromToken(signingString)
 	fmt.Println("kid: " + kid)
 	assert.Nil(t, err)
-	jwk, err := getJwkFromJwks(kid, "https://keystore.openbankingtest.org.uk/0015800001041RbAAI/fJuUU6dNt0zxnDe59eG0YN.jwks")
+	jwk, err := getJwkFromJwks(jwksKeyCache, kid, "https://keystore.openbankingtest.org.uk/0015800001041RbAAI/fJuUU6dNt0zxnDe59eG0YN.jwks")
 	assert.Nil(t, err)
 	certs, err := parseCertificateChain(jwk.X5c)
 	assert.Nil(t, err)
@@ -132,7 +132,7 @@ func verifySig(t *testing.T, signingMethod jwt.SigningMethod, signingString, sig
 func TestOzonePublicKey2EncodePayload(t *testing.T) {
 	kid, err := getKidFromToken(detachedJWT)
 	assert.Nil(t, err)
-	jwk, err := getJwkFromJwks(kid, "https://keystore.openbankingtest.org.uk/0015800001041RHAAY/0015800001041RHAAY.jwks")
+	jwk, err := getJwkFromJwks(jwksKeyCache, kid, "https://keystore.openbankingtest.org.uk/0015800001041RHAAY/0015800001041RHAAY.jwks")
 	assert.Nil(t, err)
 
 	if jwk.X5c == nil {
@@ -155,7 +155,7 @@ func TestOzonePublicKey2EncodePayload(t *testing.T) {
 func TestOzoneExpiredPublicKey2EncodePayload(t *testing.T) {
 	kid, err := getKidFromToken(expiredDetachedJWT)
 	assert.Nil(t, err)
-	jwk, err := getJwkFromJwks(kid, "https://keystore.openbankingtest.org.uk/0015800001041RHAAY/0015800001041RHAAY.jwks")
+	jwk, err := getJwkFromJwks(jwksKeyCache, kid, "https://keystore.openbankingtest.org.uk/0015800001041RHAAY/0015800001041RHAAY.jwks")
 	assert.Nil(t, err)
 
 	if jwk.X5c == nil {
@@ -0,0 +1,175 @@
+package authentication
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lestrrat-go/jwx/jwa"
+)
+
+// Names of the checks of a signature that are not header claims
+const (
+	SignatureCheckHeader           = "header"
+	SignatureCheckCertificate      = "certificate"
+	SignatureCheckCertificateChain = "certificateChain"
+	SignatureCheckIssuer           = "issuerCertificate"
+	SignatureCheckBody             = "signature"
+)
+
+// SignatureVerification - the result of verifying an `x-jws-signature`: its header claims and every check made,
+// so signature problems can be diagnosed from the results
+type SignatureVerification struct {
+	Valid       bool             `json:"valid"`
+	Kid         string           `json:"kid,omitempty"`
+	Alg         string           `json:"alg,omitempty"`
+	Issuer      string           `json:"iss,omitempty"`
+	IssuedAt    string           `json:"iat,omitempty"`
+	TrustAnchor string           `json:"tan,omitempty"`
+	Critical    []string         `json:"crit,omitempty"`
+	B64         *bool            `json:"b64,omitempty"`
+	BodyDigest  string           `json:"bodyDigest"` // hex encoded SHA-256 of the response body the signature is verified against
+	Checks      []SignatureCheck `json:"checks"`
+}
+
+// SignatureCheck - the outcome of one check of a signature
+type SignatureCheck struct {
+	Name   string `json:"name"`
+	Pass   bool   `json:"pass"`
+	Detail string `json:"detail,omitempty"`
+	err    error
+}
+
+func newSignatureCheck(name string, err error) SignatureCheck {
+	if err != nil {
+		return SignatureCheck{Name: name, Detail: err.Error(), err: err}
+	}
+	return SignatureCheck{Name: name, Pass: true}
+}
+
+// Err returns the error of the first failed check, nil when the signature is valid
+func (v SignatureVerification) Err() error {
+	for _, check := range v.Checks {
+		if !check.Pass {
+			if check.err != nil {
+				return check.err
+			}
+			return errors.New(check.Detail)
+		}
+	}
+	return nil
+}
+
+func (v *SignatureVerification) add(checks ...SignatureCheck) {
+	v.Checks = append(v.Checks, checks...)
+}
+
+// VerifySignature verifies a detached `x-jws-signature` of `body`: its header claims, the certificate of its kid
+// in the JWKS at `jwksURI`, the certificate chain, that the certificate belongs to the issuer and the signature of the body.
+// Checks that depend on a failed check are not made. The JWKS is cached process-wide, see JWKSCache.VerifySignature.
+func VerifySignature(jwtToken, body, jwksURI string, b64 bool) SignatureVerification {
+	return jwksKeyCache.VerifySignature(jwtToken, body, jwksURI, b64)
+}
+
+// VerifySignature verifies a detached `x-jws-signature` of `body` as VerifySignature does, with the JWKS cached in `c`
+func (c *JWKSCache) VerifySignature(jwtToken, body, jwksURI string, b64 bool) SignatureVerification {
+	digest := sha256.Sum256([]byte(body))
+	verification := SignatureVerification{BodyDigest: hex.EncodeToString(digest[:])}
+	verification.verify(c, jwtToken, body, jwksURI, b64)
+	verification.Valid = verification.Err() == nil
+	return verification
+}
+
+func (v *SignatureVerification) verify(cache *JWKSCache, jwtToken, body, jwksURI string, b64 bool) {
+	header, err := parseSignatureHeader(jwtToken)
+	if err != nil {
+		v.add(newSignatureCheck(SignatureCheckHeader, err))
+		return
+	}
+	v.Kid = header.Kid
+	v.Alg = header.Alg
+	v.Issuer = header.Issuer
+	if header.IssuedAt.Sign() != 0 {
+		v.IssuedAt = header.IssuedAt.String()
+	}
+	v.TrustAnchor = header.TrustAnchor
+	v.Critical = header.Critical
+	v.B64 = header.B64
+	v.add(header.checks(b64)...)
+	if header.Kid == "" {
+		return
+	}
+
+	certs, err := getCertChainForKid(cache, header.Kid, jwksURI)
+	v.add(newSignatureCheck(SignatureCheckCertificate, err))
+	if err != nil {
+		return
+	}
+	v.add(
+		newSignatureCheck(SignatureCheckCertificateChain, validateCertificateChain(certs, header.TrustAnchor, time.Now())),
+		newSignatureCheck(SignatureCheckIssuer, validateCertificateIssuer(certs[0], header)),
+	)
+
+	signature, err := insertBodyIntoJWT(jwtToken, body, b64) // b64claim
+	if err == nil {
+		_, err = JWSVerify(signature, jwa.SignatureAlgorithm(header.Alg), certs[0].PublicKey, b64)
+	}
+	v.add(newSignatureCheck(SignatureCheckBody, err))
+}
+
+// validateCertificateChain checks each certificate of an `x5c` chain is valid at `now` and signed by the next
+// certificate, the last certificate must sign itself when it is a root, and that the chain ends in a root
+// certificate of the trust anchor `tan`. The OB directory publishes the signing certificate alone, it is
+// then checked against the OB issuing and root certificates.
+func validateCertificateChain(certs []*x509.Certificate, tan string, now time.Time) error {
+	for i, cert := range certs {
+		if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+			return fmt.Errorf("certificate %q is not valid at %s: valid from %s to %s", cert.Subject,
+				now.Format(time.RFC3339), cert.NotBefore.Format(time.RFC3339), cert.NotAfter.Format(time.RFC3339))
+		}
+		if i+1 < len(certs) {
+			if err := cert.CheckSignatureFrom(certs[i+1]); err != nil {
+				return fmt.Errorf("certificate %q is not signed by %q: %v", cert.Subject, certs[i+1].Subject, err)
+			}
+		}
+	}
+	root := certs[len(certs)-1]
+	if len(certs) > 1 && root.Subject.String() == root.Issuer.String() {
+		if err := root.CheckSignature(root.SignatureAlgorithm, root.RawTBSCertificate, root.Signature); err != nil {
+			return fmt.Errorf("root certificate %q is not self-signed: %v", root.Subject, err)
+		}
+	}
+	return verifyTrustAnchorChain(certs, tan, now)
+}
+
+// validateCertificateIssuer checks the signing certificate was issued to the `iss` of an OB directory signature,
+// the organisation ID is the OU or the CN of the certificate subject
+func validateCertificateIssuer(cert *x509.Certificate, header signatureHeader) error {
+	if header.TrustAnchor != "openbanking.org.uk" {
+		return nil
+	}
+	for _, name := range append([]string{cert.Subject.CommonName}, cert.Subject.OrganizationalUnit...) {
+		if name == header.Issuer {
+			return nil
+		}
+	}
+	return fmt.Errorf("issuer %s is not the subject of the signing certificate %q", header.Issuer, cert.Subject)
+}
+
+// String summarises the failed checks of a verification
+func (v SignatureVerification) String() string {
+	failed := []string{}
+	for _, check := range v.Checks {
+		if !check.Pass {
+			failed = append(failed, check.Name+": "+check.Detail)
+		}
+	}
+	if len(failed) == 0 {
+		return "valid"
+	}
+	return strings.Join(failed, "; ")
+}
@@ -0,0 +1,221 @@
+package authentication
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+)
+
+const verificationIssuer = "0015800001041RbAAI"
+
+// newSigningJWKS - serves a JWKS with the x5c of a certificate for `kid` issued to `orgID`, trusted as a root of
+// the OB trust anchor, returns the private key
+func newSigningJWKS(t *testing.T, kid, orgID string, notAfter time.Time) (*httptest.Server, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newSigningJWKSForKey(t, key, kid, orgID, notAfter), key
+}
+
+func newSigningJWKSForKey(t *testing.T, key crypto.Signer, kid, orgID string, notAfter time.Time) *httptest.Server {
+	der := newCertificate(t, key, orgID, notAfter)
+	if err := AddTrustAnchorRoots(OBTrustAnchor, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})); err != nil {
+		t.Fatal(err)
+	}
+	return newCertificateJWKSServer(kid, der)
+}
+
+// newCertificate - a self-signed certificate of `key` issued to `orgID`
+func newCertificate(t *testing.T, key crypto.Signer, orgID string, notAfter time.Time) []byte {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: orgID},
+		NotBefore:    notAfter.Add(-24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+// newCertificateJWKSServer - serves a JWKS with the x5c of the certificate `der` for `kid`
+func newCertificateJWKSServer(kid string, der []byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"keys":[{"kid":%q,"use":"sig","x5c":[%q]}]}`, kid, base64.StdEncoding.EncodeToString(der))
+	}))
+}
+
+func failedChecks(verification SignatureVerification) []string {
+	failed := []string{}
+	for _, check := range verification.Checks {
+		if !check.Pass {
+			failed = append(failed, check.Name)
+		}
+	}
+	return failed
+}
+
+func TestVerifySignatureReportsEveryCheck(t *testing.T) {
+	require := test.NewRequire(t)
+
+	server, key := newSigningJWKS(t, "verify-kid-001", verificationIssuer, time.Now().Add(time.Hour))
+	defer server.Close()
+	signature, err := buildSignature(true, "verify-kid-001", verificationIssuer, "openbanking.org.uk", rawBody, SigningMethodPS256, key)
+	require.NoError(err)
+
+	verification := VerifySignature(signature, rawBody, server.URL, true)
+
+	require.True(verification.Valid)
+	require.NoError(verification.Err())
+	require.Equal("verify-kid-001", verification.Kid)
+	require.Equal("PS256", verification.Alg)
+	require.Equal(verificationIssuer, verification.Issuer)
+	require.Equal("openbanking.org.uk", verification.TrustAnchor)
+	require.NotEmpty(verification.IssuedAt)
+	require.Len(verification.Critical, 3)
+	require.Nil(verification.B64)
+	require.Len(verification.BodyDigest, 64)
+	require.Empty(failedChecks(verification))
+	names := []string{}
+	for _, check := range verification.Checks {
+		names = append(names, check.Name)
+	}
+	require.Equal([]string{"typ", "alg", "kid", "cty", "b64", "crit", "iat", "tan", "iss",
+		SignatureCheckCertificate, SignatureCheckCertificateChain, SignatureCheckIssuer, SignatureCheckBody}, names)
+
+	valid, err := ValidateSignature(signature, rawBody, server.URL, true)
+	require.NoError(err)
+	require.True(valid)
+}
+
+func TestVerifySignatureTamperedBody(t *testing.T) {
+	require := test.NewRequire(t)
+
+	server, key := newSigningJWKS(t, "verify-kid-002", verificationIssuer, time.Now().Add(time.Hour))
+	defer server.Close()
+	signature, err := buildSignature(true, "verify-kid-002", verificationIssuer, "openbanking.org.uk", rawBody, SigningMethodPS256, key)
+	require.NoError(err)
+
+	verification := VerifySignature(signature, rawBody+" ", server.URL, true)
+
+	require.False(verification.Valid)
+	require.Equal([]string{SignatureCheckBody}, failedChecks(verification))
+	require.EqualError(verification.Err(), "failed to verify message")
+}
+
+func TestVerifySignatureCertificateProblems(t *testing.T) {
+	require := test.NewRequire(t)
+
+	// expired certificate issued to another organisation
+	server, key := newSigningJWKS(t, "verify-kid-003", "0015800001041RZZZZ", time.Now().Add(-time.Hour))
+	defer server.Close()
+	signature, err := buildSignature(true, "verify-kid-003", verificationIssuer, "openbanking.org.uk", rawBody, SigningMethodPS256, key)
+	require.NoError(err)
+
+	verification := VerifySignature(signature, rawBody, server.URL, true)
+
+	require.False(verification.Valid)
+	require.Equal([]string{SignatureCheckCertificateChain, SignatureCheckIssuer}, failedChecks(verification))
+
+	// unknown kid: the certificate cannot be found so the signature is not checked
+	signature, err = buildSignature(true, "verify-kid-unknown", verificationIssuer, "openbanking.org.uk", rawBody, SigningMethodPS256, key)
+	require.NoError(err)
+
+	verification = VerifySignature(signature, rawBody, server.URL, true)
+
+	require.False(verification.Valid)
+	require.Equal([]string{SignatureCheckCertificate}, failedChecks(verification))
+	require.Equal(SignatureCheckCertificate, verification.Checks[len(verification.Checks)-1].Name)
+}
+
+func TestVerifySignatureUntrustedCertificate(t *testing.T) {
+	require := test.NewRequire(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(err)
+	der := newCertificate(t, key, verificationIssuer, time.Now().Add(time.Hour))
+	server := newCertificateJWKSServer("verify-kid-004", der)
+	defer server.Close()
+
+	// a certificate that does not chain to the OB directory roots is untrusted
+	signature, err := buildSignature(true, "verify-kid-004", verificationIssuer, OBTrustAnchor, rawBody, SigningMethodPS256, key)
+	require.NoError(err)
+	verification := VerifySignature(signature, rawBody, server.URL, true)
+	require.False(verification.Valid)
+	require.Equal([]string{SignatureCheckCertificateChain}, failedChecks(verification))
+	require.Contains(verification.Err().Error(), `certificate "CN=0015800001041RbAAI" is untrusted, it does not chain to a root certificate of trust anchor "openbanking.org.uk"`)
+
+	// nor is a certificate of a trust anchor without configured roots
+	signature, err = buildSignature(true, "verify-kid-004", verificationIssuer, "ob.hsbc.co.uk", rawBody, SigningMethodPS256, key)
+	require.NoError(err)
+	verification = VerifySignature(signature, rawBody, server.URL, true)
+	require.False(verification.Valid)
+	require.Equal([]string{SignatureCheckCertificateChain}, failedChecks(verification))
+	require.EqualError(verification.Err(), `certificate "CN=0015800001041RbAAI" is untrusted, no root certificates are configured for trust anchor "ob.hsbc.co.uk"`)
+
+	require.EqualError(AddTrustAnchorRoots("ob.hsbc.co.uk", []byte("not PEM")), "no PEM encoded certificates found for trust anchor ob.hsbc.co.uk")
+	require.NoError(AddTrustAnchorRoots("ob.hsbc.co.uk", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})))
+	verification = VerifySignature(signature, rawBody, server.URL, true)
+	require.True(verification.Valid, verification.String())
+}
+
+func TestVerifySignatureInvalidHeader(t *testing.T) {
+	require := test.NewRequire(t)
+
+	verification := VerifySignature("not a signature", rawBody, "http://localhost", true)
+
+	require.False(verification.Valid)
+	require.Equal([]string{SignatureCheckHeader}, failedChecks(verification))
+	require.Error(verification.Err())
+}
+
+func TestVerifySignatureES256AndEdDSAFailAlgCheck(t *testing.T) {
+	require := test.NewRequire(t)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(err)
+
+	for _, signer := range []struct {
+		alg string
+		key crypto.Signer
+	}{
+		{"ES256", ecKey},
+		{"EdDSA", edKey},
+	} {
+		server := newSigningJWKSForKey(t, signer.key, "verify-"+signer.alg, verificationIssuer, time.Now().Add(time.Hour))
+		alg, err := GetSigningAlg(signer.alg)
+		require.NoError(err)
+		signature, err := buildSignature(true, "verify-"+signer.alg, verificationIssuer, "openbanking.org.uk", rawBody, alg, signer.key)
+		require.NoError(err)
+
+		// the OB signing profile requires PS256 response signatures, the signature itself verifies
+		verification := VerifySignature(signature, rawBody, server.URL, true)
+		require.False(verification.Valid, signer.alg)
+		require.Equal(signer.alg, verification.Alg)
+		require.Equal([]string{"alg"}, failedChecks(verification), signer.alg)
+
+		verification = VerifySignature(signature, rawBody+" ", server.URL, true)
+		require.Equal([]string{"alg", SignatureCheckBody}, failedChecks(verification), signer.alg)
+		server.Close()
+	}
+}
@@ -1,18 +1,139 @@
 package authentication
 
 import (
-	"crypto/tls"
 	"crypto/x509"
 	"encoding/base64"
-	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
-	"net/http"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
 
-	"github.com/sirupsen/logrus"
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication/certificates"
 )
 
-var jwkCache = make(map[string]JWK)
+// OBTrustAnchor - the `tan` of signatures made with a certificate of the OB directory
+const OBTrustAnchor = "openbanking.org.uk"
+
+// trustAnchor - the root certificates the signing certificates of a trust anchor chain to, and the issuing
+// certificates the trust anchor does not publish in the `x5c` of its keys
+type trustAnchor struct {
+	roots         []*x509.Certificate
+	intermediates []*x509.Certificate
+}
+
+var (
+	trustAnchorsLock sync.RWMutex
+	trustAnchors     = map[string]trustAnchor{
+		OBTrustAnchor: {
+			roots:         mustParsePEMCertificates(certificates.OpenBankingRootCA(), certificates.OpenBankingSandBoxRootCA()),
+			intermediates: mustParsePEMCertificates(certificates.OpenBankingIssuingCA(), certificates.OpenBankingSandBoxIssuingCA()),
+		},
+	}
+)
+
+// AddTrustAnchorRoots trusts the PEM encoded certificates of `pemCerts` as root certificates of the trust anchor `tan`,
+// in addition to the OB directory roots for OBTrustAnchor
+func AddTrustAnchorRoots(tan string, pemCerts []byte) error {
+	roots, err := parsePEMCertificates(pemCerts)
+	if err != nil {
+		return err
+	}
+	if len(roots) == 0 {
+		return fmt.Errorf("no PEM encoded certificates found for trust anchor %s", tan)
+	}
+
+	trustAnchorsLock.Lock()
+	defer trustAnchorsLock.Unlock()
+	anchor := trustAnchors[tan]
+	anchor.roots = append(append([]*x509.Certificate{}, anchor.roots...), roots...)
+	trustAnchors[tan] = anchor
+	return nil
+}
+
+// AddTrustAnchorRootFiles trusts the certificates of PEM files as root certificates of trust anchors, see
+// AddTrustAnchorRoots. Each entry is `<tan>=<PEM file>`, e.g. `openbanking.org.uk=mock-aspsp.pem`.
+func AddTrustAnchorRootFiles(entries []string) error {
+	for _, entry := range entries {
+		separator := strings.Index(entry, "=")
+		if separator <= 0 {
+			return fmt.Errorf("trust anchor certificates %q are not given as <tan>=<PEM file>", entry)
+		}
+		tan, filename := entry[:separator], entry[separator+1:]
+		pemCerts, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return fmt.Errorf("trust anchor %s certificates: %v", tan, err)
+		}
+		if err := AddTrustAnchorRoots(tan, pemCerts); err != nil {
+			return fmt.Errorf("trust anchor %s certificates %s: %v", tan, filename, err)
+		}
+	}
+	return nil
+}
+
+// verifyTrustAnchorChain checks the signing certificate of an `x5c` chain chains to a root certificate of the
+// trust anchor `tan`, certificates of trust anchors without roots are untrusted
+func verifyTrustAnchorChain(certs []*x509.Certificate, tan string, now time.Time) error {
+	trustAnchorsLock.RLock()
+	anchor, ok := trustAnchors[tan]
+	trustAnchorsLock.RUnlock()
+	if !ok || len(anchor.roots) == 0 {
+		return fmt.Errorf("certificate %q is untrusted, no root certificates are configured for trust anchor %q", certs[0].Subject, tan)
+	}
+
+	roots := x509.NewCertPool()
+	for _, root := range anchor.roots {
+		roots.AddCert(root)
+	}
+	intermediates := x509.NewCertPool()
+	for _, intermediate := range append(append([]*x509.Certificate{}, anchor.intermediates...), certs[1:]...) {
+		intermediates.AddCert(intermediate)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return fmt.Errorf("certificate %q is untrusted, it does not chain to a root certificate of trust anchor %q: %v", certs[0].Subject, tan, err)
+	}
+	return nil
+}
+
+// parsePEMCertificates returns the certificates of the PEM blocks of `pemCerts`
+func parsePEMCertificates(pemCerts []byte) ([]*x509.Certificate, error) {
+	certs := []*x509.Certificate{}
+	for {
+		var block *pem.Block
+		block, pemCerts = pem.Decode(pemCerts)
+		if block == nil {
+			return certs, nil
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.New("parsePEMCertificates: parse certificate: " + err.Error())
+		}
+		certs = append(certs, cert)
+	}
+}
+
+func mustParsePEMCertificates(pemCerts ...[]byte) []*x509.Certificate {
+	certs := []*x509.Certificate{}
+	for _, pemCert := range pemCerts {
+		parsed, err := parsePEMCertificates(pemCert)
+		if err != nil {
+			panic(err)
+		}
+		certs = append(certs, parsed...)
+	}
+	return certs
+}
 
 var hsbcTanList = []string{
 	"https://ob.hsbc.co.uk/jwks/public.jwks",
@@ -41,68 +162,39 @@ func isHSBCTrustAnchor(tan string) bool {
 // getCertForKid
 // Given a Kid - return the public cert from the JWKS keystore of the TrustAnchor
 func getCertForKid(kid, jwks_uri string) (*x509.Certificate, error) {
-	jwk, err := getJwkFromJwks(kid, jwks_uri)
+	certs, err := getCertChainForKid(jwksKeyCache, kid, jwks_uri)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(jwk.X5c) == 0 {
-		return nil, errors.New(fmt.Sprintf("No X5c certificate chain found for kid %s", kid))
-	}
+	cert := certs[0] // assumes a single certificate in chain which is the style used by the OB directory
+
+	return cert, nil
+}
 
-	certs, err := parseCertificateChain(jwk.X5c)
+// getCertChainForKid
+// Given a Kid - return the x5c certificate chain from the JWKS keystore of the TrustAnchor cached in `cache`, signing cert first
+func getCertChainForKid(cache *JWKSCache, kid, jwks_uri string) ([]*x509.Certificate, error) {
+	jwk, err := getJwkFromJwks(cache, kid, jwks_uri)
 	if err != nil {
 		return nil, err
 	}
 
-	cert := certs[0] // assumes a single certificate in chain which is the style used by the OB directory
+	if len(jwk.X5c) == 0 {
+		return nil, errors.New(fmt.Sprintf("No X5c certificate chain found for kid %s", kid))
+	}
 
-	return cert, nil
+	return parseCertificateChain(jwk.X5c)
 }
 
 // getJwkFromJwks
 // Retieve the jwk representing a single public key from the jwks keystore
-func getJwkFromJwks(kid, jwks string) (JWK, error) {
-	if jwk, ok := jwkCache[kid]; !ok {
-		logrus.Traceln("Retrieving JWKS url: " + jwks)
-		jwks, err := getJwks(jwks)
-		if err != nil {
-			return JWK{}, fmt.Errorf("GetJwkFromJwks: errors: %v", err)
-		}
-		for _, k := range jwks.Keys {
-			if k.Kid == kid {
-				jwkCache[kid] = k
-				return k, nil
-			}
-		}
-	} else {
-		logrus.Traceln("Using cached jwk")
-		return jwk, nil
-	}
-	logrus.Traceln("no matching key found")
-	return JWK{}, nil
-}
-
-// getJwks
-// download the JWKS key store from the given url
-func getJwks(url string) (JWKS, error) {
-	tr := &http.Transport{
-		Proxy:           http.ProxyFromEnvironment,
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-	}
-	client := &http.Client{Transport: tr}
-	resp, err := client.Get(url)
+func getJwkFromJwks(cache *JWKSCache, kid, jwks string) (JWK, error) {
+	jwk, err := cache.key(kid, jwks)
 	if err != nil {
-		return JWKS{}, fmt.Errorf("GetJwkss error retrieving url: %s, %v", url, err)
-	}
-	defer resp.Body.Close()
-	jwksbytes := json.NewDecoder(resp.Body)
-	var jwks JWKS
-	if err := jwksbytes.Decode(&jwks); err != nil {
-		return JWKS{}, fmt.Errorf("GetJwks: decoding error %s : %v", url, err)
+		return JWK{}, fmt.Errorf("GetJwkFromJwks: errors: %v", err)
 	}
-
-	return jwks, nil
+	return jwk, nil
 }
 
 // parseCertificateChain
@@ -1,7 +1,12 @@
 package authentication
 
 import (
+	"io/ioutil"
+	"os"
 	"testing"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication/certificates"
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
 )
 
 var hsbcResponseSignature = "eyJodHRwOlwvXC9vcGVuYmFua2luZy5vcmcudWtcL2lhdCI6MTYwMTkyMjI5OSwiaHR0cDpcL1wvb3BlbmJhbmtpbmcub3JnLnVrXC90YW4iOiJzMy1ldS13ZXN0LTEuYW1hem9uYXdzLmNvbSIsImNyaXQiOlsiaHR0cDpcL1wvb3BlbmJhbmtpbmcub3JnLnVrXC9pYXQiLCJodHRwOlwvXC9vcGVuYmFua2luZy5vcmcudWtcL3RhbiIsImh0dHA6XC9cL29wZW5iYW5raW5nLm9yZy51a1wvaXNzIl0sImtpZCI6ImV4dGVybmFsXzIiLCJ0eXAiOiJKT1NFIiwiaHR0cDpcL1wvb3BlbmJhbmtpbmcub3JnLnVrXC9pc3MiOiJQb3N0YWxDb2RlPUIxIDFIUSwyLjUuNC45Nz1QU0RHQi1GQ0EtNzY1MTEyLENOPUhTQkMsU1RSRUVUPUJpcm1pbmdoYW0sTD1CaXJtaW5naGFtLE9VPTEgQ2VudGVuYXJ5IFNxdWFyZSxPPUhTQkMgVUssQz1VSyIsImFsZyI6IlBTMjU2In0..g3jvSLnCLo2x8E7LEsjLKjv6BVwctNBc3voHk6EhJ6v2gIuL5CYSIh4F0cJLGNEkz7jXNkXTilcSCeAYSaCkdumk6CosK-tdNj_AQXe0Ma1gQURJi5wfeNA_7uLAnSXW4nFzSe1wGjH4vUEf8nd72K5R-XGr3EOB41aYj37ON521c496IVQCDzsJ2aiS7KG4l-6-_IOIVto1utIaZfTJis2t1PDNHusFEOKq9tFCwVGz_cSEyhlBSl-blc6wik6Nket59UP3itUop1xNdaUecCA3-_CaqjWynvoA6ZH26h0tXtxczgk9BqKxweSn3VO7PEPRWD6_-GnBb6wSCev6VA"
@@ -80,3 +85,23 @@ func TestIsHSBCTrustAnchor(t *testing.T) {
 	}
 
 }
+
+func TestAddTrustAnchorRootFiles(t *testing.T) {
+	require := test.NewRequire(t)
+
+	require.NoError(AddTrustAnchorRootFiles(nil))
+	require.EqualError(AddTrustAnchorRootFiles([]string{"mock.pem"}),
+		`trust anchor certificates "mock.pem" are not given as <tan>=<PEM file>`)
+	require.EqualError(AddTrustAnchorRootFiles([]string{"ob.example.com=missing.pem"}),
+		"trust anchor ob.example.com certificates: open missing.pem: no such file or directory")
+
+	file, err := ioutil.TempFile("", "roots*.pem")
+	require.NoError(err)
+	defer os.Remove(file.Name())
+	require.NoError(file.Close())
+	require.EqualError(AddTrustAnchorRootFiles([]string{"ob.example.com=" + file.Name()}),
+		"trust anchor ob.example.com certificates "+file.Name()+": no PEM encoded certificates found for trust anchor ob.example.com")
+
+	require.NoError(ioutil.WriteFile(file.Name(), certificates.OpenBankingRootCA(), 0600))
+	require.NoError(AddTrustAnchorRootFiles([]string{"ob.example.com=" + file.Name()}))
+}
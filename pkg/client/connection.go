@@ -4,6 +4,7 @@ import (
 	"crypto/tls"
 	"errors"
 	"net/http"
+	"net/http/cookiejar"
 	"time"
 )
 
@@ -18,10 +19,16 @@ func NewConnection() (*Connection, error) {
 		Proxy:           http.ProxyFromEnvironment,
 		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
 	}
+	// the jar keeps the session cookie of servers that give each user their own session
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
 	return &Connection{
 		&http.Client{
 			Transport: tr,
 			Timeout:   5 * time.Minute,
+			Jar:       jar,
 		},
 	}, ErrInsecure
 }
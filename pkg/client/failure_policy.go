@@ -0,0 +1,98 @@
+package client
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// FailurePolicy decides if a run has failed based on its testcase results
+// so a pipeline can gate on conformance
+type FailurePolicy struct {
+	// MaxFailures is the number of counted failures tolerated before the run fails
+	MaxFailures int
+	// FailOnAPIs when not empty only counts failures from APIs whose name contains
+	// one of these values, case insensitive
+	FailOnAPIs []string
+	// AllowFail is a list of test ID glob patterns, see path.Match, whose failures are never counted
+	AllowFail []string
+}
+
+// PolicyResult is the outcome of evaluating a FailurePolicy
+type PolicyResult struct {
+	Total   int        // number of test cases
+	Failed  int        // number of failed test cases
+	Skipped int        // number of test cases skipped because a test case they depend on failed
+	Allowed int        // number of failed test cases ignored by the policy
+	Counted []TestCase // failed test cases counted by the policy
+	Passed  bool       // true if counted failures are within MaxFailures
+}
+
+// Validate checks max failures is not negative and all allow fail patterns are well formed
+func (p FailurePolicy) Validate() error {
+	if p.MaxFailures < 0 {
+		return fmt.Errorf("max failures must not be negative, got %d", p.MaxFailures)
+	}
+	for _, pattern := range p.AllowFail {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid allow fail pattern %q: %s", pattern, err.Error())
+		}
+	}
+	return nil
+}
+
+// Evaluate applies the policy to a list of testcase results
+func (p FailurePolicy) Evaluate(results []TestCase) (PolicyResult, error) {
+	if err := p.Validate(); err != nil {
+		return PolicyResult{}, err
+	}
+
+	policyResult := PolicyResult{Total: len(results), Counted: []TestCase{}}
+	for _, result := range results {
+		if result.Pass {
+			continue
+		}
+		if result.Skipped {
+			policyResult.Skipped++
+			continue
+		}
+		policyResult.Failed++
+
+		if p.isAllowedToFail(result) || !p.isAPICounted(result) {
+			policyResult.Allowed++
+			continue
+		}
+		policyResult.Counted = append(policyResult.Counted, result)
+	}
+	policyResult.Passed = len(policyResult.Counted) <= p.MaxFailures
+
+	return policyResult, nil
+}
+
+func (p FailurePolicy) isAllowedToFail(result TestCase) bool {
+	for _, pattern := range p.AllowFail {
+		// pattern has already been validated so error can be ignored
+		if matched, _ := path.Match(pattern, result.Id); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (p FailurePolicy) isAPICounted(result TestCase) bool {
+	if len(p.FailOnAPIs) == 0 {
+		return true
+	}
+	apiName := strings.ToLower(result.API)
+	for _, api := range p.FailOnAPIs {
+		if strings.Contains(apiName, strings.ToLower(api)) {
+			return true
+		}
+	}
+	return false
+}
+
+// String summary of the policy result
+func (r PolicyResult) String() string {
+	return fmt.Sprintf("%d test cases, %d failed, %d skipped, %d failures allowed, %d failures counted", r.Total, r.Failed, r.Skipped, r.Allowed, len(r.Counted))
+}
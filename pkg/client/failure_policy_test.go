@@ -0,0 +1,82 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailurePolicyDefaultFailsOnAnyFailure(t *testing.T) {
+	result, err := FailurePolicy{}.Evaluate(sampleResults())
+
+	require.NoError(t, err)
+	assert.False(t, result.Passed)
+	assert.Equal(t, 3, result.Total)
+	assert.Equal(t, 2, result.Failed)
+	assert.Equal(t, 0, result.Allowed)
+	assert.Len(t, result.Counted, 2)
+	assert.Equal(t, "3 test cases, 2 failed, 0 skipped, 0 failures allowed, 2 failures counted", result.String())
+}
+
+func TestFailurePolicyPassesWithNoFailures(t *testing.T) {
+	result, err := FailurePolicy{}.Evaluate(sampleResults()[:1])
+
+	require.NoError(t, err)
+	assert.True(t, result.Passed)
+	assert.Empty(t, result.Counted)
+}
+
+func TestFailurePolicyMaxFailures(t *testing.T) {
+	result, err := FailurePolicy{MaxFailures: 2}.Evaluate(sampleResults())
+
+	require.NoError(t, err)
+	assert.True(t, result.Passed)
+
+	result, err = FailurePolicy{MaxFailures: 1}.Evaluate(sampleResults())
+
+	require.NoError(t, err)
+	assert.False(t, result.Passed)
+}
+
+func TestFailurePolicyAllowFail(t *testing.T) {
+	policy := FailurePolicy{AllowFail: []string{"OB-301-PIS-*", "OB-301-ACC-811741"}}
+
+	result, err := policy.Evaluate(sampleResults())
+
+	require.NoError(t, err)
+	assert.True(t, result.Passed)
+	assert.Equal(t, 2, result.Allowed)
+}
+
+func TestFailurePolicyFailOnAPI(t *testing.T) {
+	policy := FailurePolicy{FailOnAPIs: []string{"payment initiation"}}
+
+	result, err := policy.Evaluate(sampleResults())
+
+	require.NoError(t, err)
+	assert.False(t, result.Passed)
+	assert.Equal(t, 1, result.Allowed)
+	require.Len(t, result.Counted, 1)
+	assert.Equal(t, "OB-301-PIS-100100", result.Counted[0].Id)
+}
+
+func TestFailurePolicyInvalid(t *testing.T) {
+	_, err := FailurePolicy{AllowFail: []string{"OB-[301"}}.Evaluate(sampleResults())
+	assert.EqualError(t, err, `invalid allow fail pattern "OB-[301": syntax error in pattern`)
+
+	_, err = FailurePolicy{MaxFailures: -1}.Evaluate(sampleResults())
+	assert.EqualError(t, err, "max failures must not be negative, got -1")
+}
+
+func TestFailurePolicySkippedNotCounted(t *testing.T) {
+	results := append(sampleResults(), TestCase{Id: "OB-301-PIS-100200", Fail: []string{"skipped: upstream failed: OB-301-PIS-100100"}, Skipped: true})
+
+	result, err := FailurePolicy{MaxFailures: 2}.Evaluate(results)
+
+	require.NoError(t, err)
+	assert.True(t, result.Passed)
+	assert.Equal(t, 4, result.Total)
+	assert.Equal(t, 2, result.Failed)
+	assert.Equal(t, 1, result.Skipped)
+}
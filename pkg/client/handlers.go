@@ -46,6 +46,8 @@ func handlerTestCaseResult(resultChan chan<- TestCase) msgHandlerFunc {
 			return errors.Wrap(err, "test case result handler")
 		}
 		if tcResult.Type == "ResultType_TestCaseResult" {
+			tcResult.Test.API = tcResult.API
+			tcResult.Test.APIVersion = tcResult.APIVersion
 			resultChan <- tcResult.Test
 		}
 		return nil
@@ -53,15 +55,32 @@ func handlerTestCaseResult(resultChan chan<- TestCase) msgHandlerFunc {
 }
 
 type TestCaseResult struct {
-	Type string   `json:"type"`
-	Test TestCase `json:"test"`
+	Type       string   `json:"type"`
+	API        string   `json:"api"`
+	APIVersion string   `json:"apiVersion"`
+	Test       TestCase `json:"test"`
 }
 
 // TestCase result for a run
 type TestCase struct {
-	Id   string `json:"id"`
-	Pass bool   `json:"pass"`
-	Fail string `json:"fail,omitempty"`
+	Id         string   `json:"id"`
+	Pass       bool     `json:"pass"`
+	Fail       []string `json:"fail,omitempty"`
+	Detail     string   `json:"detail"`
+	RefURI     string   `json:"refURI"`
+	Endpoint   string   `json:"endpoint"`
+	HttpStatus string   `json:"httpStatusCode"`
+	Metrics    Metrics  `json:"metrics"`
+	API        string   `json:"api,omitempty"`
+	APIVersion string   `json:"apiVersion,omitempty"`
+	Skipped    bool     `json:"skipped,omitempty"` // not run because a test case it depends on failed
+}
+
+// Metrics for a test case run, response time is in milliseconds
+type Metrics struct {
+	ResponseTime float64 `json:"response_time"`
+	ResponseSize int     `json:"response_size"`
+	Attempts     int     `json:"attempts,omitempty"` // more than one when the request was retried
 }
 
 type event struct {
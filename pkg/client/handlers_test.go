@@ -36,6 +36,19 @@ func TestMsgHandlersChain(t *testing.T) {
 			assert.Equal(t, TestCase{}, <-resultChan)
 		})
 
+		t.Run("a test case result should carry its api name and version", func(t *testing.T) {
+			msg := []byte(`{"type": "ResultType_TestCaseResult", "api": "Payment Initiation API", "apiVersion": "v3.1.8", "test": {"id": "OB-301-PIS-100100", "fail": ["status code 400 expected 201"]}}`)
+			err := processor.process(msg)
+			assert.NoError(t, err)
+			expected := TestCase{
+				Id:         "OB-301-PIS-100100",
+				Fail:       []string{"status code 400 expected 201"},
+				API:        "Payment Initiation API",
+				APIVersion: "v3.1.8",
+			}
+			assert.Equal(t, expected, <-resultChan)
+		})
+
 		t.Run("a test cases completed should send a message on endedChan", func(t *testing.T) {
 			msg := []byte(`{"type": "ResultType_TestCasesCompleted"}`)
 			err := processor.process(msg)
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// junitTestSuites is the root element of a JUnit XML report
+// https://llg.cubic.org/docs/junit/
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite groups the test cases of one API name and version
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name       string           `xml:"name,attr"`
+	ClassName  string           `xml:"classname,attr"`
+	Time       string           `xml:"time,attr"`
+	Properties *junitProperties `xml:"properties,omitempty"`
+	Skipped    *junitSkipped    `xml:"skipped,omitempty"`
+	Failure    *junitFailure    `xml:"failure,omitempty"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+type junitProperties struct {
+	Properties []junitProperty `xml:"property"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitFailure struct {
+	Message  string `xml:"message,attr"`
+	Type     string `xml:"type,attr"`
+	Contents string `xml:",chardata"`
+}
+
+// JUnitResultWriter writes testcase results to a writer as JUnit XML, one test suite per API name and version
+func JUnitResultWriter(w io.Writer, results []TestCase) error {
+	report := junitTestSuites{Name: "Functional Conformance Suite"}
+	var totalTime float64
+	for _, group := range groupResults(results) {
+		suite := junitTestSuite{Name: group.name()}
+		var suiteTime float64
+		for _, result := range group.Results {
+			suite.TestCases = append(suite.TestCases, newJUnitTestCase(group, result))
+			suite.Tests++
+			if result.Skipped {
+				suite.Skipped++
+			} else if !result.Pass {
+				suite.Failures++
+			}
+			suiteTime += result.Metrics.ResponseTime
+		}
+		suite.Time = junitSeconds(suiteTime)
+		report.Suites = append(report.Suites, suite)
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Skipped += suite.Skipped
+		totalTime += suiteTime
+	}
+	report.Time = junitSeconds(totalTime)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func newJUnitTestCase(group resultGroup, result TestCase) junitTestCase {
+	testCase := junitTestCase{
+		Name:      result.Id,
+		ClassName: group.name(),
+		Time:      junitSeconds(result.Metrics.ResponseTime),
+	}
+
+	properties := []junitProperty{}
+	for _, property := range []junitProperty{
+		{Name: "endpoint", Value: result.Endpoint},
+		{Name: "refURI", Value: result.RefURI},
+		{Name: "detail", Value: result.Detail},
+		{Name: "httpStatusCode", Value: result.HttpStatus},
+	} {
+		if property.Value != "" {
+			properties = append(properties, property)
+		}
+	}
+	if len(properties) > 0 {
+		testCase.Properties = &junitProperties{Properties: properties}
+	}
+
+	if result.Skipped {
+		testCase.Skipped = &junitSkipped{Message: strings.Join(result.Fail, "; ")}
+	} else if !result.Pass {
+		message := "test case failed"
+		if len(result.Fail) > 0 {
+			message = result.Fail[0]
+		}
+		testCase.Failure = &junitFailure{
+			Message:  message,
+			Type:     "ConformanceFailure",
+			Contents: strings.Join(result.Fail, "\n"),
+		}
+	}
+	return testCase
+}
+
+// junitSeconds formats a duration in milliseconds as seconds
+func junitSeconds(milliseconds float64) string {
+	return fmt.Sprintf("%.3f", milliseconds/1000)
+}
@@ -1,17 +1,150 @@
 package client
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
+	"strings"
 )
 
+// Supported result formats
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatJUnit = "junit"
+	FormatSARIF = "sarif"
+	FormatTAP   = "tap"
+)
+
+// Formats lists all the supported result formats
+func Formats() []string {
+	return []string{FormatText, FormatJSON, FormatJUnit, FormatSARIF, FormatTAP}
+}
+
+// IsSupportedFormat checks if format is one of the supported result formats
+func IsSupportedFormat(format string) bool {
+	for _, supported := range Formats() {
+		if format == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteResults writes testcase results to a writer in the given format
+func WriteResults(w io.Writer, format string, results []TestCase) error {
+	switch format {
+	case FormatText, "":
+		ResultWriter(w, results)
+		return nil
+	case FormatJSON:
+		return JSONResultWriter(w, results)
+	case FormatJUnit:
+		return JUnitResultWriter(w, results)
+	case FormatSARIF:
+		return SARIFResultWriter(w, results)
+	case FormatTAP:
+		return TAPResultWriter(w, results)
+	}
+	return fmt.Errorf("unsupported result format %q, expected one of %s", format, strings.Join(Formats(), ", "))
+}
+
 // ResultWriter writes testcase results to a writer
 func ResultWriter(w io.Writer, results []TestCase) {
 	var passMsg = map[bool]string{true: "PASS", false: "FAIL"}
 	for _, result := range results {
-		fmt.Fprintf(w, "=== %s: %s\n", passMsg[result.Pass], result.Id)
+		msg := passMsg[result.Pass]
+		if result.Skipped {
+			msg = "SKIP"
+		}
+		fmt.Fprintf(w, "=== %s: %s\n", msg, result.Id)
 		if !result.Pass {
-			fmt.Fprintf(w, "\t %s\n", result.Fail)
+			for _, reason := range result.Fail {
+				fmt.Fprintf(w, "\t %s\n", reason)
+			}
+		}
+	}
+}
+
+// JSONResultWriter writes testcase results to a writer as a JSON array
+func JSONResultWriter(w io.Writer, results []TestCase) error {
+	if results == nil {
+		results = []TestCase{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(results)
+}
+
+// TAPResultWriter writes testcase results to a writer using the Test Anything Protocol version 13
+// https://testanything.org/tap-version-13-specification.html
+func TAPResultWriter(w io.Writer, results []TestCase) error {
+	var okMsg = map[bool]string{true: "ok", false: "not ok"}
+	if _, err := fmt.Fprintf(w, "TAP version 13\n1..%d\n", len(results)); err != nil {
+		return err
+	}
+	for i, result := range results {
+		if result.Skipped {
+			if _, err := fmt.Fprintf(w, "ok %d - %s # SKIP %s\n", i+1, result.Id, strings.Join(result.Fail, "; ")); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s %d - %s\n", okMsg[result.Pass], i+1, result.Id); err != nil {
+			return err
+		}
+		if result.Pass {
+			continue
+		}
+		fmt.Fprintln(w, "  ---")
+		fmt.Fprintf(w, "  endpoint: %q\n", result.Endpoint)
+		fmt.Fprintf(w, "  status: %q\n", result.HttpStatus)
+		fmt.Fprintln(w, "  fail:")
+		for _, reason := range result.Fail {
+			fmt.Fprintf(w, "    - %q\n", reason)
+		}
+		if _, err := fmt.Fprintln(w, "  ..."); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resultGroup is the list of results for a single API name and version
+type resultGroup struct {
+	API        string
+	APIVersion string
+	Results    []TestCase
+}
+
+func (g resultGroup) name() string {
+	if g.API == "" {
+		return "unknown"
+	}
+	if g.APIVersion == "" {
+		return g.API
+	}
+	return g.API + " " + g.APIVersion
+}
+
+// groupResults groups results by API name and version, keeping the order results were run in
+func groupResults(results []TestCase) []resultGroup {
+	type key struct{ api, version string }
+	groups := []resultGroup{}
+	index := map[key]int{}
+	for _, result := range results {
+		k := key{result.API, result.APIVersion}
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, resultGroup{API: result.API, APIVersion: result.APIVersion})
 		}
+		groups[i].Results = append(groups[i].Results, result)
 	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].name() < groups[j].name()
+	})
+	return groups
 }
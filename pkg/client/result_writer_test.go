@@ -0,0 +1,218 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleResults() []TestCase {
+	return []TestCase{
+		{
+			Id:         "OB-301-ACC-120382",
+			Pass:       true,
+			Endpoint:   "/accounts",
+			RefURI:     "https://openbanking.atlassian.net/wiki/spaces/DZ/pages/937820271",
+			HttpStatus: "200 OK",
+			Metrics:    Metrics{ResponseTime: 250, ResponseSize: 10},
+			API:        "Account and Transaction API Specification",
+			APIVersion: "v3.1.8",
+		},
+		{
+			Id:         "OB-301-PIS-100100",
+			Pass:       false,
+			Fail:       []string{"status code 400 expected 201", "missing x-fapi-interaction-id"},
+			Detail:     "Check that the resource succeeds posting a domestic payment consents.",
+			Endpoint:   "/domestic-payment-consents",
+			RefURI:     "https://openbanking.atlassian.net/wiki/spaces/DZ/pages/937984109",
+			HttpStatus: "400 Bad Request",
+			Metrics:    Metrics{ResponseTime: 750, ResponseSize: 20},
+			API:        "Payment Initiation API",
+			APIVersion: "v3.1.8",
+		},
+		{
+			Id:         "OB-301-ACC-811741",
+			Pass:       false,
+			Endpoint:   "/accounts/{AccountId}",
+			API:        "Account and Transaction API Specification",
+			APIVersion: "v3.1.8",
+		},
+	}
+}
+
+func TestWriteResultsText(t *testing.T) {
+	buff := &bytes.Buffer{}
+
+	require.NoError(t, WriteResults(buff, FormatText, sampleResults()))
+
+	expected := "=== PASS: OB-301-ACC-120382\n" +
+		"=== FAIL: OB-301-PIS-100100\n" +
+		"\t status code 400 expected 201\n" +
+		"\t missing x-fapi-interaction-id\n" +
+		"=== FAIL: OB-301-ACC-811741\n"
+	assert.Equal(t, expected, buff.String())
+}
+
+func TestWriteResultsUnsupportedFormat(t *testing.T) {
+	err := WriteResults(&bytes.Buffer{}, "csv", sampleResults())
+
+	assert.EqualError(t, err, `unsupported result format "csv", expected one of text, json, junit, sarif, tap`)
+	assert.False(t, IsSupportedFormat("csv"))
+	assert.True(t, IsSupportedFormat(FormatSARIF))
+}
+
+func TestWriteResultsJSON(t *testing.T) {
+	buff := &bytes.Buffer{}
+
+	require.NoError(t, WriteResults(buff, FormatJSON, sampleResults()))
+
+	var actual []TestCase
+	require.NoError(t, json.Unmarshal(buff.Bytes(), &actual))
+	assert.Equal(t, sampleResults(), actual)
+}
+
+func TestWriteResultsJUnit(t *testing.T) {
+	buff := &bytes.Buffer{}
+
+	require.NoError(t, WriteResults(buff, FormatJUnit, sampleResults()))
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="Functional Conformance Suite" tests="3" failures="2" skipped="0" time="1.000">
+  <testsuite name="Account and Transaction API Specification v3.1.8" tests="2" failures="1" skipped="0" time="0.250">
+    <testcase name="OB-301-ACC-120382" classname="Account and Transaction API Specification v3.1.8" time="0.250">
+      <properties>
+        <property name="endpoint" value="/accounts"></property>
+        <property name="refURI" value="https://openbanking.atlassian.net/wiki/spaces/DZ/pages/937820271"></property>
+        <property name="httpStatusCode" value="200 OK"></property>
+      </properties>
+    </testcase>
+    <testcase name="OB-301-ACC-811741" classname="Account and Transaction API Specification v3.1.8" time="0.000">
+      <properties>
+        <property name="endpoint" value="/accounts/{AccountId}"></property>
+      </properties>
+      <failure message="test case failed" type="ConformanceFailure"></failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="Payment Initiation API v3.1.8" tests="1" failures="1" skipped="0" time="0.750">
+    <testcase name="OB-301-PIS-100100" classname="Payment Initiation API v3.1.8" time="0.750">
+      <properties>
+        <property name="endpoint" value="/domestic-payment-consents"></property>
+        <property name="refURI" value="https://openbanking.atlassian.net/wiki/spaces/DZ/pages/937984109"></property>
+        <property name="detail" value="Check that the resource succeeds posting a domestic payment consents."></property>
+        <property name="httpStatusCode" value="400 Bad Request"></property>
+      </properties>
+      <failure message="status code 400 expected 201" type="ConformanceFailure">status code 400 expected 201&#xA;missing x-fapi-interaction-id</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	assert.Equal(t, expected, buff.String())
+}
+
+func TestWriteResultsSARIF(t *testing.T) {
+	buff := &bytes.Buffer{}
+
+	require.NoError(t, WriteResults(buff, FormatSARIF, sampleResults()))
+
+	expected := `{
+	"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+	"version": "2.1.0",
+	"runs": [{
+		"tool": {
+			"driver": {
+				"name": "Functional Conformance Suite",
+				"informationUri": "https://github.com/OpenBankingUK/conformance-suite",
+				"rules": [
+					{
+						"id": "OB-301-ACC-811741",
+						"properties": {"api": "Account and Transaction API Specification v3.1.8", "endpoint": "/accounts/{AccountId}"}
+					},
+					{
+						"id": "OB-301-PIS-100100",
+						"shortDescription": {"text": "Check that the resource succeeds posting a domestic payment consents."},
+						"helpUri": "https://openbanking.atlassian.net/wiki/spaces/DZ/pages/937984109",
+						"properties": {"api": "Payment Initiation API v3.1.8", "endpoint": "/domestic-payment-consents"}
+					}
+				]
+			}
+		},
+		"results": [
+			{
+				"ruleId": "OB-301-ACC-811741",
+				"ruleIndex": 0,
+				"level": "error",
+				"message": {"text": "test case failed"},
+				"locations": [{"logicalLocations": [{"name": "/accounts/{AccountId}", "fullyQualifiedName": "Account and Transaction API Specification v3.1.8/OB-301-ACC-811741", "kind": "resource"}]}]
+			},
+			{
+				"ruleId": "OB-301-PIS-100100",
+				"ruleIndex": 1,
+				"level": "error",
+				"message": {"text": "status code 400 expected 201\nmissing x-fapi-interaction-id"},
+				"locations": [{"logicalLocations": [{"name": "/domestic-payment-consents", "fullyQualifiedName": "Payment Initiation API v3.1.8/OB-301-PIS-100100", "kind": "resource"}]}]
+			}
+		]
+	}]
+}`
+	assert.JSONEq(t, expected, buff.String())
+}
+
+func TestWriteResultsTAP(t *testing.T) {
+	buff := &bytes.Buffer{}
+
+	require.NoError(t, WriteResults(buff, FormatTAP, sampleResults()))
+
+	expected := `TAP version 13
+1..3
+ok 1 - OB-301-ACC-120382
+not ok 2 - OB-301-PIS-100100
+  ---
+  endpoint: "/domestic-payment-consents"
+  status: "400 Bad Request"
+  fail:
+    - "status code 400 expected 201"
+    - "missing x-fapi-interaction-id"
+  ...
+not ok 3 - OB-301-ACC-811741
+  ---
+  endpoint: "/accounts/{AccountId}"
+  status: ""
+  fail:
+  ...
+`
+	assert.Equal(t, expected, buff.String())
+}
+
+func TestWriteResultsSkipped(t *testing.T) {
+	results := []TestCase{
+		{
+			Id:         "OB-301-DOP-100400",
+			Fail:       []string{"skipped: upstream failed: OB-301-DOP-100300"},
+			Endpoint:   "/domestic-payment-consents/$consentId",
+			API:        "Payment Initiation API",
+			APIVersion: "v3.1.8",
+			Skipped:    true,
+		},
+	}
+
+	buff := &bytes.Buffer{}
+	require.NoError(t, WriteResults(buff, FormatText, results))
+	assert.Equal(t, "=== SKIP: OB-301-DOP-100400\n\t skipped: upstream failed: OB-301-DOP-100300\n", buff.String())
+
+	buff.Reset()
+	require.NoError(t, WriteResults(buff, FormatTAP, results))
+	assert.Equal(t, "TAP version 13\n1..1\nok 1 - OB-301-DOP-100400 # SKIP skipped: upstream failed: OB-301-DOP-100300\n", buff.String())
+
+	buff.Reset()
+	require.NoError(t, WriteResults(buff, FormatJUnit, results))
+	assert.Contains(t, buff.String(), `<testsuites name="Functional Conformance Suite" tests="1" failures="0" skipped="1" time="0.000">`)
+	assert.Contains(t, buff.String(), `<skipped message="skipped: upstream failed: OB-301-DOP-100300"></skipped>`)
+	assert.NotContains(t, buff.String(), "<failure")
+
+	buff.Reset()
+	require.NoError(t, WriteResults(buff, FormatSARIF, results))
+	assert.Contains(t, buff.String(), `"results": []`)
+}
@@ -0,0 +1,149 @@
+package client
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+const (
+	sarifSchema         = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion        = "2.1.0"
+	sarifToolName       = "Functional Conformance Suite"
+	sarifToolInfoURI    = "https://github.com/OpenBankingUK/conformance-suite"
+	sarifLevelError     = "error"
+	sarifLocationKind   = "resource"
+	sarifDefaultMessage = "test case failed"
+)
+
+// sarifLog is the root of a SARIF 2.1.0 log
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+// sarifRule describes a manifest test, its id is the manifest script id
+type sarifRule struct {
+	ID               string            `json:"id"`
+	ShortDescription *sarifMessage     `json:"shortDescription,omitempty"`
+	HelpURI          string            `json:"helpUri,omitempty"`
+	Properties       map[string]string `json:"properties,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// SARIFResultWriter writes failed testcase results, not skipped ones, to a writer as a SARIF log,
+// each failure references a rule identified by the failing manifest script id
+func SARIFResultWriter(w io.Writer, results []TestCase) error {
+	driver := sarifDriver{
+		Name:           sarifToolName,
+		InformationURI: sarifToolInfoURI,
+		Rules:          []sarifRule{},
+	}
+	sarifResults := []sarifResult{}
+	ruleIndex := map[string]int{}
+
+	for _, group := range groupResults(results) {
+		for _, result := range group.Results {
+			if result.Pass || result.Skipped {
+				continue
+			}
+
+			index, ok := ruleIndex[result.Id]
+			if !ok {
+				index = len(driver.Rules)
+				ruleIndex[result.Id] = index
+				driver.Rules = append(driver.Rules, newSARIFRule(group, result))
+			}
+
+			message := sarifDefaultMessage
+			if len(result.Fail) > 0 {
+				message = strings.Join(result.Fail, "\n")
+			}
+			sarifResults = append(sarifResults, sarifResult{
+				RuleID:    result.Id,
+				RuleIndex: index,
+				Level:     sarifLevelError,
+				Message:   sarifMessage{Text: message},
+				Locations: []sarifLocation{
+					{
+						LogicalLocations: []sarifLogicalLocation{
+							{
+								Name:               result.Endpoint,
+								FullyQualifiedName: group.name() + "/" + result.Id,
+								Kind:               sarifLocationKind,
+							},
+						},
+					},
+				},
+			})
+		}
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{
+			{
+				Tool:    sarifTool{Driver: driver},
+				Results: sarifResults,
+			},
+		},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
+
+func newSARIFRule(group resultGroup, result TestCase) sarifRule {
+	rule := sarifRule{
+		ID:      result.Id,
+		HelpURI: result.RefURI,
+		Properties: map[string]string{
+			"api":      group.name(),
+			"endpoint": result.Endpoint,
+		},
+	}
+	if result.Detail != "" {
+		rule.ShortDescription = &sarifMessage{Text: result.Detail}
+	}
+	return rule
+}
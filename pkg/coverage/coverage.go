@@ -0,0 +1,183 @@
+// Package coverage compares what a run exercised with the bundled OpenAPI spec of each API version:
+// the operations called, the response codes received and the response body properties seen.
+package coverage
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/schema"
+	"github.com/OpenBankingUK/conformance-suite/pkg/schemaprops"
+)
+
+// Conditionality of an operation, empty when the suite does not know it
+const (
+	ConditionMandatory   = "mandatory"
+	ConditionConditional = "conditional"
+	ConditionOptional    = "optional"
+)
+
+// APICoverage - the coverage of the spec of an API version by a run
+type APICoverage struct {
+	API           string              `json:"api"`
+	Version       string              `json:"version"`
+	Operations    []OperationCoverage `json:"operations"`
+	OperationsHit Count               `json:"operationsHit"`
+	ResponsesHit  Count               `json:"responsesHit"`
+	PropertiesHit Count               `json:"propertiesHit"`
+	Error         string              `json:"error,omitempty"` // the spec could not be loaded
+}
+
+// Count - how many of a total were exercised
+type Count struct {
+	Exercised int `json:"exercised"`
+	Total     int `json:"total"`
+}
+
+// OperationCoverage - an operation of the spec, whether the discovery model lists it and whether the run called it
+type OperationCoverage struct {
+	Method     string             `json:"method"`
+	Path       string             `json:"path"`
+	Condition  string             `json:"condition,omitempty"`
+	Discovered bool               `json:"discovered"`
+	Exercised  bool               `json:"exercised"`
+	Responses  []ResponseCoverage `json:"responses"`
+}
+
+// ResponseCoverage - a response code of an operation and which of its body properties the run saw
+type ResponseCoverage struct {
+	Code         string   `json:"code"`
+	Exercised    bool     `json:"exercised"`
+	Properties   Count    `json:"properties"`
+	NotExercised []string `json:"notExercised,omitempty"` // properties of an exercised response never seen
+}
+
+// responseFields - the JSON `schemaprops.PropertyCollector.OutputJSON` writes
+type responseFields struct {
+	ResponseFields []schemaprops.PropertyOutput `json:"responseFields"`
+}
+
+// Calculate - the coverage of each API version of `discoveryModel` by a run that collected `responseFieldsJSON`.
+// An API version whose spec cannot be loaded, such as one published as swagger only, has an `Error`.
+func Calculate(discoveryModel discovery.Model, responseFieldsJSON string) ([]APICoverage, error) {
+	fields := responseFields{}
+	if responseFieldsJSON != "" {
+		if err := json.Unmarshal([]byte(responseFieldsJSON), &fields); err != nil {
+			return nil, errors.Wrap(err, "coverage: response fields")
+		}
+	}
+
+	coverages := []APICoverage{}
+	for _, item := range discoveryModel.DiscoveryModel.DiscoveryItems {
+		spec := item.APISpecification
+		apiCoverage := APICoverage{API: spec.Name, Version: spec.Version, Operations: []OperationCoverage{}}
+		operations, err := schema.SpecOperations(spec.Name, spec.Version)
+		if err != nil {
+			apiCoverage.Error = err.Error()
+			coverages = append(coverages, apiCoverage)
+			continue
+		}
+
+		seen := seenResponses(fields.ResponseFields, spec.Name, spec.Version)
+		specification, err := model.SpecificationFromSchemaVersion(spec.SchemaVersion)
+		for _, operation := range operations {
+			operationCoverage := newOperationCoverage(operation, item.Endpoints, seen)
+			if err == nil {
+				operationCoverage.Condition = condition(operation, specification.Identifier)
+			}
+			apiCoverage.add(operationCoverage)
+		}
+		coverages = append(coverages, apiCoverage)
+	}
+	return coverages, nil
+}
+
+func (a *APICoverage) add(operation OperationCoverage) {
+	a.Operations = append(a.Operations, operation)
+	a.OperationsHit.Total++
+	if operation.Exercised {
+		a.OperationsHit.Exercised++
+	}
+	for _, response := range operation.Responses {
+		a.ResponsesHit.Total++
+		if response.Exercised {
+			a.ResponsesHit.Exercised++
+		}
+		a.PropertiesHit.Total += response.Properties.Total
+		a.PropertiesHit.Exercised += response.Properties.Exercised
+	}
+}
+
+func newOperationCoverage(operation schema.SpecOperation, endpoints []discovery.ModelEndpoint, seen map[string]map[string]bool) OperationCoverage {
+	coverage := OperationCoverage{Method: operation.Method, Path: operation.Path, Responses: []ResponseCoverage{}}
+	for _, endpoint := range endpoints {
+		if endpoint.Method == operation.Method && endpoint.Path == operation.Path {
+			coverage.Discovered = true
+		}
+	}
+
+	for _, response := range operation.Responses {
+		properties, exercised := seen[operation.Method+" "+operation.Path+" "+response.Code]
+		responseCoverage := ResponseCoverage{Code: response.Code, Exercised: exercised, Properties: Count{Total: len(response.Properties)}}
+		for _, property := range response.Properties {
+			if properties[property] {
+				responseCoverage.Properties.Exercised++
+			} else if exercised {
+				responseCoverage.NotExercised = append(responseCoverage.NotExercised, property)
+			}
+		}
+		coverage.Exercised = coverage.Exercised || exercised
+		coverage.Responses = append(coverage.Responses, responseCoverage)
+	}
+	return coverage
+}
+
+// seenResponses returns the properties the run saw by `method path code` for an API version
+func seenResponses(apis []schemaprops.PropertyOutput, name, version string) map[string]map[string]bool {
+	seen := map[string]map[string]bool{}
+	for _, api := range apis {
+		if api.Api != name || api.Version != version {
+			continue
+		}
+		for _, endpoint := range api.Endpoints {
+			for _, response := range endpoint.Responses {
+				key := endpoint.Method + " " + endpoint.Path + " " + response.Code
+				if seen[key] == nil {
+					seen[key] = map[string]bool{}
+				}
+				for _, field := range response.Fields {
+					seen[key][field] = true
+				}
+			}
+		}
+	}
+	return seen
+}
+
+func condition(operation schema.SpecOperation, specification string) string {
+	condition, err := model.GetConditionality(operation.Method, operation.Path, specification)
+	if err != nil {
+		return ""
+	}
+	switch condition {
+	case model.Mandatory:
+		return ConditionMandatory
+	case model.Conditional:
+		return ConditionConditional
+	case model.Optional:
+		return ConditionOptional
+	default:
+		return ""
+	}
+}
+
+// Percent - the percentage of the total exercised, 0 when the total is
+func (c Count) Percent() int {
+	if c.Total == 0 {
+		return 0
+	}
+	return c.Exercised * 100 / c.Total
+}
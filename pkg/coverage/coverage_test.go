@@ -0,0 +1,107 @@
+package coverage
+
+import (
+	"testing"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+)
+
+const accountsResponseFields = `{
+ "responseFields": [
+  {
+   "api": "Account and Transaction API Specification",
+   "version": "v3.1.10",
+   "endpoints": [
+    {
+     "method": "GET",
+     "path": "/accounts",
+     "responses": [
+      {"code": "200", "fields": ["Data", "Data.Account", "Data.Account.AccountId", "Links", "Links.Self", "Undocumented"]}
+     ]
+    }
+   ]
+  }
+ ]
+}`
+
+func accountsDiscoveryModel(version string) discovery.Model {
+	return discovery.Model{
+		DiscoveryModel: discovery.ModelDiscovery{
+			DiscoveryItems: []discovery.ModelDiscoveryItem{
+				{
+					APISpecification: discovery.ModelAPISpecification{
+						Name:          "Account and Transaction API Specification",
+						Version:       version,
+						SchemaVersion: "https://raw.githubusercontent.com/OpenBankingUK/read-write-api-specs/" + version + "/dist/openapi/account-info-openapi.json",
+					},
+					Endpoints: []discovery.ModelEndpoint{
+						{Method: "GET", Path: "/accounts"},
+						{Method: "GET", Path: "/accounts/{AccountId}"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func findOperation(t *testing.T, coverage APICoverage, method, path string) OperationCoverage {
+	for _, operation := range coverage.Operations {
+		if operation.Method == method && operation.Path == path {
+			return operation
+		}
+	}
+	t.Fatalf("no operation %s %s", method, path)
+	return OperationCoverage{}
+}
+
+func TestCalculate(t *testing.T) {
+	require := test.NewRequire(t)
+
+	coverages, err := Calculate(accountsDiscoveryModel("v3.1.10"), accountsResponseFields)
+	require.NoError(err)
+	require.Len(coverages, 1)
+	coverage := coverages[0]
+	require.Equal("Account and Transaction API Specification", coverage.API)
+	require.Empty(coverage.Error)
+
+	accounts := findOperation(t, coverage, "GET", "/accounts")
+	require.True(accounts.Discovered)
+	require.True(accounts.Exercised)
+	require.Equal(ConditionMandatory, accounts.Condition)
+	require.Equal("200", accounts.Responses[0].Code)
+	require.True(accounts.Responses[0].Exercised)
+	require.Equal(5, accounts.Responses[0].Properties.Exercised)
+	require.Contains(accounts.Responses[0].NotExercised, "Meta.TotalPages")
+	require.NotContains(accounts.Responses[0].NotExercised, "Data.Account.AccountId")
+	require.False(accounts.Responses[1].Exercised)
+	require.Empty(accounts.Responses[1].NotExercised)
+
+	account := findOperation(t, coverage, "GET", "/accounts/{AccountId}")
+	require.True(account.Discovered)
+	require.False(account.Exercised)
+
+	offers := findOperation(t, coverage, "GET", "/offers")
+	require.False(offers.Discovered)
+	require.Equal(ConditionOptional, offers.Condition)
+
+	require.Equal(1, coverage.OperationsHit.Exercised)
+	require.Equal(len(coverage.Operations), coverage.OperationsHit.Total)
+	require.Equal(1, coverage.ResponsesHit.Exercised)
+	require.Equal(5, coverage.PropertiesHit.Exercised)
+	require.Equal(0, Count{}.Percent())
+	require.Equal(50, Count{Exercised: 1, Total: 2}.Percent())
+}
+
+func TestCalculateWithoutOpenAPISpec(t *testing.T) {
+	require := test.NewRequire(t)
+
+	coverages, err := Calculate(accountsDiscoveryModel("v3.1.6"), "")
+	require.NoError(err)
+	require.Len(coverages, 1)
+	require.Contains(coverages[0].Error, "cannot Load OpenApi Spec from file spec/v3.1.6/account-info-openapi.json")
+	require.Empty(coverages[0].Operations)
+
+	_, err = Calculate(accountsDiscoveryModel("v3.1.10"), "not json")
+	require.Error(err)
+}
@@ -32,6 +32,9 @@ type ModelDiscoveryItem struct {
 	ResourceBaseURI        string                `json:"resourceBaseUri,omitempty" validate:"required,url"`
 	ResourceIds            ResourceIds           `json:"resourceIds,omitempty" validate:"-"`
 	Endpoints              []ModelEndpoint       `json:"endpoints,omitempty" validate:"required,gt=0,dive"`
+	// PushedAuthorizationRequests - whether consent is requested with pushed authorization requests, by default
+	// they are used when the openid configuration advertises a `pushed_authorization_request_endpoint`
+	PushedAuthorizationRequests *bool `json:"pushedAuthorizationRequests,omitempty" validate:"-"`
 }
 
 // ResourceIds section allows the replacement of endpoint resourceid values with real data parameters like accountid
@@ -0,0 +1,242 @@
+package discovery
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// defaultTLSDialTimeout - time after which a TLS handshake made by an audit is abandoned
+const defaultTLSDialTimeout = 10 * time.Second
+
+// maxConcurrentTLSHandshakes - handshakes an audit makes with a server at once
+const maxConcurrentTLSHandshakes = 8
+
+// tlsVersions - the protocol versions an audit tries, oldest first
+var tlsVersions = []uint16{tls.VersionTLS10, tls.VersionTLS11, tls.VersionTLS12, tls.VersionTLS13}
+
+// fapiCipherSuites - cipher suites FAPI permits: the TLS 1.2 suites of FAPI 1.0 Part 2 section 8.5 Go
+// supports, DHE suites are not, and the TLS 1.3 suites
+// https://openid.net/specs/openid-financial-api-part-2-1_0.html#tls-considerations
+var fapiCipherSuites = map[uint16]bool{
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256: true,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384: true,
+	tls.TLS_AES_128_GCM_SHA256:                true,
+	tls.TLS_AES_256_GCM_SHA384:                true,
+	tls.TLS_CHACHA20_POLY1305_SHA256:          true,
+}
+
+// TLSAudit - the protocol versions and cipher suites a server accepts and its certificate, with the findings
+// that make it fail: a version below the minimum, a cipher suite FAPI does not permit or an invalid certificate
+type TLSAudit struct {
+	URI          string           `json:"uri"`
+	Address      string           `json:"address"`
+	MinVersion   string           `json:"minVersion"`
+	Versions     []string         `json:"versions"`
+	CipherSuites []TLSCipherSuite `json:"cipherSuites"`
+	Certificate  *TLSCertificate  `json:"certificate,omitempty"`
+	Findings     []string         `json:"findings,omitempty"`
+	Valid        bool             `json:"valid"`
+	Error        string           `json:"error,omitempty"` // the server could not be audited
+}
+
+// TLSCipherSuite - a cipher suite a server accepts with a protocol version
+type TLSCipherSuite struct {
+	Name         string `json:"name"`
+	Version      string `json:"version"`
+	FAPIApproved bool   `json:"fapiApproved"`
+}
+
+// TLSCertificate - the certificate a server presents
+type TLSCertificate struct {
+	Subject       string    `json:"subject"`
+	Issuer        string    `json:"issuer"`
+	NotBefore     time.Time `json:"notBefore"`
+	NotAfter      time.Time `json:"notAfter"`
+	HostnameValid bool      `json:"hostnameValid"`
+	Trusted       bool      `json:"trusted"`              // chains to a root of the system pool, OB directory issued certificates do not
+	TrustError    string    `json:"trustError,omitempty"` // why the chain is not trusted
+}
+
+// AuditTLS makes a handshake with each protocol version and, up to TLS 1.2, each cipher suite to find what the
+// server at `uri` accepts and checks its certificate is in its validity period and valid for the host.
+// TLS 1.3 cipher suites cannot be chosen by the client, only the one negotiated is found.
+// The handshakes are made concurrently, at most maxConcurrentTLSHandshakes at once.
+func (v StdTLSValidator) AuditTLS(uri string) (TLSAudit, error) {
+	addr, _, err := tlsAddress(uri)
+	if err != nil {
+		return TLSAudit{}, err
+	}
+	hostname, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return TLSAudit{}, errors.Wrapf(err, "unable to parse the provided uri %s", uri)
+	}
+	minVersion, err := tlsVersionToString(v.minSupportedTLSVersion)
+	if err != nil {
+		return TLSAudit{}, err
+	}
+	audit := TLSAudit{URI: uri, Address: addr, MinVersion: minVersion, Versions: []string{}, CipherSuites: []TLSCipherSuite{}}
+
+	versionAttempts := make([]tlsHandshakeAttempt, 0, len(tlsVersions))
+	for _, version := range tlsVersions {
+		versionAttempts = append(versionAttempts, tlsHandshakeAttempt{version: version})
+	}
+	versionResults := v.handshakes(addr, hostname, versionAttempts)
+
+	// cipher suites are only tried with the versions the server accepts
+	suiteAttempts := []tlsHandshakeAttempt{}
+	for i, attempt := range versionAttempts {
+		if versionResults[i].err != nil || attempt.version == tls.VersionTLS13 {
+			continue
+		}
+		for _, suite := range cipherSuitesForVersion(attempt.version) {
+			suiteAttempts = append(suiteAttempts, tlsHandshakeAttempt{version: attempt.version, cipherSuites: []uint16{suite}})
+		}
+	}
+	suiteResults := v.handshakes(addr, hostname, suiteAttempts)
+
+	var peerCertificates []*x509.Certificate
+	for i, attempt := range versionAttempts {
+		if versionResults[i].err != nil {
+			continue
+		}
+		version, state := attempt.version, versionResults[i].state
+		name, _ := tlsVersionToString(version)
+		audit.Versions = append(audit.Versions, name)
+		if version < v.minSupportedTLSVersion {
+			audit.Findings = append(audit.Findings, fmt.Sprintf("accepts %s, below the minimum %s", name, minVersion))
+		}
+		peerCertificates = state.PeerCertificates
+
+		if version == tls.VersionTLS13 {
+			audit.addCipherSuite(state.CipherSuite, name)
+			continue
+		}
+		for j, suiteAttempt := range suiteAttempts {
+			if suiteAttempt.version == version && suiteResults[j].err == nil {
+				audit.addCipherSuite(suiteAttempt.cipherSuites[0], name)
+			}
+		}
+	}
+	if len(audit.Versions) == 0 {
+		audit.Error = fmt.Sprintf("no TLS handshake succeeded with %s", addr)
+		return audit, errors.New(audit.Error)
+	}
+
+	now := time.Now()
+	audit.Certificate = checkTLSCertificate(peerCertificates, hostname, now)
+	if audit.Certificate != nil {
+		if now.Before(audit.Certificate.NotBefore) || now.After(audit.Certificate.NotAfter) {
+			audit.Findings = append(audit.Findings, fmt.Sprintf("certificate is not valid at %s: valid from %s to %s",
+				now.Format(time.RFC3339), audit.Certificate.NotBefore.Format(time.RFC3339), audit.Certificate.NotAfter.Format(time.RFC3339)))
+		}
+		if !audit.Certificate.HostnameValid {
+			audit.Findings = append(audit.Findings, fmt.Sprintf("certificate is not valid for host %s", hostname))
+		}
+	}
+	audit.Valid = len(audit.Findings) == 0
+	return audit, nil
+}
+
+func (a *TLSAudit) addCipherSuite(id uint16, version string) {
+	suite := TLSCipherSuite{Name: tls.CipherSuiteName(id), Version: version, FAPIApproved: fapiCipherSuites[id]}
+	a.CipherSuites = append(a.CipherSuites, suite)
+	if !suite.FAPIApproved {
+		a.Findings = append(a.Findings, fmt.Sprintf("accepts cipher suite %s with %s, not approved by FAPI", suite.Name, version))
+	}
+}
+
+// tlsHandshakeAttempt - a handshake with a single protocol version, limited to `cipherSuites` when it has any
+type tlsHandshakeAttempt struct {
+	version      uint16
+	cipherSuites []uint16
+}
+
+// tlsHandshakeResult - the outcome of a tlsHandshakeAttempt
+type tlsHandshakeResult struct {
+	state tls.ConnectionState
+	err   error
+}
+
+// handshakes makes the handshakes of `attempts` concurrently, at most maxConcurrentTLSHandshakes at once,
+// and returns their results in the order of `attempts`
+func (v StdTLSValidator) handshakes(addr, hostname string, attempts []tlsHandshakeAttempt) []tlsHandshakeResult {
+	results := make([]tlsHandshakeResult, len(attempts))
+	slots := make(chan struct{}, maxConcurrentTLSHandshakes)
+	var wg sync.WaitGroup
+	for i, attempt := range attempts {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, attempt tlsHandshakeAttempt) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			results[i].state, results[i].err = v.handshake(addr, hostname, attempt.version, attempt.cipherSuites)
+		}(i, attempt)
+	}
+	wg.Wait()
+	return results
+}
+
+// handshake makes a TLS handshake with a single protocol version, limited to `cipherSuites` when it has any
+func (v StdTLSValidator) handshake(addr, hostname string, version uint16, cipherSuites []uint16) (tls.ConnectionState, error) {
+	config := v.tlsConfig.Clone()
+	config.ServerName = hostname
+	config.MinVersion = version
+	config.MaxVersion = version
+	config.CipherSuites = cipherSuites
+
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: v.dialTimeout}, "tcp", addr, config)
+	if err != nil {
+		return tls.ConnectionState{}, err
+	}
+	defer conn.Close()
+	return conn.ConnectionState(), nil
+}
+
+// cipherSuitesForVersion returns the cipher suites Go implements for a protocol version up to TLS 1.2
+func cipherSuitesForVersion(version uint16) []uint16 {
+	suites := []uint16{}
+	for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		for _, supported := range suite.SupportedVersions {
+			if supported == version {
+				suites = append(suites, suite.ID)
+				break
+			}
+		}
+	}
+	return suites
+}
+
+// checkTLSCertificate checks the leaf of the certificates a server presents is valid for the host and whether
+// the chain is trusted by the system pool
+func checkTLSCertificate(certs []*x509.Certificate, hostname string, now time.Time) *TLSCertificate {
+	if len(certs) == 0 {
+		return nil
+	}
+	leaf := certs[0]
+	certificate := &TLSCertificate{
+		Subject:       leaf.Subject.String(),
+		Issuer:        leaf.Issuer.String(),
+		NotBefore:     leaf.NotBefore,
+		NotAfter:      leaf.NotAfter,
+		HostnameValid: leaf.VerifyHostname(hostname) == nil,
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := leaf.Verify(x509.VerifyOptions{DNSName: hostname, Intermediates: intermediates, CurrentTime: now})
+	certificate.Trusted = err == nil
+	if err != nil {
+		certificate.TrustError = err.Error()
+	}
+	return certificate
+}
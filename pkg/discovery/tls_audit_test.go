@@ -0,0 +1,141 @@
+package discovery
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+)
+
+func TestAuditTLSFlagsNonFAPICipherSuites(t *testing.T) {
+	require := test.NewRequire(t)
+
+	srv, uri := test.HTTPSServer(&tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		MaxVersion:   tls.VersionTLS12,
+		CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA},
+	}, http.StatusOK, "", nil)
+	defer srv.Close()
+
+	audit, err := NewStdTLSValidator(tls.VersionTLS12).AuditTLS(uri)
+
+	require.NoError(err)
+	require.Equal(uri, audit.URI)
+	require.Equal("TLS12", audit.MinVersion)
+	require.Equal([]string{"TLS12"}, audit.Versions)
+	require.Equal([]TLSCipherSuite{
+		{Name: "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA", Version: "TLS12"},
+		{Name: "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", Version: "TLS12", FAPIApproved: true},
+	}, audit.CipherSuites)
+	require.Equal([]string{"accepts cipher suite TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA with TLS12, not approved by FAPI"}, audit.Findings)
+	require.False(audit.Valid)
+
+	// the test server certificate is valid for 127.0.0.1 but not issued by a trusted root
+	require.NotNil(audit.Certificate)
+	require.True(audit.Certificate.HostnameValid)
+	require.False(audit.Certificate.Trusted)
+	require.NotEmpty(audit.Certificate.TrustError)
+}
+
+func TestAuditTLSFlagsVersionsBelowMinimum(t *testing.T) {
+	require := test.NewRequire(t)
+
+	srv, uri := test.HTTPSServer(&tls.Config{MinVersion: tls.VersionTLS11}, http.StatusOK, "", nil)
+	defer srv.Close()
+
+	audit, err := NewStdTLSValidator(tls.VersionTLS12).AuditTLS(uri)
+
+	require.NoError(err)
+	require.Equal([]string{"TLS11", "TLS12", "TLS13"}, audit.Versions)
+	require.Contains(audit.Findings, "accepts TLS11, below the minimum TLS12")
+	require.False(audit.Valid)
+	tls13 := audit.CipherSuites[len(audit.CipherSuites)-1]
+	require.Equal("TLS13", tls13.Version)
+	require.True(tls13.FAPIApproved)
+}
+
+func TestAuditTLSPasses(t *testing.T) {
+	require := test.NewRequire(t)
+
+	srv, uri := test.HTTPSServer(&tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384},
+	}, http.StatusOK, "", nil)
+	defer srv.Close()
+
+	audit, err := NewStdTLSValidator(tls.VersionTLS12).AuditTLS(uri)
+
+	require.NoError(err)
+	require.Equal([]string{"TLS12", "TLS13"}, audit.Versions)
+	require.Empty(audit.Findings)
+	require.True(audit.Valid)
+}
+
+func TestAuditTLSFailsWithoutHandshake(t *testing.T) {
+	require := test.NewRequire(t)
+
+	srv, uri := test.HTTPServer(http.StatusOK, "", nil)
+	defer srv.Close()
+
+	audit, err := NewStdTLSValidator(tls.VersionTLS12).AuditTLS(uri)
+
+	require.Error(err)
+	require.Contains(audit.Error, "no TLS handshake succeeded with")
+	require.False(audit.Valid)
+}
+
+func TestAuditTLSHandshakesAreBounded(t *testing.T) {
+	require := test.NewRequire(t)
+
+	// a server that accepts connections and never answers, so each handshake waits for the dial timeout
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				ioutil.ReadAll(conn)
+				conn.Close()
+			}()
+		}
+	}()
+
+	validator := NewStdTLSValidator(tls.VersionTLS12)
+	validator.dialTimeout = 200 * time.Millisecond
+	batches := 3
+	attempts := make([]tlsHandshakeAttempt, batches*maxConcurrentTLSHandshakes)
+	for i := range attempts {
+		attempts[i] = tlsHandshakeAttempt{version: tls.VersionTLS12}
+	}
+
+	start := time.Now()
+	results := validator.handshakes(listener.Addr().String(), "127.0.0.1", attempts)
+	elapsed := time.Since(start)
+
+	require.Len(results, len(attempts))
+	for _, result := range results {
+		require.Error(result.err)
+	}
+	// maxConcurrentTLSHandshakes handshakes time out together
+	require.True(elapsed >= time.Duration(batches)*validator.dialTimeout, "more than %d handshakes at once: %s", maxConcurrentTLSHandshakes, elapsed)
+	require.True(elapsed < time.Duration(len(attempts)/2)*validator.dialTimeout, "handshakes were not concurrent: %s", elapsed)
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	require := test.NewRequire(t)
+
+	version, err := ParseTLSVersion("tls12")
+	require.NoError(err)
+	require.Equal(uint16(tls.VersionTLS12), version)
+
+	_, err = ParseTLSVersion("SSL30")
+	require.EqualError(err, `unknown tls version "SSL30", expected one of TLS10, TLS11, TLS12 or TLS13`)
+}
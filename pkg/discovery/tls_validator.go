@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 )
 
 type TLSValidator interface {
 	ValidateTLSVersion(uri string) (TLSValidationResult, error)
+	AuditTLS(uri string) (TLSAudit, error)
 }
 
 type TLSValidationResult struct {
@@ -21,6 +23,7 @@ type TLSValidationResult struct {
 type StdTLSValidator struct {
 	tlsConfig              *tls.Config
 	minSupportedTLSVersion uint16
+	dialTimeout            time.Duration
 }
 
 type NullTLSValidator struct{}
@@ -33,30 +36,25 @@ func (v NullTLSValidator) ValidateTLSVersion(uri string) (TLSValidationResult, e
 	return TLSValidationResult{}, nil
 }
 
+func (v NullTLSValidator) AuditTLS(uri string) (TLSAudit, error) {
+	return TLSAudit{}, nil
+}
+
 func NewStdTLSValidator(minSupportedTLSVersion uint16) StdTLSValidator {
 	return StdTLSValidator{&tls.Config{
 		InsecureSkipVerify: true,
 		Renegotiation:      tls.RenegotiateFreelyAsClient,
-	}, minSupportedTLSVersion}
+	}, minSupportedTLSVersion, defaultTLSDialTimeout}
 }
 
 func (v StdTLSValidator) ValidateTLSVersion(uri string) (TLSValidationResult, error) {
-	parsedURI, err := url.Parse(uri)
+	addr, host, err := tlsAddress(uri)
 	if err != nil {
-		return TLSValidationResult{}, errors.Wrapf(err, "unable to parse the provided uri %s", uri)
-	}
-	// url.Parse only returns error for uri containing ASCII CTL bytes
-	// in this case checking for blank URI will suffice
-	if strings.TrimSpace(parsedURI.Host) == "" {
-		return TLSValidationResult{}, fmt.Errorf("unable to parse the provided uri %s", uri)
-	}
-	addr := parsedURI.Host
-	if !strings.Contains(parsedURI.Host, ":") {
-		addr = fmt.Sprintf("%s:%d", parsedURI.Host, 443)
+		return TLSValidationResult{}, err
 	}
 	conn, err := tls.Dial("tcp", addr, v.tlsConfig)
 	if err != nil {
-		return TLSValidationResult{}, errors.Wrapf(err, "unable to detect tls version for hostname %s", parsedURI.Host)
+		return TLSValidationResult{}, errors.Wrapf(err, "unable to detect tls version for hostname %s", host)
 	}
 	defer conn.Close()
 	state := conn.ConnectionState()
@@ -71,6 +69,34 @@ func (v StdTLSValidator) ValidateTLSVersion(uri string) (TLSValidationResult, er
 	}, nil
 }
 
+// tlsAddress returns the host:port to dial for a URI, port 443 when it has none, and its host
+func tlsAddress(uri string) (string, string, error) {
+	parsedURI, err := url.Parse(uri)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "unable to parse the provided uri %s", uri)
+	}
+	// url.Parse only returns error for uri containing ASCII CTL bytes
+	// in this case checking for blank URI will suffice
+	if strings.TrimSpace(parsedURI.Host) == "" {
+		return "", "", fmt.Errorf("unable to parse the provided uri %s", uri)
+	}
+	addr := parsedURI.Host
+	if !strings.Contains(parsedURI.Host, ":") {
+		addr = fmt.Sprintf("%s:%d", parsedURI.Host, 443)
+	}
+	return addr, parsedURI.Host, nil
+}
+
+// ParseTLSVersion returns the TLS version of its name: TLS10, TLS11, TLS12 or TLS13
+func ParseTLSVersion(name string) (uint16, error) {
+	for _, version := range tlsVersions {
+		if strVersion, _ := tlsVersionToString(version); strings.EqualFold(strVersion, name) {
+			return version, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown tls version %q, expected one of TLS10, TLS11, TLS12 or TLS13", name)
+}
+
 func tlsVersionToString(v uint16) (string, error) {
 	switch int(v) {
 	case tls.VersionSSL30:
@@ -3,7 +3,6 @@ package executors
 import (
 	"fmt"
 
-	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
 	"github.com/OpenBankingUK/conformance-suite/pkg/manifest"
 	"github.com/OpenBankingUK/conformance-suite/pkg/model"
 	"github.com/pkg/errors"
@@ -15,7 +14,7 @@ func getCbpiiConsents(
 	requiredTokens []manifest.RequiredTokens,
 	ctx *model.Context,
 ) (TokenConsentIDs, error) {
-	executor := &Executor{}
+	executor := &Executor{HTTPClient: definition.HTTPClient}
 	err := executor.SetCertificates(definition.SigningCert, definition.TransportCert)
 	if err != nil {
 		logrus.Error(fmt.Sprintf("error running cbpii consent acquisition: %s", err))
@@ -46,6 +45,7 @@ func runCbpiiConsents(rt []manifest.RequiredTokens, ctx *model.Context, executor
 	localCtx := model.Context{}
 	localCtx.PutContext(ctx)
 	localCtx.PutString("scope", "fundsconfirmations")
+	localCtx.PutString("consent_spec_type", "cbpii")
 	consentJobs := manifest.GetConsentJobs()
 
 	tc, err := readClientCredentialGrant()
@@ -53,41 +53,8 @@ func runCbpiiConsents(rt []manifest.RequiredTokens, ctx *model.Context, executor
 		return nil, errors.New("cbpii PSU consent load clientCredentials testcase failed")
 	}
 
-	// Check for MTLS vs client basic authentication
-	authMethod, err := ctx.GetString("token_endpoint_auth_method")
-	if err != nil {
-		authMethod = "client_secret_basic"
-	}
-	switch authMethod {
-	case authentication.ClientSecretBasic:
-		tc.Input.SetHeader("authorization", "Basic $basic_authentication")
-	case authentication.PrivateKeyJwt:
-		clientID, err := ctx.GetString("client_id")
-		if err != nil {
-			return nil, errors.Wrap(err, "cannot find client_id for private_key_jwt form field")
-		}
-		tokenEndpoint, err := ctx.GetString("token_endpoint")
-		if err != nil {
-			return nil, errors.Wrap(err, "cannot find token_endpoint for private_key_jwt form field")
-		}
-		if tc.Input.Claims == nil {
-			tc.Input.Claims = map[string]string{}
-		}
-		tc.Input.Claims["iss"] = clientID
-		tc.Input.Claims["sub"] = clientID
-		tc.Input.Claims["aud"] = tokenEndpoint
-		clientAssertion, err := tc.Input.GenerateRequestToken(ctx)
-		if err != nil {
-			return nil, errors.Wrap(err, "cannot generate request token for private_key_jwt form field")
-		}
-		tc.Input.SetFormField(authentication.ClientAssertionType, authentication.ClientAssertionTypeValue)
-		tc.Input.SetFormField(authentication.ClientAssertion, clientAssertion)
-	case authentication.TlsClientAuth:
-		clientid, err := ctx.GetString("client_id")
-		if err != nil {
-			logrus.Warn("cannot locate client_id for tls_client_auth form field")
-		}
-		tc.Input.SetFormField("client_id", clientid)
+	if err := authenticateTokenRequest(&tc.Input, ctx); err != nil {
+		return nil, errors.Wrap(err, "cbpii PSU consent authenticate clientCredentials testcase failed")
 	}
 
 	tc.ProcessReplacementFields(&localCtx, true)
@@ -130,17 +97,6 @@ func runCbpiiConsents(rt []manifest.RequiredTokens, ctx *model.Context, executor
 		if err != nil {
 			return nil, errors.New("Cbpii PSU consent load psu_exchange testcase failed")
 		}
-		if authMethod == "tls_client_auth" {
-			clientid, err := ctx.GetString("client_id")
-			if err != nil {
-				logrus.Warn("cannot locate client_id for tls_client_auth form field")
-			}
-			exchange.Input.SetFormField("client_id", clientid)
-
-		} else {
-			exchange.Input.SetHeader("authorization", "Basic $basic_authentication")
-		}
-
 		localCtx.DumpContext("before exchange", "token_name", "consent_id")
 		err = executePaymentTest(&exchange, &localCtx, executor)
 		if err != nil {
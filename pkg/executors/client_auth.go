@@ -0,0 +1,72 @@
+package executors
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	resty "gopkg.in/resty.v1"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+)
+
+// authenticateTokenRequest authenticates a token endpoint request with the `token_endpoint_auth_method`
+// of the context, replacing any authorization header the input already has
+func authenticateTokenRequest(input *model.Input, ctx *model.Context) error {
+	auth, err := tokenRequestAuthentication(ctx)
+	if err != nil {
+		return err
+	}
+	for key := range input.Headers {
+		if strings.EqualFold(key, "authorization") {
+			delete(input.Headers, key)
+		}
+	}
+	for key, value := range auth.Headers {
+		input.SetHeader(key, value)
+	}
+	for key, value := range auth.FormData {
+		input.SetFormField(key, value)
+	}
+	return nil
+}
+
+// tokenRequest returns a form post to the token endpoint carrying `formData` authenticated with the
+// `token_endpoint_auth_method` of the context
+func tokenRequest(ctx *model.Context, formData map[string]string) (*resty.Request, error) {
+	auth, err := tokenRequestAuthentication(ctx)
+	if err != nil {
+		return nil, err
+	}
+	form := map[string]string{}
+	for key, value := range formData {
+		form[key] = value
+	}
+	for key, value := range auth.FormData {
+		form[key] = value
+	}
+	return model.HTTPClient(ctx).R().
+		SetHeader("content-type", "application/x-www-form-urlencoded").
+		SetHeader("accept", "application/json").
+		SetHeaders(auth.Headers).
+		SetFormData(form), nil
+}
+
+// isTokenRequest - whether a test case input is a request to the token endpoint
+func isTokenRequest(input model.Input) bool {
+	_, ok := input.FormData[authentication.GrantType]
+	return ok
+}
+
+func tokenRequestAuthentication(ctx *model.Context) (authentication.TokenRequestAuthentication, error) {
+	clientAuth, err := authentication.NewClientAuthentication(ctx)
+	if err != nil {
+		return authentication.TokenRequestAuthentication{}, errors.Wrap(err, "executors: token request")
+	}
+	auth, err := clientAuth.Authenticate(time.Now())
+	if err != nil {
+		return authentication.TokenRequestAuthentication{}, errors.Wrap(err, "executors: token request")
+	}
+	return auth, nil
+}
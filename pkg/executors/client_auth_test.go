@@ -0,0 +1,59 @@
+package executors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+)
+
+func TestAuthenticateTokenRequestReplacesHardwiredAuthorization(t *testing.T) {
+	ctx := &model.Context{
+		"token_endpoint_auth_method": authentication.ClientSecretPost,
+		"client_id":                  "client-id",
+		"client_secret":              "client-secret",
+		"token_endpoint":             "https://aspsp.example.com/token",
+	}
+	input := model.Input{
+		Headers:  map[string]string{"Authorization": "Basic $basic_authentication", "accept": "application/json"},
+		FormData: map[string]string{authentication.GrantType: "client_credentials"},
+	}
+	require.True(t, isTokenRequest(input))
+
+	require.NoError(t, authenticateTokenRequest(&input, ctx))
+	assert.Equal(t, map[string]string{"accept": "application/json"}, input.Headers)
+	assert.Equal(t, map[string]string{
+		authentication.GrantType: "client_credentials",
+		"client_id":              "client-id",
+		"client_secret":          "client-secret",
+	}, input.FormData)
+}
+
+func TestTokenRequestAuthenticatesCodeExchange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != "client-id" || clientSecret != "client-secret" || r.FormValue("code") != "code-001" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"access_token":"token-001"}`))
+	}))
+	defer server.Close()
+
+	ctx := &model.Context{
+		"token_endpoint_auth_method": authentication.ClientSecretBasic,
+		"client_id":                  "client-id",
+		"client_secret":              "client-secret",
+		"token_endpoint":             server.URL,
+		"redirect_url":               "https://tpp.example.com/callback",
+	}
+	token, err := exchangeCodeForToken("code-001", ctx, test.NullLogger())
+	require.NoError(t, err)
+	assert.Equal(t, "token-001", token.AccessToken)
+}
@@ -54,7 +54,7 @@ func GetHeadlessConsent(definition RunDefinition, ctx *model.Context, specRun *g
 func getPaymentHeadlessTokens(paymentTests []model.TestCase, ctx *model.Context, definition RunDefinition, requiredTokens []manifest.RequiredTokens, logger *logrus.Entry) ([]manifest.RequiredTokens, error) {
 	logger.Debug("getPaymentHeadlessTokens")
 
-	executor := Executor{}
+	executor := Executor{HTTPClient: definition.HTTPClient}
 	err := executor.SetCertificates(definition.SigningCert, definition.TransportCert)
 	if err != nil {
 		return nil, err
@@ -62,7 +62,7 @@ func getPaymentHeadlessTokens(paymentTests []model.TestCase, ctx *model.Context,
 
 	logger.Debugf("we have %d required tokens", len(requiredTokens))
 
-	requiredTokens, err = runPaymentConsents(requiredTokens, ctx, &executor)
+	requiredTokens, err = runPaymentConsents(requiredTokens, "payments", ctx, &executor)
 	if err != nil {
 		logger.Errorf("getPaymentConsents error: " + err.Error())
 	}
@@ -97,7 +97,7 @@ func CallPaymentHeadlessConsentUrls(rt *[]manifest.RequiredTokens, ctx *model.Co
 		endpoint := tokendata.ConsentURL
 		var resp *resty.Response
 
-		resp, err := resty.R().
+		resp, err := model.HTTPClient(ctx).R().
 			SetHeader("accept", "*/*").
 			Get(endpoint)
 
@@ -132,23 +132,22 @@ func CallPaymentHeadlessConsentUrls(rt *[]manifest.RequiredTokens, ctx *model.Co
 			return nil, fmt.Errorf("Exchange code is empty - cannot complete exchange")
 		}
 
-		params, err := ctx.GetStrings("basic_authentication", "token_endpoint", "redirect_url")
+		params, err := ctx.GetStrings("token_endpoint", "redirect_url")
 		if err != nil {
 			logger.Errorf("Consent Failed to get %s from context", err.Error())
 			return nil, err
 		}
 
-		resp, err = resty.R().
-			SetHeader("content-type", "application/x-www-form-urlencoded").
-			SetHeader("accept", "application/json").
-			SetHeader("authorization", "Basic "+params["basic_authentication"]).
-			SetFormData(map[string]string{
-				"code":         exchangeCode,
-				"redirect_uri": params["redirect_url"],
-				"grant_type":   "authorization_code",
-				"scope":        "payments",
-			}).
-			Post(params["token_endpoint"])
+		request, err := tokenRequest(ctx, map[string]string{
+			"code":         exchangeCode,
+			"redirect_uri": params["redirect_url"],
+			"grant_type":   "authorization_code",
+			"scope":        "payments",
+		})
+		if err != nil {
+			return nil, err
+		}
+		resp, err = request.Post(params["token_endpoint"])
 
 		if err != nil {
 			logger.WithFields(logrus.Fields{
@@ -196,7 +195,7 @@ func getAccountsHeadlessTokens(tests []model.TestCase, ctx *model.Context, defin
 	}
 
 	bodyDataEnd := fmt.Sprintf(`], "TransactionFromDateTime": "%s", "TransactionToDateTime": "%s" },  "Risk": {} }`, txnFrom, txnTo)
-	executor := NewExecutor()
+	executor := &Executor{HTTPClient: definition.HTTPClient}
 	err = executor.SetCertificates(definition.SigningCert, definition.TransportCert)
 	if err != nil {
 		return nil, err
@@ -207,7 +206,10 @@ func getAccountsHeadlessTokens(tests []model.TestCase, ctx *model.Context, defin
 		return nil, errors.New("Error trying to determine specification type from API schemaVersion: " + err.Error())
 	}
 
-	requiredTokens, err := manifest.GetRequiredTokensFromTests(tests, specType)
+	requiredTokens, err := manifest.GetRequiredTokensFromTests(tests, specType, definition.ConsentResolver)
+	if err != nil {
+		return nil, err
+	}
 
 	for k, tokenGatherer := range requiredTokens {
 
@@ -221,6 +223,7 @@ func getAccountsHeadlessTokens(tests []model.TestCase, ctx *model.Context, defin
 		tokenName := tokenGatherer.Name
 		localCtx.PutString("permission_payload", bodyData)
 		localCtx.PutString("result_token", tokenName)
+		localCtx.PutString("consent_spec_type", specType)
 
 		returnCtx, err := executeComponent(&localCtx, executor)
 		if err != nil {
@@ -272,6 +275,11 @@ func executeComponent(ctx *model.Context, executor TestCaseExecutor) (*model.Con
 	for k, test := range tests {
 		test.ProcessReplacementFields(executeCtx, false)
 		_, _ = k, test
+		if isTokenRequest(test.Input) {
+			if err := authenticateTokenRequest(&test.Input, executeCtx); err != nil {
+				return &model.Context{}, err
+			}
+		}
 		logrus.Debug("Executing ------->>")
 
 		req, err := test.Prepare(executeCtx)
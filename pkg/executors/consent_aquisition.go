@@ -10,11 +10,8 @@ import (
 	"github.com/OpenBankingUK/conformance-suite/pkg/manifest"
 	"github.com/OpenBankingUK/conformance-suite/pkg/model"
 
-	"github.com/dgrijalva/jwt-go"
-	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
-	resty "gopkg.in/resty.v1"
 )
 
 const (
@@ -39,7 +36,7 @@ func GetPsuConsent(definition RunDefinition, ctx *model.Context, runTests *gener
 			}
 
 		case "payments":
-			consentIds, err := getPaymentConsents(definition, permissions["payments"], ctx)
+			consentIds, err := getPaymentConsents(definition, "payments", permissions["payments"], ctx)
 			consentIdsToReturn = append(consentIdsToReturn, consentIds...)
 			if err != nil {
 				logrus.Error("GetPSUConsent - payments error: " + err.Error())
@@ -53,7 +50,7 @@ func GetPsuConsent(definition RunDefinition, ctx *model.Context, runTests *gener
 				return nil, nil, err
 			}
 		case "vrps":
-			consentIds, err := getPaymentConsents(definition, permissions["vrps"], ctx)
+			consentIds, err := getPaymentConsents(definition, "vrps", permissions["vrps"], ctx)
 			consentIdsToReturn = append(consentIdsToReturn, consentIds...)
 			if err != nil {
 				logrus.Error("GetPSUConsent - vrps error: " + err.Error())
@@ -190,8 +187,9 @@ type ExchangeParameters struct {
 	TokenName           string
 }
 
-// ExchangeCodeForAccessToken - runs a testcase to perform this operation
-func ExchangeCodeForAccessToken(tokenName, code string, ctx *model.Context) (accesstoken string, err error) {
+// ExchangeCodeForAccessToken - runs a testcase to perform this operation, the refresh token returned
+// alongside the access token is kept by `refresher` when not nil
+func ExchangeCodeForAccessToken(tokenName, code string, ctx *model.Context, refresher *TokenRefresher) (accesstoken string, err error) {
 	logger := logrus.StandardLogger().WithFields(logrus.Fields{
 		"module":    "ExchangeCodeForAccessToken",
 		"tokenName": tokenName,
@@ -203,17 +201,20 @@ func ExchangeCodeForAccessToken(tokenName, code string, ctx *model.Context) (acc
 		logger.WithFields(logrus.Fields{
 			"err": err,
 		}).Error("exchangeCodeForToken failed")
+		return "", err
 	}
 
-	return grantToken.AccessToken, err
+	refresher.add(tokenName, grantToken)
+	return grantToken.AccessToken, nil
 }
 
 type grantToken struct {
-	AccessToken string `json:"access_token,omitempty"`
-	TokenType   string `json:"token_type,omitempty"`
-	Expires     int32  `json:"expires_in,omitempty"`
-	Scope       string `json:"scope,omitempty"`
-	IDToken     string `json:"id_token,omitempty"`
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	TokenType    string `json:"token_type,omitempty"`
+	Expires      int32  `json:"expires_in,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
 }
 
 func exchangeCodeForToken(code string, ctx *model.Context, logger *logrus.Entry) (*grantToken, error) {
@@ -223,10 +224,6 @@ func exchangeCodeForToken(code string, ctx *model.Context, logger *logrus.Entry)
 	})
 	ctx.DumpContext()
 
-	basicAuth, err := ctx.GetString("basic_authentication")
-	if err != nil {
-		return nil, errors.Wrap(err, "executors.exchangeCodeForToken: cannot get basic authentication for code")
-	}
 	tokenEndpoint, err := ctx.GetString("token_endpoint")
 	if err != nil {
 		return nil, errors.Wrap(err, "executors.exchangeCodeForToken: cannot get token_endpoint for code exchange")
@@ -235,112 +232,16 @@ func exchangeCodeForToken(code string, ctx *model.Context, logger *logrus.Entry)
 	if err != nil {
 		return nil, errors.Wrap(err, "executors.exchangeCodeForToken: cannot get redirect_url for code exchange")
 	}
-	clientID, err := ctx.GetString("client_id")
-	if err != nil {
-		return nil, errors.Wrap(err, "executors.exchangeCodeForToken: cannot get client_id for exchange code")
-	}
-	alg, err := ctx.GetString("requestObjectSigningAlg")
-	if err != nil {
-		return nil, errors.Wrap(err, "executors.exchangeCodeForToken: cannot get requestObjectSigningAlg for exchange code")
-	}
-	privKey, err := ctx.GetString("signingPrivate")
-	if err != nil {
-		return nil, errors.Wrap(err, "executors.exchangeCodeForToken: cannot get `signingPrivate` in context")
-	}
-	pubKey, err := ctx.GetString("signingPublic")
-	if err != nil {
-		return nil, errors.Wrap(err, "executors.exchangeCodeForToken: cannot get `signingPublic` in context")
-	}
-	cert, err := authentication.NewCertificate(pubKey, privKey)
-	if err != nil {
-		return nil, errors.Wrap(err, "executors.exchangeCodeForToken: cannot get `certificate` from pub/priv keys")
-	}
 
-	// Check for MTLS vs client basic authentication
-	authMethod, err := ctx.GetString("token_endpoint_auth_method")
+	request, err := tokenRequest(ctx, map[string]string{
+		authentication.GrantType: authentication.GrantTypeAuthorizationCode,
+		"code":                   code,
+		"redirect_uri":           redirectURI,
+	})
 	if err != nil {
-		authMethod = authentication.ClientSecretBasic
-	}
-
-	var resp *resty.Response
-	var errResponse error
-	switch authMethod {
-	case authentication.ClientSecretBasic:
-		resp, errResponse = resty.R().
-			SetHeader("content-type", "application/x-www-form-urlencoded").
-			SetHeader("accept", "application/json").
-			SetHeader("authorization", "Basic "+basicAuth).
-			SetFormData(map[string]string{
-				authentication.GrantType: authentication.GrantTypeAuthorizationCode,
-				"code":                   code,
-				"redirect_uri":           redirectURI,
-			}).
-			Post(tokenEndpoint)
-	case authentication.TlsClientAuth:
-		resp, errResponse = resty.R().
-			SetHeader("content-type", "application/x-www-form-urlencoded").
-			SetHeader("accept", "application/json").
-			SetFormData(map[string]string{
-				authentication.GrantType: authentication.GrantTypeAuthorizationCode,
-				"code":                   code,
-				"redirect_uri":           redirectURI,
-				"client_id":              clientID,
-			}).
-			Post(tokenEndpoint)
-	case authentication.PrivateKeyJwt:
-		now := time.Now()
-		iat := now.Unix()
-		exp := now.Add(30 * time.Minute).Unix()
-		jti := uuid.New().String()
-		// https://openid.net/specs/openid-connect-core-1_0.html#ClientAuthentication
-		// iss
-		// REQUIRED. Issuer. This MUST contain the client_id of the OAuth Client.
-		// sub
-		// REQUIRED. Subject. This MUST contain the client_id of the OAuth Client.
-		// aud
-		// REQUIRED. Audience. The aud (audience) Claim. Value that identifies the Authorization Server as an intended audience. The Authorization Server MUST verify that it is an intended audience for the token. The Audience SHOULD be the URL of the Authorization Server's Token Endpoint.
-		claims := jwt.MapClaims{
-			"iss": clientID,
-			"sub": clientID,
-			"aud": tokenEndpoint,
-			"iat": iat,
-			"exp": exp,
-			"jti": jti,
-		}
-
-		signingMethod, err := authentication.GetSigningAlg(alg)
-		if err != nil {
-			return nil, errors.Wrap(err, "executors.exchangeCodeForToken: cannot get signingMethod")
-		}
-
-		token := jwt.NewWithClaims(signingMethod, claims) // create new token
-
-		kid, err := ctx.GetString("tpp_signature_kid")
-		if err != nil {
-			return nil, errors.Wrap(err, "executors.exchangeCodeForToken: cannot get KID")
-		}
-		token.Header["kid"] = kid
-
-		clientAssertion, err := token.SignedString(cert.PrivateKey()) // sign the token - get as encoded string
-		if err != nil {
-			return nil, errors.Wrap(err, "executors.exchangeCodeForToken: could not generate client_assertion")
-		}
-
-		resp, errResponse = resty.R().
-			SetHeader("content-type", "application/x-www-form-urlencoded").
-			SetHeader("accept", "application/json").
-			SetFormData(map[string]string{
-				authentication.GrantType:           authentication.GrantTypeAuthorizationCode,
-				"code":                             code,
-				"redirect_uri":                     redirectURI,
-				authentication.ClientAssertionType: authentication.ClientAssertionTypeValue,
-				authentication.ClientAssertion:     clientAssertion,
-			}).
-			Post(tokenEndpoint)
-	default:
-		return nil, errors.Errorf("executors.exchangeCodeForToken: token_endpoint_auth_method %q unsupported", authMethod)
+		return nil, errors.Wrap(err, "executors.exchangeCodeForToken")
 	}
-
+	resp, errResponse := request.Post(tokenEndpoint)
 	if errResponse != nil {
 		logger.WithFields(logrus.Fields{
 			"tokenEndpoint": tokenEndpoint,
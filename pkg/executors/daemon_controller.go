@@ -10,6 +10,7 @@ import (
 type DaemonController interface {
 	Stop()
 	ShouldStop() bool
+	StopSignal() <-chan struct{}
 	Stopped()
 
 	AddResult(result results.TestCase)
@@ -33,6 +34,8 @@ type daemonController struct {
 	responseFields  string
 	stopLock        *sync.Mutex
 	shouldStop      bool
+	stopSignal      chan struct{}
+	stopSignalled   bool
 	isCompletedChan chan bool
 }
 
@@ -51,6 +54,7 @@ func NewDaemonController(resultChan chan results.TestCase) *daemonController {
 		resultChan:      resultChan,
 		stopLock:        &sync.Mutex{},
 		shouldStop:      false,
+		stopSignal:      make(chan struct{}),
 		isCompletedChan: make(chan bool, 1),
 		resultsGrouped:  make(map[results.ResultKey][]results.TestCase),
 	}
@@ -61,6 +65,16 @@ func (rc *daemonController) Stop() {
 	rc.stopLock.Lock()
 	defer rc.stopLock.Unlock()
 	rc.shouldStop = true
+	if !rc.stopSignalled {
+		rc.stopSignalled = true
+		close(rc.stopSignal)
+	}
+}
+
+// StopSignal is closed once the daemon is told to stop, it stays closed after Stopped
+// so routines waiting on it, like request retries, do not carry on with the stopped run
+func (rc *daemonController) StopSignal() <-chan struct{} {
+	return rc.stopSignal
 }
 
 // Stopped tell the daemon service has stopped
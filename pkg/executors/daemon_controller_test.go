@@ -49,6 +49,28 @@ func TestDaemonControllerStops(t *testing.T) {
 	assert.True(controller.ShouldStop())
 }
 
+func TestDaemonControllerStopSignal(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	controller := NewBufferedDaemonController()
+	select {
+	case <-controller.StopSignal():
+		assert.Fail("stop signal closed before stopping")
+	default:
+	}
+
+	controller.Stop()
+	controller.Stop()
+	controller.Stopped()
+
+	assert.False(controller.ShouldStop())
+	select {
+	case <-controller.StopSignal():
+	case <-time.After(selectTimeout):
+		assert.Fail("stop signal not closed after stopping")
+	}
+}
+
 func TestNewBufferedDaemonControllerResults(t *testing.T) {
 	require := test.NewAssert(t)
 
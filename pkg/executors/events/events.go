@@ -1,5 +1,7 @@
 package events
 
+import "sync"
+
 // Events -
 type Events interface {
 	AddAcquiredAccessToken(acquiredAccessToken AcquiredAccessToken)
@@ -9,6 +11,10 @@ type Events interface {
 	AddAcquiredAllAccessTokens(acquiredAllAccessTokens AcquiredAllAccessTokens)
 	AllTokensChannel() <-chan AcquiredAllAccessTokens
 	AllAcquiredAllAccessTokens() []AcquiredAllAccessTokens
+
+	AddRefreshedAccessToken(refreshedAccessToken RefreshedAccessToken)
+	RefreshedTokensChannel() <-chan RefreshedAccessToken
+	AllRefreshedAccessTokens() []RefreshedAccessToken
 }
 
 // NewEvents -
@@ -19,6 +25,8 @@ func NewEvents() Events {
 		acquiredAccessTokensChan:   make(chan AcquiredAccessToken, size),
 		acquiredAllAccessTokens:    []AcquiredAllAccessTokens{},
 		aquiredAllAccessTokensChan: make(chan AcquiredAllAccessTokens, size),
+		refreshedAccessTokens:      []RefreshedAccessToken{},
+		refreshedAccessTokensChan:  make(chan RefreshedAccessToken, size),
 	}
 }
 
@@ -27,6 +35,9 @@ type events struct {
 	acquiredAccessTokensChan   chan AcquiredAccessToken
 	acquiredAllAccessTokens    []AcquiredAllAccessTokens
 	aquiredAllAccessTokensChan chan AcquiredAllAccessTokens
+	refreshedAccessTokensLock  sync.Mutex
+	refreshedAccessTokens      []RefreshedAccessToken
+	refreshedAccessTokensChan  chan RefreshedAccessToken
 }
 
 func (e *events) AddAcquiredAccessToken(acquiredAccessToken AcquiredAccessToken) {
@@ -54,3 +65,26 @@ func (e *events) AllTokensChannel() <-chan AcquiredAllAccessTokens {
 func (e *events) AllAcquiredAllAccessTokens() []AcquiredAllAccessTokens {
 	return e.acquiredAllAccessTokens
 }
+
+// AddRefreshedAccessToken records a refresh, tokens are refreshed by concurrent test cases while no client
+// may be listening so the event is dropped from the channel when it is full
+func (e *events) AddRefreshedAccessToken(refreshedAccessToken RefreshedAccessToken) {
+	e.refreshedAccessTokensLock.Lock()
+	e.refreshedAccessTokens = append(e.refreshedAccessTokens, refreshedAccessToken)
+	e.refreshedAccessTokensLock.Unlock()
+
+	select {
+	case e.refreshedAccessTokensChan <- refreshedAccessToken:
+	default:
+	}
+}
+
+func (e *events) RefreshedTokensChannel() <-chan RefreshedAccessToken {
+	return e.refreshedAccessTokensChan
+}
+
+func (e *events) AllRefreshedAccessTokens() []RefreshedAccessToken {
+	e.refreshedAccessTokensLock.Lock()
+	defer e.refreshedAccessTokensLock.Unlock()
+	return append([]RefreshedAccessToken{}, e.refreshedAccessTokens...)
+}
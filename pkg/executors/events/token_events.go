@@ -10,6 +10,14 @@ type AcquiredAllAccessTokens struct {
 	TokenNames []string `json:"token_names"`
 }
 
+// RefreshedAccessToken - When an `access_token` has been refreshed with its `refresh_token`.
+type RefreshedAccessToken struct {
+	TokenName string `json:"token_name"`
+	Reason    string `json:"reason"`              // `expired` or `invalid_token`
+	TestCase  string `json:"test_case,omitempty"` // test case the token was refreshed for
+	Error     string `json:"error,omitempty"`     // why the refresh failed, the test case then runs with the old token
+}
+
 // NewAcquiredAccessToken -
 func NewAcquiredAccessToken(tokenName string) AcquiredAccessToken {
 	return AcquiredAccessToken{
@@ -23,3 +31,16 @@ func NewAcquiredAllAccessTokens(tokenNames []string) AcquiredAllAccessTokens {
 		TokenNames: tokenNames,
 	}
 }
+
+// NewRefreshedAccessToken -
+func NewRefreshedAccessToken(tokenName, reason, testCase string, err error) RefreshedAccessToken {
+	event := RefreshedAccessToken{
+		TokenName: tokenName,
+		Reason:    reason,
+		TestCase:  testCase,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	return event
+}
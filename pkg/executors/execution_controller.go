@@ -6,7 +6,6 @@ import (
 	"sync"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/schema"
-	"github.com/OpenBankingUK/conformance-suite/pkg/schemaprops"
 
 	"gopkg.in/resty.v1"
 
@@ -16,9 +15,11 @@ import (
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
 	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/events"
 	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
 	"github.com/OpenBankingUK/conformance-suite/pkg/generation"
 	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/permissions"
 	"github.com/OpenBankingUK/conformance-suite/pkg/tracer"
 )
 
@@ -28,6 +29,15 @@ type RunDefinition struct {
 	SpecRun       generation.SpecRun
 	SigningCert   authentication.Certificate
 	TransportCert authentication.Certificate
+	// Workers is the number of test cases of a specification run concurrently, test cases run one at a time when less than 2
+	Workers     int
+	RetryPolicy RetryPolicy
+	Refresher   *TokenRefresher // refreshes the collected access tokens when they expire, not refreshed when nil
+	Events      events.Events   // events of the run, token refreshes are recorded in
+	// ConsentResolver selects how the account test cases of a headless run are grouped into consents
+	ConsentResolver permissions.ResolverMode
+	// HTTPClient is the client of the journey the transport certificate is set on, resty's default client when nil
+	HTTPClient *resty.Client
 }
 
 type TestCaseRunner struct {
@@ -42,7 +52,7 @@ type TestCaseRunner struct {
 // NewTestCaseRunner -
 func NewTestCaseRunner(logger *logrus.Entry, definition RunDefinition, daemonController DaemonController) *TestCaseRunner {
 	return &TestCaseRunner{
-		executor:         NewExecutor(),
+		executor:         newRunExecutor(definition, daemonController.StopSignal()),
 		definition:       definition,
 		daemonController: daemonController,
 		logger:           logger.WithField("module", "TestCaseRunner"),
@@ -54,7 +64,7 @@ func NewTestCaseRunner(logger *logrus.Entry, definition RunDefinition, daemonCon
 // NewConsentAcquisitionRunner -
 func NewConsentAcquisitionRunner(logger *logrus.Entry, definition RunDefinition, daemonController DaemonController) *TestCaseRunner {
 	return &TestCaseRunner{
-		executor:         NewExecutor(),
+		executor:         newRunExecutor(definition, daemonController.StopSignal()),
 		definition:       definition,
 		daemonController: daemonController,
 		logger:           logger.WithField("module", "ConsentAcquisitionRunner"),
@@ -66,7 +76,7 @@ func NewConsentAcquisitionRunner(logger *logrus.Entry, definition RunDefinition,
 // NewExchangeComponentRunner -
 func NewExchangeComponentRunner(definition RunDefinition, daemonController DaemonController) *TestCaseRunner {
 	return &TestCaseRunner{
-		executor:         NewExecutor(),
+		executor:         newRunExecutor(definition, daemonController.StopSignal()),
 		definition:       definition,
 		daemonController: daemonController,
 		logger:           logrus.StandardLogger().WithField("module", "ExchangeComponent"),
@@ -116,7 +126,7 @@ func (r *TestCaseRunner) runTestCasesAsync(ctx *model.Context) {
 		r.executeSpecTests(spec, ruleCtx, ctxLogger) // Run Tests for each spec
 	}
 
-	collector := schemaprops.GetPropertyCollector()
+	collector := model.PropertyCollector(ruleCtx)
 	r.daemonController.AddResponseFields(collector.OutputJSON())
 
 	r.daemonController.SetCompleted()
@@ -134,16 +144,11 @@ func (r *TestCaseRunner) runConsentAcquisitionAsync(item TokenConsentIDItem, ctx
 	ruleCtx.PutString("consent_id", item.TokenName)
 	ruleCtx.PutString("token_name", item.TokenName)
 	ruleCtx.PutString("permission_list", item.Permissions)
+	ruleCtx.PutString("consent_spec_type", "accounts") // only accounts consent is acquired by a component
 
 	ctxLogger := r.logger.WithField("id", uuid.New())
 	var comp model.Component
 
-	// Check for MTLS vs client basic authentication
-	authMethod, err := ctx.GetString("token_endpoint_auth_method")
-	if err != nil {
-		authMethod = authentication.ClientSecretBasic
-	}
-
 	if consentType == "psu" || consentType == "mobile" {
 		comp, err = model.LoadComponent("PSUConsentProviderComponent.json")
 		if err != nil {
@@ -153,7 +158,7 @@ func (r *TestCaseRunner) runConsentAcquisitionAsync(item TokenConsentIDItem, ctx
 		}
 
 	} else {
-		comp, err = model.LoadComponent("headlessTokenProviderProviderComponent.json")
+		comp, err = model.LoadComponent("headlessTokenProviderComponent.json")
 		if err != nil {
 			r.AppMsg("Load HeadlessConsent Component Failed: " + err.Error())
 			r.setNotRunning()
@@ -175,7 +180,7 @@ func (r *TestCaseRunner) runConsentAcquisitionAsync(item TokenConsentIDItem, ctx
 		comp.Tests[k] = v
 	}
 
-	r.executeComponentTests(&comp, ruleCtx, ctxLogger, item, consentIDChannel, authMethod)
+	r.executeComponentTests(&comp, ruleCtx, ctxLogger, item, consentIDChannel)
 	clientGrantToken, err := ruleCtx.GetString("client_access_token")
 	if err == nil {
 		logrus.StandardLogger().WithFields(logrus.Fields{
@@ -187,7 +192,7 @@ func (r *TestCaseRunner) runConsentAcquisitionAsync(item TokenConsentIDItem, ctx
 	r.setNotRunning()
 }
 
-func (r *TestCaseRunner) executeComponentTests(comp *model.Component, ruleCtx *model.Context, logger *logrus.Entry, item TokenConsentIDItem, consentIDChannel chan<- TokenConsentIDItem, authMethod string) {
+func (r *TestCaseRunner) executeComponentTests(comp *model.Component, ruleCtx *model.Context, logger *logrus.Entry, item TokenConsentIDItem, consentIDChannel chan<- TokenConsentIDItem) {
 	ctxLogger := logger.WithFields(logrus.Fields{
 		"component": comp.Name,
 		"module":    "TestCaseRunner",
@@ -200,68 +205,12 @@ func (r *TestCaseRunner) executeComponentTests(comp *model.Component, ruleCtx *m
 			return
 		}
 
-		if testcase.ID == "#compPsuConsent01" {
-			switch authMethod {
-			case authentication.ClientSecretBasic:
-				testcase.Input.SetHeader("authorization", "Basic $basic_authentication")
-			case authentication.TlsClientAuth:
-				clientid, err := ruleCtx.GetString("client_id")
-				if err != nil {
-					ctxLogger.WithFields(logrus.Fields{
-						"authMethod": authMethod,
-						"err":        err,
-					}).Error("cannot locate client_id to populate form field")
-					continue
-				}
-
-				testcase.Input.SetFormField("client_id", clientid)
-			case authentication.PrivateKeyJwt:
-				clientID, err := ruleCtx.GetString("client_id")
-				if err != nil {
-					ctxLogger.WithFields(logrus.Fields{
-						"authMethod": authMethod,
-						"err":        err,
-					}).Error("cannot locate client_id to populate form field")
-					continue
-				}
-
-				tokenEndpoint, err := ruleCtx.GetString("token_endpoint")
-				if err != nil {
-					ctxLogger.WithFields(logrus.Fields{
-						"authMethod": authMethod,
-						"err":        err,
-					}).Error("cannot locate token_endpoint to populate form field")
-				}
-
-				if testcase.Input.Claims == nil {
-					testcase.Input.Claims = map[string]string{}
-				}
-
-				// https://openid.net/specs/openid-connect-core-1_0.html#ClientAuthentication
-				// iss
-				// REQUIRED. Issuer. This MUST contain the client_id of the OAuth Client.
-				// sub
-				// REQUIRED. Subject. This MUST contain the client_id of the OAuth Client.
-				// aud
-				// REQUIRED. Audience. The aud (audience) Claim. Value that identifies the Authorization Server as an intended audience. The Authorization Server MUST verify that it is an intended audience for the token. The Audience SHOULD be the URL of the Authorization Server's Token Endpoint.
-				testcase.Input.Claims["iss"] = clientID
-				testcase.Input.Claims["sub"] = clientID
-				testcase.Input.Claims["aud"] = tokenEndpoint
-				clientAssertion, err := testcase.Input.GenerateRequestToken(ruleCtx)
-				if err != nil {
-					ctxLogger.WithFields(logrus.Fields{
-						"testcase": testcase,
-						"err":      err,
-					}).Error("failed on testcase.Input.GenerateRequestToken")
-					continue
-				}
-
-				testcase.Input.SetFormField(authentication.ClientAssertionType, authentication.ClientAssertionTypeValue)
-				testcase.Input.SetFormField(authentication.ClientAssertion, clientAssertion)
-			default:
+		if isTokenRequest(testcase.Input) {
+			if err := authenticateTokenRequest(&testcase.Input, ruleCtx); err != nil {
 				ctxLogger.WithFields(logrus.Fields{
-					"authMethod": authMethod,
-				}).Error("Unsupported token_endpoint_auth_method")
+					"testcase": testcase.ID,
+					"err":      err,
+				}).Error("cannot authenticate token request")
 				continue
 			}
 		}
@@ -325,9 +274,15 @@ func (r *TestCaseRunner) makeRuleCtx(ctx *model.Context) *model.Context {
 
 func (r *TestCaseRunner) executeSpecTests(spec generation.SpecificationTestCases, ruleCtx *model.Context, ctxLogger *logrus.Entry) {
 	ctxLogger = ctxLogger.WithField("spec", spec.Specification.Name)
-	collector := schemaprops.GetPropertyCollector()
+	collector := model.PropertyCollector(ruleCtx)
 	collector.SetCollectorAPIDetails(spec.Specification.Name, spec.Specification.Version)
 
+	if r.definition.Workers > 1 {
+		r.executeSpecTestsConcurrently(spec, ruleCtx, ctxLogger, r.definition.Workers)
+		return
+	}
+
+	failed := map[string]bool{}
 	for _, testcase := range spec.TestCases {
 		if r.daemonController.ShouldStop() {
 			ctxLogger.Info("stop test run received, aborting runner")
@@ -335,11 +290,35 @@ func (r *TestCaseRunner) executeSpecTests(spec generation.SpecificationTestCases
 		}
 		ctxLogger = ctxLogger.WithField("ID", testcase.ID)
 		ruleCtx.DumpContext("ruleCtx before: " + testcase.ID)
-		testResult := r.executeTest(testcase, ruleCtx, ctxLogger)
+		testResult := r.executeTestUnlessUpstreamFailed(testcase, ruleCtx, ctxLogger, failed)
 		r.daemonController.AddResult(testResult)
 	}
 }
 
+// executeTestUnlessUpstreamFailed skips a test case when a test case it depends on has failed or was skipped,
+// failed test cases are recorded in failed
+func (r *TestCaseRunner) executeTestUnlessUpstreamFailed(tc model.TestCase, ruleCtx *model.Context, logger *logrus.Entry, failed map[string]bool) results.TestCase {
+	upstream := []string{}
+	for _, id := range tc.DependsOn {
+		if failed[id] {
+			upstream = append(upstream, id)
+		}
+	}
+
+	var testResult results.TestCase
+	if len(upstream) > 0 {
+		logWithTestCase(logger, tc).WithFields(logrus.Fields{"result": "SKIP", "upstream": upstream}).Info("test result")
+		testResult = results.NewTestCaseSkipped(tc.ID, upstream, tc.Input.Endpoint, tc.APIName, tc.APIVersion, tc.Detail, tc.RefURI)
+	} else {
+		testResult = r.executeTest(tc, ruleCtx, logger)
+	}
+
+	if !testResult.Pass {
+		failed[tc.ID] = true
+	}
+	return testResult
+}
+
 func (r *TestCaseRunner) executeTest(tc model.TestCase, ruleCtx *model.Context, logger *logrus.Entry) results.TestCase {
 	ctxLogger := logWithTestCase(logger, tc)
 	req, err := tc.Prepare(ruleCtx)
@@ -358,7 +337,9 @@ func (r *TestCaseRunner) executeTest(tc model.TestCase, ruleCtx *model.Context,
 	if errs != nil {
 		detailedErrors := detailedErrors(errs, resp)
 		ctxLogger.WithField("errs", detailedErrors).WithFields(logrus.Fields{"result": passText()[result], "ID": tc.ID}).Error("test result validate")
-		return results.NewTestCaseFail(tc.ID, metrics, detailedErrors, tc.Input.Endpoint, tc.APIName, tc.APIVersion, tc.Detail, tc.RefURI, tc.StatusCode)
+		testResult := results.NewTestCaseFail(tc.ID, metrics, detailedErrors, tc.Input.Endpoint, tc.APIName, tc.APIVersion, tc.Detail, tc.RefURI, tc.StatusCode)
+		testResult.Signature = tc.SignatureVerification
+		return testResult
 	}
 
 	if !result {
@@ -367,7 +348,9 @@ func (r *TestCaseRunner) executeTest(tc model.TestCase, ruleCtx *model.Context,
 		ctxLogger.WithError(err).WithFields(logrus.Fields{"result": passText()[result], "ID": tc.ID}).Info("test result")
 	}
 
-	return results.NewTestCaseResult(tc.ID, result, metrics, []error{}, tc.Input.Endpoint, tc.APIName, tc.APIVersion, tc.Detail, tc.RefURI, tc.StatusCode)
+	testResult := results.NewTestCaseResult(tc.ID, result, metrics, []error{}, tc.Input.Endpoint, tc.APIName, tc.APIVersion, tc.Detail, tc.RefURI, tc.StatusCode)
+	testResult.Signature = tc.SignatureVerification
+	return testResult
 }
 
 type DetailError struct {
@@ -1,16 +1,22 @@
 package executors
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/test"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/executors/mocks"
+	"github.com/OpenBankingUK/conformance-suite/pkg/generation"
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewTestCaseRunner(t *testing.T) {
 	controller := &mocks.DaemonController{}
+	controller.On("StopSignal").Return(make(<-chan struct{}))
 	definition := RunDefinition{}
 	runner := NewTestCaseRunner(test.NullLogger(), definition, controller)
 
@@ -19,3 +25,36 @@ func TestNewTestCaseRunner(t *testing.T) {
 	assert.Equal(t, controller, runner.daemonController)
 	assert.False(t, runner.running)
 }
+
+func TestExecuteSpecTestsSkipsDependentsOfFailedTests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/domestic-payment-consents" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	for _, workers := range []int{0, 2} {
+		producer := newSchedulerTestCase("#t1", server.URL+"/domestic-payment-consents", "consentId")
+		dependent := newSchedulerTestCase("#t2", server.URL+"/domestic-payment-consents/$consentId", "")
+		dependent.DependsOn = []string{"#t1"}
+		transitive := newSchedulerTestCase("#t3", server.URL+"/domestic-payments", "")
+		transitive.DependsOn = []string{"#t2"}
+		independent := newSchedulerTestCase("#t4", server.URL+"/accounts", "")
+		spec := generation.SpecificationTestCases{TestCases: []model.TestCase{producer, dependent, transitive, independent}}
+		controller := NewBufferedDaemonController()
+		runner := NewTestCaseRunner(test.NullLogger(), RunDefinition{Workers: workers}, controller)
+
+		runner.executeSpecTests(spec, &model.Context{}, test.NullLogger())
+
+		testResults := controller.AllResults()
+		require.Len(t, testResults, 4)
+		assert.False(t, testResults[0].Pass)
+		assert.False(t, testResults[0].Skipped)
+		assert.True(t, testResults[1].Skipped)
+		assert.Equal(t, []string{"skipped: upstream failed: #t1"}, testResults[1].Fail)
+		assert.True(t, testResults[2].Skipped)
+		assert.Equal(t, []string{"skipped: upstream failed: #t2"}, testResults[2].Fail)
+		assert.True(t, testResults[3].Pass)
+	}
+}
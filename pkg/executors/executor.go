@@ -7,9 +7,11 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"time"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
 	"github.com/OpenBankingUK/conformance-suite/pkg/authentication/certificates"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/events"
 	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
 	"github.com/OpenBankingUK/conformance-suite/pkg/model"
 	"github.com/OpenBankingUK/conformance-suite/pkg/tracer"
@@ -31,10 +33,25 @@ func NewExecutor() TestCaseExecutor {
 	return &Executor{}
 }
 
+// NewRetryingExecutor creates an executor retrying requests that fail with a transient error
+func NewRetryingExecutor(retryPolicy RetryPolicy) TestCaseExecutor {
+	return &Executor{RetryPolicy: retryPolicy}
+}
+
+// newRunExecutor creates the executor of a run definition, its retries stop with the run's `stop` signal
+func newRunExecutor(definition RunDefinition, stop <-chan struct{}) TestCaseExecutor {
+	return &Executor{RetryPolicy: definition.RetryPolicy, Refresher: definition.Refresher, Events: definition.Events, HTTPClient: definition.HTTPClient, Stop: stop}
+}
+
 // Executor - passes request to system under test across an matls connection
 type Executor struct {
 	SigningCert   authentication.Certificate
 	TransportCert authentication.Certificate
+	RetryPolicy   RetryPolicy
+	Refresher     *TokenRefresher // refreshes expired access tokens when not nil
+	Events        events.Events   // records token refreshes when not nil
+	HTTPClient    *resty.Client   // client the transport certificate is set on, resty's default client when nil
+	Stop          <-chan struct{} // requests are not retried once closed, retries wait for their backoff when nil
 }
 
 // SetCertificates receives transport and signing certificates
@@ -52,15 +69,14 @@ func (e *Executor) ExecuteTestCase(r *resty.Request, t *model.TestCase, ctx *mod
 	}
 
 	e.appMsg(fmt.Sprintf("Execute Testcase: %s: %s", t.ID, t.Name))
-	e.appMsg(fmt.Sprintf("attempting %s %s", r.Method, r.URL))
-	resp, err := r.Execute(r.Method, r.URL)
+	resp, attempts, err := e.executeWithRefresh(r, t, ctx)
 	if err != nil {
 		if resp.StatusCode() == http.StatusFound { // catch status code 302 redirects and pass back as good response
 			header := resp.Header()
 			t.StatusCode = resp.Status()
 			logrus.StandardLogger().Printf("redirection headers: %#v\n", header)
 			e.appMsg(fmt.Sprintf("Response: (%.250s)", resp.String()))
-			return resp, metrics(t, resp), nil
+			return resp, metricsWithAttempts(t, resp, attempts), nil
 		}
 	}
 	t.StatusCode = resp.Status()
@@ -69,7 +85,101 @@ func (e *Executor) ExecuteTestCase(r *resty.Request, t *model.TestCase, ctx *mod
 		elipsis = " ..."
 	}
 	e.appMsg(fmt.Sprintf("Response: (%.450s)%s", resp.String(), elipsis))
-	return resp, metrics(t, resp), err
+	return resp, metricsWithAttempts(t, resp, attempts), err
+}
+
+// executeWithRefresh sends the request with the current access token, refreshed first when it has expired,
+// and sends it again with a refreshed access token when the resource server rejects it as `invalid_token`
+func (e *Executor) executeWithRefresh(r *resty.Request, t *model.TestCase, ctx *model.Context) (*resty.Response, int, error) {
+	accessToken := bearerToken(r)
+	if e.Refresher == nil || accessToken == "" {
+		return e.executeWithRetries(r)
+	}
+
+	current := e.Refresher.current(accessToken, ctx)
+	e.authorize(r, t, current, RefreshReasonExpired)
+	resp, attempts, err := e.executeWithRetries(r)
+	if !isInvalidToken(resp) {
+		return resp, attempts, err
+	}
+
+	refreshed := e.Refresher.refreshInvalid(current.accessToken, ctx)
+	e.authorize(r, t, refreshed, RefreshReasonInvalidToken)
+	if refreshed.accessToken == current.accessToken {
+		return resp, attempts, err
+	}
+	resp, retries, err := e.executeWithRetries(r)
+	return resp, attempts + retries, err
+}
+
+// authorize sets the access token of the request and records the refresh when one was attempted
+func (e *Executor) authorize(r *resty.Request, t *model.TestCase, token refreshedAccessToken, reason string) {
+	if token.refreshed {
+		msg := fmt.Sprintf("Refreshed access token %s (%s)", token.tokenName, reason)
+		if token.err != nil {
+			msg += ": " + token.err.Error()
+		}
+		e.appMsg(msg)
+		if e.Events != nil {
+			e.Events.AddRefreshedAccessToken(events.NewRefreshedAccessToken(token.tokenName, reason, t.ID, token.err))
+		}
+	}
+	r.SetAuthToken(token.accessToken)
+}
+
+// executeWithRetries sends the request until it succeeds, fails with an error that is not transient,
+// the retry policy allows no more attempts or the run is stopped during a backoff, returns the last
+// response and the number of attempts
+func (e *Executor) executeWithRetries(r *resty.Request) (*resty.Response, int, error) {
+	method, url := r.Method, r.URL
+	attempt := 1
+	for {
+		e.appMsg(fmt.Sprintf("attempting %s %s (attempt %d)", method, url, attempt))
+		resp, err := r.Execute(method, url)
+		if !e.RetryPolicy.canRetry(method, attempt) || !e.RetryPolicy.isRetryable(resp, err) {
+			return resp, attempt, err
+		}
+
+		delay := e.RetryPolicy.delay(attempt, resp)
+		logrus.StandardLogger().WithFields(logrus.Fields{
+			"module":  "Executor",
+			"method":  method,
+			"url":     url,
+			"attempt": attempt,
+			"status":  resp.StatusCode(),
+			"err":     err,
+			"delay":   delay,
+		}).Warn("transient error, retrying request")
+		if !e.waitForRetry(delay) {
+			e.appMsg(fmt.Sprintf("run stopped, not retrying %s %s", method, url))
+			return resp, attempt, err
+		}
+		attempt++
+	}
+}
+
+// waitForRetry waits for the backoff `delay`, returns false without waiting it out when the run is stopped
+func (e *Executor) waitForRetry(delay time.Duration) bool {
+	select {
+	case <-e.Stop:
+		return false
+	default:
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-e.Stop:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func metricsWithAttempts(testCase *model.TestCase, response *resty.Response, attempts int) results.Metrics {
+	m := metrics(testCase, response)
+	m.Attempts = attempts
+	return m
 }
 
 func metrics(testCase *model.TestCase, response *resty.Response) results.Metrics {
@@ -112,10 +222,18 @@ func (e *Executor) setupTLSCertificate(tlsCert tls.Certificate) error {
 		},
 	}
 	tlsConfig.BuildNameToCertificate()
-	resty.SetTLSClientConfig(tlsConfig)
+	e.httpClient().SetTLSClientConfig(tlsConfig)
 	return nil
 }
 
+// httpClient returns the client the transport certificate is set on
+func (e *Executor) httpClient() *resty.Client {
+	if e.HTTPClient == nil {
+		return resty.DefaultClient
+	}
+	return e.HTTPClient
+}
+
 func (e *Executor) appMsg(msg string) {
 	tracer.AppMsg("Executor", msg, "")
 }
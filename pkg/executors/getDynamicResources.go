@@ -53,7 +53,7 @@ func getDynamicResourceIds(tokenName, token string, ctx *model.Context, logger *
 
 	accountsEndpoint := resourceBaseURL + "/open-banking/" + apiVersion + "/aisp/accounts"
 	var resp *resty.Response
-	resp, err = resty.R().
+	resp, err = model.HTTPClient(ctx).R().
 		SetHeader("Authorization", "Bearer "+token).
 		SetHeader("X-Fapi-Financial-Id", xFapiFinancialID).
 		SetHeader("X-Fapi-Interaction-Id", interactionId).
@@ -108,6 +108,22 @@ func (_m *DaemonController) ShouldStop() bool {
 	return r0
 }
 
+// StopSignal provides a mock function with given fields:
+func (_m *DaemonController) StopSignal() <-chan struct{} {
+	ret := _m.Called()
+
+	var r0 <-chan struct{}
+	if rf, ok := ret.Get(0).(func() <-chan struct{}); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan struct{})
+		}
+	}
+
+	return r0
+}
+
 // Stop provides a mock function with given fields:
 func (_m *DaemonController) Stop() {
 	_m.Called()
@@ -1,7 +1,6 @@
 package executors
 
 import (
-	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
 	"github.com/pkg/errors"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/manifest"
@@ -9,8 +8,9 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func getPaymentConsents(definition RunDefinition, requiredTokens []manifest.RequiredTokens, ctx *model.Context) (TokenConsentIDs, error) {
-	executor := &Executor{}
+// getPaymentConsents acquires the consents of payments or vrps, `specType`
+func getPaymentConsents(definition RunDefinition, specType string, requiredTokens []manifest.RequiredTokens, ctx *model.Context) (TokenConsentIDs, error) {
+	executor := &Executor{HTTPClient: definition.HTTPClient}
 	err := executor.SetCertificates(definition.SigningCert, definition.TransportCert)
 	if err != nil {
 		logrus.Error("error running payment consent acquisition async: " + err.Error())
@@ -22,7 +22,7 @@ func getPaymentConsents(definition RunDefinition, requiredTokens []manifest.Requ
 		logrus.Tracef("%#v", rt)
 	}
 
-	requiredTokens, err = runPaymentConsents(requiredTokens, ctx, executor)
+	requiredTokens, err = runPaymentConsents(requiredTokens, specType, ctx, executor)
 	if err != nil {
 		logrus.Errorf("getPaymentConsents error: " + err.Error())
 	}
@@ -37,10 +37,11 @@ func getPaymentConsents(definition RunDefinition, requiredTokens []manifest.Requ
 	return consentItems, err
 }
 
-func runPaymentConsents(rt []manifest.RequiredTokens, ctx *model.Context, executor *Executor) ([]manifest.RequiredTokens, error) {
+func runPaymentConsents(rt []manifest.RequiredTokens, specType string, ctx *model.Context, executor *Executor) ([]manifest.RequiredTokens, error) {
 	localCtx := model.Context{}
 	localCtx.PutContext(ctx)
 	localCtx.PutString("scope", "payments")
+	localCtx.PutString("consent_spec_type", specType)
 	consentJobs := manifest.GetConsentJobs()
 
 	tc, err := readClientCredentialGrant()
@@ -48,44 +49,8 @@ func runPaymentConsents(rt []manifest.RequiredTokens, ctx *model.Context, execut
 		return nil, errors.New("payment PSU consent load clientCredentials testcase failed")
 	}
 
-	// Check for MTLS vs client basic authentication
-	authMethod, err := ctx.GetString("token_endpoint_auth_method")
-	logrus.Tracef("runpaymentconsent auth %s", authMethod)
-
-	if err != nil {
-		authMethod = "client_secret_basic"
-	}
-
-	switch authMethod {
-	case authentication.ClientSecretBasic:
-		tc.Input.SetHeader("authorization", "Basic $basic_authentication")
-	case authentication.PrivateKeyJwt:
-		clientID, err := ctx.GetString("client_id")
-		if err != nil {
-			return nil, errors.Wrap(err, "cannot find client_id for private_key_jwt form field")
-		}
-		tokenEndpoint, err := ctx.GetString("token_endpoint")
-		if err != nil {
-			return nil, errors.Wrap(err, "cannot find token_endpoint for private_key_jwt form field")
-		}
-		if tc.Input.Claims == nil {
-			tc.Input.Claims = map[string]string{}
-		}
-		tc.Input.Claims["iss"] = clientID
-		tc.Input.Claims["sub"] = clientID
-		tc.Input.Claims["aud"] = tokenEndpoint
-		clientAssertion, err := tc.Input.GenerateRequestToken(ctx)
-		if err != nil {
-			return nil, errors.Wrap(err, "cannot generate request token for private_key_jwt form field")
-		}
-		tc.Input.SetFormField(authentication.ClientAssertionType, authentication.ClientAssertionTypeValue)
-		tc.Input.SetFormField(authentication.ClientAssertion, clientAssertion)
-	case authentication.TlsClientAuth:
-		clientid, err := ctx.GetString("client_id")
-		if err != nil {
-			logrus.Warn("cannot locate client_id for tls_client_auth form field")
-		}
-		tc.Input.SetFormField("client_id", clientid)
+	if err := authenticateTokenRequest(&tc.Input, ctx); err != nil {
+		return nil, errors.Wrap(err, "payment PSU consent authenticate clientCredentials testcase failed")
 	}
 
 	tc.ProcessReplacementFields(&localCtx, true)
@@ -127,17 +92,6 @@ func runPaymentConsents(rt []manifest.RequiredTokens, ctx *model.Context, execut
 		if err != nil {
 			return nil, errors.New("Payment PSU consent load psu_exchange testcase failed")
 		}
-		if authMethod == "tls_client_auth" {
-			clientid, err := ctx.GetString("client_id")
-			if err != nil {
-				logrus.Warn("cannot locate client_id for tls_client_auth form field")
-			}
-			exchange.Input.SetFormField("client_id", clientid)
-
-		} else {
-			exchange.Input.SetHeader("authorization", "Basic $basic_authentication")
-		}
-
 		localCtx.DumpContext("before exchange", "token_name", "consent_id")
 		err = executePaymentTest(&exchange, &localCtx, executor)
 		if err != nil {
@@ -12,21 +12,38 @@ type Metrics struct {
 	TestCase     *model.TestCase
 	ResponseTime time.Duration // Http Response Time
 	ResponseSize int           // Size in bytes of the HTTP Response body
+	Attempts     int           // Number of times the request was sent, more than one when retried
 }
 
 // MarshalJSON is a custom marshaler which formats a Metrics struct
 // with a response time represented as unit of milliseconds
 // response time decimal precision is up the nanosecond eg: 1.234ms
 func (m Metrics) MarshalJSON() ([]byte, error) {
-	return json.Marshal(struct {
-		ResponseTime float64 `json:"response_time"`
-		ResponseSize int     `json:"response_size"`
-	}{
+	return json.Marshal(metricsJSON{
 		ResponseTime: float64(m.ResponseTime) / float64(time.Millisecond),
 		ResponseSize: m.ResponseSize,
+		Attempts:     m.Attempts,
 	})
 }
 
+// UnmarshalJSON reads metrics formatted by MarshalJSON, the test case is not part of the JSON
+func (m *Metrics) UnmarshalJSON(data []byte) error {
+	value := metricsJSON{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	m.ResponseTime = time.Duration(value.ResponseTime * float64(time.Millisecond))
+	m.ResponseSize = value.ResponseSize
+	m.Attempts = value.Attempts
+	return nil
+}
+
+type metricsJSON struct {
+	ResponseTime float64 `json:"response_time"`
+	ResponseSize int     `json:"response_size"`
+	Attempts     int     `json:"attempts,omitempty"`
+}
+
 func NoMetrics() Metrics {
 	return Metrics{}
 }
@@ -1,6 +1,7 @@
 package results
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -29,3 +30,15 @@ func TestNewMetrics(t *testing.T) {
 	assert.Equal(t, time.Second, metrics.ResponseTime)
 	assert.Equal(t, 1, metrics.ResponseSize)
 }
+
+func TestMetricsJSONRoundTrip(t *testing.T) {
+	metrics := Metrics{ResponseTime: 1234 * time.Microsecond, ResponseSize: 42, Attempts: 2}
+
+	data, err := json.Marshal(metrics)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"response_time":1.234,"response_size":42,"attempts":2}`, string(data))
+
+	read := Metrics{}
+	assert.NoError(t, json.Unmarshal(data, &read))
+	assert.Equal(t, metrics, read)
+}
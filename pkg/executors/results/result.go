@@ -1,5 +1,12 @@
 package results
 
+import (
+	"fmt"
+	"strings"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
+)
+
 // TestCase result for a run
 type TestCase struct {
 	Id         string   `json:"id"`
@@ -12,6 +19,16 @@ type TestCase struct {
 	API        string   `json:"-"`
 	APIVersion string   `json:"-"`
 	HttpStatus string   `json:"httpStatusCode"`
+	Skipped    bool     `json:"skipped,omitempty"`
+
+	Signature *authentication.SignatureVerification `json:"signature,omitempty"` // x-jws-signature verification of the response
+}
+
+// NewTestCaseSkipped returns a test that was not run because test cases it depends on failed
+func NewTestCaseSkipped(id string, upstream []string, endpoint, api, apiVersion, detail, refURI string) TestCase {
+	result := NewTestCaseFail(id, NoMetrics(), []error{fmt.Errorf("skipped: upstream failed: %s", strings.Join(upstream, ", "))}, endpoint, api, apiVersion, detail, refURI, "")
+	result.Skipped = true
+	return result
 }
 
 // NewTestCaseFail returns a failed test
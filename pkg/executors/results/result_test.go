@@ -3,6 +3,7 @@ package results
 import (
 	"encoding/json"
 
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
 	"github.com/OpenBankingUK/conformance-suite/pkg/test"
 	"github.com/stretchr/testify/require"
 
@@ -69,3 +70,42 @@ func TestTestCaseResultJsonMarshal(t *testing.T) {
 
 	require.JSONEq(t, expected, string(actual))
 }
+
+func TestTestCaseResultJsonMarshalSignature(t *testing.T) {
+	result := NewTestCaseResult("123", true, NoMetrics(), nil, "endpoint", "api-name", "api-version", "detailed description", "https://openbanking.org.uk/ref/uri", "200")
+	result.Signature = &authentication.SignatureVerification{
+		Valid:      false,
+		Kid:        "kid-001",
+		BodyDigest: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		Checks: []authentication.SignatureCheck{
+			{Name: "alg", Pass: true},
+			{Name: "signature", Detail: "failed to verify message"},
+		},
+	}
+
+	actual, err := json.Marshal(result)
+	require.NoError(t, err)
+
+	signature := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(actual, &struct {
+		Signature *map[string]interface{} `json:"signature"`
+	}{&signature}))
+	require.Equal(t, false, signature["valid"])
+	require.Equal(t, "kid-001", signature["kid"])
+	require.Equal(t, []interface{}{
+		map[string]interface{}{"name": "alg", "pass": true},
+		map[string]interface{}{"name": "signature", "pass": false, "detail": "failed to verify message"},
+	}, signature["checks"])
+}
+
+func TestNewTestCaseSkipped(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	result := NewTestCaseSkipped("OB-301-DOP-100400", []string{"OB-301-DOP-100300", "OB-301-DOP-100350"}, "endpoint", "api-name", "api-version", "detailed description", "https://openbanking.org.uk/ref/uri")
+
+	assert.False(result.Pass)
+	assert.True(result.Skipped)
+	assert.Equal([]string{"skipped: upstream failed: OB-301-DOP-100300, OB-301-DOP-100350"}, result.Fail)
+	assert.Equal("api-name", result.API)
+	assert.Equal("", result.HttpStatus)
+}
@@ -0,0 +1,129 @@
+package executors
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"gopkg.in/resty.v1"
+)
+
+// Retry policy defaults, used when a policy allows retries but leaves them unset
+const (
+	DefaultRetryInitialBackoff = 500 * time.Millisecond
+	DefaultRetryMaxBackoff     = 30 * time.Second
+)
+
+// DefaultRetryableStatusCodes are the response status codes considered transient when a policy does not list any
+var DefaultRetryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy - how requests that fail with a transient error are retried.
+// The zero value sends each request once.
+type RetryPolicy struct {
+	MaxAttempts          int           // total attempts per request including the first one, less than 2 disables retries
+	InitialBackoff       time.Duration // wait before the first retry, doubled for each following retry
+	MaxBackoff           time.Duration // upper bound of the wait between attempts, also caps Retry-After
+	RetryableStatusCodes []int         // response status codes that are retried
+	RetryPOST            bool          // POST requests are only retried when set as they may not be idempotent
+}
+
+// canRetry checks if the policy allows another attempt after attempt number of attempts
+func (p RetryPolicy) canRetry(method string, attempt int) bool {
+	if attempt >= p.MaxAttempts {
+		return false
+	}
+	return method != resty.MethodPost || p.RetryPOST
+}
+
+// isRetryable checks if the outcome of an attempt is a transient error
+func (p RetryPolicy) isRetryable(resp *resty.Response, err error) bool {
+	if err != nil {
+		return isTransientError(err)
+	}
+	if resp == nil {
+		return false
+	}
+	statusCodes := p.RetryableStatusCodes
+	if len(statusCodes) == 0 {
+		statusCodes = DefaultRetryableStatusCodes
+	}
+	for _, statusCode := range statusCodes {
+		if resp.StatusCode() == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// delay returns how long to wait before retrying after attempt number of attempts, the Retry-After
+// response header takes precedence over the exponential backoff
+func (p RetryPolicy) delay(attempt int, resp *resty.Response) time.Duration {
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultRetryMaxBackoff
+	}
+
+	if retryAfter, ok := parseRetryAfter(resp, time.Now()); ok {
+		if retryAfter > maxBackoff {
+			return maxBackoff
+		}
+		return retryAfter
+	}
+
+	backoff := p.InitialBackoff
+	if backoff <= 0 {
+		backoff = DefaultRetryInitialBackoff
+	}
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if backoff >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	if backoff > maxBackoff {
+		return maxBackoff
+	}
+	return backoff
+}
+
+// parseRetryAfter reads the Retry-After response header, either delay seconds or an http date
+func parseRetryAfter(resp *resty.Response, now time.Time) (time.Duration, bool) {
+	if resp == nil || resp.RawResponse == nil {
+		return 0, false
+	}
+	value := resp.Header().Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if date.Before(now) {
+			return 0, true
+		}
+		return date.Sub(now), true
+	}
+	return 0, false
+}
+
+// isTransientError checks for connection errors that are worth retrying
+func isTransientError(err error) bool {
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,175 @@
+package executors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/resty.v1"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+)
+
+func TestExecuteTestCaseRetriesTransientStatus(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"Data":{}}`))
+	}))
+	defer server.Close()
+
+	executor := NewRetryingExecutor(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+	resp, metrics, err := executor.ExecuteTestCase(newRetryTestRequest(resty.MethodGet, server.URL), &model.TestCase{ID: "#t1"}, &model.Context{})
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, 2, metrics.Attempts)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestExecuteTestCaseStopsAfterMaxAttempts(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	executor := NewRetryingExecutor(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+	resp, metrics, err := executor.ExecuteTestCase(newRetryTestRequest(resty.MethodGet, server.URL), &model.TestCase{ID: "#t1"}, &model.Context{})
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode())
+	assert.Equal(t, 3, metrics.Attempts)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+}
+
+func TestExecuteTestCaseRetriesPOSTOnlyWhenEnabled(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1)%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	executor := NewRetryingExecutor(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+	resp, metrics, err := executor.ExecuteTestCase(newRetryTestRequest(resty.MethodPost, server.URL), &model.TestCase{ID: "#t1"}, &model.Context{})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode())
+	assert.Equal(t, 1, metrics.Attempts)
+
+	atomic.StoreInt32(&requests, 0)
+	executor = NewRetryingExecutor(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, RetryPOST: true})
+	resp, metrics, err = executor.ExecuteTestCase(newRetryTestRequest(resty.MethodPost, server.URL), &model.TestCase{ID: "#t2"}, &model.Context{})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode())
+	assert.Equal(t, 2, metrics.Attempts)
+}
+
+func TestExecuteTestCaseZeroRetryPolicySendsOnce(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, metrics, err := NewExecutor().ExecuteTestCase(newRetryTestRequest(resty.MethodGet, server.URL), &model.TestCase{ID: "#t1"}, &model.Context{})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, metrics.Attempts)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestExecuteTestCaseStopsRetryingWhenRunStops(t *testing.T) {
+	var requests int32
+	stop := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			close(stop)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	executor := &Executor{RetryPolicy: RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour}, Stop: stop}
+	started := time.Now()
+	resp, metrics, err := executor.ExecuteTestCase(newRetryTestRequest(resty.MethodGet, server.URL), &model.TestCase{ID: "#t1"}, &model.Context{})
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode())
+	assert.Equal(t, 1, metrics.Attempts)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	assert.True(t, time.Since(started) < time.Minute, "the backoff is not waited out once the run stops")
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	assert.Equal(t, time.Second, policy.delay(1, nil))
+	assert.Equal(t, 2*time.Second, policy.delay(2, nil))
+	assert.Equal(t, 4*time.Second, policy.delay(3, nil))
+	assert.Equal(t, 5*time.Second, policy.delay(4, nil))
+	assert.Equal(t, 5*time.Second, policy.delay(40, nil))
+
+	assert.Equal(t, 3*time.Second, policy.delay(1, newRetryAfterResponse("3")))
+	assert.Equal(t, 5*time.Second, policy.delay(1, newRetryAfterResponse("120")))
+	assert.Equal(t, DefaultRetryInitialBackoff, RetryPolicy{}.delay(1, nil))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	delay, ok := parseRetryAfter(newRetryAfterResponse("7"), now)
+	assert.True(t, ok)
+	assert.Equal(t, 7*time.Second, delay)
+
+	delay, ok = parseRetryAfter(newRetryAfterResponse("Sat, 01 Jun 2019 12:00:30 GMT"), now)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, delay)
+
+	_, ok = parseRetryAfter(newRetryAfterResponse("soon"), now)
+	assert.False(t, ok)
+	_, ok = parseRetryAfter(newRetryAfterResponse(""), now)
+	assert.False(t, ok)
+}
+
+func TestRetryPolicyIsRetryable(t *testing.T) {
+	policy := RetryPolicy{}
+	assert.True(t, policy.isRetryable(newStatusResponse(http.StatusServiceUnavailable), nil))
+	assert.False(t, policy.isRetryable(newStatusResponse(http.StatusInternalServerError), nil))
+
+	policy = RetryPolicy{RetryableStatusCodes: []int{http.StatusInternalServerError}}
+	assert.True(t, policy.isRetryable(newStatusResponse(http.StatusInternalServerError), nil))
+	assert.False(t, policy.isRetryable(newStatusResponse(http.StatusServiceUnavailable), nil))
+}
+
+func newRetryTestRequest(method, url string) *resty.Request {
+	r := resty.R()
+	r.Method = method
+	r.URL = url
+	return r
+}
+
+func newRetryAfterResponse(value string) *resty.Response {
+	header := http.Header{}
+	if value != "" {
+		header.Set("Retry-After", value)
+	}
+	return &resty.Response{RawResponse: &http.Response{StatusCode: http.StatusTooManyRequests, Header: header}}
+}
+
+func newStatusResponse(statusCode int) *resty.Response {
+	return &resty.Response{RawResponse: &http.Response{StatusCode: statusCode, Header: http.Header{}}}
+}
@@ -0,0 +1,166 @@
+package executors
+
+import (
+	"encoding/json"
+	"regexp"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
+	"github.com/OpenBankingUK/conformance-suite/pkg/generation"
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+)
+
+// contextFieldRegex matches the '$name' replacement fields a test case reads from the context
+var contextFieldRegex = regexp.MustCompile(`\$([\w\-]+)`)
+
+// contextWrites returns the context variables a test case puts into the context, its own context
+// which is applied before it runs and the variables it keeps on success (keepContextOnSuccess)
+func contextWrites(tc model.TestCase) map[string]bool {
+	writes := map[string]bool{}
+	for name := range tc.Context {
+		writes[name] = true
+	}
+	for _, match := range tc.Expect.ContextPut.Matches {
+		if match.ContextName != "" {
+			writes[match.ContextName] = true
+		}
+	}
+	return writes
+}
+
+// contextReads returns the context variables referenced as replacement fields in a test case
+// input, context and expects, variables in its own context are excluded as they are always
+// applied before the test case runs
+func contextReads(tc model.TestCase) map[string]bool {
+	reads := map[string]bool{}
+	for _, value := range []interface{}{tc.Input, tc.Context, tc.Expect, tc.ExpectOneOf} {
+		j, err := json.Marshal(value)
+		if err != nil {
+			continue
+		}
+		for _, field := range contextFieldRegex.FindAllStringSubmatch(string(j), -1) {
+			if _, ok := tc.Context[field[1]]; !ok {
+				reads[field[1]] = true
+			}
+		}
+	}
+	return reads
+}
+
+// testCaseDependencies returns for each test case the indexes of the earlier test cases it must wait for,
+// a test case waits for earlier test cases it depends on, earlier test cases that write a context variable
+// it reads, and earlier test cases that read a context variable it writes, so every test case sees the
+// same context values it would see running one at a time in the order they were defined
+func testCaseDependencies(testCases []model.TestCase) [][]int {
+	reads := make([]map[string]bool, len(testCases))
+	writes := make([]map[string]bool, len(testCases))
+	for i, tc := range testCases {
+		reads[i] = contextReads(tc)
+		writes[i] = contextWrites(tc)
+	}
+
+	dependencies := make([][]int, len(testCases))
+	for i := range testCases {
+		dependencies[i] = []int{}
+		for j := 0; j < i; j++ {
+			if dependsOn(testCases[i], testCases[j].ID) || intersects(writes[j], reads[i]) || intersects(reads[j], writes[i]) {
+				dependencies[i] = append(dependencies[i], j)
+			}
+		}
+	}
+	return dependencies
+}
+
+func dependsOn(tc model.TestCase, id string) bool {
+	for _, dependency := range tc.DependsOn {
+		if dependency == id {
+			return true
+		}
+	}
+	return false
+}
+
+func intersects(left, right map[string]bool) bool {
+	for k := range left {
+		if right[k] {
+			return true
+		}
+	}
+	return false
+}
+
+// executeSpecTestsConcurrently runs the test cases of a specification on a pool of workers.
+// Each test case runs against its own copy of the rule context, the variables it writes are copied
+// back unless a test case defined after it has already written them.
+// Results are added to the daemon controller in test case order so reports stay deterministic.
+func (r *TestCaseRunner) executeSpecTestsConcurrently(spec generation.SpecificationTestCases, ruleCtx *model.Context, ctxLogger *logrus.Entry, workers int) {
+	testCases := spec.TestCases
+	dependencies := testCaseDependencies(testCases)
+	testResults := make([]*results.TestCase, len(testCases))
+	done := make([]chan struct{}, len(testCases))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+
+	ctxLock := &sync.Mutex{}
+	writtenBy := map[string]int{}
+	failed := map[string]bool{}
+	pool := make(chan struct{}, workers)
+	for i := range testCases {
+		go func(i int) {
+			defer close(done[i])
+			for _, dependency := range dependencies[i] {
+				<-done[dependency]
+			}
+
+			pool <- struct{}{}
+			defer func() { <-pool }()
+
+			if r.daemonController.ShouldStop() {
+				return
+			}
+
+			testcase := testCases[i]
+			ctxLock.Lock()
+			testCtx := r.makeRuleCtx(ruleCtx)
+			upstreamFailed := map[string]bool{}
+			for _, id := range testcase.DependsOn {
+				upstreamFailed[id] = failed[id]
+			}
+			ctxLock.Unlock()
+
+			testResult := r.executeTestUnlessUpstreamFailed(testcase, testCtx, ctxLogger.WithField("ID", testcase.ID), upstreamFailed)
+			testResults[i] = &testResult
+
+			ctxLock.Lock()
+			defer ctxLock.Unlock()
+			if !testResult.Pass {
+				failed[testcase.ID] = true
+			}
+			for name := range contextWrites(testcase) {
+				if last, ok := writtenBy[name]; ok && last > i {
+					continue
+				}
+				if value, ok := testCtx.Get(name); ok {
+					ruleCtx.Put(name, value)
+					writtenBy[name] = i
+				}
+			}
+		}(i)
+	}
+
+	stopped := false
+	for i := range testCases {
+		<-done[i]
+		if testResults[i] == nil {
+			if !stopped {
+				ctxLogger.Info("stop test run received, aborting runner")
+				stopped = true
+			}
+			continue
+		}
+		r.daemonController.AddResult(*testResults[i])
+	}
+}
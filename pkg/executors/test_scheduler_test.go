@@ -0,0 +1,113 @@
+package executors
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/generation"
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+)
+
+func TestTestCaseDependencies(t *testing.T) {
+	testCases := []model.TestCase{
+		newSchedulerTestCase("#t1", "/accounts", "accountId"),
+		newSchedulerTestCase("#t2", "/balances", ""),
+		newSchedulerTestCase("#t3", "/accounts/$accountId", ""),
+		newSchedulerTestCase("#t4", "/accounts", "accountId"),
+		newSchedulerTestCase("#t5", "/accounts/$accountId/balances", ""),
+	}
+
+	dependencies := testCaseDependencies(testCases)
+
+	assert.Equal(t, [][]int{{}, {}, {0}, {2}, {0, 3}}, dependencies)
+}
+
+func TestTestCaseDependenciesFromTestCaseContext(t *testing.T) {
+	producer := newSchedulerTestCase("#t1", "/domestic-payment-consents", "")
+	producer.Context = model.Context{"baseurl": "http://localhost", "OB-301-DOP-100300-instructionIdentification": "123"}
+	other := newSchedulerTestCase("#t2", "/domestic-payment-consents", "")
+	other.Context = model.Context{"baseurl": "http://localhost"}
+	consumer := newSchedulerTestCase("#t3", "/domestic-payments", "")
+	consumer.Context = model.Context{"baseurl": "http://localhost", "instructionIdentification": "$OB-301-DOP-100300-instructionIdentification"}
+
+	dependencies := testCaseDependencies([]model.TestCase{producer, other, consumer})
+
+	assert.Equal(t, [][]int{{}, {}, {0}}, dependencies)
+}
+
+func TestExecuteSpecTestsConcurrentlyKeepsContextOrderAndResultOrder(t *testing.T) {
+	lock := &sync.Mutex{}
+	requested := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		requested = append(requested, r.URL.Path)
+		lock.Unlock()
+		if r.URL.Path == "/accounts" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		fmt.Fprint(w, `{"Data":{"AccountId":"500000000000000000000001"}}`)
+	}))
+	defer server.Close()
+
+	spec := generation.SpecificationTestCases{
+		TestCases: []model.TestCase{
+			newSchedulerTestCase("#t1", server.URL+"/accounts", "accountId"),
+			newSchedulerTestCase("#t2", server.URL+"/accounts/$accountId", ""),
+			newSchedulerTestCase("#t3", server.URL+"/balances", ""),
+			newSchedulerTestCase("#t4", server.URL+"/products", ""),
+		},
+	}
+	controller := NewBufferedDaemonController()
+	runner := NewTestCaseRunner(test.NullLogger(), RunDefinition{Workers: 3}, controller)
+	ruleCtx := &model.Context{}
+
+	runner.executeSpecTests(spec, ruleCtx, test.NullLogger())
+
+	testResults := controller.AllResults()
+	require.Len(t, testResults, 4)
+	for i, id := range []string{"#t1", "#t2", "#t3", "#t4"} {
+		assert.Equal(t, id, testResults[i].Id)
+		assert.True(t, testResults[i].Pass, testResults[i].Fail)
+	}
+
+	require.Len(t, requested, 4)
+	assert.Equal(t, "/accounts/500000000000000000000001", requested[3])
+	accountID, err := ruleCtx.GetString("accountId")
+	require.NoError(t, err)
+	assert.Equal(t, "500000000000000000000001", accountID)
+}
+
+func TestExecuteSpecTestsConcurrentlyStops(t *testing.T) {
+	spec := generation.SpecificationTestCases{
+		TestCases: []model.TestCase{
+			newSchedulerTestCase("#t1", "http://localhost/accounts", ""),
+		},
+	}
+	controller := NewBufferedDaemonController()
+	controller.Stop()
+	runner := NewTestCaseRunner(test.NullLogger(), RunDefinition{Workers: 2}, controller)
+
+	runner.executeSpecTests(spec, &model.Context{}, test.NullLogger())
+
+	assert.Empty(t, controller.AllResults())
+}
+
+func newSchedulerTestCase(id, endpoint, contextName string) model.TestCase {
+	tc := model.TestCase{
+		ID:     id,
+		Input:  model.Input{Method: "GET", Endpoint: endpoint},
+		Expect: model.Expect{StatusCode: http.StatusOK},
+	}
+	if contextName != "" {
+		tc.Expect.ContextPut.Matches = []model.Match{{ContextName: contextName, JSON: "Data.AccountId"}}
+	}
+	return tc
+}
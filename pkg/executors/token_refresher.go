@@ -0,0 +1,211 @@
+package executors
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+	resty "gopkg.in/resty.v1"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+)
+
+const (
+	// RefreshReasonExpired - the access token was refreshed before a request as it had expired
+	RefreshReasonExpired = "expired"
+	// RefreshReasonInvalidToken - the access token was refreshed after a request failed with 401 `invalid_token`
+	RefreshReasonInvalidToken = "invalid_token"
+
+	grantTypeRefreshToken = "refresh_token"
+	// tokenExpirySkew - tokens are refreshed this long before they expire so they do not expire in flight
+	tokenExpirySkew = 30 * time.Second
+)
+
+// TokenRefresher - keeps the refresh tokens of the access tokens collected for a run and refreshes
+// the access tokens when they expire. The token endpoint is called without the lock held, so only the
+// test cases sending a token being refreshed wait for it.
+type TokenRefresher struct {
+	lock   *sync.Mutex
+	tokens map[string]*refreshableToken // by access token, a refreshed access token maps to its replacement
+	now    func() time.Time
+}
+
+type refreshableToken struct {
+	name         string
+	accessToken  string
+	refreshToken string
+	expiresAt    time.Time    // zero when the token endpoint did not return `expires_in`
+	refreshing   *refreshCall // the refresh in flight, nil when the token is not being refreshed
+}
+
+// refreshCall is a refresh of a token in flight, `done` is closed once `result` is set
+type refreshCall struct {
+	done   chan struct{}
+	result refreshedAccessToken
+}
+
+// NewTokenRefresher -
+func NewTokenRefresher() *TokenRefresher {
+	return &TokenRefresher{
+		lock:   &sync.Mutex{},
+		tokens: map[string]*refreshableToken{},
+		now:    time.Now,
+	}
+}
+
+// add keeps the refresh token of an access token, access tokens without a refresh token cannot be refreshed
+func (r *TokenRefresher) add(tokenName string, token *grantToken) {
+	if r == nil || token == nil || token.AccessToken == "" || token.RefreshToken == "" {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.tokens[token.AccessToken] = &refreshableToken{
+		name:         tokenName,
+		accessToken:  token.AccessToken,
+		refreshToken: token.RefreshToken,
+		expiresAt:    r.expiresAt(token),
+	}
+}
+
+// refreshedAccessToken is the result of refreshing the access token of a request
+type refreshedAccessToken struct {
+	tokenName   string
+	accessToken string
+	refreshed   bool  // whether a refresh was attempted
+	err         error // why the attempted refresh failed
+}
+
+// current returns the access token to send instead of `accessToken`: its latest replacement, refreshed
+// first when it has expired
+func (r *TokenRefresher) current(accessToken string, ctx *model.Context) refreshedAccessToken {
+	r.lock.Lock()
+
+	token, ok := r.tokens[accessToken]
+	if !ok {
+		r.lock.Unlock()
+		return refreshedAccessToken{accessToken: accessToken}
+	}
+	if token.expiresAt.IsZero() || r.now().Before(token.expiresAt.Add(-tokenExpirySkew)) {
+		r.lock.Unlock()
+		return refreshedAccessToken{tokenName: token.name, accessToken: token.accessToken}
+	}
+	return r.refresh(token, ctx)
+}
+
+// refreshInvalid refreshes `accessToken` after it was rejected, unless it has been replaced since
+// the request was sent
+func (r *TokenRefresher) refreshInvalid(accessToken string, ctx *model.Context) refreshedAccessToken {
+	r.lock.Lock()
+
+	token, ok := r.tokens[accessToken]
+	if !ok {
+		r.lock.Unlock()
+		return refreshedAccessToken{accessToken: accessToken}
+	}
+	if token.accessToken != accessToken {
+		r.lock.Unlock()
+		return refreshedAccessToken{tokenName: token.name, accessToken: token.accessToken}
+	}
+	return r.refresh(token, ctx)
+}
+
+// refresh exchanges the refresh token of `token` for a new access token, or waits for the refresh in flight
+// and returns its access token without reporting a refresh. The lock must be held, refresh releases it
+// before calling the token endpoint.
+func (r *TokenRefresher) refresh(token *refreshableToken, ctx *model.Context) refreshedAccessToken {
+	if call := token.refreshing; call != nil {
+		r.lock.Unlock()
+		<-call.done
+		return refreshedAccessToken{tokenName: call.result.tokenName, accessToken: call.result.accessToken}
+	}
+	call := &refreshCall{done: make(chan struct{})}
+	token.refreshing = call
+	refreshToken := token.refreshToken
+	r.lock.Unlock()
+
+	refreshed, err := refreshAccessToken(refreshToken, ctx)
+
+	r.lock.Lock()
+	result := refreshedAccessToken{tokenName: token.name, accessToken: token.accessToken, refreshed: true, err: err}
+	if err == nil {
+		token.accessToken = refreshed.AccessToken
+		if refreshed.RefreshToken != "" {
+			token.refreshToken = refreshed.RefreshToken
+		}
+		token.expiresAt = r.expiresAt(refreshed)
+		r.tokens[token.accessToken] = token
+		result.accessToken = token.accessToken
+	}
+	token.refreshing = nil
+	r.lock.Unlock()
+
+	call.result = result
+	close(call.done)
+	return result
+}
+
+func (r *TokenRefresher) expiresAt(token *grantToken) time.Time {
+	if token.Expires <= 0 {
+		return time.Time{}
+	}
+	return r.now().Add(time.Duration(token.Expires) * time.Second)
+}
+
+// refreshAccessToken calls the token endpoint with the `refresh_token` grant
+func refreshAccessToken(refreshToken string, ctx *model.Context) (*grantToken, error) {
+	tokenEndpoint, err := ctx.GetString("token_endpoint")
+	if err != nil {
+		return nil, errors.Wrap(err, "executors.refreshAccessToken: cannot get token_endpoint for refresh")
+	}
+	request, err := tokenRequest(ctx, map[string]string{
+		authentication.GrantType: grantTypeRefreshToken,
+		grantTypeRefreshToken:    refreshToken,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "executors.refreshAccessToken")
+	}
+	resp, err := request.Post(tokenEndpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, "executors.refreshAccessToken")
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("executors.refreshAccessToken: bad status code %d from token endpoint %q", resp.StatusCode(), tokenEndpoint)
+	}
+
+	token := &grantToken{}
+	if err := json.Unmarshal(resp.Body(), token); err != nil {
+		return nil, errors.Wrap(err, "executors.refreshAccessToken")
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("executors.refreshAccessToken: no access_token in token endpoint response")
+	}
+	return token, nil
+}
+
+// bearerToken returns the access token a request is authorised with, empty when it is not a bearer token
+func bearerToken(r *resty.Request) string {
+	authorization := r.Header.Get("Authorization")
+	if len(authorization) < len("Bearer ") || !strings.EqualFold(authorization[:len("Bearer ")], "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(authorization[len("Bearer "):])
+}
+
+// isInvalidToken - whether a resource server rejected the access token of a request,
+// https://tools.ietf.org/html/rfc6750#section-3.1
+func isInvalidToken(resp *resty.Response) bool {
+	if resp == nil || resp.StatusCode() != http.StatusUnauthorized {
+		return false
+	}
+	if strings.Contains(resp.Header().Get("WWW-Authenticate"), RefreshReasonInvalidToken) {
+		return true
+	}
+	return gjson.GetBytes(resp.Body(), "error").String() == RefreshReasonInvalidToken
+}
@@ -0,0 +1,181 @@
+package executors
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/resty.v1"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/events"
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+)
+
+// refreshTestServer - a token endpoint issuing `token-<n>` for each refresh and a resource accepting the latest token
+type refreshTestServer struct {
+	*httptest.Server
+	lock      sync.Mutex
+	valid     string
+	refreshes int
+}
+
+func newRefreshTestServer() *refreshTestServer {
+	s := &refreshTestServer{valid: "token-0"}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		if r.URL.Path == "/token" {
+			if r.FormValue("grant_type") != "refresh_token" || r.FormValue("refresh_token") != fmt.Sprintf("refresh-%d", s.refreshes) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			s.refreshes++
+			s.valid = fmt.Sprintf("token-%d", s.refreshes)
+			fmt.Fprintf(w, `{"access_token":%q,"refresh_token":"refresh-%d","expires_in":300}`, s.valid, s.refreshes)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer "+s.valid {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"Data":{}}`))
+	}))
+	return s
+}
+
+func refreshTestContext(tokenEndpoint string) *model.Context {
+	return &model.Context{
+		"token_endpoint_auth_method": authentication.ClientSecretBasic,
+		"client_id":                  "client-id",
+		"client_secret":              "client-secret",
+		"token_endpoint":             tokenEndpoint,
+	}
+}
+
+func newBearerRequest(url, token string) *resty.Request {
+	r := newRetryTestRequest(resty.MethodGet, url)
+	r.SetHeader("Authorization", "Bearer "+token)
+	return r
+}
+
+func TestExecuteTestCaseRefreshesInvalidToken(t *testing.T) {
+	server := newRefreshTestServer()
+	defer server.Close()
+	ctx := refreshTestContext(server.URL + "/token")
+	refresher := NewTokenRefresher()
+	refresher.add("account01", &grantToken{AccessToken: "token-0", RefreshToken: "refresh-0"})
+	runEvents := events.NewEvents()
+	executor := &Executor{Refresher: refresher, Events: runEvents}
+
+	server.valid = "revoked"
+	resp, metrics, err := executor.ExecuteTestCase(newBearerRequest(server.URL+"/accounts", "token-0"), &model.TestCase{ID: "#t1"}, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, 2, metrics.Attempts)
+	assert.Equal(t, []events.RefreshedAccessToken{{TokenName: "account01", Reason: RefreshReasonInvalidToken, TestCase: "#t1"}},
+		runEvents.AllRefreshedAccessTokens())
+
+	// test cases prepared with the old token send the refreshed one
+	resp, _, err = executor.ExecuteTestCase(newBearerRequest(server.URL+"/accounts", "token-0"), &model.TestCase{ID: "#t2"}, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, 1, server.refreshes)
+}
+
+func TestExecuteTestCaseRefreshesExpiredToken(t *testing.T) {
+	server := newRefreshTestServer()
+	defer server.Close()
+	ctx := refreshTestContext(server.URL + "/token")
+	refresher := NewTokenRefresher()
+	refresher.add("account01", &grantToken{AccessToken: "token-0", RefreshToken: "refresh-0", Expires: 60})
+	refresher.now = func() time.Time { return time.Now().Add(time.Hour) }
+	runEvents := events.NewEvents()
+	executor := &Executor{Refresher: refresher, Events: runEvents}
+
+	resp, metrics, err := executor.ExecuteTestCase(newBearerRequest(server.URL+"/accounts", "token-0"), &model.TestCase{ID: "#t1"}, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, 1, metrics.Attempts)
+	assert.Equal(t, 1, server.refreshes)
+	assert.Equal(t, RefreshReasonExpired, runEvents.AllRefreshedAccessTokens()[0].Reason)
+}
+
+func TestExecuteTestCaseDoesNotRefreshUnknownTokens(t *testing.T) {
+	server := newRefreshTestServer()
+	defer server.Close()
+	runEvents := events.NewEvents()
+	executor := &Executor{Refresher: NewTokenRefresher(), Events: runEvents}
+
+	resp, _, err := executor.ExecuteTestCase(newBearerRequest(server.URL+"/accounts", "invalid"), &model.TestCase{ID: "#t1"}, refreshTestContext(server.URL+"/token"))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+	assert.Equal(t, 0, server.refreshes)
+	assert.Empty(t, runEvents.AllRefreshedAccessTokens())
+}
+
+func TestExecuteTestCaseRecordsFailedRefresh(t *testing.T) {
+	server := newRefreshTestServer()
+	defer server.Close()
+	refresher := NewTokenRefresher()
+	refresher.add("account01", &grantToken{AccessToken: "token-0", RefreshToken: "unknown"})
+	runEvents := events.NewEvents()
+	executor := &Executor{Refresher: refresher, Events: runEvents}
+
+	server.valid = "revoked"
+	resp, _, err := executor.ExecuteTestCase(newBearerRequest(server.URL+"/accounts", "token-0"), &model.TestCase{ID: "#t1"}, refreshTestContext(server.URL+"/token"))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+	refreshed := runEvents.AllRefreshedAccessTokens()
+	require.Len(t, refreshed, 1)
+	assert.Contains(t, refreshed[0].Error, "bad status code 400")
+}
+
+func TestTokenRefresherRefreshesOutsideTheLock(t *testing.T) {
+	release := make(chan struct{})
+	requested := make(chan struct{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested <- struct{}{}
+		<-release
+		fmt.Fprint(w, `{"access_token":"token-1","refresh_token":"refresh-1","expires_in":300}`)
+	}))
+	defer server.Close()
+	ctx := refreshTestContext(server.URL)
+	refresher := NewTokenRefresher()
+	refresher.add("account01", &grantToken{AccessToken: "token-0", RefreshToken: "refresh-0", Expires: 60})
+	refresher.add("account02", &grantToken{AccessToken: "other-0", RefreshToken: "other-refresh-0", Expires: 7200})
+	refresher.now = func() time.Time { return time.Now().Add(time.Hour) }
+
+	results := make(chan refreshedAccessToken, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			results <- refresher.current("token-0", ctx)
+		}()
+	}
+	<-requested
+
+	// a token that has not expired is not held up by the refresh in flight
+	other := refresher.current("other-0", ctx)
+	assert.Equal(t, "other-0", other.accessToken)
+	assert.False(t, other.refreshed)
+
+	close(release)
+	refreshed := 0
+	for i := 0; i < 3; i++ {
+		result := <-results
+		assert.Equal(t, "token-1", result.accessToken)
+		assert.NoError(t, result.err)
+		if result.refreshed {
+			refreshed++
+		}
+	}
+	// the test cases sending the token while it was refreshed waited for that refresh
+	assert.Equal(t, 1, refreshed)
+	assert.Len(t, requested, 0)
+}
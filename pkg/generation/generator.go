@@ -2,6 +2,7 @@ package generation
 
 import (
 	"github.com/OpenBankingUK/conformance-suite/pkg/schema"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
@@ -15,6 +16,8 @@ import (
 type SpecificationTestCases struct {
 	Specification discovery.ModelAPISpecification `json:"apiSpecification"`
 	TestCases     []model.TestCase                `json:"testCases"`
+	// UnsatisfiedReferences - context variables the manifest scripts reference that no earlier script puts
+	UnsatisfiedReferences []manifest.UnsatisfiedReference `json:"unsatisfiedReferences,omitempty"`
 }
 
 // GeneratorConfig -
@@ -26,12 +29,13 @@ type GeneratorConfig struct {
 	AuthorizationEndpoint string
 	RedirectURL           string
 	ResourceIDs           model.ResourceIDs
+	ConsentResolver       permissions.ResolverMode // how account test cases are grouped into consents, greedy when empty
 }
 
 // Generator - generates test cases from discovery model
 type Generator interface {
 	GenerateManifestTests(log *logrus.Entry, config GeneratorConfig, discovery discovery.ModelDiscovery,
-		ctx *model.Context, conditional []discovery.ConditionalAPIProperties) (SpecRun, manifest.Scripts, map[string][]manifest.RequiredTokens)
+		ctx *model.Context, conditional []discovery.ConditionalAPIProperties) (SpecRun, manifest.Scripts, map[string][]manifest.RequiredTokens, error)
 }
 
 // NewGenerator - returns implementation of Generator interface
@@ -70,8 +74,11 @@ func shouldIgnoreDiscoveryItem(apiSpecification discovery.ModelAPISpecification)
 }
 
 // Work in progress to integrate Manifest Test
+// Returns an error when the manifest scripts of a specification depend on each other in a cycle, or when the heuristic
+// or exact consent resolver finds test cases of a specification no consent satisfies, a specification whose test cases
+// or consents cannot be otherwise retrieved is skipped
 func (g generator) GenerateManifestTests(log *logrus.Entry, config GeneratorConfig, discovery discovery.ModelDiscovery,
-	ctx *model.Context, conditionalProperties []discovery.ConditionalAPIProperties) (SpecRun, manifest.Scripts, map[string][]manifest.RequiredTokens) {
+	ctx *model.Context, conditionalProperties []discovery.ConditionalAPIProperties) (SpecRun, manifest.Scripts, map[string][]manifest.RequiredTokens, error) {
 	log = log.WithField("module", "GenerateManifestTests")
 	for k, item := range discovery.DiscoveryItems {
 		spectype, err := manifest.GetSpecType(item.APISpecification.SchemaVersion)
@@ -111,16 +118,22 @@ func (g generator) GenerateManifestTests(log *logrus.Entry, config GeneratorConf
 			Validator:    validator,
 			Conditional:  conditionalProperties,
 		}
-		tcs, fsc, err := manifest.GenerateTestCases(&params)
+		tcs, fsc, unsatisfied, err := manifest.GenerateTestCases(&params)
 
 		filteredScripts = fsc
+		if errors.Is(err, manifest.ErrDependencyCycle) {
+			return SpecRun{}, manifest.Scripts{}, nil, errors.Wrapf(err, "test cases of %s", item.APISpecification.Name)
+		}
 		if err != nil {
-			log.Warnf("manifest testcase generation failed for %s", item.APISpecification.SchemaVersion)
+			log.WithError(err).Warnf("manifest testcase generation failed for %s", item.APISpecification.SchemaVersion)
 			continue
 		}
 
 		spectype := item.APISpecification.SpecType
-		requiredSpecTokens, err := manifest.GetRequiredTokensFromTests(tcs, spectype)
+		requiredSpecTokens, err := manifest.GetRequiredTokensFromTests(tcs, spectype, config.ConsentResolver)
+		if errors.Is(err, manifest.ErrUnsatisfiableTestCases) {
+			return SpecRun{}, manifest.Scripts{}, nil, errors.Wrapf(err, "consents required by %s", item.APISpecification.Name)
+		}
 		if err != nil {
 			log.Warnf("failed to retrieve required spec tokens from test for spec %s", spectype)
 			continue
@@ -137,7 +150,7 @@ func (g generator) GenerateManifestTests(log *logrus.Entry, config GeneratorConf
 			// three sets of test case. all, UI, consent (Non-ui)
 			tcs = getUITests(tcs)
 		}
-		stc := SpecificationTestCases{Specification: item.APISpecification, TestCases: tcs}
+		stc := SpecificationTestCases{Specification: item.APISpecification, TestCases: tcs, UnsatisfiedReferences: unsatisfied}
 		logrus.Debugf("%d test cases generated for %s", len(tcs), item.APISpecification.Name)
 		specTestCases = append(specTestCases, stc)
 		tokens[spectype] = requiredSpecTokens
@@ -149,7 +162,7 @@ func (g generator) GenerateManifestTests(log *logrus.Entry, config GeneratorConf
 	for _, v := range tokens {
 		logrus.Tracef("%#v", v)
 	}
-	return SpecRun{specTestCases, scrSlice}, filteredScripts, tokens
+	return SpecRun{specTestCases, scrSlice}, filteredScripts, tokens, nil
 }
 
 // taks all the payment testscases
@@ -182,6 +195,7 @@ func getSpecConsentsFromRequiredTokens(rt []manifest.RequiredTokens, apiName str
 		np.CodeSet = permissions.CodeSetResult{}
 		np.CodeSet.TestIds = append(np.CodeSet.TestIds, permissions.StringSliceToTestID(v.IDs)...)
 		np.CodeSet.CodeSet = append(np.CodeSet.CodeSet, permissions.StringSliceToCodeSet(v.Perms)...)
+		np.Diagnostic = v.Diagnostic
 		npa = append(npa, np)
 	}
 	specConsentReq := model.SpecConsentRequirements{Identifier: apiName, NamedPermissions: npa}
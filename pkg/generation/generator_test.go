@@ -5,9 +5,12 @@ import (
 	"regexp"
 	"testing"
 
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+	"github.com/OpenBankingUK/conformance-suite/pkg/manifest"
 	"github.com/OpenBankingUK/conformance-suite/pkg/model"
 	"github.com/OpenBankingUK/conformance-suite/pkg/permissions"
 	"github.com/OpenBankingUK/conformance-suite/pkg/test"
@@ -67,6 +70,23 @@ func TestPermissionsShouldPassAllTestsToResolver(t *testing.T) {
 	assert.Regexp(matchName, specTokens[0].NamedPermissions[1].Name)
 }
 
+func TestSpecConsentsFromRequiredTokensKeepTheirDiagnostic(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	diagnostic := &permissions.ConsentDiagnostic{CodeSet: permissions.CodeSet{"ReadAccountsBasic"}, OnlyFor: []permissions.TestId{"1"}}
+	requiredTokens := []manifest.RequiredTokens{
+		{Name: "account0", IDs: []string{"1"}, Perms: []string{"ReadAccountsBasic"}, Diagnostic: diagnostic},
+		{Name: "account1", IDs: []string{"2"}, Perms: []string{"ReadAccountsDetail"}},
+	}
+
+	specConsents, err := getSpecConsentsFromRequiredTokens(requiredTokens, "Account and Transaction API Specification")
+
+	assert.NoError(err)
+	assert.Len(specConsents.NamedPermissions, 2)
+	assert.Same(diagnostic, specConsents.NamedPermissions[0].Diagnostic)
+	assert.Nil(specConsents.NamedPermissions[1].Diagnostic)
+}
+
 func TestShouldIgnoreDiscoveryItem(t *testing.T) {
 	require := test.NewAssert(t)
 
@@ -92,3 +112,23 @@ func TestShouldIgnoreDiscoveryItem(t *testing.T) {
 		}))
 	}
 }
+
+func TestGenerateManifestTestsDependencyCycle(t *testing.T) {
+	require := test.NewRequire(t)
+
+	discoveryModel := testLoadDiscoveryModel(t)
+	var item discovery.ModelDiscoveryItem
+	for _, discoveryItem := range discoveryModel.DiscoveryItems {
+		if discoveryItem.APISpecification.Name == "Confirmation of Funds API Specification" {
+			item = discoveryItem
+		}
+	}
+	item.APISpecification.Manifest = "file://testdata/cycle-manifest.json"
+	discoveryModel.DiscoveryItems = []discovery.ModelDiscoveryItem{item}
+	ctx := &model.Context{"apiversions": []interface{}{"cbpii_v3.1.6"}}
+
+	_, _, _, err := NewGenerator().GenerateManifestTests(logrus.NewEntry(logrus.New()), GeneratorConfig{}, *discoveryModel, ctx, nil)
+
+	require.True(errors.Is(err, manifest.ErrDependencyCycle))
+	require.EqualError(err, "test cases of Confirmation of Funds API Specification: manifest scripts depend on each other in a cycle: OB-301-CBPII-900001 -> OB-301-CBPII-900002 -> OB-301-CBPII-900001")
+}
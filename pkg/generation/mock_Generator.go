@@ -14,7 +14,7 @@ type MockGenerator struct {
 }
 
 // GenerateManifestTests provides a mock function with given fields: log, config, _a2, ctx
-func (_m *MockGenerator) GenerateManifestTests(log *logrus.Entry, config GeneratorConfig, _a2 discovery.ModelDiscovery, ctx *model.Context, cond []discovery.ConditionalAPIProperties) (SpecRun, manifest.Scripts, map[string][]manifest.RequiredTokens) {
+func (_m *MockGenerator) GenerateManifestTests(log *logrus.Entry, config GeneratorConfig, _a2 discovery.ModelDiscovery, ctx *model.Context, cond []discovery.ConditionalAPIProperties) (SpecRun, manifest.Scripts, map[string][]manifest.RequiredTokens, error) {
 	ret := _m.Called(log, config, _a2, ctx)
 
 	var r0 SpecRun
@@ -40,5 +40,12 @@ func (_m *MockGenerator) GenerateManifestTests(log *logrus.Entry, config Generat
 		}
 	}
 
-	return r0, r1, r2
+	var r3 error
+	if rf, ok := ret.Get(3).(func(*logrus.Entry, GeneratorConfig, discovery.ModelDiscovery, *model.Context) error); ok {
+		r3 = rf(log, config, _a2, ctx)
+	} else {
+		r3 = ret.Error(3)
+	}
+
+	return r0, r1, r2, r3
 }
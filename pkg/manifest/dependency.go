@@ -0,0 +1,228 @@
+package manifest
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+)
+
+// replacementFieldRegex matches '$name' replacement fields in script values
+var replacementFieldRegex = regexp.MustCompile(`\$([\w\-]+)`)
+
+// runtimeContextVariables are put into the context while test cases run, not by scripts
+var runtimeContextVariables = map[string]bool{
+	"client_access_token": true,
+}
+
+// ErrDependencyCycle - the scripts of a manifest depend on each other in a cycle
+var ErrDependencyCycle = errors.New("manifest scripts depend on each other in a cycle")
+
+// DependencyGraph - explicit dependencies between the scripts of a manifest.
+// A script depends on the script that puts a context variable it references, either on
+// success (keepContextOnSuccess) or as one of its parameters.
+type DependencyGraph struct {
+	ids          []string
+	dependencies map[string][]string
+	onSuccess    map[string][]string
+	unsatisfied  []UnsatisfiedReference
+}
+
+// UnsatisfiedReference - a context variable referenced by a script that is not available when the script runs
+type UnsatisfiedReference struct {
+	ScriptID string `json:"scriptId"`
+	Variable string `json:"variable"`
+	Reason   string `json:"reason"`
+}
+
+func (u UnsatisfiedReference) String() string {
+	return fmt.Sprintf("%s references $%s: %s", u.ScriptID, u.Variable, u.Reason)
+}
+
+// NewDependencyGraph builds the dependency graph of scripts in manifest order. Variables found in
+// reference data or in the context are not dependencies. Returns an error if the scripts depend on
+// each other in a cycle.
+func NewDependencyGraph(scripts Scripts, refs References, ctx *model.Context) (DependencyGraph, error) {
+	graph := DependencyGraph{dependencies: map[string][]string{}, onSuccess: map[string][]string{}}
+
+	for i, script := range scripts.Scripts {
+		graph.ids = append(graph.ids, script.ID)
+		dependencies := []string{}
+		onSuccess := []string{}
+		for _, variable := range script.references() {
+			if _, ok := refs.References[variable]; ok || runtimeContextVariables[variable] {
+				continue
+			}
+			if ctx != nil {
+				if _, ok := ctx.Get(variable); ok {
+					continue
+				}
+			}
+
+			if producer, found := lastProducer(scripts.Scripts[:i], variable); found {
+				dependencies = appendUnique(dependencies, producer.ID)
+				if producer.ContextPut["name"] == variable {
+					onSuccess = appendUnique(onSuccess, producer.ID)
+				}
+				continue
+			}
+
+			if producer, found := firstProducer(scripts.Scripts[i+1:], variable); found {
+				dependencies = appendUnique(dependencies, producer.ID)
+				graph.unsatisfied = append(graph.unsatisfied, UnsatisfiedReference{
+					ScriptID: script.ID,
+					Variable: variable,
+					Reason:   "put by " + producer.ID + " which runs later",
+				})
+				continue
+			}
+
+			graph.unsatisfied = append(graph.unsatisfied, UnsatisfiedReference{
+				ScriptID: script.ID,
+				Variable: variable,
+				Reason:   "not put by any script",
+			})
+		}
+		graph.dependencies[script.ID] = dependencies
+		graph.onSuccess[script.ID] = onSuccess
+	}
+
+	if cycle := graph.findCycle(); len(cycle) > 0 {
+		return DependencyGraph{}, fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(cycle, " -> "))
+	}
+	return graph, nil
+}
+
+// DependsOn returns the IDs of the scripts a script depends on
+func (g DependencyGraph) DependsOn(id string) []string {
+	return g.dependencies[id]
+}
+
+// DependsOnSuccessOf returns the IDs of the scripts whose context kept on success a script references,
+// the script cannot run meaningfully when one of them fails
+func (g DependencyGraph) DependsOnSuccessOf(id string) []string {
+	return g.onSuccess[id]
+}
+
+// Unsatisfied returns the references no earlier script puts
+func (g DependencyGraph) Unsatisfied() []UnsatisfiedReference {
+	return g.unsatisfied
+}
+
+// findCycle returns the script IDs forming a cycle, empty if the graph is acyclic
+func (g DependencyGraph) findCycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[string]int{}
+	path := []string{}
+
+	var visit func(id string) []string
+	visit = func(id string) []string {
+		state[id] = visiting
+		path = append(path, id)
+		for _, dependency := range g.dependencies[id] {
+			switch state[dependency] {
+			case visiting:
+				for i, pathID := range path {
+					if pathID == dependency {
+						return append(append([]string{}, path[i:]...), dependency)
+					}
+				}
+			case unvisited:
+				if cycle := visit(dependency); len(cycle) > 0 {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = visited
+		return nil
+	}
+
+	for _, id := range g.ids {
+		if state[id] == unvisited {
+			if cycle := visit(id); len(cycle) > 0 {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// puts returns the context variables a script puts, its parameters and the variable kept on success
+func (s Script) puts() map[string]bool {
+	puts := map[string]bool{}
+	for k := range s.Parameters {
+		puts[k] = true
+	}
+	if name, exists := s.ContextPut["name"]; exists {
+		puts[name] = true
+	}
+	return puts
+}
+
+// references returns the context variables a script references which it does not put itself, sorted by name
+func (s Script) references() []string {
+	values := []string{s.URI, s.Body}
+	for _, v := range s.Parameters {
+		if !isFunction(v) {
+			values = append(values, v)
+		}
+	}
+	for _, v := range s.Headers {
+		values = append(values, v)
+	}
+	for _, v := range s.QueryParameters {
+		values = append(values, v)
+	}
+
+	puts := s.puts()
+	found := map[string]bool{}
+	for _, value := range values {
+		for _, field := range replacementFieldRegex.FindAllStringSubmatch(value, -1) {
+			if !puts[field[1]] {
+				found[field[1]] = true
+			}
+		}
+	}
+
+	references := []string{}
+	for variable := range found {
+		references = append(references, variable)
+	}
+	sort.Strings(references)
+	return references
+}
+
+func lastProducer(scripts []Script, variable string) (Script, bool) {
+	for i := len(scripts) - 1; i >= 0; i-- {
+		if scripts[i].puts()[variable] {
+			return scripts[i], true
+		}
+	}
+	return Script{}, false
+}
+
+func firstProducer(scripts []Script, variable string) (Script, bool) {
+	for _, script := range scripts {
+		if script.puts()[variable] {
+			return script, true
+		}
+	}
+	return Script{}, false
+}
+
+func appendUnique(ids []string, id string) []string {
+	for _, existing := range ids {
+		if existing == id {
+			return ids
+		}
+	}
+	return append(ids, id)
+}
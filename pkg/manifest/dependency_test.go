@@ -0,0 +1,108 @@
+package manifest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+)
+
+func TestNewDependencyGraph(t *testing.T) {
+	scripts := Scripts{Scripts: []Script{
+		{
+			ID:         "OB-301-DOP-100300",
+			URI:        "/domestic-payment-consents",
+			Parameters: map[string]string{"postData": "$minimalDomesticPaymentConsent", "instructedAmountValue": "$instructedAmountValue", "OB-301-DOP-100300-instructionIdentification": "$instructionIdentification", "instructionIdentification": "$fn:instructionIdentificationID()"},
+			ContextPut: map[string]string{"name": "OB-301-DOP-100300-ConsentId", "value": "Data.ConsentId"},
+		},
+		{
+			ID:         "OB-301-DOP-100400",
+			URI:        "/domestic-payment-consents/$consentId",
+			Parameters: map[string]string{"consentId": "$OB-301-DOP-100300-ConsentId"},
+		},
+		{
+			ID:         "OB-301-DOP-100600",
+			URI:        "/domestic-payments",
+			Parameters: map[string]string{"consentId": "$OB-301-DOP-100300-ConsentId", "instructionIdentification": "$OB-301-DOP-100300-instructionIdentification"},
+			ContextPut: map[string]string{"name": "OB-301-DOP-100600-DomesticPaymentId", "value": "Data.DomesticPaymentId"},
+		},
+		{
+			ID:      "OB-301-DOP-100700",
+			URI:     "/domestic-payments/$OB-301-DOP-100600-DomesticPaymentId",
+			Headers: map[string]string{"Authorization": "Bearer $client_access_token"},
+		},
+	}}
+	refs := References{References: map[string]Reference{"minimalDomesticPaymentConsent": {}}}
+	ctx := &model.Context{"instructedAmountValue": "1.00"}
+
+	graph, err := NewDependencyGraph(scripts, refs, ctx)
+
+	require.NoError(t, err)
+	assert.Empty(t, graph.DependsOn("OB-301-DOP-100300"))
+	assert.Equal(t, []string{"OB-301-DOP-100300"}, graph.DependsOn("OB-301-DOP-100400"))
+	assert.Equal(t, []string{"OB-301-DOP-100300"}, graph.DependsOnSuccessOf("OB-301-DOP-100400"))
+	assert.Equal(t, []string{"OB-301-DOP-100300"}, graph.DependsOn("OB-301-DOP-100600"))
+	assert.Equal(t, []string{"OB-301-DOP-100600"}, graph.DependsOnSuccessOf("OB-301-DOP-100700"))
+	assert.Empty(t, graph.Unsatisfied())
+}
+
+func TestNewDependencyGraphParameterDependencyDoesNotRequireSuccess(t *testing.T) {
+	scripts := Scripts{Scripts: []Script{
+		{ID: "OB-301-DIR-102504", URI: "/direct-debits", Parameters: map[string]string{"x-fapi-interaction-id": "f5a10e5e-0c46-41c5-a7b7-c8f560205200"}},
+		{ID: "OB-301-OFF-102700", URI: "/offers", Headers: map[string]string{"x-fapi-interaction-id": "$x-fapi-interaction-id"}},
+	}}
+
+	graph, err := NewDependencyGraph(scripts, References{}, &model.Context{})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"OB-301-DIR-102504"}, graph.DependsOn("OB-301-OFF-102700"))
+	assert.Empty(t, graph.DependsOnSuccessOf("OB-301-OFF-102700"))
+}
+
+func TestNewDependencyGraphUnsatisfied(t *testing.T) {
+	scripts := Scripts{Scripts: []Script{
+		{ID: "OB-301-DOP-101100", URI: "/domestic-scheduled-payments/$paymentID", Parameters: map[string]string{"paymentID": "$OB-301-DOP-101000-DomesticScheduledPaymentId"}},
+		{ID: "OB-301-DOP-101200", URI: "/domestic-standing-order-consents/$OB-301-DOP-101300-ConsentId"},
+		{ID: "OB-301-DOP-101300", URI: "/domestic-standing-order-consents", ContextPut: map[string]string{"name": "OB-301-DOP-101300-ConsentId", "value": "Data.ConsentId"}},
+	}}
+
+	graph, err := NewDependencyGraph(scripts, References{}, &model.Context{})
+
+	require.NoError(t, err)
+	assert.Equal(t, []UnsatisfiedReference{
+		{ScriptID: "OB-301-DOP-101100", Variable: "OB-301-DOP-101000-DomesticScheduledPaymentId", Reason: "not put by any script"},
+		{ScriptID: "OB-301-DOP-101200", Variable: "OB-301-DOP-101300-ConsentId", Reason: "put by OB-301-DOP-101300 which runs later"},
+	}, graph.Unsatisfied())
+	assert.Equal(t, "OB-301-DOP-101200 references $OB-301-DOP-101300-ConsentId: put by OB-301-DOP-101300 which runs later", graph.Unsatisfied()[1].String())
+}
+
+func TestNewDependencyGraphCycle(t *testing.T) {
+	scripts := Scripts{Scripts: []Script{
+		{ID: "A", URI: "/a/$b", ContextPut: map[string]string{"name": "a", "value": "Data.Id"}},
+		{ID: "B", URI: "/b/$a", ContextPut: map[string]string{"name": "b", "value": "Data.Id"}},
+	}}
+
+	_, err := NewDependencyGraph(scripts, References{}, nil)
+
+	assert.EqualError(t, err, "manifest scripts depend on each other in a cycle: A -> B -> A")
+}
+
+func TestNewDependencyGraphBundledManifests(t *testing.T) {
+	ctx := &model.Context{}
+	ctx.PutStringSlice("apiversions", []string{"accounts_v3.1.10", "payments_v3.1.10", "cbpii_v3.1.10", "vrps_v3.1.10"})
+
+	for specType, manifest := range map[string]string{
+		"accounts": "file://../../manifests/ob_3.1_accounts_transactions_fca.json",
+		"payments": "file://../../manifests/ob_3.1_payment_fca.json",
+		"cbpii":    "file://../../manifests/ob_3.1_cbpii_fca.json",
+		"vrps":     "file://../../manifests/ob_3.1_variable_recurring_payments.json",
+	} {
+		scripts, refs, err := LoadGenerationResources(specType, manifest, ctx)
+		require.NoError(t, err)
+
+		_, err = NewDependencyGraph(scripts, refs, ctx)
+		assert.NoError(t, err, specType)
+	}
+}
@@ -10,8 +10,12 @@ import (
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
 	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/permissions"
 )
 
+// ErrUnsatisfiableTestCases - the heuristic or exact consent resolver found test cases no consent satisfies
+var ErrUnsatisfiableTestCases = errors.New("no account consent satisfies test cases")
+
 // TestCasePermission -
 type TestCasePermission struct {
 	ID     string   `json:"id,omitempty"`
@@ -32,6 +36,8 @@ type RequiredTokens struct {
 	ConsentParam    string
 	ConsentProvider string
 	AccountID       string
+	// Diagnostic - why the consent is needed, set for the consents of the heuristic and exact resolvers
+	Diagnostic *permissions.ConsentDiagnostic `json:"diagnostic,omitempty"`
 }
 
 // TokenStore eats tokens
@@ -72,15 +78,17 @@ func GetSpecType(spec string) (string, error) {
 
 // GetRequiredTokensFromTests - Given a set of testcases with the permissions defined
 // in the context using 'permissions' and 'permissions-excluded'
-// provides a RequiredTokens structure which can be used to capture token requirements
-func GetRequiredTokensFromTests(tcs []model.TestCase, spec string) (rt []RequiredTokens, err error) {
+// provides a RequiredTokens structure which can be used to capture token requirements.
+// `mode` selects how account test cases are grouped into consents, the heuristic and exact
+// modes minimise the consents a PSU has to authorise
+func GetRequiredTokensFromTests(tcs []model.TestCase, spec string, mode permissions.ResolverMode) (rt []RequiredTokens, err error) {
 	switch spec {
 	case "accounts":
 		tcp, err := getTestCasePermissions(tcs)
 		if err != nil {
 			return nil, err
 		}
-		rt, err = getRequiredTokens(tcp)
+		rt, err = getRequiredTokens(tcp, mode)
 		if err != nil {
 			return nil, err
 		}
@@ -220,8 +228,11 @@ func getTestCasePermissions(tcs []model.TestCase) ([]TestCasePermission, error)
 	return tcps, nil
 }
 
-// GetRequiredTokens - gathers all tokens
-func getRequiredTokens(tcps []TestCasePermission) ([]RequiredTokens, error) {
+// GetRequiredTokens - gathers all tokens, greedy keeps the TokenStore grouping
+func getRequiredTokens(tcps []TestCasePermission, mode permissions.ResolverMode) ([]RequiredTokens, error) {
+	if mode == permissions.ResolverModeHeuristic || mode == permissions.ResolverModeExact {
+		return resolveRequiredTokens(tcps, mode)
+	}
 	te := TokenStore{}
 	for _, tcp := range tcps {
 		te.createOrUpdate(tcp)
@@ -229,6 +240,61 @@ func getRequiredTokens(tcps []TestCasePermission) ([]RequiredTokens, error) {
 	return te.store, nil
 }
 
+// resolveRequiredTokens - a token for each consent the resolver of `mode` finds, each test case is mapped to the
+// first consent satisfying it. Each token has the diagnostic of why its consent is needed.
+// Test cases excluding a permission they include are an error as no consent satisfies them.
+func resolveRequiredTokens(tcps []TestCasePermission, mode permissions.ResolverMode) ([]RequiredTokens, error) {
+	groups := make([]permissions.Group, 0, len(tcps))
+	for _, tcp := range tcps {
+		groups = append(groups, permissions.NewGroup(tcp.ID, permissions.StringSliceToCodeSet(tcp.Perms), permissions.StringSliceToCodeSet(tcp.Permsx)))
+	}
+	resultSet := permissions.NewResolver(mode)(groups)
+
+	diagnostics := permissions.Explain(groups, resultSet)
+	if len(diagnostics.Unsatisfied) > 0 {
+		return nil, fmt.Errorf("%w %v, they include a permission they exclude", ErrUnsatisfiableTestCases, diagnostics.Unsatisfied)
+	}
+
+	te := TokenStore{}
+	mapped := map[string]bool{}
+	for k, result := range resultSet {
+		ids := []string{}
+		permsx := []string{}
+		for _, testID := range result.TestIds {
+			if mapped[string(testID)] {
+				continue
+			}
+			mapped[string(testID)] = true
+			ids = append(ids, string(testID))
+			for _, tcp := range tcps {
+				if tcp.ID == string(testID) {
+					permsx = uniqueSlice(append(permsx, tcp.Permsx...))
+				}
+			}
+		}
+		if len(ids) == 0 { // test cases all satisfied by earlier consents
+			continue
+		}
+
+		consent := diagnostics.Consents[k]
+		rt := RequiredTokens{Name: te.GetNextTokenName("account"), IDs: ids, Perms: []string{}, Permsx: permsx, Diagnostic: &consent}
+		for _, code := range result.CodeSet {
+			rt.Perms = append(rt.Perms, string(code))
+		}
+		te.store = append(te.store, rt)
+
+		logrus.WithFields(logrus.Fields{
+			"token":     rt.Name,
+			"mode":      mode,
+			"codes":     consent.CodeSet,
+			"required":  fmt.Sprintf("%v", consent.Required),
+			"onlyFor":   consent.OnlyFor,
+			"conflicts": consent.Conflicts,
+		}).Info("account consent required")
+	}
+	return te.store, nil
+}
+
 // MapTokensToTestCases - applies consented tokens to testcases
 func MapTokensToTestCases(rt []RequiredTokens, tcs []model.TestCase) map[string]string {
 	ctxLogger := logrus.StandardLogger().WithFields(logrus.Fields{
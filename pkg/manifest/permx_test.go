@@ -1,6 +1,7 @@
 package manifest
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -12,6 +13,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/permissions"
 )
 
 const manifestPath = "file://manifests/ob_3.1_payment_fca.json"
@@ -40,14 +42,14 @@ func TestPermx(t *testing.T) {
 		ManifestPath: manifestPath,
 		Validator:    schema.NewNullValidator(),
 	}
-	tests, _, err := GenerateTestCases(&params)
+	tests, _, _, err := GenerateTestCases(&params)
 
 	assert.Nil(t, err)
 
 	testcasePermissions, err := getTestCasePermissions(tests)
 	assert.Nil(t, err)
 
-	_, err = getRequiredTokens(testcasePermissions)
+	_, err = getRequiredTokens(testcasePermissions, permissions.ResolverModeGreedy)
 	assert.Nil(t, err)
 }
 
@@ -73,13 +75,13 @@ func TestGetScriptConsentTokens(t *testing.T) {
 		ManifestPath: manifestPath,
 		Validator:    schema.NewNullValidator(),
 	}
-	tests, _, err := GenerateTestCases(&params)
+	tests, _, _, err := GenerateTestCases(&params)
 	assert.Nil(t, err)
 
 	testcasePermissions, err := getTestCasePermissions(tests)
 	assert.Nil(t, err)
 
-	requiredTokens, err := getRequiredTokens(testcasePermissions)
+	requiredTokens, err := getRequiredTokens(testcasePermissions, permissions.ResolverModeGreedy)
 	assert.Nil(t, err)
 
 	populateTokens(t, requiredTokens)
@@ -151,3 +153,71 @@ func TestCompareApiVersions(t *testing.T) {
 	fmt.Printf("compare %s,%s = %d\n", api1[0], api3[0], s1.Compare(s3))
 
 }
+
+func TestResolveRequiredTokens(t *testing.T) {
+	apiSpec := discovery.ModelAPISpecification{
+		SchemaVersion: accountSwaggerLocation31,
+	}
+
+	var values []interface{}
+	values = append(values, "accounts_v3.1.1", "payments_v3.1.1")
+	context := model.Context{"apiversions": values}
+
+	specType, err := GetSpecType(apiSpec.SchemaVersion)
+	scripts, _, err := LoadGenerationResources(specType, "file://manifests/ob_3.1_accounts_transactions_fca.json", &context)
+	assert.Nil(t, err)
+
+	params := GenerationParameters{
+		Scripts:      scripts,
+		Spec:         apiSpec,
+		Baseurl:      "http://mybaseurl",
+		Ctx:          &context,
+		Endpoints:    readDiscovery(),
+		ManifestPath: "file://manifests/ob_3.1_accounts_transactions_fca.json",
+		Validator:    schema.NewNullValidator(),
+	}
+	tests, _, _, err := GenerateTestCases(&params)
+	assert.Nil(t, err)
+
+	testcasePermissions, err := getTestCasePermissions(tests)
+	assert.Nil(t, err)
+
+	greedyTokens, err := getRequiredTokens(testcasePermissions, permissions.ResolverModeGreedy)
+	assert.Nil(t, err)
+
+	exactTokens, err := getRequiredTokens(testcasePermissions, permissions.ResolverModeExact)
+	assert.Nil(t, err)
+
+	t.Logf("%d greedy tokens, %d exact tokens", len(greedyTokens), len(exactTokens))
+	assert.NotEmpty(t, exactTokens)
+	assert.True(t, len(exactTokens) <= len(greedyTokens))
+	for _, tcp := range testcasePermissions {
+		tokenName, _, err := getRequiredTokenForTestcase(exactTokens, tcp.ID)
+		assert.Nil(t, err)
+		for _, token := range exactTokens {
+			if token.Name != tokenName {
+				continue
+			}
+			assert.NotNil(t, token.Diagnostic, token.Name)
+			assert.NotNil(t, token.Diagnostic, token.Name)
+			assert.Subset(t, token.Perms, tcp.Perms, tcp.ID)
+			for _, excluded := range tcp.Permsx {
+				assert.NotContains(t, token.Perms, excluded, tcp.ID)
+			}
+		}
+	}
+}
+
+func TestResolveRequiredTokensRejectsTestsExcludingWhatTheyInclude(t *testing.T) {
+	tcps := []TestCasePermission{
+		{ID: "t1", Perms: []string{"ReadAccountsBasic"}},
+		{ID: "t2", Perms: []string{"ReadAccountsDetail"}, Permsx: []string{"ReadAccountsDetail"}},
+	}
+
+	for _, mode := range []permissions.ResolverMode{permissions.ResolverModeHeuristic, permissions.ResolverModeExact} {
+		tokens, err := getRequiredTokens(tcps, mode)
+		assert.EqualError(t, err, "no account consent satisfies test cases [t2], they include a permission they exclude", string(mode))
+		assert.True(t, errors.Is(err, ErrUnsatisfiableTestCases), string(mode))
+		assert.Nil(t, tokens, string(mode))
+	}
+}
@@ -107,15 +107,16 @@ type GenerationParameters struct {
 	Conditional  []discovery.ConditionalAPIProperties
 }
 
-// GenerateTestCases examines a manifest file, asserts file and resources definition, then builds the associated test cases
-func GenerateTestCases(params *GenerationParameters) ([]model.TestCase, Scripts, error) {
+// GenerateTestCases examines a manifest file, asserts file and resources definition, then builds the associated test cases.
+// Also returns the context variables the scripts reference that no earlier script puts.
+func GenerateTestCases(params *GenerationParameters) ([]model.TestCase, Scripts, []UnsatisfiedReference, error) {
 	logger := logrus.WithFields(logrus.Fields{
 		"function": "GenerateTestCases",
 	})
 
 	specType, err := GetSpecType(params.Spec.SchemaVersion)
 	if err != nil {
-		return nil, Scripts{}, errors.New("unknown specification " + params.Spec.SchemaVersion)
+		return nil, Scripts{}, nil, errors.New("unknown specification " + params.Spec.SchemaVersion)
 
 	}
 	logrus.Debug("GenerateManifestTestCases for spec type:" + specType)
@@ -124,7 +125,7 @@ func GenerateTestCases(params *GenerationParameters) ([]model.TestCase, Scripts,
 		logger.WithFields(logrus.Fields{
 			"err": err,
 		}).Error("Error on loadGenerationResources")
-		return nil, Scripts{}, err
+		return nil, Scripts{}, nil, err
 	}
 	var filteredScripts Scripts
 	if specType == "accounts" {
@@ -151,6 +152,17 @@ func GenerateTestCases(params *GenerationParameters) ([]model.TestCase, Scripts,
 		filteredScripts = scripts // normal processing
 	}
 
+	graph, err := NewDependencyGraph(filteredScripts, refs, params.Ctx)
+	if err != nil {
+		logger.WithError(err).Error("Error on NewDependencyGraph")
+		return nil, Scripts{}, nil, err
+	}
+	unsatisfied := []UnsatisfiedReference{}
+	for _, reference := range graph.Unsatisfied() {
+		logger.WithField("reference", reference.String()).Warn("unsatisfied context reference in manifest")
+		unsatisfied = append(unsatisfied, reference)
+	}
+
 	params.Ctx.DumpContext("Incoming Ctx")
 
 	tests := []model.TestCase{}
@@ -159,7 +171,7 @@ func GenerateTestCases(params *GenerationParameters) ([]model.TestCase, Scripts,
 		localCtx, err := script.processParameters(&refs, params.Ctx)
 		if err != nil {
 			logger.WithError(err).Error("Error on processParameters")
-			return nil, Scripts{}, err
+			return nil, Scripts{}, nil, err
 		}
 
 		interactionId := uuid.New().String()
@@ -174,14 +186,15 @@ func GenerateTestCases(params *GenerationParameters) ([]model.TestCase, Scripts,
 
 		err = addConditionalPropertiesToRequest(&tc, params.Conditional, logger)
 		if err != nil {
-			return nil, Scripts{}, err
+			return nil, Scripts{}, nil, err
 		}
 
 		addQueryParametersToRequest(&tc, script.QueryParameters)
+		tc.DependsOn = graph.DependsOnSuccessOf(script.ID)
 		tests = append(tests, tc)
 	}
 
-	return tests, filteredScripts, nil
+	return tests, filteredScripts, unsatisfied, nil
 }
 
 func addQueryParametersToRequest(tc *model.TestCase, parameters map[string]string) {
@@ -14,6 +14,7 @@ import (
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
 	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/permissions"
 )
 
 func readVrpDiscoveryEndpoints() ([]discovery.ModelEndpoint, error) {
@@ -169,7 +170,7 @@ func TestVrpGenerateTestCases(t *testing.T) {
 			Endpoints: endpoints,
 		},
 	}
-	_, _, err = GenerateTestCases(&params)
+	_, _, _, err = GenerateTestCases(&params)
 	assert.Nil(t, err)
 }
 
@@ -195,7 +196,7 @@ func TestGenerateTestCases(t *testing.T) {
 		ManifestPath: manifestPath,
 		Validator:    schema.NewNullValidator(),
 	}
-	tests, _, err := GenerateTestCases(&params)
+	tests, _, _, err := GenerateTestCases(&params)
 	assert.Nil(t, err)
 
 	perms := getAccountPermissions(tests)
@@ -204,7 +205,7 @@ func TestGenerateTestCases(t *testing.T) {
 		t.Logf("perms: %s %-50.50s %s\n", v.ID, v.Path, v.Permissions)
 		m[v.Path] = v.ID
 	}
-	requiredTokens, err := GetRequiredTokensFromTests(tests, "accounts")
+	requiredTokens, err := GetRequiredTokensFromTests(tests, "accounts", permissions.ResolverModeGreedy)
 	for _, v := range requiredTokens {
 		fmt.Println(v)
 	}
@@ -231,7 +232,7 @@ func TestPaymentPermissions(t *testing.T) {
 		ManifestPath: manifestPath,
 		Validator:    schema.NewNullValidator(),
 	}
-	tests, _, err := GenerateTestCases(&params)
+	tests, _, _, err := GenerateTestCases(&params)
 	assert.NoError(t, err)
 
 	fmt.Printf("we have %d tests\n", len(tests))
@@ -314,7 +315,7 @@ func TestPermissionFiteringAccounts(t *testing.T) {
 		ManifestPath: manifestPath,
 		Validator:    schema.NewNullValidator(),
 	}
-	tests, _, err := GenerateTestCases(&params)
+	tests, _, _, err := GenerateTestCases(&params)
 	assert.NoError(t, err)
 
 	fmt.Printf("%d tests loaded", len(tests))
@@ -444,7 +445,7 @@ func TestPaymentTestCaseCreation(t *testing.T) {
 		ManifestPath: manifestPath,
 		Validator:    schema.NewNullValidator(),
 	}
-	tests, _, err := GenerateTestCases(&params)
+	tests, _, _, err := GenerateTestCases(&params)
 	assert.Nil(t, err)
 
 	fmt.Printf("we have %d tests\n", len(tests))
@@ -648,3 +649,29 @@ func TestContains(t *testing.T) {
 	assert.True(t, contains(collection, subjectExists))
 	assert.False(t, contains(collection, subjectNotExists))
 }
+
+func TestGenerateTestCasesReturnsUnsatisfiedReferences(t *testing.T) {
+	apiSpec := discovery.ModelAPISpecification{
+		SchemaVersion: accountSwaggerLocation31,
+	}
+	context := model.Context{"apiversions": []interface{}{"accounts_v3.1.1"}}
+
+	params := GenerationParameters{
+		Spec:         apiSpec,
+		Baseurl:      "http://mybaseurl",
+		Ctx:          &context,
+		Endpoints:    readDiscovery(),
+		ManifestPath: "file://manifests/ob_3.1_accounts_transactions_fca.json",
+		Validator:    schema.NewNullValidator(),
+	}
+	_, _, unsatisfied, err := GenerateTestCases(&params)
+	assert.NoError(t, err)
+	assert.Contains(t, unsatisfied, UnsatisfiedReference{ScriptID: "OB-301-ACC-100000", Variable: "consentedAccountId", Reason: "not put by any script"})
+
+	context.PutString("consentedAccountId", "500000000000000000000001")
+	_, _, unsatisfied, err = GenerateTestCases(&params)
+	assert.NoError(t, err)
+	for _, reference := range unsatisfied {
+		assert.NotEqual(t, "consentedAccountId", reference.Variable)
+	}
+}
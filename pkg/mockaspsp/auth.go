@@ -0,0 +1,226 @@
+package mockaspsp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/sirupsen/logrus"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
+)
+
+const (
+	openIDConfigurationPath = "/.well-known/openid-configuration"
+	jwksPath                = "/jwks"
+	authorizePath           = "/authorize"
+	tokenPath               = "/token"
+	parPath                 = "/par"
+
+	accessTokenExpiresIn = 3600
+	// requestURIExpiresIn - seconds a pushed authorization request can be used for, long enough for a PSU
+	// to open the consent URL the suite shows
+	requestURIExpiresIn = 600
+	requestURIPrefix    = "urn:ietf:params:oauth:request_uri:"
+)
+
+// oauthError - error response of the token and authorisation endpoints, RFC 6749 section 5.2
+type oauthError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// tokenResponse - successful response of the token endpoint
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// requestObject - the claims of the request object passed to the authorisation endpoint the mock uses
+type requestObject struct {
+	RedirectURI string `json:"redirect_uri"`
+	State       string `json:"state"`
+	Claims      struct {
+		IDToken struct {
+			IntentID struct {
+				Value string `json:"value"`
+			} `json:"openbanking_intent_id"`
+		} `json:"id_token"`
+	} `json:"claims"`
+}
+
+func (s *Server) openIDConfigurationHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, authentication.OpenIDConfiguration{
+		Issuer:                                 s.config.BaseURL,
+		AuthorizationEndpoint:                  s.config.BaseURL + authorizePath,
+		TokenEndpoint:                          s.config.BaseURL + tokenPath,
+		PushedAuthorizationRequestEndpoint:     s.config.BaseURL + parPath,
+		JwksURI:                                s.config.BaseURL + jwksPath,
+		TokenEndpointAuthMethodsSupported:      authentication.SuiteSupportedAuthMethodsMostSecureFirst(),
+		RequestObjectSigningAlgValuesSupported: []string{"PS256", "RS256", "none"},
+		ResponseTypesSupported:                 []string{"code", "code id_token"},
+		AcrValuesSupported:                     []string{"urn:openbanking:psd2:sca", "urn:openbanking:psd2:ca"},
+	})
+}
+
+func (s *Server) jwksHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, s.signer.jwks())
+}
+
+// authorizeHandler - headless consent, the PSU authorises the consent of the request object straight away
+// and is redirected back with an authorisation code
+func (s *Server) authorizeHandler(c echo.Context) error {
+	params := c.QueryParams()
+	if requestURI := params.Get("request_uri"); requestURI != "" {
+		pushed, ok := s.store.redeemPushedRequest(requestURI, time.Now())
+		if !ok || pushed.clientID != params.Get("client_id") {
+			return c.JSON(http.StatusBadRequest, oauthError{Error: "invalid_request_uri", ErrorDescription: "unknown, used or expired request_uri"})
+		}
+		params = pushed.params
+	}
+
+	request := requestObject{}
+	if err := decodeJWTClaims(params.Get("request"), &request); err != nil {
+		return c.JSON(http.StatusBadRequest, oauthError{Error: "invalid_request_object", ErrorDescription: err.Error()})
+	}
+
+	redirectURI := firstNonEmpty(params.Get("redirect_uri"), request.RedirectURI)
+	if redirectURI == "" {
+		return c.JSON(http.StatusBadRequest, oauthError{Error: "invalid_request", ErrorDescription: "redirect_uri missing"})
+	}
+	state := firstNonEmpty(params.Get("state"), request.State)
+
+	// parameters are returned in the fragment for hybrid flows
+	separator := "?"
+	if strings.Contains(params.Get("response_type"), "id_token") {
+		separator = "#"
+	}
+
+	consentID := request.Claims.IDToken.IntentID.Value
+	code, ok := s.store.authorise(consentID)
+	if !ok {
+		s.logger.WithField("consentId", consentID).Warn("authorisation of unknown consent")
+		return c.Redirect(http.StatusFound, redirectURI+separator+"error=access_denied&state="+url.QueryEscape(state))
+	}
+
+	s.logger.WithField("consentId", consentID).Debug("consent authorised")
+	// the code comes first and only the state follows, clients extract the code with `code=(.*)&`
+	return c.Redirect(http.StatusFound, redirectURI+separator+"code="+code+"&state="+url.QueryEscape(state))
+}
+
+// parHandler - pushed authorization request endpoint, https://www.rfc-editor.org/rfc/rfc9126
+func (s *Server) parHandler(c echo.Context) error {
+	clientID := tokenClientID(c)
+	if clientID == "" {
+		return c.JSON(http.StatusUnauthorized, oauthError{Error: "invalid_client", ErrorDescription: "client authentication missing"})
+	}
+	params, err := c.FormParams()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, oauthError{Error: "invalid_request", ErrorDescription: err.Error()})
+	}
+	if params.Get("request_uri") != "" {
+		return c.JSON(http.StatusBadRequest, oauthError{Error: "invalid_request", ErrorDescription: "request_uri cannot be pushed"})
+	}
+	for _, key := range []string{"client_secret", "client_assertion", "client_assertion_type"} {
+		params.Del(key)
+	}
+
+	requestURI := s.store.pushRequest(pushedRequest{
+		clientID:  clientID,
+		params:    params,
+		expiresAt: time.Now().Add(requestURIExpiresIn * time.Second),
+	})
+	s.logger.WithField("clientId", clientID).Debug("authorization request pushed")
+	return c.JSON(http.StatusCreated, authentication.PushedAuthorizationResponse{RequestURI: requestURI, ExpiresIn: requestURIExpiresIn})
+}
+
+func (s *Server) tokenHandler(c echo.Context) error {
+	clientID := tokenClientID(c)
+	if clientID == "" {
+		return c.JSON(http.StatusUnauthorized, oauthError{Error: "invalid_client", ErrorDescription: "client authentication missing"})
+	}
+
+	token := accessToken{clientID: clientID}
+	switch grantType := c.FormValue("grant_type"); grantType {
+	case "client_credentials":
+	case "authorization_code":
+		consentID, ok := s.store.redeemCode(c.FormValue("code"))
+		if !ok {
+			return c.JSON(http.StatusBadRequest, oauthError{Error: "invalid_grant", ErrorDescription: "unknown or used authorisation code"})
+		}
+		token.consentID = consentID
+	case "refresh_token":
+		refreshed, ok := s.store.redeemRefreshToken(c.FormValue("refresh_token"))
+		if !ok || refreshed.clientID != clientID {
+			return c.JSON(http.StatusBadRequest, oauthError{Error: "invalid_grant", ErrorDescription: "unknown or used refresh token"})
+		}
+		token = refreshed
+	default:
+		return c.JSON(http.StatusBadRequest, oauthError{Error: "unsupported_grant_type", ErrorDescription: grantType})
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"clientId":  clientID,
+		"consentId": token.consentID,
+	}).Debug("access token issued")
+	response := tokenResponse{
+		AccessToken: s.store.issueToken(token),
+		TokenType:   "Bearer",
+		ExpiresIn:   accessTokenExpiresIn,
+		Scope:       c.FormValue("scope"),
+	}
+	if token.consentID != "" {
+		response.RefreshToken = s.store.issueRefreshToken(token)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// tokenClientID returns the client ID of any client authentication method the mock advertises,
+// credentials are not checked
+func tokenClientID(c echo.Context) string {
+	if clientID, _, ok := c.Request().BasicAuth(); ok {
+		return clientID
+	}
+	if assertion := c.FormValue("client_assertion"); assertion != "" {
+		claims := struct {
+			Iss string `json:"iss"`
+			Sub string `json:"sub"`
+		}{}
+		if err := decodeJWTClaims(assertion, &claims); err == nil {
+			return firstNonEmpty(claims.Sub, claims.Iss)
+		}
+	}
+	return c.FormValue("client_id")
+}
+
+// decodeJWTClaims decodes the claims of a JWT without verifying its signature, an empty JWT has no claims
+func decodeJWTClaims(token string, claims interface{}) error {
+	if token == "" {
+		return nil
+	}
+	segments := strings.Split(token, ".")
+	if len(segments) < 2 {
+		return errMalformedJWT
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(segments[1], "="))
+	if err != nil {
+		return errMalformedJWT
+	}
+	return json.Unmarshal(payload, claims)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
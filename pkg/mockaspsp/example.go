@@ -0,0 +1,241 @@
+package mockaspsp
+
+import (
+	"regexp/syntax"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// preferredEnumValues - enum values picked before the first value of an enum, so new resources
+// look like they do at an ASPSP, e.g. consents are created awaiting authorisation
+var preferredEnumValues = []string{
+	"AwaitingAuthorisation",
+	"AcceptedSettlementInProcess",
+	"Authorised",
+}
+
+// exampleGenerator builds the smallest value that is valid against a schema
+type exampleGenerator struct {
+	named map[string]string // string properties that take a given value, e.g. path parameters
+	now   time.Time
+}
+
+func newExampleGenerator(named map[string]string, now time.Time) exampleGenerator {
+	return exampleGenerator{named: named, now: now}
+}
+
+// example returns a value valid against `schema`, only required properties of objects are set
+// apart from those of `Data`, the payload of OB requests and responses
+func (g exampleGenerator) example(schema *openapi3.SchemaRef) interface{} {
+	return g.value("", schema, 0)
+}
+
+// payloadProperty - all properties of the payload are set so responses carry the resources asked for
+const payloadProperty = "Data"
+
+// maxDepth - guards against recursive schemas
+const maxDepth = 32
+
+func (g exampleGenerator) value(name string, ref *openapi3.SchemaRef, depth int) interface{} {
+	if ref == nil || ref.Value == nil || depth > maxDepth {
+		return nil
+	}
+	schema := ref.Value
+
+	if len(schema.AllOf) > 0 {
+		var merged map[string]interface{}
+		var scalar interface{}
+		for _, sub := range schema.AllOf {
+			value := g.value(name, sub, depth+1)
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				// allOf also refines scalars, e.g. a string with a description of its own
+				if scalar == nil {
+					scalar = value
+				}
+				continue
+			}
+			if merged == nil {
+				merged = map[string]interface{}{}
+			}
+			for key, value := range object {
+				merged[key] = value
+			}
+		}
+		if merged == nil {
+			return scalar
+		}
+		return merged
+	}
+	if len(schema.OneOf) > 0 {
+		return g.value(name, schema.OneOf[0], depth+1)
+	}
+	if len(schema.AnyOf) > 0 {
+		return g.value(name, schema.AnyOf[0], depth+1)
+	}
+
+	schemaType := schema.Type
+	if schemaType == "" && len(schema.Properties) > 0 {
+		schemaType = "object"
+	}
+	switch schemaType {
+	case "object":
+		object := map[string]interface{}{}
+		for _, property := range schema.Required {
+			object[property] = g.value(property, schema.Properties[property], depth+1)
+		}
+		if name == payloadProperty {
+			for property, propertySchema := range schema.Properties {
+				object[property] = g.value(property, propertySchema, depth+1)
+			}
+		}
+		return object
+	case "array":
+		count := int(schema.MinItems)
+		if count == 0 {
+			count = 1
+		}
+		items := make([]interface{}, 0, count)
+		for i := 0; i < count; i++ {
+			items = append(items, g.value(name, schema.Items, depth+1))
+		}
+		return items
+	case "integer", "number":
+		if schema.Min != nil {
+			return *schema.Min
+		}
+		return 1
+	case "boolean":
+		return true
+	default:
+		if len(schema.Enum) > 0 {
+			return enumValue(schema.Enum)
+		}
+		if value, ok := g.named[name]; ok {
+			return value
+		}
+		return g.stringValue(schema)
+	}
+}
+
+func enumValue(enum []interface{}) interface{} {
+	for _, preferred := range preferredEnumValues {
+		for _, value := range enum {
+			if value == preferred {
+				return value
+			}
+		}
+	}
+	return enum[0]
+}
+
+func (g exampleGenerator) stringValue(schema *openapi3.Schema) string {
+	var value string
+	switch {
+	case schema.Format == "date-time":
+		value = g.now.UTC().Format(time.RFC3339)
+	case schema.Format == "date":
+		value = g.now.UTC().Format("2006-01-02")
+	case schema.Format == "uri":
+		value = "https://aspsp.example.com"
+	case schema.Pattern != "":
+		value = patternExample(schema.Pattern)
+	default:
+		value = "string"
+	}
+
+	if length := int(schema.MinLength); len(value) < length {
+		value += strings.Repeat("x", length-len(value))
+	}
+	if schema.MaxLength != nil && uint64(len(value)) > *schema.MaxLength {
+		value = value[:*schema.MaxLength]
+	}
+	return value
+}
+
+// patternExample returns the shortest string matching the regular expression `pattern`
+func patternExample(pattern string) string {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return ""
+	}
+	builder := &strings.Builder{}
+	writePatternExample(builder, re.Simplify())
+	return builder.String()
+}
+
+func writePatternExample(builder *strings.Builder, re *syntax.Regexp) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		builder.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		if len(re.Rune) > 0 {
+			builder.WriteRune(charClassExample(re.Rune))
+		}
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		builder.WriteRune('a')
+	case syntax.OpCapture:
+		writePatternExample(builder, re.Sub[0])
+	case syntax.OpPlus:
+		writePatternExample(builder, re.Sub[0])
+	case syntax.OpRepeat:
+		for i := 0; i < re.Min; i++ {
+			writePatternExample(builder, re.Sub[0])
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			writePatternExample(builder, sub)
+		}
+	case syntax.OpAlternate:
+		writePatternExample(builder, re.Sub[0])
+	}
+}
+
+// charClassExample picks a readable rune of a character class, given as pairs of rune ranges
+func charClassExample(ranges []rune) rune {
+	for _, readable := range []rune{'1', 'A', 'a'} {
+		for i := 0; i+1 < len(ranges); i += 2 {
+			if ranges[i] <= readable && readable <= ranges[i+1] {
+				return readable
+			}
+		}
+	}
+	return ranges[0]
+}
+
+// overlay copies the values of `request` onto `generated` for the properties `schema` defines,
+// so resources created by a request echo its content as they do at an ASPSP
+func overlay(ref *openapi3.SchemaRef, generated, request interface{}) interface{} {
+	if ref == nil || ref.Value == nil {
+		return generated
+	}
+	schema := ref.Value
+	for _, sub := range append(append(schema.AllOf, schema.OneOf...), schema.AnyOf...) {
+		generated = overlay(sub, generated, request)
+	}
+
+	generatedObject, ok := generated.(map[string]interface{})
+	if !ok {
+		return generated
+	}
+	requestObject, ok := request.(map[string]interface{})
+	if !ok {
+		return generated
+	}
+	for name, property := range schema.Properties {
+		value, ok := requestObject[name]
+		if !ok {
+			continue
+		}
+		if existing, ok := generatedObject[name]; ok {
+			generatedObject[name] = overlay(property, existing, value)
+			if _, isObject := existing.(map[string]interface{}); isObject {
+				continue
+			}
+		}
+		generatedObject[name] = value
+	}
+	return generatedObject
+}
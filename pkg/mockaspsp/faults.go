@@ -0,0 +1,143 @@
+package mockaspsp
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// Faults a profile can inject, each makes the mock non-conformant in a way an assertion of the manifests must catch
+const (
+	FaultStatusCode           = "status-code"            // answers with `statusCode` instead of the status of the spec
+	FaultMissingInteractionID = "missing-interaction-id" // leaves out the `x-fapi-interaction-id` header
+	FaultSchemaViolation      = "schema-violation"       // sends a string as the `Data` payload, an object in every response schema
+	FaultBadSignature         = "bad-signature"          // sends an `x-jws-signature` that does not match the body
+	FaultContentType          = "content-type"           // answers with `contentType` instead of JSON
+	FaultTLS11Only            = "tls1.1-only"            // only negotiates TLS 1.0 and 1.1, applies to the whole listener
+)
+
+// faults - the faults a profile can inject
+var faults = []string{
+	FaultStatusCode,
+	FaultMissingInteractionID,
+	FaultSchemaViolation,
+	FaultBadSignature,
+	FaultContentType,
+	FaultTLS11Only,
+}
+
+const (
+	defaultFaultStatusCode  = http.StatusInternalServerError
+	defaultFaultContentType = "text/plain; charset=utf-8"
+
+	faultsContextKey = "faults"
+)
+
+// FaultProfile - faults the mock injects in the responses of the endpoints they apply to
+type FaultProfile struct {
+	Name   string      `json:"name,omitempty"`
+	Faults []FaultRule `json:"faults"`
+}
+
+// FaultRule - a fault and the endpoints it applies to
+type FaultRule struct {
+	Fault       string `json:"fault"`
+	Method      string `json:"method,omitempty"`      // HTTP method the fault applies to, all methods when empty
+	Endpoint    string `json:"endpoint,omitempty"`    // path of the operation in the spec, e.g. /accounts/{AccountId}, all endpoints when empty
+	StatusCode  int    `json:"statusCode,omitempty"`  // status of the status-code fault, defaults to 500
+	ContentType string `json:"contentType,omitempty"` // content type of the content-type fault, defaults to text/plain
+}
+
+// LoadFaultProfile reads a fault profile from a JSON file
+func LoadFaultProfile(filename string) (FaultProfile, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return FaultProfile{}, err
+	}
+	profile := FaultProfile{}
+	if err := json.Unmarshal(content, &profile); err != nil {
+		return FaultProfile{}, fmt.Errorf("fault profile %s: %s", filename, err)
+	}
+	if err := profile.Validate(); err != nil {
+		return FaultProfile{}, fmt.Errorf("fault profile %s: %s", filename, err)
+	}
+	return profile, nil
+}
+
+// Validate checks every rule names a known fault
+func (p FaultProfile) Validate() error {
+	for i, rule := range p.Faults {
+		if !isFault(rule.Fault) {
+			return fmt.Errorf("rule %d: unknown fault %q, one of %s", i, rule.Fault, strings.Join(faults, ", "))
+		}
+	}
+	return nil
+}
+
+// TLSConfig returns the TLS configuration of a listener serving `certificate`, TLS 1.1 at most
+// when the profile injects the tls1.1-only fault
+func (p FaultProfile) TLSConfig(certificate tls.Certificate) *tls.Config {
+	config := &tls.Config{Certificates: []tls.Certificate{certificate}}
+	for _, rule := range p.Faults {
+		if rule.Fault == FaultTLS11Only {
+			config.MinVersion = tls.VersionTLS10
+			config.MaxVersion = tls.VersionTLS11
+		}
+	}
+	return config
+}
+
+// match returns the rules of the faults applying to an operation by fault, the last rule of a fault wins
+func (p FaultProfile) match(method, basePath, path string) map[string]FaultRule {
+	matched := map[string]FaultRule{}
+	for _, rule := range p.Faults {
+		if rule.Method != "" && !strings.EqualFold(rule.Method, method) {
+			continue
+		}
+		if rule.Endpoint != "" && rule.Endpoint != path && rule.Endpoint != basePath+path {
+			continue
+		}
+		matched[rule.Fault] = rule
+	}
+	return matched
+}
+
+func (r FaultRule) statusCode() int {
+	if r.StatusCode == 0 {
+		return defaultFaultStatusCode
+	}
+	return r.StatusCode
+}
+
+func (r FaultRule) contentType() string {
+	if r.ContentType == "" {
+		return defaultFaultContentType
+	}
+	return r.ContentType
+}
+
+func isFault(fault string) bool {
+	for _, known := range faults {
+		if fault == known {
+			return true
+		}
+	}
+	return false
+}
+
+// violateSchema replaces the payload of a response with a string
+func violateSchema(body []byte) []byte {
+	object := map[string]interface{}{}
+	if err := json.Unmarshal(body, &object); err != nil {
+		object = map[string]interface{}{}
+	}
+	object[payloadProperty] = FaultSchemaViolation
+	violated, err := json.Marshal(object)
+	if err != nil {
+		return []byte("{}")
+	}
+	return violated
+}
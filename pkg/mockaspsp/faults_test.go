@@ -0,0 +1,333 @@
+package mockaspsp
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/resty.v1"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+	"github.com/OpenBankingUK/conformance-suite/pkg/manifest"
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/schema"
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+)
+
+const (
+	manifestsDir   = "../../manifests"
+	interactionID  = "b6d1e3d6-8ad1-4c43-a0c5-7d4a0ab4f1d1"
+	assertOn200    = "OB3GLOAssertOn200"
+	assertOn201    = "OB3GLOAssertOn201"
+	assertOn204    = "OB3GLOAssertOn204"
+	assertFAPI     = "OB3GLOFAPIHeader"
+	assertPlayBack = "OB3GLOAssertFAPIPlayBack"
+	assertJSONType = "OB3GLOAssertContentType"
+)
+
+// manifestAPIs - the manifests the self-test runs and the API their scripts call
+var manifestAPIs = map[string]string{
+	"ob_3.1_accounts_transactions_fca.json":   "Account and Transaction API Specification",
+	"ob_3.1_cbpii_fca.json":                   "Confirmation of Funds API Specification",
+	"ob_3.1_payment_fca.json":                 "Payment Initiation API",
+	"ob_3.1_variable_recurring_payments.json": "OBIE VRP Profile",
+}
+
+// successAsserts - the assertion of the status a conformant ASPSP answers a request of each method with
+var successAsserts = map[string]string{
+	"get":    assertOn200,
+	"post":   assertOn201,
+	"delete": assertOn204,
+}
+
+var scriptParam = regexp.MustCompile(`\$[\w-]+`)
+
+func TestFaultProfileMatch(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	profile := FaultProfile{Faults: []FaultRule{
+		{Fault: FaultStatusCode, StatusCode: http.StatusNotFound},
+		{Fault: FaultBadSignature, Method: "get", Endpoint: "/accounts/{AccountId}"},
+		{Fault: FaultContentType, Endpoint: accountsBasePath + "/accounts"},
+		{Fault: FaultStatusCode, Method: http.MethodPost, StatusCode: http.StatusBadGateway},
+	}}
+
+	matched := profile.match(http.MethodGet, accountsBasePath, "/accounts/{AccountId}")
+	assert.Len(matched, 2)
+	assert.Equal(http.StatusNotFound, matched[FaultStatusCode].statusCode())
+	assert.Contains(matched, FaultBadSignature)
+
+	matched = profile.match(http.MethodGet, accountsBasePath, "/accounts")
+	assert.Len(matched, 2)
+	assert.Equal(defaultFaultContentType, matched[FaultContentType].contentType())
+
+	matched = profile.match(http.MethodPost, accountsBasePath, "/account-access-consents")
+	assert.Len(matched, 1)
+	assert.Equal(http.StatusBadGateway, matched[FaultStatusCode].statusCode())
+}
+
+func TestLoadFaultProfile(t *testing.T) {
+	require := test.NewRequire(t)
+
+	profile, err := LoadFaultProfile("testdata/faults.json")
+	require.NoError(err)
+	require.Len(profile.Faults, len(faults))
+
+	_, err = NewServer(Config{Faults: FaultProfile{Faults: []FaultRule{{Fault: "slow"}}}}, nullLogger())
+	require.EqualError(err, `mock ASPSP: fault profile: rule 0: unknown fault "slow", one of `+
+		"status-code, missing-interaction-id, schema-violation, bad-signature, content-type, tls1.1-only")
+}
+
+// TestFaultsFailManifestTests runs the tests of the manifests against the mock, they pass against
+// the conformant mock and fail under each fault their assertions are meant to catch, see excludedReason
+// for the tests that are not run
+func TestFaultsFailManifestTests(t *testing.T) {
+	refs := loadAssertions(t)
+	server, mock := newTestServer(t)
+	defer server.Close()
+
+	for file, apiName := range manifestAPIs {
+		scripts := manifest.Scripts{}
+		readJSON(t, filepath.Join(manifestsDir, file), &scripts)
+		validator, err := schema.NewSwaggerOBSpecValidator(apiName, DefaultVersion)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		api := apiNamed(t, mock, apiName)
+		for _, script := range scripts.Scripts {
+			if reason := excludedReason(script); reason != "" {
+				t.Logf("%s: not run, %s", script.ID, reason)
+				continue
+			}
+			tc := testCase(t, script, api, refs, validator)
+
+			mock.config.Faults = FaultProfile{}
+			if passed, errs := run(t, server.URL, mock, api, tc); !passed {
+				t.Errorf("%s: failed against the conformant mock: %v", script.ID, errs)
+				continue
+			}
+			for _, fault := range intendedFaults(script) {
+				mock.config.Faults = FaultProfile{Faults: []FaultRule{{Fault: fault}}}
+				if passed, _ := run(t, server.URL, mock, api, tc); passed {
+					t.Errorf("%s: passed with fault %s", script.ID, fault)
+				}
+			}
+		}
+	}
+}
+
+func TestFaultTLS11OnlyFailsTLSCheck(t *testing.T) {
+	require := test.NewRequire(t)
+
+	certificate, err := NewTLSCertificate([]string{"127.0.0.1"})
+	require.NoError(err)
+	profile := FaultProfile{Faults: []FaultRule{{Fault: FaultTLS11Only}}}
+
+	server := httptest.NewUnstartedServer(http.NotFoundHandler())
+	server.TLS = profile.TLSConfig(certificate)
+	server.StartTLS()
+	defer server.Close()
+
+	result, err := discovery.NewStdTLSValidator(tls.VersionTLS12).ValidateTLSVersion(server.URL)
+	require.False(err == nil && result.Valid, "TLS 1.1 only listener passed TLS check: %+v", result)
+}
+
+// excludedReason - why the self-test does not run a script, empty when it does. The self-test builds conformant
+// requests, it does not run:
+//   - scripts asserting an error status, their requests are built from the data templates of the manifests and
+//     the resources of earlier tests to provoke the error
+//   - scripts asserting one of several statuses, or no status, the status-code fault cannot fail them
+func excludedReason(script manifest.Script) string {
+	success, ok := successAsserts[script.Method]
+	if !ok {
+		return "unsupported method " + script.Method
+	}
+	if len(script.AssertsOneOf) > 0 {
+		return "asserts one of several statuses"
+	}
+	for _, assert := range script.Asserts {
+		if assert != success && strings.HasPrefix(assert, "OB3GLOAssertOn") {
+			return "asserts the error status of " + assert
+		}
+	}
+	if !contains(script.Asserts, success) {
+		return "asserts no status"
+	}
+	return ""
+}
+
+// intendedFaults - the faults the assertions of a script are meant to catch, a 204 response has no body
+// so only its status and headers can be faulted
+func intendedFaults(script manifest.Script) []string {
+	intended := []string{FaultStatusCode}
+	if contains(script.Asserts, assertFAPI) || contains(script.Asserts, assertPlayBack) {
+		intended = append(intended, FaultMissingInteractionID)
+	}
+	if contains(script.Asserts, assertOn204) {
+		return intended
+	}
+	if script.SchemaCheck {
+		intended = append(intended, FaultSchemaViolation)
+	}
+	if script.SchemaCheck || contains(script.Asserts, assertJSONType) {
+		intended = append(intended, FaultContentType)
+	}
+	if script.ValidateSignature {
+		intended = append(intended, FaultBadSignature)
+	}
+	return intended
+}
+
+// testCase builds the test case of a script as the manifest does, the body and headers of a request
+// are generated from the spec of its operation
+func testCase(t *testing.T, script manifest.Script, api api, refs manifest.References, validator schema.Validator) model.TestCase {
+	tc := model.MakeTestCase()
+	tc.ID = script.ID
+	tc.Input.Method = strings.ToUpper(script.Method)
+	tc.Input.Endpoint = api.basePath + scriptParam.ReplaceAllString(script.URI, "id-001")
+	tc.Input.QueryParameters = script.QueryParameters
+	tc.Input.Headers = map[string]string{}
+	tc.Validator = validator
+	tc.Expect.SchemaValidation = script.SchemaCheck
+	tc.ValidateSignature = script.ValidateSignature
+	for _, assert := range script.Asserts {
+		ref := refs.References[assert]
+		expect := ref.Expect.Clone()
+		if expect.StatusCode != 0 {
+			tc.Expect.StatusCode = expect.StatusCode
+		}
+		tc.Expect.Matches = append(tc.Expect.Matches, expect.Matches...)
+	}
+	tc.ProcessReplacementFields(&model.Context{headerInteractionID: interactionID}, false)
+
+	request, err := http.NewRequest(tc.Input.Method, tc.Input.Endpoint, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	route, _, err := api.router.FindRoute(request)
+	if err != nil {
+		t.Fatalf("%s: %s %s: %s", script.ID, tc.Input.Method, tc.Input.Endpoint, err)
+	}
+	generator := newExampleGenerator(exampleValues, time.Now())
+	for _, parameter := range route.Operation.Parameters {
+		if parameter.Value.In == "header" && parameter.Value.Required {
+			tc.Input.Headers[parameter.Value.Name] = generator.example(parameter.Value.Schema).(string)
+		}
+	}
+	if route.Operation.RequestBody != nil {
+		if _, mediaType := responseMediaType(route.Operation.RequestBody.Value.Content); mediaType != nil && mediaType.Schema != nil {
+			body, err := json.Marshal(generator.example(mediaType.Schema))
+			if err != nil {
+				t.Fatal(err)
+			}
+			tc.Input.RequestBody = string(body)
+			tc.Input.Headers["Content-Type"] = mimeJSONUTF8
+		}
+	}
+	tc.Input.Headers[headerInteractionID] = interactionID
+	return tc
+}
+
+// run calls the mock with a test case and reports whether it passed, the resource it reads or deletes
+// is created beforehand as the journey does
+func run(t *testing.T, serverURL string, mock *Server, api api, tc model.TestCase) (bool, []error) {
+	if tc.Input.Method != http.MethodPost {
+		seedResource(t, mock, api, tc.Input.Endpoint)
+	}
+
+	request := resty.New().R().
+		SetHeaders(tc.Input.Headers).
+		SetAuthToken(mock.store.issueToken(accessToken{clientID: "client", consentID: "consent-001"})).
+		SetQueryParams(tc.Input.QueryParameters)
+	if tc.Input.RequestBody != "" {
+		request.SetBody(tc.Input.RequestBody)
+	}
+	response, err := request.Execute(tc.Input.Method, serverURL+tc.Input.Endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := model.Context{
+		"jwks_uri":    serverURL + jwksPath,
+		"apiversions": []interface{}{"payments_" + DefaultVersion},
+	}
+	passed, errs := tc.Validate(response, &ctx)
+	return passed && len(errs) == 0, errs
+}
+
+// seedResource creates the resource at `path` when it is created by a POST, as the journey does before reading it,
+// consents are authorised by the PSU
+func seedResource(t *testing.T, mock *Server, api api, path string) {
+	request, err := http.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	route, pathParams, err := api.router.FindRoute(request)
+	if err != nil {
+		t.Fatalf("%s: %s", path, err)
+	}
+	if !isCreatedResource(api.doc, route.Path) {
+		return
+	}
+
+	named := map[string]string{}
+	for name, value := range exampleValues {
+		named[name] = value
+	}
+	for name, value := range pathParams {
+		named[name] = value
+	}
+	_, mediaType := responseMediaType(route.Operation.Responses.Get(http.StatusOK).Value.Content)
+	resource := newExampleGenerator(named, time.Now()).example(mediaType.Schema).(map[string]interface{})
+
+	idParam := route.Path[strings.LastIndex(route.Path, "{")+1 : len(route.Path)-1]
+	if idParam != "ConsentId" {
+		mock.store.saveResource(path, resource)
+		return
+	}
+	mock.store.saveConsent(pathParams[idParam], path, resource)
+	mock.store.authorise(pathParams[idParam])
+}
+
+func apiNamed(t *testing.T, mock *Server, name string) api {
+	for _, api := range mock.apis {
+		if api.name == name {
+			return api
+		}
+	}
+	t.Fatalf("API %s not served", name)
+	return api{}
+}
+
+func loadAssertions(t *testing.T) manifest.References {
+	refs := manifest.References{}
+	readJSON(t, filepath.Join(manifestsDir, "assertions.json"), &refs)
+	return refs
+}
+
+func readJSON(t *testing.T, filename string, v interface{}) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(content, v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
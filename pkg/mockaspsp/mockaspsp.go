@@ -0,0 +1,156 @@
+// Package mockaspsp is an ASPSP that runs offline for developing manifests and testing the suite.
+// It serves the Account Info, Payment Initiation, Confirmation of Funds and VRP APIs of the bundled
+// OpenAPI specs, answering each request with the smallest response valid against its spec, and is
+// its own authorisation server with headless consent.
+package mockaspsp
+
+import (
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+	legacyrouter "github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/labstack/echo"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/schema"
+)
+
+// DefaultVersion - version of the APIs served when none is configured
+const DefaultVersion = "v3.1.10"
+
+// DefaultOrgID - organisation ID the mock signs responses as
+const DefaultOrgID = "0015800001041RHAAY"
+
+// specNames - the APIs served, named as in the bundled specs
+var specNames = []string{
+	"Account and Transaction API Specification",
+	"Payment Initiation API",
+	"Confirmation of Funds API Specification",
+	"OBIE VRP Profile",
+}
+
+// Config - configures the mock ASPSP
+type Config struct {
+	BaseURL string // URL the mock is reached at, used as the issuer and in the endpoints it advertises
+	Version string // version of the APIs served, one of the bundled OpenAPI 3 specs, e.g. v3.1.10
+	OrgID   string // organisation ID the responses are signed as
+	Faults  FaultProfile
+}
+
+// Server - the mock ASPSP, an `http.Handler`
+type Server struct {
+	config Config
+	apis   []api
+	signer signer
+	store  *store
+	echo   *echo.Echo
+	logger *logrus.Entry
+}
+
+// api - one of the APIs served
+type api struct {
+	name     string
+	basePath string
+	doc      *openapi3.T
+	router   routers.Router
+}
+
+// NewServer creates a mock ASPSP serving the APIs of `config.Version`
+func NewServer(config Config, logger *logrus.Entry) (*Server, error) {
+	if config.Version == "" {
+		config.Version = DefaultVersion
+	}
+	if config.OrgID == "" {
+		config.OrgID = DefaultOrgID
+	}
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
+
+	if err := config.Faults.Validate(); err != nil {
+		return nil, errors.Wrap(err, "mock ASPSP: fault profile")
+	}
+
+	useOpenAPI3, err := schema.ShouldUseOpenApi3(config.Version)
+	if err != nil {
+		return nil, err
+	}
+	if !useOpenAPI3 {
+		return nil, fmt.Errorf("mock ASPSP: version %s is not supported, only versions with OpenAPI 3 specs are", config.Version)
+	}
+
+	apis := make([]api, 0, len(specNames))
+	for _, name := range specNames {
+		doc, err := schema.LoadOpenAPI3Spec(name, config.Version)
+		if err != nil {
+			return nil, errors.Wrapf(err, "mock ASPSP: loading %s", name)
+		}
+		router, err := legacyrouter.NewRouter(doc)
+		if err != nil {
+			return nil, errors.Wrapf(err, "mock ASPSP: routing %s", name)
+		}
+		apis = append(apis, api{name: name, basePath: doc.Servers[0].URL, doc: doc, router: router})
+	}
+
+	signer, err := newSigner(config.OrgID)
+	if err != nil {
+		return nil, errors.Wrap(err, "mock ASPSP")
+	}
+
+	s := &Server{
+		config: config,
+		apis:   apis,
+		signer: signer,
+		store:  newStore(),
+		echo:   echo.New(),
+		logger: logger.WithField("module", "mockaspsp"),
+	}
+	s.echo.HideBanner = true
+	s.echo.HTTPErrorHandler = s.httpErrorHandler
+	s.echo.GET(openIDConfigurationPath, s.openIDConfigurationHandler)
+	s.echo.GET(jwksPath, s.jwksHandler)
+	s.echo.GET(authorizePath, s.authorizeHandler)
+	s.echo.POST(tokenPath, s.tokenHandler)
+	s.echo.POST(parPath, s.parHandler)
+	s.echo.Any("/open-banking/*", s.resourceHandler)
+	return s, nil
+}
+
+// ServeHTTP - serves the authorisation server and APIs
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.echo.ServeHTTP(w, r)
+}
+
+// OpenIDConfigurationURL - where the mock publishes its OpenID configuration
+func (s *Server) OpenIDConfigurationURL() string {
+	return s.config.BaseURL + openIDConfigurationPath
+}
+
+// ResourceBaseURLs - the base URL of each API served by name
+func (s *Server) ResourceBaseURLs() map[string]string {
+	urls := map[string]string{}
+	for _, api := range s.apis {
+		urls[api.name] = s.config.BaseURL + api.basePath
+	}
+	return urls
+}
+
+// SigningCertificatePEM - the PEM encoded certificate responses are signed with, it is self-signed so the suite only
+// trusts it as a root of the openbanking.org.uk trust anchor, see authentication.AddTrustAnchorRoots
+func (s *Server) SigningCertificatePEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.signer.certificate.Raw})
+}
+
+// httpErrorHandler answers requests no route matches with an OB error response
+func (s *Server) httpErrorHandler(err error, c echo.Context) {
+	code := http.StatusInternalServerError
+	if httpError, ok := err.(*echo.HTTPError); ok {
+		code = httpError.Code
+	}
+	if errJSON := c.JSON(code, newErrorResponse(code, errorCodeResourceNotFound, err.Error())); errJSON != nil {
+		s.logger.WithError(errJSON).Error("writing error response")
+	}
+}
@@ -0,0 +1,369 @@
+package mockaspsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
+	"github.com/OpenBankingUK/conformance-suite/pkg/schema"
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+)
+
+const (
+	accountsBasePath = "/open-banking/v3.1/aisp"
+	redirectURL      = "https://127.0.0.1:8443/conformancesuite/callback"
+)
+
+func TestPatternExample(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, pattern := range []string{
+		`^\d{1,13}$|^\d{1,13}\.\d{1,5}$`,
+		`^[A-Z]{3,3}$`,
+		`^[A-Z]{2,2}$`,
+		`[A-Z0-9]{4}[A-Z]{2}[A-Z0-9]{2}([A-Z0-9]{3})?`,
+		`^(-?\d+)\.(\d{1,10})$`,
+	} {
+		example := patternExample(pattern)
+		assert.Regexp(regexp.MustCompile(pattern), example, pattern)
+	}
+}
+
+func TestServerHeadlessConsentJourney(t *testing.T) {
+	require := test.NewRequire(t)
+
+	server, mock := newTestServer(t)
+	defer server.Close()
+
+	openIDConfig := authentication.OpenIDConfiguration{}
+	code, body, _ := call(t, http.MethodGet, server.URL+openIDConfigurationPath, "", nil, nil)
+	require.Equal(http.StatusOK, code)
+	require.NoError(json.Unmarshal(body, &openIDConfig))
+	require.Equal(server.URL, openIDConfig.Issuer)
+	require.Equal(server.URL+"/jwks", openIDConfig.JwksURI)
+	require.Equal(server.URL+accountsBasePath, mock.ResourceBaseURLs()["Account and Transaction API Specification"])
+
+	clientToken := clientCredentialsToken(t, openIDConfig.TokenEndpoint)
+
+	// consent is created awaiting authorisation and signed
+	consentRequest := `{"Data":{"Permissions":["ReadAccountsBasic","ReadAccountsDetail"]},"Risk":{}}`
+	code, body, headers := call(t, http.MethodPost, server.URL+accountsBasePath+"/account-access-consents", clientToken, []byte(consentRequest), http.Header{
+		"x-fapi-interaction-id": {"93bac548-d2de-4546-b106-880a5018460d"},
+	})
+	require.Equal(http.StatusCreated, code, string(body))
+	require.Equal("93bac548-d2de-4546-b106-880a5018460d", headers.Get(headerInteractionID))
+	valid, err := authentication.ValidateSignature(headers.Get(headerJWSSignature), string(body), openIDConfig.JwksURI, true)
+	require.NoError(err)
+	require.True(valid)
+
+	consent := map[string]map[string]interface{}{}
+	require.NoError(json.Unmarshal(body, &consent))
+	consentID := consent["Data"]["ConsentId"].(string)
+	require.Equal("AwaitingAuthorisation", consent["Data"]["Status"])
+	require.Equal([]interface{}{"ReadAccountsBasic", "ReadAccountsDetail"}, consent["Data"]["Permissions"])
+
+	// accounts need a token the PSU consented to
+	code, _, _ = call(t, http.MethodGet, server.URL+accountsBasePath+"/accounts", clientToken, nil, nil)
+	require.Equal(http.StatusForbidden, code)
+	code, _, _ = call(t, http.MethodGet, server.URL+accountsBasePath+"/accounts", "", nil, nil)
+	require.Equal(http.StatusUnauthorized, code)
+
+	// headless consent redirects with the code straight away
+	consentURL, err := authentication.PSUURLGenerate(authentication.PSUConsentClaims{
+		AuthorizationEndpoint: openIDConfig.AuthorizationEndpoint,
+		Aud:                   openIDConfig.Issuer,
+		Iss:                   "client",
+		ResponseType:          "code id_token",
+		Scope:                 "openid accounts",
+		RedirectURI:           redirectURL,
+		ConsentId:             consentID,
+		State:                 "accountToken0001",
+	})
+	require.NoError(err)
+	code, _, headers = call(t, http.MethodGet, consentURL.String(), "", nil, nil)
+	require.Equal(http.StatusFound, code)
+	location := headers.Get("Location")
+	require.True(strings.HasPrefix(location, redirectURL+"#code="), location)
+	exchangeCode := regexp.MustCompile("code=([^&]*)&").FindStringSubmatch(location)[1]
+
+	code, body, _ = call(t, http.MethodGet, server.URL+accountsBasePath+"/account-access-consents/"+consentID, clientToken, nil, nil)
+	require.Equal(http.StatusOK, code)
+	require.Contains(string(body), `"Status":"Authorised"`)
+
+	psuToken := codeToken(t, openIDConfig.TokenEndpoint, exchangeCode)
+	code, _ = post(t, openIDConfig.TokenEndpoint, url.Values{"grant_type": {"authorization_code"}, "code": {exchangeCode}, "client_id": {"client"}})
+	require.Equal(http.StatusBadRequest, code)
+
+	code, body, headers = call(t, http.MethodGet, server.URL+accountsBasePath+"/accounts/acc-001", psuToken, nil, nil)
+	require.Equal(http.StatusOK, code, string(body))
+	require.Contains(string(body), `"AccountId":"acc-001"`)
+	requireValidResponse(t, "Account and Transaction API Specification", http.MethodGet, accountsBasePath+"/accounts/acc-001", code, headers, body)
+
+	code, _, _ = call(t, http.MethodDelete, server.URL+accountsBasePath+"/account-access-consents/"+consentID, clientToken, nil, nil)
+	require.Equal(http.StatusNoContent, code)
+	code, _, _ = call(t, http.MethodGet, server.URL+accountsBasePath+"/account-access-consents/"+consentID, clientToken, nil, nil)
+	require.Equal(http.StatusBadRequest, code)
+}
+
+func TestServerRejectsInvalidRequests(t *testing.T) {
+	require := test.NewRequire(t)
+
+	server, _ := newTestServer(t)
+	defer server.Close()
+	clientToken := clientCredentialsToken(t, server.URL+tokenPath)
+
+	code, body, _ := call(t, http.MethodPost, server.URL+accountsBasePath+"/account-access-consents", clientToken, []byte(`{"Data":{}}`), nil)
+	require.Equal(http.StatusBadRequest, code)
+	require.Contains(string(body), errorCodeFieldInvalid)
+
+	code, _, _ = call(t, http.MethodGet, server.URL+accountsBasePath+"/unknown", clientToken, nil, nil)
+	require.Equal(http.StatusNotFound, code)
+
+	code, _ = post(t, server.URL+tokenPath, url.Values{"grant_type": {"client_credentials"}})
+	require.Equal(http.StatusUnauthorized, code)
+}
+
+func TestServerIgnoresQueryParameters(t *testing.T) {
+	require := test.NewRequire(t)
+
+	server, mock := newTestServer(t)
+	defer server.Close()
+	token := mock.store.issueToken(accessToken{clientID: "client", consentID: "consent-001"})
+
+	// filters an ASPSP cannot parse are ignored rather than rejected
+	code, body, _ := call(t, http.MethodGet, server.URL+accountsBasePath+"/accounts/acc-001/transactions?fromBookingDateTime=yesterday", token, nil, nil)
+	require.Equal(http.StatusOK, code, string(body))
+}
+
+func TestServerRefreshToken(t *testing.T) {
+	require := test.NewRequire(t)
+
+	server, mock := newTestServer(t)
+	defer server.Close()
+	refreshToken := mock.store.issueRefreshToken(accessToken{clientID: "client", consentID: "consent-001"})
+
+	code, _, headers := call(t, http.MethodGet, server.URL+accountsBasePath+"/accounts", "expired", nil, nil)
+	require.Equal(http.StatusUnauthorized, code)
+	require.Equal(`Bearer error="invalid_token"`, headers.Get("WWW-Authenticate"))
+
+	code, body := post(t, server.URL+tokenPath, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refreshToken}, "client_id": {"client"}})
+	require.Equal(http.StatusOK, code, string(body))
+	response := tokenResponse{}
+	require.NoError(json.Unmarshal(body, &response))
+	require.NotEmpty(response.RefreshToken)
+	token, ok := mock.store.token(response.AccessToken)
+	require.True(ok)
+	require.Equal("consent-001", token.consentID)
+
+	code, _ = post(t, server.URL+tokenPath, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refreshToken}, "client_id": {"client"}})
+	require.Equal(http.StatusBadRequest, code)
+}
+
+func TestServerPushedAuthorizationRequest(t *testing.T) {
+	require := test.NewRequire(t)
+
+	server, mock := newTestServer(t)
+	defer server.Close()
+	mock.store.saveConsent("consent-001", accountsBasePath+"/account-access-consents/consent-001", map[string]interface{}{
+		"Data": map[string]interface{}{"ConsentId": "consent-001", "Status": "AwaitingAuthorisation"},
+	})
+
+	frontChannelURL, err := authentication.PSUURLGenerate(authentication.PSUConsentClaims{
+		AuthorizationEndpoint: server.URL + authorizePath,
+		Aud:                   server.URL,
+		Iss:                   "client",
+		ResponseType:          "code id_token",
+		Scope:                 "openid accounts",
+		RedirectURI:           redirectURL,
+		ConsentId:             "consent-001",
+		State:                 "accountToken0001",
+	})
+	require.NoError(err)
+	code, body := post(t, server.URL+parPath, frontChannelURL.Query())
+	require.Equal(http.StatusCreated, code, string(body))
+	pushed := authentication.PushedAuthorizationResponse{}
+	require.NoError(json.Unmarshal(body, &pushed))
+	require.True(strings.HasPrefix(pushed.RequestURI, requestURIPrefix), pushed.RequestURI)
+	require.Equal(requestURIExpiresIn, pushed.ExpiresIn)
+
+	consentURL, err := authentication.PushedAuthorizationURL(server.URL+authorizePath, "client", pushed.RequestURI)
+	require.NoError(err)
+	code, _, headers := call(t, http.MethodGet, consentURL, "", nil, nil)
+	require.Equal(http.StatusFound, code)
+	require.True(strings.HasPrefix(headers.Get("Location"), redirectURL+"#code="), headers.Get("Location"))
+	require.True(strings.HasSuffix(headers.Get("Location"), "&state=accountToken0001"), headers.Get("Location"))
+
+	// request_uri can be used once
+	code, _, _ = call(t, http.MethodGet, consentURL, "", nil, nil)
+	require.Equal(http.StatusBadRequest, code)
+
+	code, _ = post(t, server.URL+parPath, url.Values{"response_type": {"code"}})
+	require.Equal(http.StatusUnauthorized, code)
+}
+
+// TestServerResponsesMatchSpec calls every operation of every API with a request generated from its spec
+// and validates the response against the spec, as the suite does
+func TestServerResponsesMatchSpec(t *testing.T) {
+	server, mock := newTestServer(t)
+	defer server.Close()
+	token := mock.store.issueToken(accessToken{clientID: "client", consentID: "consent"})
+
+	for _, api := range mock.apis {
+		paths := make([]string, 0, len(api.doc.Paths))
+		for path := range api.doc.Paths {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		for _, path := range paths {
+			for method, operation := range api.doc.Paths[path].Operations() {
+				if method == http.MethodDelete {
+					continue
+				}
+
+				generator := newExampleGenerator(exampleValues, time.Now())
+				headers := http.Header{}
+				for _, parameter := range operation.Parameters {
+					if parameter.Value.In == "header" && parameter.Value.Required {
+						headers.Set(parameter.Value.Name, generator.example(parameter.Value.Schema).(string))
+					}
+				}
+				var requestBody []byte
+				if operation.RequestBody != nil {
+					if _, mediaType := responseMediaType(operation.RequestBody.Value.Content); mediaType != nil && mediaType.Schema != nil {
+						requestBody, _ = json.Marshal(generator.example(mediaType.Schema))
+					}
+				}
+
+				resourcePath := api.basePath + regexp.MustCompile(`\{[^}]*\}`).ReplaceAllString(path, "id-001")
+				if isCreatedResource(api.doc, path) {
+					// created resources are read back after creating them
+					continue
+				}
+				code, body, responseHeaders := call(t, method, server.URL+resourcePath, token, requestBody, headers)
+				if code >= http.StatusBadRequest {
+					t.Errorf("%s %s: %d %s", method, resourcePath, code, body)
+					continue
+				}
+				requireValidResponse(t, api.name, method, resourcePath, code, responseHeaders, body)
+			}
+		}
+	}
+}
+
+func newTestServer(t *testing.T) (*httptest.Server, *Server) {
+	handler := &serverHandler{}
+	server := httptest.NewServer(handler)
+	mock, err := NewServer(Config{BaseURL: server.URL}, nullLogger())
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	// the suite verifies signatures against the trust anchor roots, which the self-signed certificate is not one of
+	if err := authentication.AddTrustAnchorRoots(authentication.OBTrustAnchor, mock.SigningCertificatePEM()); err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	handler.server = mock
+	return server, mock
+}
+
+// serverHandler lets the test server start before the mock, which needs its URL
+type serverHandler struct {
+	server *Server
+}
+
+func (h *serverHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.server.ServeHTTP(w, r)
+}
+
+func nullLogger() *logrus.Entry {
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+	return logger.WithField("app", "test")
+}
+
+func requireValidResponse(t *testing.T, specName, method, path string, code int, headers http.Header, body []byte) {
+	validator, err := schema.NewSwaggerOBSpecValidator(specName, DefaultVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failures, err := validator.Validate(schema.HTTPResponse{
+		Method:     method,
+		Path:       path,
+		Header:     headers,
+		Body:       bytes.NewReader(body),
+		StatusCode: code,
+	})
+	if err != nil {
+		t.Errorf("%s %s: %s\n%s", method, path, err, body)
+	}
+	for _, failure := range failures {
+		t.Errorf("%s %s: %s\n%s", method, path, failure.Message, body)
+	}
+}
+
+func clientCredentialsToken(t *testing.T, tokenEndpoint string) string {
+	code, body := post(t, tokenEndpoint, url.Values{"grant_type": {"client_credentials"}, "client_id": {"client"}})
+	test.NewRequire(t).Equal(http.StatusOK, code, string(body))
+	return accessTokenFrom(t, body)
+}
+
+func codeToken(t *testing.T, tokenEndpoint, code string) string {
+	status, body := post(t, tokenEndpoint, url.Values{"grant_type": {"authorization_code"}, "code": {code}, "client_id": {"client"}})
+	test.NewRequire(t).Equal(http.StatusOK, status, string(body))
+	return accessTokenFrom(t, body)
+}
+
+func accessTokenFrom(t *testing.T, body []byte) string {
+	response := tokenResponse{}
+	test.NewRequire(t).NoError(json.Unmarshal(body, &response))
+	return response.AccessToken
+}
+
+func post(t *testing.T, endpoint string, form url.Values) (int, []byte) {
+	request, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	test.NewRequire(t).NoError(err)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	response, err := http.DefaultClient.Do(request)
+	test.NewRequire(t).NoError(err)
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	test.NewRequire(t).NoError(err)
+	return response.StatusCode, body
+}
+
+func call(t *testing.T, method, endpoint, token string, body []byte, headers http.Header) (int, []byte, http.Header) {
+	require := test.NewRequire(t)
+
+	request, err := http.NewRequest(method, endpoint, bytes.NewReader(body))
+	require.NoError(err)
+	for name, values := range headers {
+		request.Header[http.CanonicalHeaderKey(name)] = values
+	}
+	if len(body) > 0 {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	response, err := client.Do(request)
+	require.NoError(err)
+	defer response.Body.Close()
+	responseBody, err := ioutil.ReadAll(response.Body)
+	require.NoError(err)
+	return response.StatusCode, responseBody, response.Header
+}
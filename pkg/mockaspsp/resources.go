@@ -0,0 +1,306 @@
+package mockaspsp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/google/uuid"
+	"github.com/labstack/echo"
+	"github.com/sirupsen/logrus"
+)
+
+// OB error codes returned by the mock
+const (
+	errorCodeFieldInvalid     = "UK.OBIE.Field.Invalid"
+	errorCodeResourceNotFound = "UK.OBIE.Resource.NotFound"
+	errorCodeUnexpected       = "UK.OBIE.UnexpectedError"
+)
+
+const (
+	headerInteractionID = "x-fapi-interaction-id"
+	headerJWSSignature  = "x-jws-signature"
+	mimeJSONUTF8        = "application/json; charset=utf-8"
+)
+
+var errMalformedJWT = errors.New("malformed JWT")
+
+// exampleValues - values used for properties of the responses wherever the spec allows them
+var exampleValues = map[string]string{
+	"Currency":       "GBP",
+	"SchemeName":     "UK.OBIE.SortCodeAccountNumber",
+	"Identification": "11280001234567",
+}
+
+// errorResponse - OBErrorResponse1
+type errorResponse struct {
+	Code    string           `json:"Code"`
+	ID      string           `json:"Id"`
+	Message string           `json:"Message"`
+	Errors  []errorResponse1 `json:"Errors"`
+}
+
+// errorResponse1 - OBError1
+type errorResponse1 struct {
+	ErrorCode string `json:"ErrorCode"`
+	Message   string `json:"Message"`
+}
+
+func newErrorResponse(status int, errorCode, message string) errorResponse {
+	return errorResponse{
+		Code:    strconv.Itoa(status),
+		ID:      uuid.New().String(),
+		Message: http.StatusText(status),
+		Errors:  []errorResponse1{{ErrorCode: errorCode, Message: message}},
+	}
+}
+
+// resourceHandler - serves every operation of the APIs from their spec
+func (s *Server) resourceHandler(c echo.Context) error {
+	request := c.Request()
+	logger := s.logger.WithFields(logrus.Fields{"method": request.Method, "path": request.URL.Path})
+
+	interactionID := request.Header.Get(headerInteractionID)
+	if interactionID == "" {
+		interactionID = uuid.New().String()
+	}
+	c.Response().Header().Set(headerInteractionID, interactionID)
+
+	body, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, newErrorResponse(http.StatusBadRequest, errorCodeFieldInvalid, err.Error()))
+	}
+
+	api, route, pathParams, err := s.findRoute(request, body)
+	if err == routers.ErrMethodNotAllowed {
+		return c.JSON(http.StatusMethodNotAllowed, newErrorResponse(http.StatusMethodNotAllowed, errorCodeResourceNotFound, err.Error()))
+	}
+	if err != nil {
+		return c.JSON(http.StatusNotFound, newErrorResponse(http.StatusNotFound, errorCodeResourceNotFound, err.Error()))
+	}
+
+	faults := s.config.Faults.match(request.Method, api.basePath, route.Path)
+	c.Set(faultsContextKey, faults)
+	if _, ok := faults[FaultMissingInteractionID]; ok {
+		c.Response().Header().Del(headerInteractionID)
+	}
+	if rule, ok := faults[FaultStatusCode]; ok {
+		logger.WithField("fault", FaultStatusCode).Debug("injecting fault")
+		status := rule.statusCode()
+		return c.JSON(status, newErrorResponse(status, errorCodeUnexpected, "fault injected by mock ASPSP"))
+	}
+
+	token, ok := s.store.token(strings.TrimPrefix(request.Header.Get(echo.HeaderAuthorization), "Bearer "))
+	if !ok {
+		logger.Debug("missing or unknown access token")
+		c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+		return c.NoContent(http.StatusUnauthorized)
+	}
+	if requiresPSUConsent(route.Path) && token.consentID == "" {
+		logger.Debug("client credentials token used for a resource the PSU must consent to")
+		return c.NoContent(http.StatusForbidden)
+	}
+
+	if err := validateRequest(request, body, route, pathParams); err != nil {
+		logger.WithError(err).Debug("invalid request")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(http.StatusBadRequest, errorCodeFieldInvalid, err.Error()))
+	}
+
+	if request.Method == http.MethodDelete {
+		if !s.store.deleteResource(request.URL.Path) && isCreatedResource(api.doc, route.Path) {
+			return c.JSON(http.StatusBadRequest, newErrorResponse(http.StatusBadRequest, errorCodeResourceNotFound, "resource not found"))
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	if request.Method == http.MethodGet {
+		stored, ok, err := s.store.resource(request.URL.Path)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, newErrorResponse(http.StatusInternalServerError, errorCodeUnexpected, err.Error()))
+		}
+		if ok {
+			return s.signedJSON(c, http.StatusOK, stored)
+		}
+		if isCreatedResource(api.doc, route.Path) {
+			return c.JSON(http.StatusBadRequest, newErrorResponse(http.StatusBadRequest, errorCodeResourceNotFound, "resource not found"))
+		}
+	}
+
+	return s.generatedResponse(c, api, route, pathParams, body)
+}
+
+// generatedResponse answers with a response generated from the operation's success response schema,
+// resources created by POST requests echo the request and are kept so they can be read back
+func (s *Server) generatedResponse(c echo.Context, api api, route *routers.Route, pathParams map[string]string, requestBody []byte) error {
+	request := c.Request()
+	status := http.StatusOK
+	if request.Method == http.MethodPost && route.Operation.Responses.Get(http.StatusCreated) != nil {
+		status = http.StatusCreated
+	}
+	response := route.Operation.Responses.Get(status)
+	if response == nil || response.Value == nil {
+		return c.NoContent(status)
+	}
+
+	contentType, mediaType := responseMediaType(response.Value.Content)
+	if mediaType == nil || mediaType.Schema == nil {
+		return c.NoContent(status)
+	}
+	if !strings.Contains(contentType, "json") {
+		return c.Blob(status, contentType, []byte("mock ASPSP "+route.Path))
+	}
+
+	named := map[string]string{"Self": s.config.BaseURL + request.URL.Path}
+	for name, value := range exampleValues {
+		named[name] = value
+	}
+	for name, value := range pathParams {
+		named[name] = value
+	}
+
+	// the ID of a resource created by a POST is the parameter of its path, e.g. ConsentId of /domestic-payment-consents/{ConsentId}
+	var createdPath, idParam string
+	if request.Method == http.MethodPost {
+		if idParam = itemParam(api.doc, route.Path); idParam != "" {
+			id := uuid.New().String()
+			named[idParam] = id
+			createdPath = request.URL.Path + "/" + id
+		}
+	}
+
+	generated := newExampleGenerator(named, time.Now()).example(mediaType.Schema)
+	if len(requestBody) > 0 {
+		var requestJSON interface{}
+		if err := json.Unmarshal(requestBody, &requestJSON); err == nil {
+			generated = overlay(mediaType.Schema, generated, requestJSON)
+		}
+	}
+
+	body, err := json.Marshal(generated)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(http.StatusInternalServerError, errorCodeUnexpected, err.Error()))
+	}
+
+	if resource, ok := generated.(map[string]interface{}); ok && createdPath != "" {
+		if idParam == "ConsentId" {
+			s.store.saveConsent(named[idParam], createdPath, resource)
+		} else {
+			s.store.saveResource(createdPath, resource)
+		}
+	}
+	return s.signedJSON(c, status, body)
+}
+
+// signedJSON writes a JSON response signed in the `x-jws-signature` header, applying the faults of the request
+func (s *Server) signedJSON(c echo.Context, status int, body []byte) error {
+	faults, _ := c.Get(faultsContextKey).(map[string]FaultRule)
+	if _, ok := faults[FaultSchemaViolation]; ok {
+		body = violateSchema(body)
+	}
+
+	signed := body
+	if _, ok := faults[FaultBadSignature]; ok {
+		signed = append([]byte("tampered"), body...)
+	}
+	signature, err := s.signer.sign(signed)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(http.StatusInternalServerError, errorCodeUnexpected, err.Error()))
+	}
+	c.Response().Header().Set(headerJWSSignature, signature)
+
+	contentType := mimeJSONUTF8
+	if rule, ok := faults[FaultContentType]; ok {
+		contentType = rule.contentType()
+	}
+	return c.Blob(status, contentType, body)
+}
+
+// findRoute returns the API and operation of a request, the body is left readable
+func (s *Server) findRoute(request *http.Request, body []byte) (api, *routers.Route, map[string]string, error) {
+	err := routers.ErrPathNotFound
+	for _, api := range s.apis {
+		route, pathParams, errFind := api.router.FindRoute(routingRequest(request, body))
+		if errFind == nil {
+			return api, route, pathParams, nil
+		}
+		if errFind == routers.ErrMethodNotAllowed {
+			err = errFind
+		}
+	}
+	return api{}, nil, nil, err
+}
+
+// routingRequest - a copy of `request` without scheme and host as the servers of the specs are relative
+func routingRequest(request *http.Request, body []byte) *http.Request {
+	routing := request.Clone(context.Background())
+	routing.URL = &url.URL{Path: request.URL.Path, RawQuery: request.URL.RawQuery}
+	routing.Host = ""
+	routing.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return routing
+}
+
+// validateRequest validates a request against its operation, query parameters are left out as they are
+// optional filters an ASPSP ignores when it cannot parse them
+func validateRequest(request *http.Request, body []byte, route *routers.Route, pathParams map[string]string) error {
+	validated := routingRequest(request, body)
+	validated.URL.RawQuery = ""
+	input := &openapi3filter.RequestValidationInput{
+		Request:    validated,
+		PathParams: pathParams,
+		Route:      route,
+		Options: &openapi3filter.Options{
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		},
+	}
+	return openapi3filter.ValidateRequest(context.Background(), input)
+}
+
+// requiresPSUConsent - resources other than consents need an access token of a consent the PSU authorised
+func requiresPSUConsent(path string) bool {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	return !strings.HasSuffix(segments[0], "-consents") || segments[len(segments)-1] == "funds-confirmation"
+}
+
+// itemParam returns the path parameter naming the resources created by POST requests to `path`
+func itemParam(doc *openapi3.T, path string) string {
+	for itemPath, item := range doc.Paths {
+		if item.Get == nil || !strings.HasPrefix(itemPath, path+"/{") || strings.Count(itemPath, "/") != strings.Count(path, "/")+1 {
+			continue
+		}
+		return strings.TrimSuffix(strings.TrimPrefix(itemPath, path+"/{"), "}")
+	}
+	return ""
+}
+
+// isCreatedResource - the resource at `path` is created by a POST request, it cannot be read before it is created
+func isCreatedResource(doc *openapi3.T, path string) bool {
+	index := strings.LastIndex(path, "/")
+	if index <= 0 || !strings.HasPrefix(path[index+1:], "{") {
+		return false
+	}
+	collection := doc.Paths.Find(path[:index])
+	return collection != nil && collection.Post != nil
+}
+
+// responseMediaType prefers JSON content
+func responseMediaType(content openapi3.Content) (string, *openapi3.MediaType) {
+	for _, contentType := range []string{mimeJSONUTF8, echo.MIMEApplicationJSON} {
+		if mediaType, ok := content[contentType]; ok {
+			return contentType, mediaType
+		}
+	}
+	for contentType, mediaType := range content {
+		return contentType, mediaType
+	}
+	return "", nil
+}
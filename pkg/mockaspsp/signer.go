@@ -0,0 +1,113 @@
+package mockaspsp
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"net"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
+)
+
+const (
+	signatureTrustAnchor = "openbanking.org.uk"
+	keySize              = 2048
+)
+
+// signer signs responses with a key generated when the mock starts, its certificate is published in the JWKS
+type signer struct {
+	key         *rsa.PrivateKey
+	certificate *x509.Certificate
+	kid         string
+	orgID       string
+}
+
+func newSigner(orgID string) (signer, error) {
+	key, certificate, err := newSelfSignedCertificate(orgID, nil)
+	if err != nil {
+		return signer{}, errors.Wrap(err, "creating signing certificate")
+	}
+	kid, err := authentication.CalcKid(base64.RawURLEncoding.EncodeToString(key.N.Bytes()))
+	if err != nil {
+		return signer{}, errors.Wrap(err, "calculating signing key kid")
+	}
+	return signer{key: key, certificate: certificate, kid: kid, orgID: orgID}, nil
+}
+
+// sign returns the detached `x-jws-signature` of `body`
+func (s signer) sign(body []byte) (string, error) {
+	token := authentication.GetSignatureToken314Plus(s.kid, s.orgID, signatureTrustAnchor, jwt.SigningMethodPS256)
+	signed, err := authentication.CreateSignature(&token, s.key, string(body), true)
+	if err != nil {
+		return "", err
+	}
+	return authentication.SplitJWSWithBody(signed), nil
+}
+
+// jwks returns the key set holding the signing certificate
+func (s signer) jwks() authentication.JWKS {
+	return authentication.JWKS{Keys: []authentication.JWK{{
+		Alg: jwt.SigningMethodPS256.Alg(),
+		Kty: "RSA",
+		Use: "sig",
+		Kid: s.kid,
+		N:   base64.RawURLEncoding.EncodeToString(s.key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.key.E)).Bytes()),
+		X5c: []string{base64.StdEncoding.EncodeToString(s.certificate.Raw)},
+	}}}
+}
+
+// NewTLSCertificate returns a self-signed server certificate for `hosts`, names or IP addresses
+func NewTLSCertificate(hosts []string) (tls.Certificate, error) {
+	key, certificate, err := newSelfSignedCertificate("mock-aspsp", hosts)
+	if err != nil {
+		return tls.Certificate{}, errors.Wrap(err, "creating TLS certificate")
+	}
+	return tls.Certificate{Certificate: [][]byte{certificate.Raw}, PrivateKey: key, Leaf: certificate}, nil
+}
+
+func newSelfSignedCertificate(commonName string, hosts []string) (*rsa.PrivateKey, *x509.Certificate, error) {
+	key, err := rsa.GenerateKey(rand.Reader, keySize)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName, Organization: []string{"Mock ASPSP"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(1, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, certificate, nil
+}
@@ -0,0 +1,166 @@
+package mockaspsp
+
+import (
+	"encoding/json"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	statusAuthorised = "Authorised"
+)
+
+// accessToken - what an access token issued by the mock grants
+type accessToken struct {
+	clientID  string
+	consentID string // empty for client credentials tokens
+}
+
+// store keeps the resources, authorisation codes and tokens the mock issued
+type store struct {
+	lock      *sync.Mutex
+	resources map[string]map[string]interface{} // created resources by path
+	consents  map[string]string                 // path of each consent by consent ID
+	codes     map[string]string                 // consent ID of each authorisation code
+	tokens    map[string]accessToken
+	refreshes map[string]accessToken   // what the access tokens issued with each refresh token grant
+	pushed    map[string]pushedRequest // pushed authorization requests by request_uri
+}
+
+// pushedRequest - the parameters of an authorization request pushed by a client
+type pushedRequest struct {
+	clientID  string
+	params    url.Values
+	expiresAt time.Time
+}
+
+func newStore() *store {
+	return &store{
+		lock:      &sync.Mutex{},
+		resources: map[string]map[string]interface{}{},
+		consents:  map[string]string{},
+		codes:     map[string]string{},
+		tokens:    map[string]accessToken{},
+		refreshes: map[string]accessToken{},
+		pushed:    map[string]pushedRequest{},
+	}
+}
+
+// resource returns the JSON of a created resource, encoded with the lock held as consents change when authorised
+func (s *store) resource(path string) ([]byte, bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	resource, ok := s.resources[path]
+	if !ok {
+		return nil, false, nil
+	}
+	body, err := json.Marshal(resource)
+	return body, true, err
+}
+
+func (s *store) saveResource(path string, resource map[string]interface{}) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.resources[path] = resource
+}
+
+func (s *store) saveConsent(consentID, path string, resource map[string]interface{}) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.resources[path] = resource
+	s.consents[consentID] = path
+}
+
+func (s *store) deleteResource(path string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	_, ok := s.resources[path]
+	delete(s.resources, path)
+	return ok
+}
+
+// authorise marks a consent as authorised by the PSU and returns an authorisation code for it
+func (s *store) authorise(consentID string) (string, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	path, ok := s.consents[consentID]
+	if !ok {
+		return "", false
+	}
+	if consent, ok := s.resources[path]; ok {
+		if data, ok := consent["Data"].(map[string]interface{}); ok {
+			if _, ok := data["Status"]; ok {
+				data["Status"] = statusAuthorised
+			}
+		}
+	}
+	code := uuid.New().String()
+	s.codes[code] = consentID
+	return code, true
+}
+
+// redeemCode returns the consent ID of an authorisation code, codes can be redeemed once
+func (s *store) redeemCode(code string) (string, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	consentID, ok := s.codes[code]
+	delete(s.codes, code)
+	return consentID, ok
+}
+
+func (s *store) issueToken(token accessToken) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	value := uuid.New().String()
+	s.tokens[value] = token
+	return value
+}
+
+// issueRefreshToken returns a refresh token issuing access tokens that grant what `token` grants
+func (s *store) issueRefreshToken(token accessToken) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	value := uuid.New().String()
+	s.refreshes[value] = token
+	return value
+}
+
+// redeemRefreshToken returns what a refresh token grants, refresh tokens are rotated so each is redeemed once
+func (s *store) redeemRefreshToken(value string) (accessToken, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	token, ok := s.refreshes[value]
+	delete(s.refreshes, value)
+	return token, ok
+}
+
+func (s *store) token(value string) (accessToken, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	token, ok := s.tokens[value]
+	return token, ok
+}
+
+// pushRequest keeps the parameters of a pushed authorization request and returns the request_uri referencing them
+func (s *store) pushRequest(request pushedRequest) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	requestURI := requestURIPrefix + uuid.New().String()
+	s.pushed[requestURI] = request
+	return requestURI
+}
+
+// redeemPushedRequest returns the parameters of a pushed authorization request, a request_uri can be used once
+func (s *store) redeemPushedRequest(requestURI string, now time.Time) (pushedRequest, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	request, ok := s.pushed[requestURI]
+	delete(s.pushed, requestURI)
+	if !ok || now.After(request.expiresAt) {
+		return pushedRequest{}, false
+	}
+	return request, true
+}
@@ -62,7 +62,7 @@ func (i *Input) CreateRequest(tc *TestCase, ctx *Context) (*resty.Request, error
 		return nil, i.AppErr(fmt.Sprintf("error empty Endpoint(%s) or Method(%s)", i.Endpoint, i.Method))
 	}
 
-	req := resty.R() // create basic request that will be sent to endpoint
+	req := HTTPClient(ctx).R() // create basic request that will be sent to endpoint
 
 	tc.Input.Endpoint, err = replaceContextField(tc.Input.Endpoint, ctx)
 	if err != nil {
@@ -150,7 +150,10 @@ func (i *Input) setClaims(tc *TestCase, ctx *Context) error {
 			i.AppMsg(fmt.Sprintf("jwt consent Token: %s", token))
 
 			authEndpoint, _ := ctx.Get("authorisation_endpoint")
-			consent := consentURL(authEndpoint.(string), i.Claims, token)
+			consent, err := authorizationURL(authEndpoint.(string), i.Claims, token, ctx)
+			if err != nil {
+				return i.AppErr(fmt.Sprintf("error creating consent url %s", err.Error()))
+			}
 
 			tc.Input.Endpoint = consent           // Result - set jwt token in endpoint url
 			ctx.PutString("consent_url", consent) // make consent available in context
@@ -180,7 +183,36 @@ func (i *Input) GenerateRequestToken(ctx *Context) (string, error) {
 	return i.generateRequestJWT(ctx, signingMethod)
 }
 
-func consentURL(authEndpoint string, claims map[string]string, token string) string {
+// authorizationURL returns the consent URL of a request object, the request is pushed first to the pushed authorization
+// request endpoint of the specification type being consented, `consent_spec_type`, when the context has one
+func authorizationURL(authEndpoint string, claims map[string]string, token string, ctx *Context) (string, error) {
+	specType, err := ctx.GetString("consent_spec_type")
+	if err != nil {
+		return consentURL(authEndpoint, claims, token), nil
+	}
+	parEndpoint, err := ctx.GetString("pushed_authorization_request_endpoint_" + specType)
+	if err != nil || parEndpoint == "" {
+		return consentURL(authEndpoint, claims, token), nil
+	}
+
+	clientAuth, err := authentication.NewClientAuthentication(ctx)
+	if err != nil {
+		return "", err
+	}
+	pushed, err := authentication.PushAuthorizationRequest(HTTPClient(ctx), parEndpoint, clientAuth, consentQuery(claims, token))
+	if err != nil {
+		return "", err
+	}
+	logrus.WithFields(logrus.Fields{
+		"specType":    specType,
+		"parEndpoint": parEndpoint,
+		"requestURI":  pushed.RequestURI,
+		"expiresIn":   pushed.ExpiresIn,
+	}).Trace("Pushed authorization request")
+	return authentication.PushedAuthorizationURL(authEndpoint, claims["iss"], pushed.RequestURI)
+}
+
+func consentQuery(claims map[string]string, token string) url.Values {
 	queryString := url.Values{}
 	queryString.Set("client_id", claims["iss"])
 	queryString.Set("response_type", claims["responseType"])
@@ -188,6 +220,11 @@ func consentURL(authEndpoint string, claims map[string]string, token string) str
 	queryString.Set("request", token)
 	queryString.Set("state", claims["state"])
 	queryString.Set("redirect_uri", claims["redirect_url"])
+	return queryString
+}
+
+func consentURL(authEndpoint string, claims map[string]string, token string) string {
+	queryString := consentQuery(claims, token)
 
 	consentURL := fmt.Sprintf("%s?%s", authEndpoint, queryString.Encode())
 
@@ -8,6 +8,8 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"strings"
@@ -235,6 +237,60 @@ func TestInputClaimsWithContextReplacementParameters(t *testing.T) {
 
 }
 
+func TestInputClaimsPushedAuthorizationRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, ok := r.BasicAuth(); !ok || r.FormValue("request") == "" || r.FormValue("client_id") != "8672384e-9a33-439f-8924-67bb14340d71" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"request_uri":"urn:ietf:params:oauth:request_uri:001","expires_in":90}`))
+	}))
+	defer server.Close()
+
+	i := Input{Endpoint: "/accounts", Method: "POST",
+		Generation: map[string]string{
+			"strategy": "consenturl",
+		},
+		Claims: map[string]string{
+			"iss":          "8672384e-9a33-439f-8924-67bb14340d71",
+			"scope":        "openid accounts",
+			"redirect_url": "https://test.example.co.uk/redir",
+			"responseType": "code",
+		}}
+	ctx := &Context{
+		"authorisation_endpoint":                         "https://example.com/authorisation",
+		"consent_spec_type":                              "accounts",
+		"pushed_authorization_request_endpoint_accounts": server.URL,
+		"pushed_authorization_request_endpoint_payments": "",
+		"token_endpoint_auth_method":                     "client_secret_basic",
+		"client_id":                                      "8672384e-9a33-439f-8924-67bb14340d71",
+		"client_secret":                                  "client-secret",
+		"token_endpoint":                                 "https://example.com/token",
+	}
+	tc := TestCase{Input: i}
+	_, err := tc.Prepare(ctx)
+	require.NoError(t, err)
+
+	consentURL, err := ctx.GetString("consent_url")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/authorisation?client_id=8672384e-9a33-439f-8924-67bb14340d71&request_uri=urn%3Aietf%3Aparams%3Aoauth%3Arequest_uri%3A001", consentURL)
+
+	tc.Input.Claims["iss"] = "unknown"
+	_, err = tc.Prepare(ctx)
+	assert.EqualError(t, err, "createRequest: error creating consent url pushed authorization request: bad status code 400 from \""+server.URL+"\": ")
+
+	tc.Input.Claims["iss"] = "8672384e-9a33-439f-8924-67bb14340d71"
+	ctx.PutString("consent_spec_type", "payments")
+	_, err = tc.Prepare(ctx)
+	require.NoError(t, err)
+
+	consentURL, err = ctx.GetString("consent_url")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(consentURL, "https://example.com/authorisation?client_id=8672384e-9a33-439f-8924-67bb14340d71&redirect_uri="))
+	assert.Contains(t, consentURL, "&request=")
+}
+
 func TestInputClaimsConsentId(t *testing.T) {
 	ctx := Context{"consent_id": "aac-fee2b8eb-ce1b-48f1-af7f-dc8f576d53dc", "xchange_code": "10e9d80b-10d4-4abd-9fe0-15789cc512b5", "baseurl": "https://modelobankauth2018.o3bank.co.uk:4101", "access_token": "18d5a754-0b76-4a8f-9c68-dc5caaf812e2", "authorisation_endpoint": "https://example.com/authorisation"}
 	i := Input{Endpoint: "/accounts", Method: "POST",
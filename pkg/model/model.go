@@ -80,6 +80,9 @@ type TestCase struct {
 	Validator         schema.Validator `json:"-"` // Swagger schema validator
 	ValidateSignature bool             `json:"validateSignature,omitempty"`
 	StatusCode        string           `json:"statusCode,omitempty"`
+	DependsOn         []string         `json:"dependsOn,omitempty"` // IDs of test cases whose context kept on success this test case uses
+
+	SignatureVerification *authentication.SignatureVerification `json:"-"` // x-jws-signature of the response, when validated
 }
 
 // MakeTestCase builds an empty testcase
@@ -153,52 +156,80 @@ func (t *TestCase) Validate(resp *resty.Response, ctx *Context) (bool, []error)
 	// Apply Signature Validator
 	if t.ValidateSignature && !disableJws {
 		xJwsSignature := resp.Header().Get("x-jws-signature")
-		logrus.Warn("Validating Signature: " + xJwsSignature)
-		logrus.Warn("body: ", t.Body)
-		valid, err := validateSignature(xJwsSignature, t.Body, ctx)
+		logrus.Debug("Validating Signature: " + xJwsSignature)
+		logrus.Trace("body: ", t.Body)
+		verification, err := validateSignature(xJwsSignature, t.Body, ctx)
+		t.SignatureVerification = verification
 		if err != nil {
 			return false, []error{t.AppErr("Signature validation failed: " + err.Error())}
 		}
-		if !valid {
-			errs = append(errs, errors.New("Invalid x-jws-signature found - unable to validate"))
-		} else {
-			logrus.Infoln("x-jws-signature validation succeded")
-		}
+		logrus.Infoln("x-jws-signature validation succeded")
 	}
 
 	// Gather fields within json response - for reporting
-	collector := schemaprops.GetPropertyCollector()
+	collector := PropertyCollector(ctx)
 	collector.CollectProperties(t.Input.Method, t.Input.Endpoint, t.Body, resp.StatusCode())
 
 	return pass, errs
 }
 
-func validateSignature(signature, body string, ctx *Context) (bool, error) {
-	var pass bool
-	if signature != "" {
-		jwksURI, err := ctx.GetString("jwks_uri")
-		if err != nil {
-			return false, errors.New("ValidateSignature - JWKS_URI not present ")
-		}
+// CtxPropertyCollector is the context key of the schemaprops.PropertyCollector the fields of responses are
+// collected in, CtxJWKSCache of the authentication.JWKSCache response signatures are verified with and CtxHTTPClient
+// of the resty.Client requests are sent with. A journey puts its own in its context so concurrent journeys do not
+// share them, the process-wide ones are used when not set.
+const (
+	CtxPropertyCollector = "property_collector"
+	CtxJWKSCache         = "jwks_cache"
+	CtxHTTPClient        = "http_client"
+)
 
-		b64encoding, err := authentication.GetB64Encoding(ctx)
-		if err != nil {
-			return false, errors.New("ValidationSignature cannot get B64Encoding: " + err.Error())
+// HTTPClient returns the client requests are sent with in `ctx`, resty's default client when not set
+func HTTPClient(ctx *Context) *resty.Client {
+	if ctx != nil {
+		if client, ok := ctx.Get(CtxHTTPClient); ok {
+			return client.(*resty.Client)
 		}
+	}
+	return resty.DefaultClient
+}
 
-		pass, err = authentication.ValidateSignature(signature, body, jwksURI, b64encoding)
-		if err != nil {
-			return false, errors.New("Invalid x-jws-signature found - unable to validate: " + err.Error())
-		}
-		if !pass {
-			return false, errors.New("Invalid x-jws-signature - fails validation")
+// PropertyCollector returns the collector of response fields in `ctx`, the process-wide collector when not set
+func PropertyCollector(ctx *Context) schemaprops.PropertyCollector {
+	if ctx != nil {
+		if collector, ok := ctx.Get(CtxPropertyCollector); ok {
+			return collector.(schemaprops.PropertyCollector)
 		}
-		logrus.Infoln("x-jws-signature validation succeded")
+	}
+	return schemaprops.GetPropertyCollector()
+}
+
+// validateSignature verifies the x-jws-signature of a response body, the verification is returned when the
+// signature could be checked, valid or not
+func validateSignature(signature, body string, ctx *Context) (*authentication.SignatureVerification, error) {
+	if signature == "" {
+		return nil, errors.New("x-jws-signature header not found for Validation")
+	}
+	jwksURI, err := ctx.GetString("jwks_uri")
+	if err != nil {
+		return nil, errors.New("ValidateSignature - JWKS_URI not present ")
+	}
+
+	b64encoding, err := authentication.GetB64Encoding(ctx)
+	if err != nil {
+		return nil, errors.New("ValidationSignature cannot get B64Encoding: " + err.Error())
+	}
+
+	var verification authentication.SignatureVerification
+	if cache, ok := ctx.Get(CtxJWKSCache); ok {
+		verification = cache.(*authentication.JWKSCache).VerifySignature(signature, body, jwksURI, b64encoding)
 	} else {
-		return false, errors.New("x-jws-signature header not found for Validation")
+		verification = authentication.VerifySignature(signature, body, jwksURI, b64encoding)
+	}
+	if err := verification.Err(); err != nil {
+		return &verification, errors.New("Invalid x-jws-signature found - unable to validate: " + err.Error())
 	}
 	logrus.Tracef("Signature validation succeeded")
-	return pass, nil
+	return &verification, nil
 }
 
 func logSchemaValidationOffWarning(testCase *TestCase) {
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/schema"
@@ -226,3 +228,25 @@ func TestGetReplacementField(t *testing.T) {
 	}
 
 }
+
+func TestValidateRecordsSignatureVerification(t *testing.T) {
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"keys":[]}`)
+	}))
+	defer jwks.Close()
+	ctx := Context{"jwks_uri": jwks.URL, "apiversions": []interface{}{"payments_v3.1.6"}}
+	tc := TestCase{ValidateSignature: true, Expect: Expect{StatusCode: 200}}
+	resp := test.CreateHTTPResponse(200, "OK", OzoneResponseBody, "x-jws-signature", OzoneTestSignature)
+
+	pass, errs := tc.Validate(resp, &ctx)
+
+	assert.False(t, pass)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "Signature validation failed: Invalid x-jws-signature found - unable to validate")
+	require.NotNil(t, tc.SignatureVerification)
+	assert.False(t, tc.SignatureVerification.Valid)
+	assert.Equal(t, "DKePOLAOiXLwYhMfLS8aS6YU-d0", tc.SignatureVerification.Kid)
+	last := tc.SignatureVerification.Checks[len(tc.SignatureVerification.Checks)-1]
+	assert.Equal(t, "certificate", last.Name)
+	assert.False(t, last.Pass)
+}
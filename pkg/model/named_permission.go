@@ -10,6 +10,8 @@ type NamedPermission struct {
 	Name       string                    `json:"name"`
 	CodeSet    permissions.CodeSetResult `json:"codeSet"`
 	ConsentURL string                    `json:"consentUrl"`
+	// Diagnostic - why the consent is needed, set when the consents are grouped by the heuristic or exact resolver
+	Diagnostic *permissions.ConsentDiagnostic `json:"diagnostic,omitempty"`
 }
 
 // NamedPermissions - permission structure
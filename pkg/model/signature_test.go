@@ -51,7 +51,7 @@ func TestSimpleb64falseSignature(t *testing.T) {
 	ctx.PutString("tpp_signature_issuer", "x/x")
 	ctx.PutString("tpp_signature_tan", "openbanking.org.uk")
 	cert, _ := authentication.SigningCertFromContext(ctx)
-	pubKey := cert.PublicKey()
+	pubKey := cert.PublicKey().(*rsa.PublicKey)
 	_ = pubKey
 	i := Input{JwsSig: true, Method: "POST", Endpoint: "https://google.com", RequestBody: "$domestic_payment_template"}
 	tc := TestCase{Input: i}
@@ -71,7 +71,7 @@ func TestSimpleb64trueSignature(t *testing.T) {
 	ctx.PutString("tpp_signature_issuer", "x/x")
 	ctx.PutString("tpp_signature_tan", "openbanking.org.uk")
 	cert, _ := authentication.SigningCertFromContext(ctx)
-	pubKey := cert.PublicKey()
+	pubKey := cert.PublicKey().(*rsa.PublicKey)
 	_ = pubKey
 	i := Input{JwsSig: true, Method: "POST", Endpoint: "https://google.com", RequestBody: "$domestic_payment_template"}
 	tc := TestCase{Input: i}
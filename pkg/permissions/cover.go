@@ -0,0 +1,289 @@
+package permissions
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ResolverMode selects how test permission groups are combined into consents
+type ResolverMode string
+
+const (
+	// ResolverModeGreedy adds each group to the first compatible consent, see Resolver
+	ResolverModeGreedy ResolverMode = "greedy"
+	// ResolverModeHeuristic colours the most constrained group first, see HeuristicResolver
+	ResolverModeHeuristic ResolverMode = "heuristic"
+	// ResolverModeExact searches for the fewest consents, see ExactResolver
+	ResolverModeExact ResolverMode = "exact"
+)
+
+// ResolverModes - the supported resolver modes
+var ResolverModes = []ResolverMode{ResolverModeGreedy, ResolverModeHeuristic, ResolverModeExact}
+
+// exactSearchLimit - the search steps after which ExactResolver settles for the fewest consents found so far
+const exactSearchLimit = 20000
+
+// ParseResolverMode - the resolver mode named `mode`, one of greedy, heuristic or exact
+func ParseResolverMode(mode string) (ResolverMode, error) {
+	for _, resolverMode := range ResolverModes {
+		if string(resolverMode) == strings.ToLower(mode) {
+			return resolverMode, nil
+		}
+	}
+	return "", fmt.Errorf("unknown consent resolver %q, expected one of greedy, heuristic or exact", mode)
+}
+
+// NewResolver - the resolver of `mode`, the greedy Resolver for an unknown mode
+func NewResolver(mode ResolverMode) func(groups []Group) CodeSetResultSet {
+	switch mode {
+	case ResolverModeHeuristic:
+		return HeuristicResolver
+	case ResolverModeExact:
+		return ExactResolver
+	default:
+		return Resolver
+	}
+}
+
+// HeuristicResolver finds few consents satisfying a set Group of permissions (endpoints).
+//
+// Choosing the fewest consents that cover every test is a set cover. As two tests can share a consent exactly when
+// neither excludes a code the other includes, it is solved as a colouring of the graph of conflicting tests, each
+// colour a consent. The heuristic colours the test conflicting with the most colours first (DSATUR).
+func HeuristicResolver(groups []Group) CodeSetResultSet {
+	if len(groups) == 0 {
+		return nil
+	}
+	graph := newConflictGraph(groups)
+	return mapToCodeSets(groups, graph.consents(graph.heuristicColours()))
+}
+
+// ExactResolver finds the fewest consents satisfying a set Group of permissions (endpoints), see HeuristicResolver.
+// Its branch and bound search starts from the heuristic colouring and stops at a clique of conflicting tests, which
+// needs as many consents, or after `exactSearchLimit` steps with the fewest consents found.
+func ExactResolver(groups []Group) CodeSetResultSet {
+	if len(groups) == 0 {
+		return nil
+	}
+	graph := newConflictGraph(groups)
+	return mapToCodeSets(groups, graph.consents(graph.exactColours(exactSearchLimit)))
+}
+
+// conflictGraph - the distinct groups to cover and which pairs of them cannot share a consent
+type conflictGraph struct {
+	groups    []Group
+	conflicts [][]bool
+}
+
+// newConflictGraph merges groups with the same included and excluded codes, and leaves out the groups excluding
+// a code they include as no consent satisfies them
+func newConflictGraph(groups []Group) conflictGraph {
+	graph := conflictGraph{}
+	for _, group := range groups {
+		if group.Included.HasAny(group.Excluded) || graph.has(group) {
+			continue
+		}
+		graph.groups = append(graph.groups, group)
+	}
+
+	graph.conflicts = make([][]bool, len(graph.groups))
+	for i := range graph.groups {
+		graph.conflicts[i] = make([]bool, len(graph.groups))
+		for j := range graph.groups {
+			graph.conflicts[i][j] = i != j && !(graph.groups[i].isCompatible(&graph.groups[j]) && graph.groups[j].isCompatible(&graph.groups[i]))
+		}
+	}
+	return graph
+}
+
+func (g conflictGraph) has(group Group) bool {
+	for _, found := range g.groups {
+		if found.Included.Equals(group.Included) && found.Excluded.Equals(group.Excluded) {
+			return true
+		}
+	}
+	return false
+}
+
+// consents returns a consent for each colour, the union of the codes of its groups, ordered by their first group
+func (g conflictGraph) consents(colours []int) []*Group {
+	var consents []*Group
+	byColour := map[int]*Group{}
+	for i, colour := range colours {
+		consent, ok := byColour[colour]
+		if !ok {
+			consent = &Group{Included: CodeSet{}, Excluded: CodeSet{}}
+			byColour[colour] = consent
+			consents = append(consents, consent)
+		}
+		consent.add(&g.groups[i])
+	}
+	return consents
+}
+
+// saturation returns the number of colours of the groups conflicting with group `i`
+func (g conflictGraph) saturation(colours []int, i int) int {
+	seen := map[int]bool{}
+	for j, conflict := range g.conflicts[i] {
+		if conflict && colours[j] >= 0 {
+			seen[colours[j]] = true
+		}
+	}
+	return len(seen)
+}
+
+func (g conflictGraph) degree(i int) int {
+	degree := 0
+	for _, conflict := range g.conflicts[i] {
+		if conflict {
+			degree++
+		}
+	}
+	return degree
+}
+
+// mostSaturated returns the uncoloured group with the highest saturation, ties broken by degree then order
+func (g conflictGraph) mostSaturated(colours []int) int {
+	best, bestSaturation, bestDegree := -1, -1, -1
+	for i, colour := range colours {
+		if colour >= 0 {
+			continue
+		}
+		saturation, degree := g.saturation(colours, i), g.degree(i)
+		if saturation > bestSaturation || (saturation == bestSaturation && degree > bestDegree) {
+			best, bestSaturation, bestDegree = i, saturation, degree
+		}
+	}
+	return best
+}
+
+func (g conflictGraph) canColour(colours []int, i, colour int) bool {
+	for j, conflict := range g.conflicts[i] {
+		if conflict && colours[j] == colour {
+			return false
+		}
+	}
+	return true
+}
+
+func (g conflictGraph) uncoloured() []int {
+	colours := make([]int, len(g.groups))
+	for i := range colours {
+		colours[i] = -1
+	}
+	return colours
+}
+
+// heuristicColours gives each group in DSATUR order the lowest colour none of its conflicting groups has
+func (g conflictGraph) heuristicColours() []int {
+	colours := g.uncoloured()
+	for range g.groups {
+		i := g.mostSaturated(colours)
+		colour := 0
+		for !g.canColour(colours, i, colour) {
+			colour++
+		}
+		colours[i] = colour
+	}
+	return colours
+}
+
+// cliqueSize returns the size of a clique of conflicting groups found greedily, a lower bound of the colours needed
+func (g conflictGraph) cliqueSize() int {
+	order := make([]int, len(g.groups))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return g.degree(order[a]) > g.degree(order[b])
+	})
+
+	largest := 0
+	for _, start := range order {
+		clique := []int{start}
+		for _, candidate := range order {
+			inClique := true
+			for _, member := range clique {
+				if !g.conflicts[candidate][member] {
+					inClique = false
+					break
+				}
+			}
+			if inClique {
+				clique = append(clique, candidate)
+			}
+		}
+		if len(clique) > largest {
+			largest = len(clique)
+		}
+	}
+	return largest
+}
+
+// colourSearch - the state of the branch and bound search for the fewest colours
+type colourSearch struct {
+	graph      conflictGraph
+	colours    []int
+	best       []int
+	bestCount  int
+	lowerBound int
+	steps      int
+	limit      int
+}
+
+// exactColours returns a colouring with the fewest colours, or the fewest found in `limit` search steps
+func (g conflictGraph) exactColours(limit int) []int {
+	best := g.heuristicColours()
+	search := colourSearch{
+		graph:      g,
+		colours:    g.uncoloured(),
+		best:       best,
+		bestCount:  colourCount(best),
+		lowerBound: g.cliqueSize(),
+		limit:      limit,
+	}
+	if search.bestCount > search.lowerBound {
+		search.search(0, 0)
+	}
+	return search.best
+}
+
+func (s *colourSearch) search(coloured, used int) {
+	if s.steps >= s.limit || s.bestCount <= s.lowerBound {
+		return
+	}
+	s.steps++
+	if coloured == len(s.colours) {
+		s.best = append([]int{}, s.colours...)
+		s.bestCount = used
+		return
+	}
+
+	i := s.graph.mostSaturated(s.colours)
+	for colour := 0; colour <= used; colour++ {
+		if colour == used && used+1 >= s.bestCount {
+			break
+		}
+		if !s.graph.canColour(s.colours, i, colour) {
+			continue
+		}
+		s.colours[i] = colour
+		next := used
+		if colour == used {
+			next++
+		}
+		s.search(coloured+1, next)
+		s.colours[i] = -1
+	}
+}
+
+func colourCount(colours []int) int {
+	count := 0
+	for _, colour := range colours {
+		if colour+1 > count {
+			count = colour + 1
+		}
+	}
+	return count
+}
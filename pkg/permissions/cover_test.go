@@ -0,0 +1,110 @@
+package permissions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// firstFitGroups - four tests of which the greedy Resolver needs three consents when two are enough:
+// {t1, t3} and {t2, t4}
+func firstFitGroups() []Group {
+	return []Group{
+		NewGroup("t1", CodeSet{"c12"}, CodeSet{}),
+		NewGroup("t4", CodeSet{"d"}, CodeSet{"c34"}),
+		NewGroup("t2", CodeSet{"c23"}, CodeSet{"c12"}),
+		NewGroup("t3", CodeSet{"c34"}, CodeSet{"c23"}),
+	}
+}
+
+func assertAllSatisfied(t *testing.T, groups []Group, result CodeSetResultSet) {
+	t.Helper()
+	for _, group := range groups {
+		satisfied := false
+		for _, codeSet := range result {
+			consent := Group{Included: codeSet.CodeSet}
+			if group.isSatisfiedBy(&consent) {
+				satisfied = true
+			}
+		}
+		assert.True(t, satisfied, "no consent satisfies %s", group.TestId)
+	}
+}
+
+func TestParseResolverMode(t *testing.T) {
+	mode, err := ParseResolverMode("Exact")
+	assert.NoError(t, err)
+	assert.Equal(t, ResolverModeExact, mode)
+
+	_, err = ParseResolverMode("fastest")
+	assert.EqualError(t, err, `unknown consent resolver "fastest", expected one of greedy, heuristic or exact`)
+}
+
+func TestResolversMinimiseConsents(t *testing.T) {
+	groups := firstFitGroups()
+
+	assert.Len(t, Resolver(groups), 3)
+
+	for _, mode := range []ResolverMode{ResolverModeHeuristic, ResolverModeExact} {
+		result := NewResolver(mode)(groups)
+
+		expected := CodeSetResultSet{
+			{CodeSet: CodeSet{"c12", "c34"}, TestIds: []TestId{"t1", "t3"}},
+			{CodeSet: CodeSet{"d", "c23"}, TestIds: []TestId{"t4", "t2"}},
+		}
+		assert.Equal(t, expected, result, string(mode))
+		assertAllSatisfied(t, groups, result)
+	}
+}
+
+func TestExactResolver(t *testing.T) {
+	t.Run("given empty Group expect empty slice result", func(t *testing.T) {
+		var expected CodeSetResultSet
+		assert.Equal(t, expected, ExactResolver(nil))
+	})
+
+	t.Run("with duplicated Group expect single permission set result", func(t *testing.T) {
+		groups := []Group{
+			NewGroup("1", CodeSet{"ReadAccountsBasic"}, CodeSet{"ReadAccountsDetail"}),
+			NewGroup("2", CodeSet{"ReadAccountsBasic"}, CodeSet{"ReadAccountsDetail"}),
+		}
+
+		result := ExactResolver(groups)
+
+		assert.Equal(t, CodeSetResultSet{{CodeSet: CodeSet{"ReadAccountsBasic"}, TestIds: []TestId{"1", "2"}}}, result)
+	})
+
+	t.Run("with four mutually exclusive configs expect four permission set result", func(t *testing.T) {
+		groups := []Group{
+			NewGroup("1", CodeSet{"ReadTransactionsBasic", "ReadTransactionsCredits"}, CodeSet{"ReadTransactionsDebits"}),
+			NewGroup("2", CodeSet{"ReadTransactionsBasic", "ReadTransactionsDebits"}, CodeSet{"ReadTransactionsCredits"}),
+			NewGroup("3", CodeSet{"ReadTransactionsBasic"}, CodeSet{"ReadTransactionsDebits", "ReadTransactionsCredits"}),
+			NewGroup("4", CodeSet{"ReadTransactionsBasic", "ReadTransactionsCredits", "ReadTransactionsDebits"}, CodeSet{}),
+			NewGroup("5", CodeSet{"ReadAccountsBasic"}, CodeSet{"ReadAccountsDetail"}),
+		}
+
+		result := ExactResolver(groups)
+
+		assert.Len(t, result, 4)
+		assertAllSatisfied(t, groups, result)
+	})
+
+	t.Run("with a Group excluding a code it includes expect it unsatisfied", func(t *testing.T) {
+		groups := []Group{
+			NewGroup("1", CodeSet{"a"}, CodeSet{"a"}),
+			NewGroup("2", CodeSet{"b"}, CodeSet{}),
+		}
+
+		result := ExactResolver(groups)
+
+		assert.Equal(t, CodeSetResultSet{{CodeSet: CodeSet{"b"}, TestIds: []TestId{"2"}}}, result)
+	})
+}
+
+func TestExactColoursStopsAtSearchLimit(t *testing.T) {
+	graph := newConflictGraph(firstFitGroups())
+
+	assert.Equal(t, 2, graph.cliqueSize())
+	assert.Equal(t, 2, colourCount(graph.exactColours(0)))
+	assert.Equal(t, 2, colourCount(graph.exactColours(exactSearchLimit)))
+}
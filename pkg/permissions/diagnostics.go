@@ -0,0 +1,108 @@
+package permissions
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Diagnostics explains why each consent resolved for a set Group of permissions is needed
+type Diagnostics struct {
+	Consents    []ConsentDiagnostic `json:"consents"`
+	Unsatisfied []TestId            `json:"unsatisfied,omitempty"` // tests no consent satisfies
+}
+
+// ConsentDiagnostic - the tests needing each code of a consent, the tests only it satisfies, and why it is not
+// merged with each other consent
+type ConsentDiagnostic struct {
+	CodeSet   CodeSet           `json:"codes"`
+	Required  []CodeRequirement `json:"required"`
+	OnlyFor   []TestId          `json:"onlyFor"`
+	Conflicts []string          `json:"conflicts"`
+}
+
+// CodeRequirement - a code of a consent and the tests it satisfies that include the code
+type CodeRequirement struct {
+	Code    Code     `json:"code"`
+	TestIds []TestId `json:"testIds"`
+}
+
+// Explain returns the Diagnostics of the consents `resultSet` a resolver found for `groups`.
+// A consent that could be merged with another without leaving a test unsatisfied says so in its conflicts,
+// which shows where a greedy resolution needs more consents than necessary.
+func Explain(groups []Group, resultSet CodeSetResultSet) Diagnostics {
+	consents := make([]*Group, len(resultSet))
+	for i, result := range resultSet {
+		consents[i] = &Group{Included: result.CodeSet}
+	}
+
+	diagnostics := Diagnostics{Consents: []ConsentDiagnostic{}}
+	for _, group := range groups {
+		if !group.isSatisfiedByAnyOf(consents) {
+			diagnostics.Unsatisfied = append(diagnostics.Unsatisfied, group.TestId)
+		}
+	}
+
+	for i, consent := range consents {
+		diagnostic := ConsentDiagnostic{CodeSet: consent.Included, Required: []CodeRequirement{}, OnlyFor: []TestId{}, Conflicts: []string{}}
+		for _, code := range consent.Included {
+			requirement := CodeRequirement{Code: code, TestIds: []TestId{}}
+			for _, group := range groups {
+				if group.Included.Has(code) && group.isSatisfiedBy(consent) {
+					requirement.TestIds = append(requirement.TestIds, group.TestId)
+				}
+			}
+			diagnostic.Required = append(diagnostic.Required, requirement)
+		}
+		for _, group := range groups {
+			if group.isSatisfiedBy(consent) && !group.isSatisfiedByAnyOf(otherConsents(consents, i)) {
+				diagnostic.OnlyFor = append(diagnostic.OnlyFor, group.TestId)
+			}
+		}
+		for j := range consents {
+			if j != i {
+				diagnostic.Conflicts = append(diagnostic.Conflicts, mergeConflict(groups, consents, i, j))
+			}
+		}
+		diagnostics.Consents = append(diagnostics.Consents, diagnostic)
+	}
+	return diagnostics
+}
+
+// mergeConflict explains why consents `i` and `j` are not one consent: a test only they satisfy excludes a code
+// of the other
+func mergeConflict(groups []Group, consents []*Group, i, j int) string {
+	merged := &Group{Included: consents[i].Included.Union(consents[j].Included)}
+	var remaining []*Group
+	for k, consent := range consents {
+		if k != i && k != j {
+			remaining = append(remaining, consent)
+		}
+	}
+
+	for _, group := range groups {
+		if !group.isSatisfiedBy(consents[i]) && !group.isSatisfiedBy(consents[j]) {
+			continue
+		}
+		if group.isSatisfiedBy(merged) || group.isSatisfiedByAnyOf(remaining) {
+			continue
+		}
+		var granted []string
+		for _, code := range group.Excluded {
+			if merged.Included.Has(code) {
+				granted = append(granted, string(code))
+			}
+		}
+		return fmt.Sprintf("merged with consent %d it would grant %s, which test %q excludes", j+1, strings.Join(granted, ", "), group.TestId)
+	}
+	return fmt.Sprintf("could be merged with consent %d", j+1)
+}
+
+func otherConsents(consents []*Group, i int) []*Group {
+	var others []*Group
+	for k, consent := range consents {
+		if k != i {
+			others = append(others, consent)
+		}
+	}
+	return others
+}
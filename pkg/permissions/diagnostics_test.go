@@ -0,0 +1,45 @@
+package permissions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain(t *testing.T) {
+	groups := firstFitGroups()
+
+	diagnostics := Explain(groups, Resolver(groups))
+
+	assert.Empty(t, diagnostics.Unsatisfied)
+	assert.Len(t, diagnostics.Consents, 3)
+	first := diagnostics.Consents[0]
+	assert.Equal(t, CodeSet{"c12", "d"}, first.CodeSet)
+	assert.Equal(t, []CodeRequirement{
+		{Code: "c12", TestIds: []TestId{"t1"}},
+		{Code: "d", TestIds: []TestId{"t4"}},
+	}, first.Required)
+	assert.Equal(t, []TestId{"t1", "t4"}, first.OnlyFor)
+	assert.Equal(t, []string{
+		`merged with consent 2 it would grant c12, which test "t2" excludes`,
+		`merged with consent 3 it would grant c34, which test "t4" excludes`,
+	}, first.Conflicts)
+}
+
+func TestExplainMergeableConsents(t *testing.T) {
+	groups := []Group{
+		NewGroup("1", CodeSet{"a"}, CodeSet{}),
+		NewGroup("2", CodeSet{"b"}, CodeSet{}),
+		NewGroup("3", CodeSet{"c"}, CodeSet{"c"}),
+	}
+	resultSet := CodeSetResultSet{
+		{CodeSet: CodeSet{"a"}, TestIds: []TestId{"1"}},
+		{CodeSet: CodeSet{"b"}, TestIds: []TestId{"2"}},
+	}
+
+	diagnostics := Explain(groups, resultSet)
+
+	assert.Equal(t, []TestId{"3"}, diagnostics.Unsatisfied)
+	assert.Equal(t, []string{"could be merged with consent 2"}, diagnostics.Consents[0].Conflicts)
+	assert.Equal(t, []string{"could be merged with consent 1"}, diagnostics.Consents[1].Conflicts)
+}
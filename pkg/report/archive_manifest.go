@@ -0,0 +1,92 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// archiveManifestFilename - the manifest of a report ZIP archive, not to be confused with the test manifests it holds
+const archiveManifestFilename = "manifest.json"
+
+// ArchiveManifest - the SHA-256 of every file of a report ZIP archive but the manifest itself and `signature.json`,
+// so an import can tell a missing, extra or modified file. `signature.json` signs the manifest with the other files.
+type ArchiveManifest struct {
+	Files []ArchiveManifestFile `json:"files"`
+}
+
+// ArchiveManifestFile - a file of a report ZIP archive and the hex encoded SHA-256 of its contents
+type ArchiveManifestFile struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+}
+
+// newArchiveManifest lists the files of a report archive sorted by name
+func newArchiveManifest(files map[string][]byte) (ArchiveManifest, error) {
+	manifest := ArchiveManifest{Files: []ArchiveManifestFile{}}
+	for _, name := range manifestedFileNames(files) {
+		digest, err := calculateDigest(files[name])
+		if err != nil {
+			return ArchiveManifest{}, err
+		}
+		manifest.Files = append(manifest.Files, ArchiveManifestFile{Name: name, SHA256: digest})
+	}
+	return manifest, nil
+}
+
+// verifyArchiveManifest checks the files of a report archive are exactly those of its `manifest.json` with
+// the same contents, the error names every missing, extra and modified file
+func verifyArchiveManifest(files map[string][]byte) error {
+	manifestJSON, ok := files[archiveManifestFilename]
+	if !ok {
+		return fmt.Errorf("no %s in ZIP archive", archiveManifestFilename)
+	}
+	manifest := ArchiveManifest{}
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return errors.Wrapf(err, "%s", archiveManifestFilename)
+	}
+
+	problems := []string{}
+	listed := map[string]bool{}
+	for _, file := range manifest.Files {
+		listed[file.Name] = true
+		contents, ok := files[file.Name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%q is missing", file.Name))
+			continue
+		}
+		digest, err := calculateDigest(contents)
+		if err != nil {
+			return err
+		}
+		if digest != file.SHA256 {
+			problems = append(problems, fmt.Sprintf("%q is modified, its SHA-256 is %s not %s", file.Name, digest, file.SHA256))
+		}
+	}
+	for _, name := range manifestedFileNames(files) {
+		if !listed[name] {
+			problems = append(problems, fmt.Sprintf("%q is not listed", name))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("ZIP archive does not match %s: %s", archiveManifestFilename, strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// manifestedFileNames returns the sorted names of the files an archive manifest lists, directories are not files
+func manifestedFileNames(files map[string][]byte) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		if name == archiveManifestFilename || name == signatureFilename || strings.HasSuffix(name, "/") {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
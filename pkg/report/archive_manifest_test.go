@@ -0,0 +1,83 @@
+package report
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+)
+
+func TestZipExporterArchiveManifest(t *testing.T) {
+	require := test.NewRequire(t)
+
+	buff := &bytes.Buffer{}
+	require.NoError(NewZipExporter(archiveReport(), buff).Export())
+	files, err := readZipFiles(buff)
+	require.NoError(err)
+
+	manifest := ArchiveManifest{}
+	require.NoError(json.Unmarshal(files[archiveManifestFilename], &manifest))
+	names := []string{}
+	for _, file := range manifest.Files {
+		names = append(names, file.Name)
+		require.Len(file.SHA256, 64)
+	}
+	require.Equal([]string{discoveryFilename, "ob_3.1_accounts_transactions_fca.json", htmlReportFilename, reportFilename, responseFieldsFilename}, names)
+	require.NoError(verifyArchiveManifest(files))
+}
+
+func TestVerifyArchiveManifest(t *testing.T) {
+	require := test.NewRequire(t)
+
+	buff := &bytes.Buffer{}
+	require.NoError(NewZipExporter(archiveReport(), buff).Export())
+	files, err := readZipFiles(buff)
+	require.NoError(err)
+
+	archiveWith := func(change func(files map[string][]byte)) *bytes.Buffer {
+		changed := map[string][]byte{}
+		for name, contents := range files {
+			changed[name] = contents
+		}
+		change(changed)
+		buff := &bytes.Buffer{}
+		zipWriter := zip.NewWriter(buff)
+		require.NoError(writeFiles(zipWriter, changed))
+		require.NoError(zipWriter.Close())
+		return buff
+	}
+
+	_, err = NewZipImporter(archiveWith(func(files map[string][]byte) {
+		delete(files, responseFieldsFilename)
+	})).Import()
+	require.EqualError(err, `zipImporter.Import: ZIP archive does not match manifest.json: "responseFields.json" is missing`)
+
+	_, err = NewZipImporter(archiveWith(func(files map[string][]byte) {
+		files["__MACOSX/._report.json"] = []byte("extra")
+		files[discoveryFilename] = []byte(`{}`)
+	})).Import()
+	require.EqualError(err, `zipImporter.Import: ZIP archive does not match manifest.json: "discovery.json" is modified, `+
+		`its SHA-256 is 44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a not `+sha256Of(t, files[discoveryFilename])+
+		`, "__MACOSX/._report.json" is not listed`)
+
+	_, err = NewZipImporter(archiveWith(func(files map[string][]byte) {
+		delete(files, archiveManifestFilename)
+	})).Import()
+	require.EqualError(err, "zipImporter.Import: no manifest.json in ZIP archive")
+
+	_, err = ReadZipArchive(archiveWith(func(files map[string][]byte) {
+		files[reportFilename] = []byte(`{"fails":0}`)
+	}))
+	require.Error(err)
+	require.Contains(err.Error(), `"report.json" is modified`)
+}
+
+func sha256Of(t *testing.T, contents []byte) string {
+	digest, err := calculateDigest(contents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return digest
+}
@@ -0,0 +1,32 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+type coverageExporter struct {
+	report Report
+	writer io.Writer
+}
+
+// NewCoverageExporter - return new `Exporter` that exports the coverage of the report's API versions as JSON to `writer`.
+func NewCoverageExporter(report Report, writer io.Writer) Exporter {
+	return &coverageExporter{
+		report: report,
+		writer: writer,
+	}
+}
+
+// Export - export the coverage of `report` as a JSON array with an entry per API version.
+func (e *coverageExporter) Export() error {
+	coverageJSON, err := json.MarshalIndent(e.report.Coverage, marshalIndentPrefix, marshalIndent)
+	if err != nil {
+		return fmt.Errorf("%w: json.MarshalIndent failed: %s", ErrExportFailure, err.Error())
+	}
+	if _, err := e.writer.Write(coverageJSON); err != nil {
+		return fmt.Errorf("%w: writing coverage: %s", ErrExportFailure, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
+)
+
+// Default thresholds of a response time regression
+const (
+	DefaultResponseTimeIncrease    = 0.5
+	DefaultMinResponseTimeIncrease = 100 * time.Millisecond
+)
+
+// DiffOptions - thresholds used when comparing reports
+type DiffOptions struct {
+	ResponseTimeIncrease    float64       // fraction the response time must increase by to be a regression, 0.5 is 50% slower
+	MinResponseTimeIncrease time.Duration // smaller increases are not regressions however large the fraction
+}
+
+// DefaultDiffOptions - options used when none are provided
+func DefaultDiffOptions() DiffOptions {
+	return DiffOptions{
+		ResponseTimeIncrease:    DefaultResponseTimeIncrease,
+		MinResponseTimeIncrease: DefaultMinResponseTimeIncrease,
+	}
+}
+
+// Diff - what changed between an old and a new report, by API version
+type Diff struct {
+	OldReportID string    `json:"oldReportId"`
+	NewReportID string    `json:"newReportId"`
+	APIs        []APIDiff `json:"apis"`
+}
+
+// APIDiff - what changed for the test cases of an API version. Skipped test cases count as failing.
+type APIDiff struct {
+	Name                    string                   `json:"name"`
+	Version                 string                   `json:"version"`
+	NewlyFailing            []string                 `json:"newlyFailing"`
+	NewlyPassing            []string                 `json:"newlyPassing"`
+	Added                   []string                 `json:"added"`
+	Removed                 []string                 `json:"removed"`
+	ChangedFailures         []FailureChange          `json:"changedFailures"`
+	ResponseTimeRegressions []ResponseTimeRegression `json:"responseTimeRegressions"`
+}
+
+// FailureChange - a test case failing in both reports for different reasons
+type FailureChange struct {
+	ID  string   `json:"id"`
+	Old []string `json:"old"`
+	New []string `json:"new"`
+}
+
+// ResponseTimeRegression - a test case whose response time increased beyond the thresholds, times are in milliseconds
+type ResponseTimeRegression struct {
+	ID  string  `json:"id"`
+	Old float64 `json:"old"`
+	New float64 `json:"new"`
+}
+
+// NewDiff compares the test case results of two reports
+func NewDiff(oldReport, newReport Report, options DiffOptions) Diff {
+	oldAPIs := resultsByAPIVersion(oldReport)
+	newAPIs := resultsByAPIVersion(newReport)
+
+	keys := []results.ResultKey{}
+	for key := range oldAPIs {
+		keys = append(keys, key)
+	}
+	for key := range newAPIs {
+		if _, ok := oldAPIs[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].APIName != keys[j].APIName {
+			return keys[i].APIName < keys[j].APIName
+		}
+		return keys[i].APIVersion < keys[j].APIVersion
+	})
+
+	diff := Diff{OldReportID: oldReport.ID, NewReportID: newReport.ID, APIs: []APIDiff{}}
+	for _, key := range keys {
+		apiDiff := newAPIDiff(key, oldAPIs[key], newAPIs[key], options)
+		if !apiDiff.Empty() {
+			diff.APIs = append(diff.APIs, apiDiff)
+		}
+	}
+	return diff
+}
+
+// Empty checks if nothing changed between the reports
+func (d Diff) Empty() bool {
+	return len(d.APIs) == 0
+}
+
+// NewlyFailing returns the number of test cases failing in the new report that passed in the old one
+func (d Diff) NewlyFailing() int {
+	count := 0
+	for _, api := range d.APIs {
+		count += len(api.NewlyFailing)
+	}
+	return count
+}
+
+// Empty checks if nothing changed for the API version
+func (d APIDiff) Empty() bool {
+	return len(d.NewlyFailing) == 0 && len(d.NewlyPassing) == 0 && len(d.Added) == 0 && len(d.Removed) == 0 &&
+		len(d.ChangedFailures) == 0 && len(d.ResponseTimeRegressions) == 0
+}
+
+// WriteText writes the diff in a human readable format
+func (d Diff) WriteText(w io.Writer) error {
+	if d.Empty() {
+		_, err := fmt.Fprintf(w, "No changes between report %s and report %s\n", d.OldReportID, d.NewReportID)
+		return err
+	}
+
+	lines := []string{fmt.Sprintf("Changes between report %s and report %s", d.OldReportID, d.NewReportID)}
+	for _, api := range d.APIs {
+		lines = append(lines, "", fmt.Sprintf("=== %s %s", api.Name, api.Version))
+		lines = appendIDs(lines, "newly failing", api.NewlyFailing)
+		lines = appendIDs(lines, "newly passing", api.NewlyPassing)
+		lines = appendIDs(lines, "added", api.Added)
+		lines = appendIDs(lines, "removed", api.Removed)
+		for _, change := range api.ChangedFailures {
+			lines = append(lines, fmt.Sprintf("changed failure: %s", change.ID),
+				fmt.Sprintf("    old: %s", strings.Join(change.Old, "; ")),
+				fmt.Sprintf("    new: %s", strings.Join(change.New, "; ")))
+		}
+		for _, regression := range api.ResponseTimeRegressions {
+			lines = append(lines, fmt.Sprintf("slower: %s %.3fms -> %.3fms", regression.ID, regression.Old, regression.New))
+		}
+	}
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
+
+func appendIDs(lines []string, label string, ids []string) []string {
+	for _, id := range ids {
+		lines = append(lines, fmt.Sprintf("%s: %s", label, id))
+	}
+	return lines
+}
+
+func newAPIDiff(key results.ResultKey, oldResults, newResults map[string]results.TestCase, options DiffOptions) APIDiff {
+	diff := APIDiff{
+		Name:                    key.APIName,
+		Version:                 key.APIVersion,
+		NewlyFailing:            []string{},
+		NewlyPassing:            []string{},
+		Added:                   []string{},
+		Removed:                 []string{},
+		ChangedFailures:         []FailureChange{},
+		ResponseTimeRegressions: []ResponseTimeRegression{},
+	}
+
+	for _, id := range sortedIDs(oldResults) {
+		if _, ok := newResults[id]; !ok {
+			diff.Removed = append(diff.Removed, id)
+		}
+	}
+
+	for _, id := range sortedIDs(newResults) {
+		newResult := newResults[id]
+		oldResult, ok := oldResults[id]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, id)
+		case passed(oldResult) && !passed(newResult):
+			diff.NewlyFailing = append(diff.NewlyFailing, id)
+		case !passed(oldResult) && passed(newResult):
+			diff.NewlyPassing = append(diff.NewlyPassing, id)
+		case !passed(oldResult) && !passed(newResult) && !sameReasons(oldResult.Fail, newResult.Fail):
+			diff.ChangedFailures = append(diff.ChangedFailures, FailureChange{ID: id, Old: oldResult.Fail, New: newResult.Fail})
+		}
+
+		if ok && isResponseTimeRegression(oldResult.Metrics.ResponseTime, newResult.Metrics.ResponseTime, options) {
+			diff.ResponseTimeRegressions = append(diff.ResponseTimeRegressions, ResponseTimeRegression{
+				ID:  id,
+				Old: milliseconds(oldResult.Metrics.ResponseTime),
+				New: milliseconds(newResult.Metrics.ResponseTime),
+			})
+		}
+	}
+	return diff
+}
+
+// resultsByAPIVersion indexes the test case results of a report by API version and test case ID
+func resultsByAPIVersion(report Report) map[results.ResultKey]map[string]results.TestCase {
+	apis := map[results.ResultKey]map[string]results.TestCase{}
+	for _, spec := range report.APISpecification {
+		key := results.ResultKey{APIName: spec.Name, APIVersion: spec.Version}
+		if _, ok := apis[key]; !ok {
+			apis[key] = map[string]results.TestCase{}
+		}
+		for _, result := range spec.Results {
+			apis[key][result.Id] = result
+		}
+	}
+	return apis
+}
+
+func sortedIDs(testCases map[string]results.TestCase) []string {
+	ids := make([]string, 0, len(testCases))
+	for id := range testCases {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func passed(result results.TestCase) bool {
+	return result.Pass && !result.Skipped
+}
+
+// sameReasons compares the failure reasons of a test case without the response bodies they embed,
+// bodies carry interaction IDs and timestamps that differ between runs failing the same way
+func sameReasons(old, new []string) bool {
+	if len(old) != len(new) {
+		return false
+	}
+	for i := range old {
+		if failureWithoutResponse(old[i]) != failureWithoutResponse(new[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// failureWithoutResponse returns the response code and message of a failure reported with the response
+// it failed on, `{"endpointResponseCode":..,"endpointResponse":..,"testCaseMessage":..}`, other failures as they are
+func failureWithoutResponse(failure string) string {
+	detail := struct {
+		EndpointResponseCode int    `json:"endpointResponseCode"`
+		TestCaseMessage      string `json:"testCaseMessage"`
+	}{}
+	if err := json.Unmarshal([]byte(failure), &detail); err != nil || detail.TestCaseMessage == "" {
+		return failure
+	}
+	return fmt.Sprintf("%d %s", detail.EndpointResponseCode, detail.TestCaseMessage)
+}
+
+func isResponseTimeRegression(old, new time.Duration, options DiffOptions) bool {
+	if old <= 0 || new <= old {
+		return false
+	}
+	increase := new - old
+	return increase >= options.MinResponseTimeIncrease && float64(increase)/float64(old) >= options.ResponseTimeIncrease
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
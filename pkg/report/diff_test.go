@@ -0,0 +1,151 @@
+package report
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
+)
+
+func TestNewDiff(t *testing.T) {
+	oldReport := Report{ID: "old", APISpecification: []APISpecification{
+		{Name: "Account and Transaction API Specification", Version: "v3.1", Results: []results.TestCase{
+			diffTestCase("OB-301-ACC-001", true, 100*time.Millisecond),
+			diffTestCase("OB-301-ACC-002", false, 100*time.Millisecond, "status code 500"),
+			diffTestCase("OB-301-ACC-003", false, 100*time.Millisecond, "status code 500"),
+			diffTestCase("OB-301-ACC-004", true, 100*time.Millisecond),
+			diffTestCase("OB-301-ACC-005", true, 100*time.Millisecond),
+			diffTestCase("OB-301-ACC-006", true, 100*time.Millisecond),
+		}},
+		{Name: "Payment Initiation API", Version: "v3.1", Results: []results.TestCase{
+			diffTestCase("OB-301-DOP-001", true, 100*time.Millisecond),
+		}},
+	}}
+	newReport := Report{ID: "new", APISpecification: []APISpecification{
+		{Name: "Account and Transaction API Specification", Version: "v3.1", Results: []results.TestCase{
+			diffTestCase("OB-301-ACC-001", false, 100*time.Millisecond, "status code 403"),
+			diffTestCase("OB-301-ACC-002", true, 100*time.Millisecond),
+			diffTestCase("OB-301-ACC-003", false, 100*time.Millisecond, "status code 503"),
+			diffTestCase("OB-301-ACC-005", true, 300*time.Millisecond),
+			diffTestCase("OB-301-ACC-006", true, 150*time.Millisecond),
+			diffTestCase("OB-301-ACC-007", true, 100*time.Millisecond),
+		}},
+		{Name: "Payment Initiation API", Version: "v3.1", Results: []results.TestCase{
+			diffTestCase("OB-301-DOP-001", true, 100*time.Millisecond),
+		}},
+	}}
+
+	diff := NewDiff(oldReport, newReport, DefaultDiffOptions())
+
+	require.Len(t, diff.APIs, 1)
+	assert.Equal(t, APIDiff{
+		Name:            "Account and Transaction API Specification",
+		Version:         "v3.1",
+		NewlyFailing:    []string{"OB-301-ACC-001"},
+		NewlyPassing:    []string{"OB-301-ACC-002"},
+		Added:           []string{"OB-301-ACC-007"},
+		Removed:         []string{"OB-301-ACC-004"},
+		ChangedFailures: []FailureChange{{ID: "OB-301-ACC-003", Old: []string{"status code 500"}, New: []string{"status code 503"}}},
+		ResponseTimeRegressions: []ResponseTimeRegression{
+			{ID: "OB-301-ACC-005", Old: 100, New: 300},
+		},
+	}, diff.APIs[0])
+	assert.Equal(t, 1, diff.NewlyFailing())
+	assert.False(t, diff.Empty())
+}
+
+func TestNewDiffSkippedCountsAsFailing(t *testing.T) {
+	skipped := results.NewTestCaseSkipped("OB-301-ACC-002", []string{"OB-301-ACC-001"}, "/accounts", "", "", "", "")
+	oldReport := Report{ID: "old", APISpecification: []APISpecification{
+		{Name: "Accounts", Version: "v3.1", Results: []results.TestCase{diffTestCase("OB-301-ACC-002", true, 0)}},
+	}}
+	newReport := Report{ID: "new", APISpecification: []APISpecification{
+		{Name: "Accounts", Version: "v3.1", Results: []results.TestCase{skipped}},
+	}}
+
+	diff := NewDiff(oldReport, newReport, DefaultDiffOptions())
+
+	require.Len(t, diff.APIs, 1)
+	assert.Equal(t, []string{"OB-301-ACC-002"}, diff.APIs[0].NewlyFailing)
+}
+
+func TestNewDiffIgnoresResponseBodiesOfFailures(t *testing.T) {
+	failure := func(id string, code int, body string) results.TestCase {
+		return results.NewTestCaseFail(id, results.NoMetrics(), []error{executors.DetailError{
+			EndpointResponseCode: code,
+			EndpointResponse:     body,
+			TestCaseMessage:      fmt.Sprintf("Status check: expected 200 got %d", code),
+		}}, "/accounts", "Accounts", "v3.1", "", "", strconv.Itoa(code))
+	}
+	oldReport := Report{ID: "old", APISpecification: []APISpecification{
+		{Name: "Accounts", Version: "v3.1", Results: []results.TestCase{
+			failure("OB-301-ACC-002", 400, `{"Code":"400 BadRequest","Id":"a9e1b3c2","Errors":[{"Message":"at 2019-06-01T12:00:00Z"}]}`),
+			failure("OB-301-ACC-003", 400, `{"Code":"400 BadRequest","Id":"a9e1b3c3"}`),
+		}},
+	}}
+	newReport := Report{ID: "new", APISpecification: []APISpecification{
+		{Name: "Accounts", Version: "v3.1", Results: []results.TestCase{
+			failure("OB-301-ACC-002", 400, `{"Code":"400 BadRequest","Id":"07f4d8e6","Errors":[{"Message":"at 2019-06-02T09:30:00Z"}]}`),
+			failure("OB-301-ACC-003", 403, `{"Code":"400 BadRequest","Id":"a9e1b3c3"}`),
+		}},
+	}}
+
+	diff := NewDiff(oldReport, newReport, DefaultDiffOptions())
+
+	require.Len(t, diff.APIs, 1)
+	require.Len(t, diff.APIs[0].ChangedFailures, 1)
+	assert.Equal(t, "OB-301-ACC-003", diff.APIs[0].ChangedFailures[0].ID)
+}
+
+func TestNewDiffAPIVersionAdded(t *testing.T) {
+	newReport := Report{ID: "new", APISpecification: []APISpecification{
+		{Name: "Accounts", Version: "v3.1.10", Results: []results.TestCase{diffTestCase("OB-301-ACC-001", true, 0)}},
+	}}
+
+	diff := NewDiff(Report{ID: "old"}, newReport, DefaultDiffOptions())
+
+	require.Len(t, diff.APIs, 1)
+	assert.Equal(t, "v3.1.10", diff.APIs[0].Version)
+	assert.Equal(t, []string{"OB-301-ACC-001"}, diff.APIs[0].Added)
+}
+
+func TestDiffWriteText(t *testing.T) {
+	buff := &bytes.Buffer{}
+	require.NoError(t, Diff{OldReportID: "old", NewReportID: "new"}.WriteText(buff))
+	assert.Equal(t, "No changes between report old and report new\n", buff.String())
+
+	buff.Reset()
+	diff := Diff{OldReportID: "old", NewReportID: "new", APIs: []APIDiff{{
+		Name:                    "Accounts",
+		Version:                 "v3.1",
+		NewlyFailing:            []string{"OB-301-ACC-001"},
+		ChangedFailures:         []FailureChange{{ID: "OB-301-ACC-003", Old: []string{"status code 500"}, New: []string{"status code 503"}}},
+		ResponseTimeRegressions: []ResponseTimeRegression{{ID: "OB-301-ACC-005", Old: 100, New: 300}},
+	}}}
+	require.NoError(t, diff.WriteText(buff))
+	assert.Equal(t, `Changes between report old and report new
+
+=== Accounts v3.1
+newly failing: OB-301-ACC-001
+changed failure: OB-301-ACC-003
+    old: status code 500
+    new: status code 503
+slower: OB-301-ACC-005 100.000ms -> 300.000ms
+`, buff.String())
+}
+
+func diffTestCase(id string, pass bool, responseTime time.Duration, reasons ...string) results.TestCase {
+	errs := []error{}
+	for _, reason := range reasons {
+		errs = append(errs, errors.New(reason))
+	}
+	return results.NewTestCaseResult(id, pass, results.NewMetrics(nil, responseTime, 0), errs, "/accounts", "", "", "", "", "")
+}
@@ -2,17 +2,18 @@ package report
 
 import (
 	"archive/zip"
-	"crypto/sha256"
-	"encoding/hex"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 )
 
@@ -22,15 +23,12 @@ const (
 	reportFilename         = "report.json"
 	discoveryFilename      = "discovery.json"
 	responseFieldsFilename = "responseFields.json"
+	htmlReportFilename     = "report.html"
 )
 
 var (
 	// ErrExportFailure is the common error type returned on all export errors
 	ErrExportFailure = errors.New("export failed")
-
-	// Not a real secret; it's used when calculating a checksum for the exported report files.
-	// The main purpose is to protect against accidental edits in the exported files.
-	exportSecret = []byte(os.Getenv("EXPORT_SECRET"))
 )
 
 // Exporter - allows the exporting of a `Report`.
@@ -41,6 +39,7 @@ type Exporter interface {
 type zipExporter struct {
 	report Report
 	writer io.Writer
+	signer *Signer
 }
 
 // NewZipExporter - return new `Exporter` that exports to a ZIP archive to `writer`.
@@ -58,6 +57,16 @@ func NewZipExporter(report Report, writer io.Writer) Exporter {
 	}
 }
 
+// NewSignedZipExporter - return new `Exporter` that exports to a ZIP archive to `writer` with a `signature.json`
+// signed by `signer`, see VerifyZip.
+func NewSignedZipExporter(report Report, signer *Signer, writer io.Writer) Exporter {
+	return &zipExporter{
+		report: report,
+		writer: writer,
+		signer: signer,
+	}
+}
+
 // Export - export `report` as a `.zip` to file named `filename`.
 func (e *zipExporter) Export() error {
 	// Create a new zip archive.
@@ -69,6 +78,15 @@ func (e *zipExporter) Export() error {
 		return fmt.Errorf("%w: %s", ErrExportFailure, err)
 	}
 
+	var chain SignatureChain
+	if e.signer != nil {
+		chain, err = e.signer.signatureChain(uuid.New().String())
+		if err != nil {
+			return fmt.Errorf("%w: signature chain: %s", ErrExportFailure, err)
+		}
+		e.report.SignatureChain = &[]SignatureChain{chain}
+	}
+
 	reportJSON, err := json.MarshalIndent(e.report, marshalIndentPrefix, marshalIndent)
 	if err != nil {
 		return fmt.Errorf("%w: json.MarshalIndent failed: %s, report=%+v", ErrExportFailure, err.Error(), e.report)
@@ -82,20 +100,32 @@ func (e *zipExporter) Export() error {
 	toExport[reportFilename] = reportJSON
 	toExport[discoveryFilename] = discoveryJSON
 	toExport[responseFieldsFilename] = []byte(e.report.ResponseFields)
-	toExport["report.checksum"] = createChecksum(exportSecret, reportJSON)
 
-	return writeFiles(zipWriter, toExport)
-}
+	htmlReport := &bytes.Buffer{}
+	if err := NewHTMLExporter(e.report, htmlReport).Export(); err != nil {
+		return err
+	}
+	toExport[htmlReportFilename] = htmlReport.Bytes()
+
+	manifest, err := newArchiveManifest(toExport)
+	if err != nil {
+		return fmt.Errorf("%w: archive manifest: %s", ErrExportFailure, err)
+	}
+	manifestJSON, err := json.MarshalIndent(manifest, marshalIndentPrefix, marshalIndent)
+	if err != nil {
+		return fmt.Errorf("%w: json.MarshalIndent failed: %s, manifest=%+v", ErrExportFailure, err.Error(), manifest)
+	}
+	toExport[archiveManifestFilename] = manifestJSON
 
-func createChecksum(data, secret []byte) []byte {
-	h := sha256.New()
-	h.Write(data)   // never returns non-nil error
-	h.Write(secret) //
+	if e.signer != nil {
+		signature, err := e.signer.signFiles(e.report, chain, toExport)
+		if err != nil {
+			return fmt.Errorf("%w: signing failed: %s", ErrExportFailure, err)
+		}
+		toExport[signatureFilename] = []byte(signature)
+	}
 
-	src := h.Sum(nil)
-	dst := make([]byte, hex.EncodedLen(len(src)))
-	hex.Encode(dst, src)
-	return dst
+	return writeFiles(zipWriter, toExport)
 }
 
 func writeFiles(zipWriter *zip.Writer, files map[string][]byte) error {
@@ -133,6 +163,10 @@ func readManifestFiles(report *Report) (map[string][]byte, error) {
 }
 
 func readManifestFile(path string) ([]byte, error) {
+	// the manifests of an imported report are restored to an absolute path
+	if filepath.IsAbs(path) {
+		return ioutil.ReadFile(path)
+	}
 	searchPaths := []string{".", "../.."}
 	var err error
 	for _, searchPath := range searchPaths {
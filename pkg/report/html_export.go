@@ -0,0 +1,296 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
+)
+
+type htmlExporter struct {
+	report Report
+	writer io.Writer
+}
+
+// NewHTMLExporter - return new `Exporter` that exports a self-contained HTML page to `writer`.
+// The page needs no other files, styles are inlined, so it can be opened or shared on its own.
+func NewHTMLExporter(report Report, writer io.Writer) Exporter {
+	return &htmlExporter{
+		report: report,
+		writer: writer,
+	}
+}
+
+// Export - export `report` as a single HTML page.
+func (e *htmlExporter) Export() error {
+	if err := htmlReportTemplate.Execute(e.writer, newHTMLReport(e.report)); err != nil {
+		return fmt.Errorf("%w: rendering HTML report: %s", ErrExportFailure, err)
+	}
+	return nil
+}
+
+// htmlReport - the data rendered by `htmlReportTemplate`
+type htmlReport struct {
+	Report
+	Summary htmlCounts
+	APIs    []htmlAPI
+}
+
+// Signature - the first signature of the report's signature chain, nil when the report is not signed
+func (r htmlReport) Signature() *SignatureChain {
+	if r.SignatureChain == nil || len(*r.SignatureChain) == 0 {
+		return nil
+	}
+	return &(*r.SignatureChain)[0]
+}
+
+type htmlAPI struct {
+	APISpecification
+	Counts htmlCounts
+}
+
+type htmlCounts struct {
+	Total   int
+	Passed  int
+	Failed  int
+	Skipped int
+}
+
+func (c *htmlCounts) add(result results.TestCase) {
+	c.Total++
+	switch {
+	case result.Skipped:
+		c.Skipped++
+	case result.Pass:
+		c.Passed++
+	default:
+		c.Failed++
+	}
+}
+
+func newHTMLReport(report Report) htmlReport {
+	apis := make([]htmlAPI, 0, len(report.APISpecification))
+	summary := htmlCounts{}
+	for _, spec := range report.APISpecification {
+		api := htmlAPI{APISpecification: spec}
+		for _, result := range spec.Results {
+			api.Counts.add(result)
+			summary.add(result)
+		}
+		apis = append(apis, api)
+	}
+	sort.SliceStable(apis, func(i, j int) bool {
+		if apis[i].Name != apis[j].Name {
+			return apis[i].Name < apis[j].Name
+		}
+		return apis[i].Version < apis[j].Version
+	})
+
+	return htmlReport{
+		Report:  report,
+		Summary: summary,
+		APIs:    apis,
+	}
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"milliseconds": func(d time.Duration) string {
+		return fmt.Sprintf("%.0f ms", float64(d)/float64(time.Millisecond))
+	},
+	"timestamp": func(t time.Time) string {
+		return t.Format(time.RFC3339)
+	},
+	"result": func(result results.TestCase) string {
+		switch {
+		case result.Skipped:
+			return "skipped"
+		case result.Pass:
+			return "pass"
+		default:
+			return "fail"
+		}
+	},
+}).Parse(htmlReportPage + htmlCountTemplate))
+
+// htmlCountTemplate renders a `coverage.Count` as `exercised / total (percent%)`
+const htmlCountTemplate = `{{define "count"}}{{.Exercised}} / {{.Total}} ({{.Percent}}%){{end}}`
+
+const htmlReportPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Functional Conformance Suite Report {{.ID}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.4em 0.6em; text-align: left; vertical-align: top; }
+th { background: #f2f2f2; }
+dl { display: grid; grid-template-columns: max-content auto; gap: 0.3em 1em; }
+dt { font-weight: bold; }
+dd { margin: 0; }
+ul { margin: 0; padding-left: 1.2em; }
+.pass { color: #1a7f37; }
+.fail { color: #cf222e; }
+.skipped { color: #9a6700; }
+</style>
+</head>
+<body>
+<h1>Functional Conformance Suite Report</h1>
+<dl>
+<dt>Report</dt><dd>{{.ID}}</dd>
+<dt>Created</dt><dd>{{.Created}}</dd>
+{{- with .Expiration}}
+<dt>Expires</dt><dd>{{.}}</dd>
+{{- end}}
+<dt>Status</dt><dd>{{.Status}}</dd>
+<dt>Environment</dt><dd>{{.CertifiedBy.Environment}}</dd>
+<dt>FCS version</dt><dd>{{.FCSVersion}}</dd>
+<dt>Implementer</dt><dd>{{.CertifiedBy.Brand}}</dd>
+<dt>Authorised by</dt><dd>{{.CertifiedBy.AuthorisedBy}}, {{.CertifiedBy.JobTitle}}</dd>
+<dt>Products</dt><dd>{{range $i, $product := .Products}}{{if $i}}, {{end}}{{$product}}{{end}}</dd>
+<dt>JWS status</dt><dd>{{.JWSStatus}}</dd>
+<dt>Signature</dt><dd>{{with .Signature}}{{.Type}} by {{.Creator}}{{else}}not signed{{end}}</dd>
+</dl>
+
+<h2>Summary</h2>
+<table>
+<thead>
+<tr><th>API</th><th>Version</th><th>TLS version</th><th>Total</th><th>Passed</th><th>Failed</th><th>Skipped</th></tr>
+</thead>
+<tbody>
+{{- range .APIs}}
+<tr>
+<td><a href="#{{.Name}}-{{.Version}}">{{.Name}}</a></td>
+<td>{{.Version}}</td>
+<td class="{{if .TLSVersionValid}}pass{{else}}fail{{end}}">{{.TLSVersion}}</td>
+<td>{{.Counts.Total}}</td>
+<td class="pass">{{.Counts.Passed}}</td>
+<td class="fail">{{.Counts.Failed}}</td>
+<td class="skipped">{{.Counts.Skipped}}</td>
+</tr>
+{{- end}}
+<tr>
+<th colspan="3">Total</th>
+<th>{{.Summary.Total}}</th>
+<th>{{.Summary.Passed}}</th>
+<th>{{.Summary.Failed}}</th>
+<th>{{.Summary.Skipped}}</th>
+</tr>
+</tbody>
+</table>
+{{- with .JWKSKeys}}
+
+<h2>Signing keys</h2>
+<table>
+<thead>
+<tr><th>JWKS</th><th>Kid</th><th>Algorithm</th><th>First seen</th><th>Rotation</th></tr>
+</thead>
+<tbody>
+{{- range .}}
+<tr>
+<td>{{.JwksURI}}</td>
+<td>{{.Kid}}</td>
+<td>{{.Alg}}</td>
+<td>{{timestamp .FirstSeen}}</td>
+<td>{{if .Added}}added{{end}}{{if and .Added .Removed}}, {{end}}{{with .Removed}}removed {{timestamp .}}{{end}}</td>
+</tr>
+{{- end}}
+</tbody>
+</table>
+{{- end}}
+{{- with .TLSAudits}}
+
+<h2>TLS</h2>
+<table>
+<thead>
+<tr><th>URI</th><th>Result</th><th>Versions</th><th>Cipher suites</th><th>Certificate</th><th>Findings</th></tr>
+</thead>
+<tbody>
+{{- range .}}
+<tr>
+<td>{{.URI}}</td>
+<td class="{{if .Valid}}pass{{else}}fail{{end}}">{{if .Valid}}pass{{else}}fail{{end}}</td>
+<td>{{range $i, $version := .Versions}}{{if $i}}, {{end}}{{$version}}{{end}}</td>
+<td>{{with .CipherSuites}}<ul>{{range .}}<li class="{{if .FAPIApproved}}pass{{else}}fail{{end}}">{{.Name}} ({{.Version}})</li>{{end}}</ul>{{end}}</td>
+<td>{{with .Certificate}}{{.Subject}}, expires {{timestamp .NotAfter}}{{end}}</td>
+<td>{{with .Error}}{{.}}{{end}}{{with .Findings}}<ul>{{range .}}<li>{{.}}</li>{{end}}</ul>{{end}}</td>
+</tr>
+{{- end}}
+</tbody>
+</table>
+{{- end}}
+{{- with .Coverage}}
+
+<h2>Coverage</h2>
+<table>
+<thead>
+<tr><th>API</th><th>Version</th><th>Operations</th><th>Response codes</th><th>Response properties</th></tr>
+</thead>
+<tbody>
+{{- range .}}
+<tr>
+<td>{{.API}}</td>
+<td>{{.Version}}</td>
+{{- if .Error}}
+<td colspan="3">{{.Error}}</td>
+{{- else}}
+<td>{{template "count" .OperationsHit}}</td>
+<td>{{template "count" .ResponsesHit}}</td>
+<td>{{template "count" .PropertiesHit}}</td>
+{{- end}}
+</tr>
+{{- end}}
+</tbody>
+</table>
+{{- range .}}
+{{- if .Operations}}
+
+<h3>{{.API}} {{.Version}} coverage</h3>
+<table>
+<thead>
+<tr><th>Operation</th><th>Condition</th><th>Discovered</th><th>Exercised</th><th>Response codes</th><th>Properties never seen</th></tr>
+</thead>
+<tbody>
+{{- range .Operations}}
+<tr>
+<td>{{.Method}} {{.Path}}</td>
+<td>{{.Condition}}</td>
+<td>{{if .Discovered}}yes{{else}}no{{end}}</td>
+<td class="{{if .Exercised}}pass{{else}}fail{{end}}">{{if .Exercised}}yes{{else}}no{{end}}</td>
+<td>{{range $i, $response := .Responses}}{{if $i}}, {{end}}<span class="{{if .Exercised}}pass{{else}}skipped{{end}}">{{.Code}}</span>{{end}}</td>
+<td>{{range .Responses}}{{if .NotExercised}}{{.Code}}: {{range $i, $property := .NotExercised}}{{if $i}}, {{end}}{{$property}}{{end}}<br>{{end}}{{end}}</td>
+</tr>
+{{- end}}
+</tbody>
+</table>
+{{- end}}
+{{- end}}
+{{- end}}
+{{range .APIs}}
+<h2 id="{{.Name}}-{{.Version}}">{{.Name}} {{.Version}}</h2>
+<table>
+<thead>
+<tr><th>Test</th><th>Result</th><th>Detail</th><th>Endpoint</th><th>HTTP status</th><th>Response time</th><th>Response size</th><th>Failure reasons</th></tr>
+</thead>
+<tbody>
+{{- range .Results}}
+<tr>
+<td>{{if .RefURI}}<a href="{{.RefURI}}">{{.Id}}</a>{{else}}{{.Id}}{{end}}</td>
+<td class="{{result .}}">{{result .}}</td>
+<td>{{.Detail}}</td>
+<td>{{.Endpoint}}</td>
+<td>{{.HttpStatus}}</td>
+<td>{{milliseconds .Metrics.ResponseTime}}{{if gt .Metrics.Attempts 1}} ({{.Metrics.Attempts}} attempts){{end}}</td>
+<td>{{.Metrics.ResponseSize}} bytes</td>
+<td>{{with .Fail}}<ul>{{range .}}<li>{{.}}</li>{{end}}</ul>{{end}}</td>
+</tr>
+{{- end}}
+</tbody>
+</table>
+{{- end}}
+</body>
+</html>
+`
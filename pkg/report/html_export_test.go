@@ -0,0 +1,186 @@
+package report
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
+	"github.com/OpenBankingUK/conformance-suite/pkg/coverage"
+	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+)
+
+func TestHTMLExporterExport(t *testing.T) {
+	require := test.NewRequire(t)
+
+	report := Report{
+		ID:          "f47ac10b-58cc-4372-a567-0e02b2c3d479",
+		Created:     "2019-06-01T12:00:00+00:00",
+		Status:      StatusComplete,
+		CertifiedBy: CertifiedBy{Environment: CertifiedByEnvironmentTesting, Brand: "Model Bank <Testing>"},
+		JWSStatus:   "Enabled",
+		APISpecification: []APISpecification{
+			{Name: "Payments", Version: "v3.1", TLSVersion: "TLS12", TLSVersionValid: true, Results: []results.TestCase{
+				{Id: "OB-301-DOP-100300", Pass: true, RefURI: "https://openbanking.atlassian.net/wiki/spaces/DZ", Metrics: results.Metrics{ResponseTime: 120 * time.Millisecond, ResponseSize: 512, Attempts: 2}},
+			}},
+			{Name: "Accounts", Version: "v3.1", TLSVersion: "TLS12", TLSVersionValid: true, Results: []results.TestCase{
+				{Id: "OB-301-ACC-001", Pass: true},
+				{Id: "OB-301-ACC-002", Fail: []string{"status code 500 <Internal Server Error>"}},
+				{Id: "OB-301-ACC-003", Skipped: true, Fail: []string{"skipped: upstream failed: OB-301-ACC-002"}},
+			}},
+		},
+	}
+
+	buff := &bytes.Buffer{}
+	require.NoError(NewHTMLExporter(report, buff).Export())
+	page := buff.String()
+
+	require.Contains(page, "<!DOCTYPE html>")
+	require.Contains(page, "<dt>Status</dt><dd>Complete</dd>")
+	require.Contains(page, "<dt>Environment</dt><dd>testing</dd>")
+	require.Contains(page, "<dt>JWS status</dt><dd>Enabled</dd>")
+	require.Contains(page, "<dt>Signature</dt><dd>not signed</dd>")
+	require.Contains(page, "Model Bank &lt;Testing&gt;")
+	require.Contains(page, `<a href="https://openbanking.atlassian.net/wiki/spaces/DZ">OB-301-DOP-100300</a>`)
+	require.Contains(page, "120 ms (2 attempts)")
+	require.Contains(page, "<li>status code 500 &lt;Internal Server Error&gt;</li>")
+	require.Contains(page, `<td class="skipped">skipped</td>`)
+	require.Contains(page, "<th>4</th>\n<th>2</th>\n<th>1</th>\n<th>1</th>")
+
+	// APIs are listed by name and version
+	require.True(bytes.Index(buff.Bytes(), []byte(`<h2 id="Accounts-v3.1">`)) < bytes.Index(buff.Bytes(), []byte(`<h2 id="Payments-v3.1">`)))
+}
+
+func TestHTMLExporterExportSigned(t *testing.T) {
+	require := test.NewRequire(t)
+
+	report := Report{
+		Status:         StatusComplete,
+		SignatureChain: &[]SignatureChain{{Type: "RsaSignature2018", Creator: "Model Bank"}},
+	}
+
+	buff := &bytes.Buffer{}
+	require.NoError(NewHTMLExporter(report, buff).Export())
+	require.Contains(buff.String(), "<dt>Signature</dt><dd>RsaSignature2018 by Model Bank</dd>")
+}
+
+func TestZipExporterExportHTMLReport(t *testing.T) {
+	require := test.NewRequire(t)
+
+	buff := &bytes.Buffer{}
+	require.NoError(NewZipExporter(Report{Status: StatusComplete, CertifiedBy: CertifiedBy{Environment: CertifiedByEnvironmentTesting}}, buff).Export())
+	archive, err := zip.NewReader(bytes.NewReader(buff.Bytes()), int64(buff.Len()))
+	require.NoError(err)
+
+	var page []byte
+	for _, file := range archive.File {
+		if file.Name != htmlReportFilename {
+			continue
+		}
+		reader, err := file.Open()
+		require.NoError(err)
+		page, err = ioutil.ReadAll(reader)
+		require.NoError(err)
+	}
+	require.Contains(string(page), "<!DOCTYPE html>")
+	require.Contains(string(page), "<dt>Status</dt><dd>Complete</dd>")
+}
+
+func TestHTMLExporterExportJWKSKeys(t *testing.T) {
+	require := test.NewRequire(t)
+
+	firstSeen := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	removed := firstSeen.Add(time.Hour)
+	report := Report{
+		Status: StatusComplete,
+		JWKSKeys: []authentication.JWKSKey{
+			{JwksURI: "https://aspsp.example.com/jwks", Kid: "kid-001", Alg: "PS256", FirstSeen: firstSeen, Removed: &removed},
+			{JwksURI: "https://aspsp.example.com/jwks", Kid: "kid-002", Alg: "PS256", FirstSeen: removed, Added: true},
+		},
+	}
+
+	buff := &bytes.Buffer{}
+	require.NoError(NewHTMLExporter(report, buff).Export())
+	page := buff.String()
+
+	require.Contains(page, "<h2>Signing keys</h2>")
+	require.Contains(page, "<td>kid-001</td>\n<td>PS256</td>\n<td>2019-06-01T12:00:00Z</td>\n<td>removed 2019-06-01T13:00:00Z</td>")
+	require.Contains(page, "<td>kid-002</td>\n<td>PS256</td>\n<td>2019-06-01T13:00:00Z</td>\n<td>added</td>")
+}
+
+func TestHTMLExporterExportTLSAudits(t *testing.T) {
+	require := test.NewRequire(t)
+
+	report := Report{
+		Status: StatusComplete,
+		TLSAudits: []discovery.TLSAudit{
+			{
+				URI:      "https://aspsp.example.com/open-banking",
+				Versions: []string{"TLS11", "TLS12"},
+				CipherSuites: []discovery.TLSCipherSuite{
+					{Name: "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA", Version: "TLS12"},
+					{Name: "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", Version: "TLS12", FAPIApproved: true},
+				},
+				Findings: []string{"accepts TLS11, below the minimum TLS12"},
+			},
+			{URI: "https://auth.example.com/token", Error: "no TLS handshake succeeded with auth.example.com:443"},
+		},
+	}
+
+	buff := &bytes.Buffer{}
+	require.NoError(NewHTMLExporter(report, buff).Export())
+	page := buff.String()
+
+	require.Contains(page, "<h2>TLS</h2>")
+	require.Contains(page, "<td>https://aspsp.example.com/open-banking</td>\n<td class=\"fail\">fail</td>\n<td>TLS11, TLS12</td>")
+	require.Contains(page, `<li class="fail">TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA (TLS12)</li><li class="pass">TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 (TLS12)</li>`)
+	require.Contains(page, "<li>accepts TLS11, below the minimum TLS12</li>")
+	require.Contains(page, "<td>no TLS handshake succeeded with auth.example.com:443</td>")
+}
+
+func TestHTMLExporterExportCoverage(t *testing.T) {
+	require := test.NewRequire(t)
+
+	report := Report{
+		Status: StatusComplete,
+		Coverage: []coverage.APICoverage{
+			{
+				API:     "Account and Transaction API Specification",
+				Version: "v3.1.10",
+				Operations: []coverage.OperationCoverage{
+					{
+						Method: "GET", Path: "/accounts", Condition: coverage.ConditionMandatory, Discovered: true, Exercised: true,
+						Responses: []coverage.ResponseCoverage{
+							{Code: "200", Exercised: true, Properties: coverage.Count{Exercised: 1, Total: 2}, NotExercised: []string{"Meta.TotalPages"}},
+							{Code: "400", Properties: coverage.Count{Total: 1}},
+						},
+					},
+				},
+				OperationsHit: coverage.Count{Exercised: 1, Total: 1},
+				ResponsesHit:  coverage.Count{Exercised: 1, Total: 2},
+				PropertiesHit: coverage.Count{Exercised: 1, Total: 3},
+			},
+			{API: "Payment Initiation API", Version: "v3.1.6", Error: "cannot Load OpenApi Spec"},
+		},
+	}
+
+	buff := &bytes.Buffer{}
+	require.NoError(NewHTMLExporter(report, buff).Export())
+	page := buff.String()
+
+	require.Contains(page, "<h2>Coverage</h2>")
+	require.Contains(page, "<td>1 / 1 (100%)</td>\n<td>1 / 2 (50%)</td>\n<td>1 / 3 (33%)</td>")
+	require.Contains(page, `<td colspan="3">cannot Load OpenApi Spec</td>`)
+	require.Contains(page, "<h3>Account and Transaction API Specification v3.1.10 coverage</h3>")
+	require.Contains(page, `<td>GET /accounts</td>
+<td>mandatory</td>
+<td>yes</td>
+<td class="pass">yes</td>
+<td><span class="pass">200</span>, <span class="skipped">400</span></td>
+<td>200: Meta.TotalPages<br></td>`)
+	require.NotContains(page, "<h3>Payment Initiation API")
+}
@@ -1,13 +1,19 @@
 package report
 
 import (
-	"archive/zip"
 	"bytes"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
 
 	"github.com/pkg/errors"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
 )
 
 // Importer - allows the importing of a `Report`.
@@ -26,57 +32,103 @@ func NewZipImporter(reader io.Reader) Importer {
 	}
 }
 
-// Import - import `report.json` from `reader`.
+// Import - import `report.json` from `reader`, the files of the archive must be those of its `manifest.json`.
 func (i *zipImporter) Import() (Report, error) {
-	// We need to determine the length of `i.reader`, so read until EOF and use that as the size to the call to `zip.NewReader`.
-	// Could possibly use one of these libraries:
-	// * https://godoc.org/go4.org/readerutil#NewBufferingReaderAt
-	// * https://github.com/go4org/go4/blob/master/readerutil/bufreaderat.go#L23
-	// But `go4` makes no backwards compatibility promises.
-	buff := bytes.NewBuffer([]byte{})
-	size, err := io.Copy(buff, i.reader)
+	files, err := readZipFiles(i.reader)
 	if err != nil {
-		return Report{}, errors.Wrapf(err, "zipImporter.Import: io.Copy failed, copied=%d bytes", size)
+		return Report{}, errors.Wrap(err, "zipImporter.Import")
+	}
+
+	reportJSON, ok := files[reportFilename]
+	if !ok {
+		return Report{}, fmt.Errorf("zipImporter.Import: could not find %q in ZIP archive", reportFilename)
+	}
+	if err := verifyArchiveManifest(files); err != nil {
+		return Report{}, errors.Wrap(err, "zipImporter.Import")
 	}
 
-	reader := bytes.NewReader(buff.Bytes())
+	report := Report{}
+	if err := json.Unmarshal(reportJSON, &report); err != nil {
+		return Report{}, errors.Wrapf(err, "zipImporter.Import: json.Unmarshal failed, could not marshall %q to Report", reportFilename)
+	}
+	return report, nil
+}
+
+// Archive - an exported report ZIP archive: the report, the discovery model it was run with and the other files
+type Archive struct {
+	Report    Report
+	Discovery discovery.Model
+	files     map[string][]byte
+}
 
-	// Open a zip archive for reading.
-	zipReader, err := zip.NewReader(reader, size)
+// ReadZipArchive - reads a report ZIP archive from `reader`, `report.json` is imported with `NewZipImporter` so
+// an archive with missing, extra or modified files is rejected.
+// The discovery model is empty when the archive has no `discovery.json`.
+func ReadZipArchive(reader io.Reader) (Archive, error) {
+	contents, err := readZipArchive(reader)
+	if err != nil {
+		return Archive{}, errors.Wrap(err, "ReadZipArchive")
+	}
+	report, err := NewZipImporter(bytes.NewReader(contents)).Import()
 	if err != nil {
-		return Report{}, errors.Wrapf(err, "zipImporter.Import: zip.NewReader failed, could not get new zip.Reader with size=%d", size)
+		return Archive{}, err
+	}
+	files, err := readZipFiles(bytes.NewReader(contents))
+	if err != nil {
+		return Archive{}, err
 	}
 
-	for _, file := range zipReader.File {
-		// Ignore anything that isn't `reportFilename`.
-		if file.Name != reportFilename {
-			continue
+	archive := Archive{Report: report, files: files}
+	if discoveryJSON, ok := files[discoveryFilename]; ok {
+		if err := json.Unmarshal(discoveryJSON, &archive.Discovery); err != nil {
+			return Archive{}, errors.Wrapf(err, "ReadZipArchive: json.Unmarshal failed, could not marshall %q to discovery.Model", discoveryFilename)
 		}
+	}
+	archive.Report.Discovery = archive.Discovery
+	archive.Report.ResponseFields = string(files[responseFieldsFilename])
+	return archive, nil
+}
 
-		// Open `reportFilename` and read its contents, then Unmarshall to `Report` struct.
-		readerCloser, err := file.Open()
-		if err != nil {
-			return Report{}, errors.Wrapf(err, "zipImporter.Import: file.Open failed, could not open %q", reportFilename)
-		}
+// ReportJSON - the `report.json` of the archive as it was exported
+func (a Archive) ReportJSON() []byte {
+	return a.files[reportFilename]
+}
 
-		buff := bytes.NewBuffer([]byte{})
-		if size, err := io.Copy(buff, readerCloser); err != nil {
-			readerCloser.Close()
-			return Report{}, errors.Wrapf(err, "zipImporter.Import: io.Copy failed, copied=%d bytes from %q", size, reportFilename)
-		}
+// Signed - the archive has a `signature.json`
+func (a Archive) Signed() bool {
+	_, ok := a.files[signatureFilename]
+	return ok
+}
 
-		report := Report{}
-		if err := json.Unmarshal(buff.Bytes(), &report); err != nil {
-			readerCloser.Close()
-			return Report{}, errors.Wrapf(err, "zipImporter.Import: json.Unmarshal failed, could not marshall %q to Report", reportFilename)
-		}
+// VerifySignature - verifies the `signature.json` of the archive as VerifyZip does.
+// Returns the certificate the report is signed with.
+func (a Archive) VerifySignature(trusted *x509.Certificate) (*x509.Certificate, error) {
+	return verifyFiles(a.files, trusted)
+}
 
-		if err := readerCloser.Close(); err != nil {
-			return Report{}, errors.Wrapf(err, "zipImporter.Import: file.Close failed, could not close %q", reportFilename)
-		}
+// RestoreDiscovery - writes the manifests of the archive to `dir` and returns the discovery model with
+// the `file://` manifest of each discovery item pointing at its restored copy, so the run can be repeated
+// without the manifests it was exported with.
+func (a Archive) RestoreDiscovery(dir string) (discovery.Model, error) {
+	restored := a.Discovery
+	items := make([]discovery.ModelDiscoveryItem, len(a.Discovery.DiscoveryModel.DiscoveryItems))
+	copy(items, a.Discovery.DiscoveryModel.DiscoveryItems)
+	restored.DiscoveryModel.DiscoveryItems = items
 
-		return report, nil
+	for i, item := range items {
+		if !strings.HasPrefix(item.APISpecification.Manifest, "file://") {
+			continue
+		}
+		fileName := path.Base(strings.TrimPrefix(item.APISpecification.Manifest, "file://"))
+		contents, ok := a.files[fileName]
+		if !ok {
+			return discovery.Model{}, fmt.Errorf("RestoreDiscovery: manifest %q is not in ZIP archive", fileName)
+		}
+		manifestPath := filepath.Join(dir, fileName)
+		if err := ioutil.WriteFile(manifestPath, contents, 0600); err != nil {
+			return discovery.Model{}, errors.Wrapf(err, "RestoreDiscovery: could not write %q", manifestPath)
+		}
+		items[i].APISpecification.Manifest = "file://" + manifestPath
 	}
-
-	return Report{}, fmt.Errorf("zipImporter.Import: could not find %q in ZIP archive", reportFilename)
+	return restored, nil
 }
@@ -1,11 +1,17 @@
 package report
 
 import (
+	"archive/zip"
+	"bytes"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
 	"github.com/OpenBankingUK/conformance-suite/pkg/test"
 	"github.com/stretchr/testify/require"
 )
@@ -80,3 +86,120 @@ func Test_zipImporter_Import(t *testing.T) {
 		}
 	})
 }
+
+func archiveReport() Report {
+	return Report{
+		ID:          "f47ac10b-58cc-4372-a567-0e02b2c3d479",
+		Status:      StatusComplete,
+		CertifiedBy: CertifiedBy{Environment: CertifiedByEnvironmentTesting},
+		Discovery: discovery.Model{
+			DiscoveryModel: discovery.ModelDiscovery{
+				DiscoveryItems: []discovery.ModelDiscoveryItem{
+					{APISpecification: discovery.ModelAPISpecification{Manifest: "file://manifests/ob_3.1_accounts_transactions_fca.json"}},
+				},
+			},
+		},
+		ResponseFields: `{"accountId":"500000000000000000000001"}`,
+	}
+}
+
+func TestReadZipArchive(t *testing.T) {
+	require := test.NewRequire(t)
+
+	buff := &bytes.Buffer{}
+	require.NoError(NewZipExporter(archiveReport(), buff).Export())
+
+	archive, err := ReadZipArchive(buff)
+	require.NoError(err)
+	require.Equal("f47ac10b-58cc-4372-a567-0e02b2c3d479", archive.Report.ID)
+	require.Equal(archiveReport().Discovery, archive.Discovery)
+	require.Equal(archive.Discovery, archive.Report.Discovery)
+	require.Equal(archiveReport().ResponseFields, archive.Report.ResponseFields)
+	require.False(archive.Signed())
+	_, err = archive.VerifySignature(nil)
+	require.EqualError(err, "report is not signed, no signature.json in ZIP archive, so manifest.json does not show its files are unmodified")
+}
+
+func TestReadZipArchiveSigned(t *testing.T) {
+	require := test.NewRequire(t)
+
+	signer, err := NewSigner([]byte(samplePrivateKey), sampleCertificate(t, "Conformance Suite"))
+	require.NoError(err)
+
+	archive, err := ReadZipArchive(bytes.NewReader(signedReportZip(t, signer, archiveReport())))
+	require.NoError(err)
+	require.True(archive.Signed())
+	certificate, err := archive.VerifySignature(nil)
+	require.NoError(err)
+	require.Equal("CN=Conformance Suite", certificate.Subject.String())
+}
+
+func TestReadZipArchiveRejectsLargeArchives(t *testing.T) {
+	require := test.NewRequire(t)
+
+	_, err := ReadZipArchive(bytes.NewReader(make([]byte, maxZipArchiveSize+1)))
+	require.EqualError(err, "ReadZipArchive: ZIP archive is larger than 52428800 bytes")
+}
+
+func TestReadZipArchiveRejectsTooManyFiles(t *testing.T) {
+	require := test.NewRequire(t)
+
+	buff := &bytes.Buffer{}
+	writer := zip.NewWriter(buff)
+	for i := 0; i <= maxZipFiles; i++ {
+		_, err := writer.Create(fmt.Sprintf("file%d.txt", i))
+		require.NoError(err)
+	}
+	require.NoError(writer.Close())
+
+	_, err := ReadZipArchive(buff)
+	require.EqualError(err, "zipImporter.Import: readZipFiles: ZIP archive has 101 files, more than 100")
+}
+
+func TestReadZipArchiveRejectsLargeUncompressedFiles(t *testing.T) {
+	require := test.NewRequire(t)
+
+	buff := &bytes.Buffer{}
+	writer := zip.NewWriter(buff)
+	file, err := writer.Create(reportFilename)
+	require.NoError(err)
+	// compresses to a fraction of maxZipArchiveSize
+	_, err = file.Write(make([]byte, maxZipUncompressedSize+1))
+	require.NoError(err)
+	require.NoError(writer.Close())
+	require.True(buff.Len() < maxZipArchiveSize)
+
+	_, err = ReadZipArchive(buff)
+	require.EqualError(err, "zipImporter.Import: readZipFiles: ZIP archive files are larger than 209715200 bytes uncompressed")
+}
+
+func TestArchiveRestoreDiscovery(t *testing.T) {
+	require := test.NewRequire(t)
+
+	buff := &bytes.Buffer{}
+	require.NoError(NewZipExporter(archiveReport(), buff).Export())
+	archive, err := ReadZipArchive(buff)
+	require.NoError(err)
+
+	dir, err := ioutil.TempDir("", "report-import")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	restored, err := archive.RestoreDiscovery(dir)
+	require.NoError(err)
+	manifestPath := filepath.Join(dir, "ob_3.1_accounts_transactions_fca.json")
+	require.Equal("file://"+manifestPath, restored.DiscoveryModel.DiscoveryItems[0].APISpecification.Manifest)
+	require.Equal("file://manifests/ob_3.1_accounts_transactions_fca.json", archive.Discovery.DiscoveryModel.DiscoveryItems[0].APISpecification.Manifest)
+
+	restoredManifest, err := ioutil.ReadFile(manifestPath)
+	require.NoError(err)
+	require.Equal(archive.files["ob_3.1_accounts_transactions_fca.json"], restoredManifest)
+
+	// a restored manifest is exported again from its absolute path
+	archive.Report.Discovery = restored
+	require.NoError(NewZipExporter(archive.Report, &bytes.Buffer{}).Export())
+
+	delete(archive.files, "ob_3.1_accounts_transactions_fca.json")
+	_, err = archive.RestoreDiscovery(dir)
+	require.EqualError(err, `RestoreDiscovery: manifest "ob_3.1_accounts_transactions_fca.json" is not in ZIP archive`)
+}
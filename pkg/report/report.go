@@ -7,6 +7,8 @@ import (
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/version"
 
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
+	"github.com/OpenBankingUK/conformance-suite/pkg/coverage"
 	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
 	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
 	"github.com/OpenBankingUK/conformance-suite/pkg/server/models"
@@ -40,6 +42,13 @@ type Report struct {
 	Products         []string           `json:"products"`                 // Products tested, e.g., "Business, Personal, Cards"
 	JWSStatus        string             `json:"jwsStatus"`                // Signature status
 	AgreedTC         bool               `json:"agreedTermsConditions"`    // Implementer acknowledged and agreed to T&C as displayed on the UI
+
+	// JWKSKeys - keys of the JWKS response signatures were verified with, including keys rotated during the run
+	JWKSKeys []authentication.JWKSKey `json:"jwksKeys,omitempty"`
+	// TLSAudits - TLS versions, cipher suites and certificates of the resource servers and the token endpoint
+	TLSAudits []discovery.TLSAudit `json:"tlsAudits,omitempty"`
+	// Coverage - spec operations, response codes and response properties of each API version the run exercised
+	Coverage []coverage.APICoverage `json:"coverage,omitempty"`
 }
 
 // APIVersionList is a sortable collection of API name and version pairs
@@ -133,6 +142,11 @@ func NewReport(exportResults models.ExportResults, environment string) (Report,
 		apiSpecs = append(apiSpecs, apiSpec)
 	}
 
+	apiCoverage, err := coverage.Calculate(exportResults.DiscoveryModel, exportResults.ResponseFields)
+	if err != nil {
+		return Report{}, err
+	}
+
 	sort.Sort(apiVersions)
 	return Report{
 		ID:               uuid.String(),
@@ -151,6 +165,9 @@ func NewReport(exportResults models.ExportResults, environment string) (Report,
 		Products:         exportResults.ExportRequest.Products,
 		JWSStatus:        exportResults.JWSStatus,
 		AgreedTC:         exportResults.ExportRequest.HasAgreed,
+		JWKSKeys:         exportResults.JWKSKeys,
+		TLSAudits:        exportResults.TLSAudits,
+		Coverage:         apiCoverage,
 	}, nil
 }
 
@@ -3,9 +3,19 @@ package report
 import (
 	"crypto/rsa"
 	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"time"
+
 	"github.com/dgrijalva/jwt-go"
 	"github.com/pkg/errors"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
+	internal_time "github.com/OpenBankingUK/conformance-suite/pkg/time"
 )
 
 /*
@@ -14,15 +24,25 @@ import (
 	The custom claims added to the token are as follows:
 	- reportDigest: SHA256 digest of the report file.
 	- discoveryDigest: SHA256 digest of the discovery file.
-	- manifestDigest: SHA256 digest of the manifest file.
+	- manifestDigest: SHA256 digest of the other files of the archive, see manifestDigest.
 
 	The intention is that during the signing process, the above digests are passed as claims. To verify the report
 	the overall JWT is validated first, then each of the above digests is matched against the calculated digest of
 	each of the files.
 
 	Note: The digests are represented as hex strings.
+
+	The certificate of the signing key is in the `x5c` header so a report can be verified on its own, see VerifyZip.
 */
 
+const (
+	signatureFilename = "signature.json"
+	// signatureIssuer - `iss` of report signatures
+	signatureIssuer = "https://openbanking.org.uk/fcs/reporting"
+	// SignatureType - `type` of the signature chain of a signed report
+	SignatureType = "PS256"
+)
+
 type reportClaims struct {
 	jwt.StandardClaims
 	ReportDigest    string `json:"reportDigest,omitempty"`
@@ -47,7 +67,7 @@ func (sc reportClaims) Valid() error {
 // - ReportDigest
 // - DiscoveryDigest
 // - ManifestDigest
-func sign(claims reportClaims, meta map[string]string, privateKey *rsa.PrivateKey) (string, error) {
+func sign(claims reportClaims, meta map[string]interface{}, privateKey *rsa.PrivateKey) (string, error) {
 	t := jwt.NewWithClaims(jwt.SigningMethodPS256, claims)
 
 	for k, v := range meta {
@@ -63,7 +83,10 @@ func sign(claims reportClaims, meta map[string]string, privateKey *rsa.PrivateKe
 }
 
 func verifySignature(rawJwt string, publicKey *rsa.PublicKey, claims reportClaims) error {
-	keyFunc := func(*jwt.Token) (interface{}, error) {
+	keyFunc := func(t *jwt.Token) (interface{}, error) {
+		if t.Method != jwt.SigningMethodPS256 {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
 		return publicKey, nil
 	}
 
@@ -114,3 +137,125 @@ func calculateDigest(data []byte) (string, error) {
 
 	return result, nil
 }
+
+// Signer - the RSA key exported reports are signed with and its certificate
+type Signer struct {
+	privateKey  *rsa.PrivateKey
+	certificate *x509.Certificate
+}
+
+// NewSigner - create a `Signer` from a PEM encoded PKCS #1 or PKCS #8 RSA private key and the PEM encoded
+// certificate of its public key.
+func NewSigner(privateKeyPem, certificatePem []byte) (*Signer, error) {
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privateKeyPem)
+	if err != nil {
+		return nil, errors.Wrap(err, "report signing key")
+	}
+	block, _ := pem.Decode(certificatePem)
+	if block == nil {
+		return nil, errors.New("report signing certificate: not PEM encoded")
+	}
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "report signing certificate")
+	}
+	if !privateKey.PublicKey.Equal(certificate.PublicKey) {
+		return nil, errors.New("report signing certificate is not the certificate of the signing key")
+	}
+	return &Signer{privateKey: privateKey, certificate: certificate}, nil
+}
+
+// Certificate - the certificate reports signed by the `Signer` are verified against
+func (s *Signer) Certificate() *x509.Certificate {
+	return s.certificate
+}
+
+// signatureChain returns the signature chain of a report signed with nonce `nonce`, it is part of the
+// report so it cannot hold the signature itself
+func (s *Signer) signatureChain(nonce string) (SignatureChain, error) {
+	kid, err := authentication.CalcKidFromPublicKey(s.certificate.PublicKey)
+	if err != nil {
+		return SignatureChain{}, err
+	}
+	return SignatureChain{
+		Type:    SignatureType,
+		Creator: s.certificate.Subject.String(),
+		Domain:  signatureIssuer,
+		Nounce:  nonce,
+		Value:   kid,
+	}, nil
+}
+
+// signFiles signs the digests of the files of a report archive, the `jti` is the nonce of the report signature
+// chain and the signature expires with the report.
+func (s *Signer) signFiles(report Report, chain SignatureChain, files map[string][]byte) (string, error) {
+	claims, err := digestClaims(files)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	claims.StandardClaims = jwt.StandardClaims{
+		Issuer:    signatureIssuer,
+		Subject:   report.ID,
+		Id:        chain.Nounce,
+		IssuedAt:  now.Unix(),
+		NotBefore: now.Unix(),
+	}
+	if report.Expiration != nil {
+		expiration, err := time.Parse(internal_time.Layout, *report.Expiration)
+		if err != nil {
+			return "", errors.Wrap(err, "report expiration")
+		}
+		claims.ExpiresAt = expiration.Unix()
+	}
+
+	meta := map[string]interface{}{
+		"kid": chain.Value,
+		"x5c": []string{base64.StdEncoding.EncodeToString(s.certificate.Raw)},
+	}
+	return sign(claims, meta, s.privateKey)
+}
+
+// digestClaims returns the digests of the report, discovery and other files of a report archive
+func digestClaims(files map[string][]byte) (reportClaims, error) {
+	reportDigest, err := calculateDigest(files[reportFilename])
+	if err != nil {
+		return reportClaims{}, err
+	}
+	discoveryDigest, err := calculateDigest(files[discoveryFilename])
+	if err != nil {
+		return reportClaims{}, err
+	}
+	manifestDigest, err := manifestDigest(files)
+	if err != nil {
+		return reportClaims{}, err
+	}
+	return reportClaims{
+		ReportDigest:    reportDigest,
+		DiscoveryDigest: discoveryDigest,
+		ManifestDigest:  manifestDigest,
+	}, nil
+}
+
+// manifestDigest - digest of every file of a report archive but the report, the discovery model and the signature:
+// the manifests, response fields and `manifest.json`. It is the digest of a line with the name and the digest of each file,
+// sorted by name, so a file cannot be renamed, added or removed without changing it.
+func manifestDigest(files map[string][]byte) (string, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		if name != reportFilename && name != discoveryFilename && name != signatureFilename {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	lines := []byte{}
+	for _, name := range names {
+		digest, err := calculateDigest(files[name])
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, fmt.Sprintf("%s %s\n", name, digest)...)
+	}
+	return calculateDigest(lines)
+}
@@ -1,10 +1,17 @@
 package report
 
 import (
+	"archive/zip"
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/pem"
 	"fmt"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/test"
 	"github.com/dgrijalva/jwt-go"
@@ -59,7 +66,7 @@ func TestReportSigning(t *testing.T) {
 	privateKey, err := x509.ParsePKCS1PrivateKey(pb.Bytes)
 	require.NoError(err, "parse private key")
 
-	meta := map[string]string{
+	meta := map[string]interface{}{
 		"header-foo": "header-bar",
 	}
 
@@ -191,3 +198,142 @@ func TestDigestVerification(t *testing.T) {
 	err := verifyDigest([]byte(input), hexSHA256)
 	require.NoError(err)
 }
+
+// sampleCertificate returns a PEM encoded self-signed certificate of the sample key
+func sampleCertificate(t *testing.T, commonName string) []byte {
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(samplePrivateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestNewSigner(t *testing.T) {
+	require := test.NewRequire(t)
+
+	signer, err := NewSigner([]byte(samplePrivateKey), sampleCertificate(t, "Conformance Suite"))
+	require.NoError(err)
+	require.NotNil(signer)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(err)
+	otherKeyPem := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(otherKey)})
+	_, err = NewSigner(otherKeyPem, sampleCertificate(t, "Conformance Suite"))
+	require.EqualError(err, "report signing certificate is not the certificate of the signing key")
+
+	_, err = NewSigner([]byte(samplePrivateKey), []byte(samplePublicKey))
+	require.Error(err)
+}
+
+func signedReportZip(t *testing.T, signer *Signer, report Report) []byte {
+	buff := &bytes.Buffer{}
+	if err := NewSignedZipExporter(report, signer, buff).Export(); err != nil {
+		t.Fatal(err)
+	}
+	return buff.Bytes()
+}
+
+func TestSignedZipExporterVerifyZip(t *testing.T) {
+	require := test.NewRequire(t)
+
+	certificatePem := sampleCertificate(t, "Conformance Suite")
+	signer, err := NewSigner([]byte(samplePrivateKey), certificatePem)
+	require.NoError(err)
+	block, _ := pem.Decode(certificatePem)
+	trusted, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(err)
+
+	expiration := time.Now().Add(time.Hour).Format(time.RFC3339)
+	report := Report{
+		ID:          "f47ac10b-58cc-4372-a567-0e02b2c3d479",
+		Created:     time.Now().Format(time.RFC3339),
+		Expiration:  &expiration,
+		Status:      StatusComplete,
+		CertifiedBy: CertifiedBy{Environment: CertifiedByEnvironmentTesting},
+	}
+	archive := signedReportZip(t, signer, report)
+
+	certificate, err := VerifyZip(bytes.NewReader(archive), trusted)
+	require.NoError(err)
+	require.Equal("CN=Conformance Suite", certificate.Subject.String())
+
+	imported, err := NewZipImporter(bytes.NewReader(archive)).Import()
+	require.NoError(err)
+	require.Len(*imported.SignatureChain, 1)
+	chain := (*imported.SignatureChain)[0]
+	require.Equal(SignatureType, chain.Type)
+	require.Equal("CN=Conformance Suite", chain.Creator)
+	require.NotEmpty(chain.Nounce)
+	files, err := readZipFiles(bytes.NewReader(archive))
+	require.NoError(err)
+	token, _, err := new(jwt.Parser).ParseUnverified(string(files[signatureFilename]), &reportClaims{})
+	require.NoError(err)
+	require.Equal(chain.Nounce, token.Claims.(*reportClaims).Id)
+	require.Equal(report.ID, token.Claims.(*reportClaims).Subject)
+	require.Equal(chain.Value, token.Header["kid"])
+
+	_, err = VerifyZip(bytes.NewReader(archive), nil)
+	require.NoError(err)
+
+	other, err := x509.ParseCertificate(mustDecodePEM(t, sampleCertificate(t, "Another Suite")))
+	require.NoError(err)
+	_, err = VerifyZip(bytes.NewReader(archive), other)
+	require.EqualError(err, `report is signed by an untrusted certificate "CN=Conformance Suite", not by the trusted certificate "CN=Another Suite"`)
+}
+
+func TestVerifyZipTampered(t *testing.T) {
+	require := test.NewRequire(t)
+
+	signer, err := NewSigner([]byte(samplePrivateKey), sampleCertificate(t, "Conformance Suite"))
+	require.NoError(err)
+	archive := signedReportZip(t, signer, Report{
+		ID:          "f47ac10b-58cc-4372-a567-0e02b2c3d479",
+		Status:      StatusComplete,
+		CertifiedBy: CertifiedBy{Environment: CertifiedByEnvironmentTesting},
+	})
+	files, err := readZipFiles(bytes.NewReader(archive))
+	require.NoError(err)
+
+	tamper := func(name string, contents []byte) []byte {
+		buff := &bytes.Buffer{}
+		zipWriter := zip.NewWriter(buff)
+		tampered := map[string][]byte{}
+		for fileName, fileContents := range files {
+			tampered[fileName] = fileContents
+		}
+		tampered[name] = contents
+		require.NoError(writeFiles(zipWriter, tampered))
+		require.NoError(zipWriter.Close())
+		return buff.Bytes()
+	}
+
+	_, err = VerifyZip(bytes.NewReader(tamper(reportFilename, []byte(`{"fails":0}`))), nil)
+	require.EqualError(err, "report digest mismatch")
+	_, err = VerifyZip(bytes.NewReader(tamper(discoveryFilename, []byte(`{}`))), nil)
+	require.EqualError(err, "discovery digest mismatch")
+	_, err = VerifyZip(bytes.NewReader(tamper("ob_3.1_accounts_transactions_fca.json", []byte(`{}`))), nil)
+	require.EqualError(err, "manifest digest mismatch")
+
+	buff := &bytes.Buffer{}
+	require.NoError(NewZipExporter(Report{Status: StatusComplete, CertifiedBy: CertifiedBy{Environment: CertifiedByEnvironmentTesting}}, buff).Export())
+	_, err = VerifyZip(buff, nil)
+	require.EqualError(err, "report is not signed, no signature.json in ZIP archive, so manifest.json does not show its files are unmodified")
+}
+
+func mustDecodePEM(t *testing.T, pemBytes []byte) []byte {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		t.Fatal("not PEM encoded")
+	}
+	return block.Bytes
+}
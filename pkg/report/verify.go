@@ -0,0 +1,140 @@
+package report
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+)
+
+// VerifyZip - verifies the `signature.json` of a report ZIP archive read from `reader`: the JWT is signed with PS256
+// by the key of its `x5c` certificate and not expired, and the report, discovery and manifest digests match the
+// files of the archive. When `trusted` is not nil the `x5c` certificate must be `trusted`.
+// Returns the certificate the report is signed with.
+func VerifyZip(reader io.Reader, trusted *x509.Certificate) (*x509.Certificate, error) {
+	files, err := readZipFiles(reader)
+	if err != nil {
+		return nil, err
+	}
+	return verifyFiles(files, trusted)
+}
+
+// verifyFiles verifies the `signature.json` of the files of a report ZIP archive, see VerifyZip
+func verifyFiles(files map[string][]byte, trusted *x509.Certificate) (*x509.Certificate, error) {
+	signature, ok := files[signatureFilename]
+	if !ok {
+		// anyone can recompute the SHA-256 of an edited file in `manifest.json`, only a signature shows it is unmodified
+		return nil, fmt.Errorf("report is not signed, no %s in ZIP archive, so %s does not show its files are unmodified", signatureFilename, archiveManifestFilename)
+	}
+
+	certificate, err := signatureCertificate(string(signature))
+	if err != nil {
+		return nil, err
+	}
+	if trusted != nil && !certificate.Equal(trusted) {
+		return nil, fmt.Errorf("report is signed by an untrusted certificate %q, not by the trusted certificate %q", certificate.Subject, trusted.Subject)
+	}
+	publicKey, ok := certificate.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("report signing certificate has a %T key, not an RSA key", certificate.PublicKey)
+	}
+
+	claims, err := digestClaims(files)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(string(signature), publicKey, claims); err != nil {
+		return nil, err
+	}
+	return certificate, nil
+}
+
+// signatureCertificate returns the first `x5c` certificate of a report signature, the signature is not verified
+func signatureCertificate(rawJwt string) (*x509.Certificate, error) {
+	token, _, err := new(jwt.Parser).ParseUnverified(rawJwt, &reportClaims{})
+	if err != nil {
+		return nil, errors.Wrap(err, "jwt.ParseUnverified()")
+	}
+	x5c, ok := token.Header["x5c"].([]interface{})
+	if !ok || len(x5c) == 0 {
+		return nil, errors.New("signature has no x5c header")
+	}
+	encoded, ok := x5c[0].(string)
+	if !ok {
+		return nil, errors.New("signature x5c header is not an array of strings")
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "signature x5c certificate")
+	}
+	certificate, err := x509.ParseCertificate(raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "signature x5c certificate")
+	}
+	return certificate, nil
+}
+
+const (
+	// maxZipArchiveSize is the largest report ZIP archive that is read
+	maxZipArchiveSize = 50 << 20
+	// maxZipUncompressedSize is the largest total size of the files of a report ZIP archive
+	maxZipUncompressedSize = 200 << 20
+	// maxZipFiles is the largest number of files of a report ZIP archive
+	maxZipFiles = 100
+)
+
+// readZipFiles returns the contents of the files of a ZIP archive by name, archives larger than maxZipArchiveSize,
+// with more than maxZipFiles files or with more than maxZipUncompressedSize of file contents are rejected
+func readZipFiles(reader io.Reader) (map[string][]byte, error) {
+	contents, err := readZipArchive(reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "readZipFiles")
+	}
+	zipReader, err := zip.NewReader(bytes.NewReader(contents), int64(len(contents)))
+	if err != nil {
+		return nil, errors.Wrap(err, "readZipFiles: zip.NewReader failed")
+	}
+	if len(zipReader.File) > maxZipFiles {
+		return nil, fmt.Errorf("readZipFiles: ZIP archive has %d files, more than %d", len(zipReader.File), maxZipFiles)
+	}
+
+	files := map[string][]byte{}
+	remaining := int64(maxZipUncompressedSize)
+	for _, file := range zipReader.File {
+		readerCloser, err := file.Open()
+		if err != nil {
+			return nil, errors.Wrapf(err, "readZipFiles: could not open %q", file.Name)
+		}
+		// the uncompressed size in the archive header can be forged, so the contents are limited as they are read
+		fileContents, err := ioutil.ReadAll(io.LimitReader(readerCloser, remaining+1))
+		readerCloser.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "readZipFiles: could not read %q", file.Name)
+		}
+		remaining -= int64(len(fileContents))
+		if remaining < 0 {
+			return nil, fmt.Errorf("readZipFiles: ZIP archive files are larger than %d bytes uncompressed", maxZipUncompressedSize)
+		}
+		files[file.Name] = fileContents
+	}
+	return files, nil
+}
+
+// readZipArchive reads a ZIP archive of at most maxZipArchiveSize bytes from `reader`
+func readZipArchive(reader io.Reader) ([]byte, error) {
+	contents, err := ioutil.ReadAll(io.LimitReader(reader, maxZipArchiveSize+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "read failed")
+	}
+	if len(contents) > maxZipArchiveSize {
+		return nil, fmt.Errorf("ZIP archive is larger than %d bytes", maxZipArchiveSize)
+	}
+	return contents, nil
+}
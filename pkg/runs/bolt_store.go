@@ -0,0 +1,98 @@
+package runs
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	runsBucket      = []byte("runs")
+	summariesBucket = []byte("summaries")
+)
+
+type boltStore struct {
+	db *bolt.DB
+}
+
+// NewBoltStore opens the BoltDB file at path, creating it if it does not exist.
+// Summaries are stored next to the runs so listing does not read every result.
+func NewBoltStore(path string) (Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, errors.Wrapf(err, "opening run store %s", path)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{runsBucket, summariesBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, errors.Wrapf(err, "creating run store buckets %s", path)
+	}
+
+	return &boltStore{db: db}, nil
+}
+
+func (s *boltStore) Save(run Run) error {
+	runJSON, err := json.Marshal(run)
+	if err != nil {
+		return errors.Wrap(err, "saving run")
+	}
+	summaryJSON, err := json.Marshal(run.Summary())
+	if err != nil {
+		return errors.Wrap(err, "saving run summary")
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(runsBucket).Put([]byte(run.ID), runJSON); err != nil {
+			return errors.Wrap(err, "saving run")
+		}
+		return errors.Wrap(tx.Bucket(summariesBucket).Put([]byte(run.ID), summaryJSON), "saving run summary")
+	})
+}
+
+func (s *boltStore) Get(id string) (Run, error) {
+	run := Run{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(runsBucket).Get([]byte(id))
+		if value == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(value, &run)
+	})
+	if err != nil {
+		return Run{}, err
+	}
+	return run, nil
+}
+
+func (s *boltStore) List() ([]Summary, error) {
+	summaries := []Summary{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(summariesBucket).ForEach(func(_, value []byte) error {
+			summary := Summary{}
+			if err := json.Unmarshal(value, &summary); err != nil {
+				return err
+			}
+			summaries = append(summaries, summary)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing runs")
+	}
+	sortSummaries(summaries)
+	return summaries, nil
+}
+
+func (s *boltStore) Close() error {
+	return s.db.Close()
+}
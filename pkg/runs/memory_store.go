@@ -0,0 +1,50 @@
+package runs
+
+import (
+	"sync"
+)
+
+type memoryStore struct {
+	runs map[string]Run
+	lock *sync.RWMutex
+}
+
+// NewMemoryStore creates a store keeping runs in memory, they are lost when the process exits
+func NewMemoryStore() Store {
+	return &memoryStore{
+		runs: map[string]Run{},
+		lock: &sync.RWMutex{},
+	}
+}
+
+func (s *memoryStore) Save(run Run) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.runs[run.ID] = run
+	return nil
+}
+
+func (s *memoryStore) Get(id string) (Run, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	run, ok := s.runs[id]
+	if !ok {
+		return Run{}, ErrNotFound
+	}
+	return run, nil
+}
+
+func (s *memoryStore) List() ([]Summary, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	summaries := make([]Summary, 0, len(s.runs))
+	for _, run := range s.runs {
+		summaries = append(summaries, run.Summary())
+	}
+	sortSummaries(summaries)
+	return summaries, nil
+}
+
+func (s *memoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,115 @@
+package runs
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
+)
+
+// ErrNotFound - no run is stored with the requested id
+var ErrNotFound = errors.New("run not found")
+
+// Store - keeps the history of test runs
+type Store interface {
+	Save(run Run) error
+	Get(id string) (Run, error)
+	List() ([]Summary, error)
+	Close() error
+}
+
+// Run - a completed test run
+type Run struct {
+	ID             string          `json:"id"`
+	StartedAt      time.Time       `json:"startedAt"`
+	CompletedAt    time.Time       `json:"completedAt"`
+	ConfigHash     string          `json:"configHash"` // SHA-256 of the configuration the run used
+	DiscoveryModel discovery.Model `json:"discoveryModel"`
+	Results        []Result        `json:"results"`
+	ResponseFields json.RawMessage `json:"responseFields,omitempty"`
+}
+
+// Result - a test case result of a run, keeping the API it belongs to
+type Result struct {
+	results.TestCase
+	API        string `json:"api"`
+	APIVersion string `json:"apiVersion"`
+}
+
+// Summary - describes a run without its results
+type Summary struct {
+	ID            string    `json:"id"`
+	StartedAt     time.Time `json:"startedAt"`
+	CompletedAt   time.Time `json:"completedAt"`
+	ConfigHash    string    `json:"configHash"`
+	DiscoveryName string    `json:"discoveryName"`
+	Total         int       `json:"total"`
+	Passed        int       `json:"passed"`
+	Failed        int       `json:"failed"`
+	Skipped       int       `json:"skipped"`
+}
+
+// NewRun creates a run from the results of its test cases, responseFields is ignored unless valid JSON
+func NewRun(id string, startedAt, completedAt time.Time, configHash string, discoveryModel discovery.Model, testCases []results.TestCase, responseFields string) Run {
+	run := Run{
+		ID:             id,
+		StartedAt:      startedAt,
+		CompletedAt:    completedAt,
+		ConfigHash:     configHash,
+		DiscoveryModel: discoveryModel,
+		Results:        make([]Result, 0, len(testCases)),
+	}
+	for _, testCase := range testCases {
+		run.Results = append(run.Results, Result{TestCase: testCase, API: testCase.API, APIVersion: testCase.APIVersion})
+	}
+	if json.Valid([]byte(responseFields)) {
+		run.ResponseFields = json.RawMessage(responseFields)
+	}
+	return run
+}
+
+// TestCases returns the results of the run's test cases
+func (r Run) TestCases() []results.TestCase {
+	testCases := make([]results.TestCase, 0, len(r.Results))
+	for _, result := range r.Results {
+		testCase := result.TestCase
+		testCase.API = result.API
+		testCase.APIVersion = result.APIVersion
+		testCases = append(testCases, testCase)
+	}
+	return testCases
+}
+
+// Summary returns the summary of the run
+func (r Run) Summary() Summary {
+	summary := Summary{
+		ID:            r.ID,
+		StartedAt:     r.StartedAt,
+		CompletedAt:   r.CompletedAt,
+		ConfigHash:    r.ConfigHash,
+		DiscoveryName: r.DiscoveryModel.DiscoveryModel.Name,
+		Total:         len(r.Results),
+	}
+	for _, result := range r.Results {
+		switch {
+		case result.Pass:
+			summary.Passed++
+		case result.Skipped:
+			summary.Skipped++
+		default:
+			summary.Failed++
+		}
+	}
+	return summary
+}
+
+// sortSummaries orders summaries most recent run first
+func sortSummaries(summaries []Summary) {
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].StartedAt.After(summaries[j].StartedAt)
+	})
+}
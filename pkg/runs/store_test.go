@@ -0,0 +1,104 @@
+package runs
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
+)
+
+func TestStores(t *testing.T) {
+	dir, err := ioutil.TempDir("", "runs")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	boltStore, err := NewBoltStore(filepath.Join(dir, "runs.db"))
+	require.NoError(t, err)
+
+	for name, store := range map[string]Store{"memory": NewMemoryStore(), "bolt": boltStore} {
+		t.Run(name, func(t *testing.T) {
+			defer store.Close()
+			first := newTestRun("run-1", time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC))
+			second := newTestRun("run-2", time.Date(2019, 6, 2, 12, 0, 0, 0, time.UTC))
+			require.NoError(t, store.Save(first))
+			require.NoError(t, store.Save(second))
+
+			summaries, err := store.List()
+			require.NoError(t, err)
+			require.Len(t, summaries, 2)
+			assert.Equal(t, "run-2", summaries[0].ID)
+			assert.Equal(t, Summary{
+				ID:            "run-1",
+				StartedAt:     first.StartedAt,
+				CompletedAt:   first.CompletedAt,
+				ConfigHash:    "abc",
+				DiscoveryName: "ob-v3.1-ozone",
+				Total:         3,
+				Passed:        1,
+				Failed:        1,
+				Skipped:       1,
+			}, summaries[1])
+
+			run, err := store.Get("run-1")
+			require.NoError(t, err)
+			require.Len(t, run.TestCases(), 3)
+			for i, testCase := range run.TestCases() {
+				expected := first.TestCases()[i]
+				assert.Equal(t, expected.Id, testCase.Id)
+				assert.Equal(t, expected.Pass, testCase.Pass)
+				assert.Equal(t, expected.Skipped, testCase.Skipped)
+				assert.Equal(t, expected.Metrics, testCase.Metrics)
+				assert.Equal(t, "accounts", testCase.API)
+				assert.Equal(t, "v3.1", testCase.APIVersion)
+			}
+			assert.JSONEq(t, `{"fields":[]}`, string(run.ResponseFields))
+
+			_, err = store.Get("run-3")
+			assert.True(t, errors.Is(err, ErrNotFound))
+		})
+	}
+}
+
+func TestBoltStorePersistsRuns(t *testing.T) {
+	dir, err := ioutil.TempDir("", "runs")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "runs.db")
+
+	store, err := NewBoltStore(path)
+	require.NoError(t, err)
+	require.NoError(t, store.Save(newTestRun("run-1", time.Now().UTC())))
+	require.NoError(t, store.Close())
+
+	store, err = NewBoltStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+	run, err := store.Get("run-1")
+	require.NoError(t, err)
+	assert.Len(t, run.Results, 3)
+}
+
+func TestNewRunIgnoresInvalidResponseFields(t *testing.T) {
+	run := NewRun("run-1", time.Now(), time.Now(), "abc", discovery.Model{}, nil, "")
+
+	assert.Nil(t, run.ResponseFields)
+	assert.Empty(t, run.Results)
+}
+
+func newTestRun(id string, startedAt time.Time) Run {
+	discoveryModel := discovery.Model{DiscoveryModel: discovery.ModelDiscovery{Name: "ob-v3.1-ozone"}}
+	testCases := []results.TestCase{
+		results.NewTestCaseResult("OB-301-ACC-120382", true, results.NewMetrics(nil, 1500*time.Microsecond, 120), nil, "/accounts", "accounts", "v3.1", "", "", "200 OK"),
+		results.NewTestCaseFail("OB-301-ACC-351286", results.NoMetrics(), []error{errors.New("status code 500")}, "/balances", "accounts", "v3.1", "", "", "500"),
+		results.NewTestCaseSkipped("OB-301-ACC-977272", []string{"OB-301-ACC-351286"}, "/balances/1", "accounts", "v3.1", "", ""),
+	}
+	return NewRun(id, startedAt, startedAt.Add(time.Minute), "abc", discoveryModel, testCases, `{"fields":[]}`)
+}
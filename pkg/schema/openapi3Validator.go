@@ -85,31 +85,39 @@ func (v OpenAPI3Validator) IsRequestProperty(checkmethod, checkpath, propertyPat
 }
 
 func getRouterForSpec(specName, version string) (routers.Router, *openapi3.T, error) {
+	doc, err := LoadOpenAPI3Spec(specName, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	router, err := legacyrouter.NewRouter(doc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot Load OpenApi Router for %s version %s", specName, version)
+	}
 
+	return router, doc, nil
+}
+
+// LoadOpenAPI3Spec - loads and validates the bundled OpenAPI 3 spec of an API version
+func LoadOpenAPI3Spec(specName, version string) (*openapi3.T, error) {
 	filenamePattern := getSpecFilePathPattern(specName)
 	if filenamePattern == "" {
-		return nil, nil, errors.New("cannot get router for spec: " + specName)
+		return nil, errors.New("cannot get router for spec: " + specName)
 	}
 
 	filename := fmt.Sprintf(filenamePattern, version)
 
 	doc, err := loadSpecFromFile(filename)
-
 	if err != nil {
-		return nil, nil, fmt.Errorf("cannot Load OpenApi Spec from file %s, %s", filename, err)
+		return nil, fmt.Errorf("cannot Load OpenApi Spec from file %s, %s", filename, err)
 	}
 
 	err = doc.Validate(context.Background())
 	if err != nil {
-		return nil, nil, fmt.Errorf("cannot Load OpenApi Spec from file %s, %s", filename, err)
+		return nil, fmt.Errorf("cannot Load OpenApi Spec from file %s, %s", filename, err)
 	}
 
-	router, err := legacyrouter.NewRouter(doc)
-	if err != nil {
-		return nil, nil, fmt.Errorf("cannot Load OpenApi Router for %s file %s", specName, filename)
-	}
-
-	return router, doc, nil
+	return doc, nil
 }
 
 func loadSpecFromFile(filename string) (*openapi3.T, error) {
@@ -0,0 +1,119 @@
+package schema
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// maxPropertyDepth - properties nested deeper are not listed, as the response field collector only records 20 levels
+const maxPropertyDepth = 20
+
+// SpecOperation - an operation of an OpenAPI 3 spec and its responses
+type SpecOperation struct {
+	Method    string
+	Path      string
+	Responses []SpecResponse
+}
+
+// SpecResponse - a response code of an operation and the properties of its JSON body, as dotted paths
+// without array indexes the way `schemaprops` records them, e.g. `Data.Account.Account.Identification`
+type SpecResponse struct {
+	Code       string
+	Properties []string
+}
+
+// SpecOperations - the operations of the bundled OpenAPI 3 spec of an API version, sorted by path and method
+func SpecOperations(specName, version string) ([]SpecOperation, error) {
+	doc, err := LoadOpenAPI3Spec(specName, version)
+	if err != nil {
+		return nil, err
+	}
+	return oas3SpecOperations(doc), nil
+}
+
+func oas3SpecOperations(doc *openapi3.T) []SpecOperation {
+	operations := []SpecOperation{}
+	for path, pathItem := range doc.Paths {
+		for method, op := range getOas3Operations(pathItem) {
+			operation := SpecOperation{Method: method, Path: path, Responses: []SpecResponse{}}
+			for code, response := range op.Responses {
+				operation.Responses = append(operation.Responses, SpecResponse{Code: code, Properties: oas3ResponseProperties(response)})
+			}
+			sort.Slice(operation.Responses, func(i, j int) bool {
+				return operation.Responses[i].Code < operation.Responses[j].Code
+			})
+			operations = append(operations, operation)
+		}
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		if operations[i].Path != operations[j].Path {
+			return operations[i].Path < operations[j].Path
+		}
+		return operations[i].Method < operations[j].Method
+	})
+	return operations
+}
+
+// oas3ResponseProperties lists the properties of the JSON body of a response, `application/json` is preferred
+// to the other JSON media types
+func oas3ResponseProperties(response *openapi3.ResponseRef) []string {
+	if response == nil || response.Value == nil {
+		return []string{}
+	}
+	mediaType := response.Value.Content.Get("application/json")
+	if mediaType == nil {
+		mediaTypes := make([]string, 0, len(response.Value.Content))
+		for name := range response.Value.Content {
+			if strings.Contains(name, "json") {
+				mediaTypes = append(mediaTypes, name)
+			}
+		}
+		sort.Strings(mediaTypes)
+		if len(mediaTypes) == 0 {
+			return []string{}
+		}
+		mediaType = response.Value.Content[mediaTypes[0]]
+	}
+	if mediaType.Schema == nil {
+		return []string{}
+	}
+
+	found := map[string]bool{}
+	collectOas3Properties(mediaType.Schema.Value, "", 0, found)
+	properties := make([]string, 0, len(found))
+	for property := range found {
+		properties = append(properties, property)
+	}
+	sort.Strings(properties)
+	return properties
+}
+
+// collectOas3Properties adds the properties of a schema below `prefix` to `found`, through array items and
+// the schemas it is composed of
+func collectOas3Properties(sc *openapi3.Schema, prefix string, depth int, found map[string]bool) {
+	if sc == nil || depth > maxPropertyDepth {
+		return
+	}
+	for name, property := range sc.Properties {
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		found[path] = true
+		if property != nil {
+			collectOas3Properties(property.Value, path, depth+1, found)
+		}
+	}
+	if sc.Items != nil {
+		collectOas3Properties(sc.Items.Value, prefix, depth+1, found)
+	}
+	for _, composed := range [][]*openapi3.SchemaRef{sc.AllOf, sc.OneOf, sc.AnyOf} {
+		for _, ref := range composed {
+			if ref != nil {
+				collectOas3Properties(ref.Value, prefix, depth+1, found)
+			}
+		}
+	}
+}
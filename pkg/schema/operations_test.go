@@ -0,0 +1,39 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+)
+
+func TestSpecOperations(t *testing.T) {
+	require := test.NewRequire(t)
+
+	operations, err := SpecOperations("Account and Transaction API Specification", "v3.1.10")
+	require.NoError(err)
+
+	require.Equal("/account-access-consents", operations[0].Path)
+	require.Equal("POST", operations[0].Method)
+	require.Equal("/account-access-consents/{ConsentId}", operations[1].Path)
+	require.Equal("DELETE", operations[1].Method)
+	require.Equal("GET", operations[2].Method)
+
+	var accounts SpecOperation
+	for _, operation := range operations {
+		if operation.Method == "GET" && operation.Path == "/accounts" {
+			accounts = operation
+		}
+	}
+	codes := []string{}
+	for _, response := range accounts.Responses {
+		codes = append(codes, response.Code)
+	}
+	require.Equal([]string{"200", "400", "401", "403", "405", "406", "429", "500"}, codes)
+	require.Subset(accounts.Responses[0].Properties, []string{
+		"Data", "Data.Account", "Data.Account.AccountId", "Data.Account.Account.Identification", "Links.Self", "Meta.TotalPages",
+	})
+	require.Subset(accounts.Responses[1].Properties, []string{"Code", "Errors", "Errors.ErrorCode"})
+
+	_, err = SpecOperations("Unknown API", "v3.1.10")
+	require.EqualError(err, "cannot get router for spec: Unknown API")
+}
@@ -9,6 +9,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 )
@@ -21,6 +22,7 @@ type PropertyCollector interface {
 }
 
 type Collector struct {
+	lock       *sync.Mutex // test cases can be run concurrently
 	level      int
 	currentApi int
 	path       []string
@@ -68,13 +70,20 @@ func GetPropertyCollector() PropertyCollector {
 }
 
 func MakeCollector() PropertyCollector {
-	c := &Collector{}
+	c := &Collector{lock: &sync.Mutex{}}
 	c.path = make([]string, 20)
 	c.Apis = []PropertyOutput{}
 	return c
 }
 
 func (c *Collector) SetCollectorAPIDetails(api, version string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.setCollectorAPIDetails(api, version)
+}
+
+// setCollectorAPIDetails expects c.lock to be held
+func (c *Collector) setCollectorAPIDetails(api, version string) {
 	p := PropertyOutput{Api: api, Version: version}
 	p.endpoints = make(map[string]map[string]int, 0)
 	c.Apis = append(c.Apis, p)
@@ -118,9 +127,12 @@ func sortPathStrings(m map[string]string) []string {
 }
 
 func (c *Collector) CollectProperties(method, endpoint, body string, code int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
 	if len(c.Apis) == 0 {
 		logrus.Warnln("Warning no API defined yet")
-		c.SetCollectorAPIDetails("undefined", "0.0")
+		c.setCollectorAPIDetails("undefined", "0.0")
 	}
 
 	requestPaths := make(map[string]int, 20)
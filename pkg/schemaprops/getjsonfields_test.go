@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"sync"
 	"testing"
 
 	"github.com/sirupsen/logrus"
@@ -5381,3 +5382,19 @@ var (
 	}
  }`)
 )
+
+func TestCollectPropertiesConcurrentlyWithoutAPIDefined(t *testing.T) {
+	c := MakeCollector()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.CollectProperties("GET", "https://myserver/open-banking/3.1/aisp/accounts", string(accounts), 200)
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, c.(*Collector).Apis, 1)
+}
@@ -1,6 +1,9 @@
 package server
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -10,15 +13,17 @@ import (
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
 	"github.com/OpenBankingUK/conformance-suite/pkg/server/models"
-	"gopkg.in/resty.v1"
 
 	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/go-ozzo/ozzo-validation/is"
 	"github.com/labstack/echo"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors"
 	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/permissions"
 )
 
 // ResponseType - Needs to be a interface{} slice, see the official test for an example
@@ -35,8 +40,7 @@ func responseTypesSupported() [3]ResponseType {
 }
 
 type configHandlers struct {
-	logger  *logrus.Entry
-	journey Journey
+	logger *logrus.Entry
 }
 
 // SupportedRequestSignAlg -
@@ -46,7 +50,7 @@ type SupportedRequestSignAlg interface{}
 
 // SupportedRequestSignAlgValues -
 func SupportedRequestSignAlgValues() []interface{} {
-	return []interface{}{"PS256", "RS256", "NONE"}
+	return []interface{}{"PS256", "RS256", "ES256", "EdDSA", "NONE"}
 }
 
 // SupportedAcrValues returns a slice of supported acr values to be used in the request object
@@ -71,6 +75,7 @@ type GlobalConfiguration struct {
 	ResponseType                  string                               `json:"response_type" validate:"not_empty"`
 	TokenEndpointAuthMethod       string                               `json:"token_endpoint_auth_method" validate:"not_empty"`
 	AuthorizationEndpoint         string                               `json:"authorization_endpoint" validate:"valid_url"`
+	PushedAuthorizationEndpoints  map[string]string                    `json:"pushed_authorization_request_endpoints,omitempty"` // by discovery item, consent is requested with a front-channel request object when empty
 	ResourceBaseURL               string                               `json:"resource_base_url" validate:"valid_url"`
 	XFAPIFinancialID              string                               `json:"x_fapi_financial_id" validate:"not_empty"`
 	XFAPICustomerIPAddress        string                               `json:"x_fapi_customer_ip_address,omitempty"`
@@ -89,6 +94,10 @@ type GlobalConfiguration struct {
 	AcrValuesSupported            []string                             `json:"acr_values_supported,omitempty"`
 	ConditionalProperties         []discovery.ConditionalAPIProperties `json:"conditional_properties,omitempty"`
 	CBPIIDebtorAccount            discovery.CBPIIDebtorAccount         `json:"cbpii_debtor_account"`
+	TestCaseWorkers               int                                  `json:"test_case_workers,omitempty"` // test cases of a specification run concurrently, sequential when 0 or 1
+	RetryPolicy                   *RetryConfiguration                  `json:"retry_policy,omitempty"`      // requests are sent once when not set
+	ClientAssertion               *ClientAssertionConfiguration        `json:"client_assertion,omitempty"`  // claims of private_key_jwt and client_secret_jwt assertions
+	ConsentResolver               string                               `json:"consent_resolver,omitempty"`  // greedy, heuristic or exact, the server's consent_resolver flag when not set
 	// Should be taken from the well-known endpoint:
 	Issuer string `json:"issuer" validate:"valid_url"`
 }
@@ -107,9 +116,86 @@ func (c GlobalConfiguration) Validate() error {
 		validation.Field(&c.RequestedExecutionDateTime, validation.By(futureDateTimeValidator)),
 		validation.Field(&c.PaymentFrequency, validation.Required),
 		validation.Field(&c.CBPIIDebtorAccount, validation.Required),
+		validation.Field(&c.TestCaseWorkers, validation.Min(0)),
+		validation.Field(&c.RetryPolicy),
+		validation.Field(&c.ClientAssertion),
+		validation.Field(&c.ConsentResolver, validation.By(consentResolverValidator)),
+		validation.Field(&c.PushedAuthorizationEndpoints, validation.By(pushedAuthorizationEndpointsValidator)),
 	)
 }
 
+// RetryConfiguration - how test case requests failing with a transient error
+// (connection reset, 429, 503, ...) are retried
+type RetryConfiguration struct {
+	MaxAttempts          int   `json:"max_attempts"`                     // total attempts per request including the first one
+	InitialBackoffMillis int   `json:"initial_backoff_ms,omitempty"`     // wait before the first retry, doubled for each following retry
+	MaxBackoffMillis     int   `json:"max_backoff_ms,omitempty"`         // upper bound of the wait between attempts, also caps Retry-After
+	RetryableStatusCodes []int `json:"retryable_status_codes,omitempty"` // defaults to 429, 502, 503 and 504
+	RetryPOST            bool  `json:"retry_post,omitempty"`             // POST requests may not be idempotent so are only retried when set
+}
+
+// Validate - used by https://github.com/go-ozzo/ozzo-validation to validate struct.
+func (c RetryConfiguration) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.MaxAttempts, validation.Min(0), validation.Max(10)),
+		validation.Field(&c.InitialBackoffMillis, validation.Min(0)),
+		validation.Field(&c.MaxBackoffMillis, validation.Min(0)),
+		validation.Field(&c.RetryableStatusCodes, validation.By(statusCodesValidator)),
+	)
+}
+
+// ClientAssertionConfiguration - claims of the JWT the client authenticates at the token endpoint with
+// when `token_endpoint_auth_method` is `private_key_jwt` or `client_secret_jwt`
+type ClientAssertionConfiguration struct {
+	Audience        string `json:"aud,omitempty"`              // defaults to the token endpoint
+	LifetimeSeconds int    `json:"lifetime_seconds,omitempty"` // exp is the time the assertion is issued plus the lifetime, defaults to 30 minutes
+	JTI             string `json:"jti,omitempty"`              // defaults to a new UUID for every assertion
+}
+
+// Validate - used by https://github.com/go-ozzo/ozzo-validation to validate struct.
+func (c ClientAssertionConfiguration) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Audience, is.URL),
+		validation.Field(&c.LifetimeSeconds, validation.Min(0)),
+	)
+}
+
+// claims converts the configuration to the authentication client assertion claims
+func (c *ClientAssertionConfiguration) claims() authentication.ClientAssertionClaims {
+	if c == nil {
+		return authentication.ClientAssertionClaims{}
+	}
+	return authentication.ClientAssertionClaims{
+		Audience: c.Audience,
+		Lifetime: time.Duration(c.LifetimeSeconds) * time.Second,
+		JTI:      c.JTI,
+	}
+}
+
+func statusCodesValidator(value interface{}) error {
+	statusCodes, _ := value.([]int)
+	for _, statusCode := range statusCodes {
+		if statusCode < 100 || statusCode > 599 {
+			return fmt.Errorf("invalid status code %d", statusCode)
+		}
+	}
+	return nil
+}
+
+// retryPolicy converts the configuration to the executors retry policy
+func (c *RetryConfiguration) retryPolicy() executors.RetryPolicy {
+	if c == nil {
+		return executors.RetryPolicy{}
+	}
+	return executors.RetryPolicy{
+		MaxAttempts:          c.MaxAttempts,
+		InitialBackoff:       time.Duration(c.InitialBackoffMillis) * time.Millisecond,
+		MaxBackoff:           time.Duration(c.MaxBackoffMillis) * time.Millisecond,
+		RetryableStatusCodes: c.RetryableStatusCodes,
+		RetryPOST:            c.RetryPOST,
+	}
+}
+
 func futureDateTimeValidator(value interface{}) error {
 	dateTimeStr, ok := value.(string)
 	if !ok {
@@ -126,6 +212,29 @@ func futureDateTimeValidator(value interface{}) error {
 	return nil
 }
 
+func consentResolverValidator(value interface{}) error {
+	mode, ok := value.(string)
+	if !ok || mode == "" {
+		return nil
+	}
+	_, err := permissions.ParseResolverMode(mode)
+	return err
+}
+
+func pushedAuthorizationEndpointsValidator(value interface{}) error {
+	endpoints, ok := value.(map[string]string)
+	if !ok {
+		return nil
+	}
+	for key, endpoint := range endpoints {
+		if err := is.URL.Validate(endpoint); err != nil {
+			return fmt.Errorf("pushedAuthorizationEndpointsValidator: `pushed_authorization_request_endpoints` invalid value of %s: %s", key, err)
+		}
+	}
+
+	return nil
+}
+
 func acrValuesValidator(value interface{}) error {
 	values, ok := value.([]string)
 	if !ok {
@@ -154,16 +263,15 @@ func strSliceContains(slice []string, str string) bool {
 	return false
 }
 
-func newConfigHandlers(journey Journey, logger *logrus.Entry) configHandlers {
+func newConfigHandlers(logger *logrus.Entry) configHandlers {
 	return configHandlers{
-		journey: journey,
-		logger:  logger.WithField("module", "configHandlers"),
+		logger: logger.WithField("module", "configHandlers"),
 	}
 }
 
 // GET /api/config/conditional-property
 func (h configHandlers) configConditionalPropertyHandler(c echo.Context) error {
-	conditionalProperties := h.journey.ConditionalProperties()
+	conditionalProperties := journeyFrom(c).ConditionalProperties()
 	filteredProps := make([]discovery.ConditionalAPIProperties, 0, len(conditionalProperties))
 	for _, v := range conditionalProperties {
 		if len(v.Endpoints) > 0 {
@@ -189,10 +297,7 @@ func (h configHandlers) configGlobalPostHandler(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
 	}
 
-	// Use the transport keys for MATLS as some endpoints require this
-	resty.SetCertificates(journeyConfig.certificateTransport.TLSCert())
-
-	err = h.journey.SetConfig(journeyConfig)
+	err = journeyFrom(c).SetConfig(journeyConfig)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
 	}
@@ -200,6 +305,51 @@ func (h configHandlers) configGlobalPostHandler(c echo.Context) error {
 	return c.JSON(http.StatusCreated, config)
 }
 
+// ConfigCheckRequest - the configuration to check before a run, the JWKS of the TPP signing key
+// is only checked when its URI is set
+type ConfigCheckRequest struct {
+	GlobalConfiguration
+	TPPSignatureJWKSURI string `json:"tpp_signature_jwks_uri,omitempty"`
+}
+
+// ConfigCheckResponse - the checklist of the certificates of a configuration, valid when every check passes
+type ConfigCheckResponse struct {
+	Valid  bool                              `json:"valid"`
+	Checks []authentication.CertificateCheck `json:"checks"`
+}
+
+// POST /api/config/check
+func (h configHandlers) configCheckPostHandler(c echo.Context) error {
+	request := new(ConfigCheckRequest)
+	if err := c.Bind(request); err != nil {
+		return c.JSON(http.StatusBadRequest, NewErrorResponse(errors.Wrap(err, "error with Bind")))
+	}
+
+	return c.JSON(http.StatusOK, checkConfig(request, time.Now(), mtlsCheckTimeout))
+}
+
+// mtlsCheckTimeout - time after which the MTLS handshake with the token endpoint of a configuration check is abandoned
+const mtlsCheckTimeout = 10 * time.Second
+
+// checkConfig checks the signing and transport certificates of a configuration and the MTLS handshake
+// with its token endpoint
+func checkConfig(request *ConfigCheckRequest, now time.Time, timeout time.Duration) ConfigCheckResponse {
+	checks := authentication.CheckCertificate(authentication.CertificateUsageSigning, request.SigningPublic, request.SigningPrivate, now)
+	checks = append(checks, authentication.CheckSigningKid(request.SigningPublic, request.TPPSignatureKID, request.TPPSignatureJWKSURI)...)
+	checks = append(checks, authentication.CheckCertificate(authentication.CertificateUsageTransport, request.TransportPublic, request.TransportPrivate, now)...)
+	if request.TokenEndpoint != "" {
+		checks = append(checks, authentication.CheckMTLSHandshake(request.TransportPublic, request.TransportPrivate, request.TokenEndpoint, timeout))
+	}
+
+	response := ConfigCheckResponse{Valid: true, Checks: checks}
+	for _, check := range checks {
+		if !check.Pass {
+			response.Valid = false
+		}
+	}
+	return response
+}
+
 // MakeJourneyConfig -
 func MakeJourneyConfig(config *GlobalConfiguration) (JourneyConfig, error) {
 	ok, message := validateConfig(config)
@@ -217,6 +367,19 @@ func MakeJourneyConfig(config *GlobalConfiguration) (JourneyConfig, error) {
 		return JourneyConfig{}, errors.Wrap(err, "error with transport certificate")
 	}
 
+	configHash, err := hashConfig(config)
+	if err != nil {
+		return JourneyConfig{}, errors.Wrap(err, "error hashing config")
+	}
+
+	var consentResolver permissions.ResolverMode
+	if config.ConsentResolver != "" {
+		consentResolver, err = permissions.ParseResolverMode(config.ConsentResolver)
+		if err != nil {
+			return JourneyConfig{}, err
+		}
+	}
+
 	return JourneyConfig{
 		certificateSigning:            certificateSigning,
 		certificateTransport:          certificateTransport,
@@ -231,6 +394,7 @@ func MakeJourneyConfig(config *GlobalConfiguration) (JourneyConfig, error) {
 		ResponseType:                  config.ResponseType,
 		tokenEndpointAuthMethod:       config.TokenEndpointAuthMethod,
 		authorizationEndpoint:         config.AuthorizationEndpoint,
+		pushedAuthorizationEndpoints:  config.PushedAuthorizationEndpoints,
 		resourceBaseURL:               config.ResourceBaseURL,
 		xXFAPIFinancialID:             config.XFAPIFinancialID,
 		xXFAPICustomerIPAddress:       config.XFAPICustomerIPAddress,
@@ -250,9 +414,24 @@ func MakeJourneyConfig(config *GlobalConfiguration) (JourneyConfig, error) {
 		conditionalProperties:         config.ConditionalProperties,
 		cbpiiDebtorAccount:            config.CBPIIDebtorAccount,
 		issuer:                        config.Issuer, // TBD: available from well-known ?
+		testCaseWorkers:               config.TestCaseWorkers,
+		retryPolicy:                   config.RetryPolicy.retryPolicy(),
+		clientAssertion:               config.ClientAssertion.claims(),
+		consentResolver:               consentResolver,
+		configHash:                    configHash,
 	}, nil
 }
 
+// hashConfig returns the hex encoded SHA-256 of the configuration, identifying runs made with the same configuration
+func hashConfig(config *GlobalConfiguration) (string, error) {
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(configJSON)
+	return hex.EncodeToString(hash[:]), nil
+}
+
 func validateConfig(config *GlobalConfiguration) (bool, string) {
 	rules := parseRules(config)
 	for _, rule := range rules {
@@ -7,10 +7,13 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
 	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors"
 	"github.com/OpenBankingUK/conformance-suite/pkg/generation"
 	"github.com/OpenBankingUK/conformance-suite/pkg/model"
 	"github.com/sirupsen/logrus"
@@ -896,3 +899,70 @@ func testJourney() Journey {
 	testGenerator := generation.NewGenerator()
 	return NewJourney(logger, testGenerator, validatorEngine, discovery.NewNullTLSValidator(), false)
 }
+
+func TestRetryConfigurationValidate(t *testing.T) {
+	assert.NoError(t, RetryConfiguration{MaxAttempts: 3, RetryableStatusCodes: []int{429, 503}}.Validate())
+	assert.Error(t, RetryConfiguration{MaxAttempts: -1}.Validate())
+	assert.Error(t, RetryConfiguration{MaxAttempts: 3, InitialBackoffMillis: -1}.Validate())
+	assert.Error(t, RetryConfiguration{MaxAttempts: 3, RetryableStatusCodes: []int{42}}.Validate())
+}
+
+func TestRetryConfigurationRetryPolicy(t *testing.T) {
+	var unset *RetryConfiguration
+	assert.Equal(t, executors.RetryPolicy{}, unset.retryPolicy())
+
+	config := &RetryConfiguration{MaxAttempts: 3, InitialBackoffMillis: 250, MaxBackoffMillis: 4000, RetryableStatusCodes: []int{503}, RetryPOST: true}
+	assert.Equal(t, executors.RetryPolicy{
+		MaxAttempts:          3,
+		InitialBackoff:       250 * time.Millisecond,
+		MaxBackoff:           4 * time.Second,
+		RetryableStatusCodes: []int{503},
+		RetryPOST:            true,
+	}, config.retryPolicy())
+}
+
+func TestConsentResolverValidator(t *testing.T) {
+	assert.NoError(t, consentResolverValidator(""))
+	assert.NoError(t, consentResolverValidator("exact"))
+	assert.EqualError(t, consentResolverValidator("fastest"), `unknown consent resolver "fastest", expected one of greedy, heuristic or exact`)
+}
+
+func TestServerConfigCheckPost(t *testing.T) {
+	require := test.NewRequire(t)
+	server := NewServer(testJourney(), nullLogger(), &mocks.Version{})
+	defer func() {
+		require.NoError(server.Shutdown(context.TODO()))
+	}()
+
+	configJSON, err := json.Marshal(ConfigCheckRequest{
+		GlobalConfiguration: GlobalConfiguration{
+			SigningPrivate:   privateKey,
+			SigningPublic:    publicKey,
+			TransportPrivate: privateKey,
+			TransportPublic:  "------------",
+		},
+	})
+	require.NoError(err)
+
+	code, body, headers := request(http.MethodPost, "/api/config/check", bytes.NewReader(configJSON), server)
+
+	require.Equal(http.StatusOK, code)
+	require.Equal(expectedJSONHeaders(), headers)
+	response := ConfigCheckResponse{}
+	require.NoError(json.Unmarshal(body.Bytes(), &response))
+	require.False(response.Valid)
+	last := response.Checks[len(response.Checks)-1]
+	require.Equal(authentication.CertificateCheck{
+		Certificate: authentication.CertificateUsageTransport,
+		Name:        authentication.CertificateCheckCertificate,
+		Detail:      "certificate is not PEM encoded",
+	}, last)
+	for _, check := range response.Checks {
+		if check.Name == authentication.CertificateCheckKeyPair {
+			require.True(check.Pass, check.Detail)
+		}
+	}
+
+	code, _, _ = request(http.MethodPost, "/api/config/check", strings.NewReader("{"), server)
+	require.Equal(http.StatusBadRequest, code)
+}
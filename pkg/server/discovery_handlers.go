@@ -16,6 +16,8 @@ import (
 const (
 	defaultTxnFrom = "2016-01-01T10:40:00+02:00"
 	defaultTxnTo   = "2025-12-31T10:40:00+02:00"
+	// discoveryItemKeyPrefix - the response maps are keyed by discovery item, `schema_version=<schema version>`
+	discoveryItemKeyPrefix = "schema_version="
 )
 
 // PostDiscoveryModelResponse -
@@ -26,6 +28,7 @@ type PostDiscoveryModelResponse struct {
 	RequestObjectSigningAlgValuesSupported        map[string][]string `json:"request_object_signing_alg_values_supported"`
 	DefaultRequestObjectSigningAlgValuesSupported map[string]string   `json:"default_request_object_signing_alg_values_supported"`
 	AuthorizationEndpoints                        map[string]string   `json:"authorization_endpoints"`
+	PushedAuthorizationRequestEndpoints           map[string]string   `json:"pushed_authorization_request_endpoints"`
 	Issuers                                       map[string]string   `json:"issuers"`
 	DefaultTxnFromDateTime                        string              `json:"default_transaction_from_date"`
 	DefaultTxnToDateTime                          string              `json:"default_transaction_to_date"`
@@ -38,12 +41,11 @@ type validationFailuresResponse struct {
 }
 
 type discoveryHandlers struct {
-	webJourney Journey
-	logger     *logrus.Entry
+	logger *logrus.Entry
 }
 
-func newDiscoveryHandlers(webJourney Journey, logger *logrus.Entry) discoveryHandlers {
-	return discoveryHandlers{webJourney, logger.WithField("handler", "discoveryHandlers")}
+func newDiscoveryHandlers(logger *logrus.Entry) discoveryHandlers {
+	return discoveryHandlers{logger.WithField("handler", "discoveryHandlers")}
 }
 
 func (d discoveryHandlers) setDiscoveryModelHandler(c echo.Context) error {
@@ -56,7 +58,7 @@ func (d discoveryHandlers) setDiscoveryModelHandler(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
 	}
 
-	failures, err := d.webJourney.SetDiscoveryModel(discoveryModel)
+	failures, err := journeyFrom(c).SetDiscoveryModel(discoveryModel)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
 	}
@@ -73,14 +75,15 @@ func (d discoveryHandlers) setDiscoveryModelHandler(c echo.Context) error {
 		RequestObjectSigningAlgValuesSupported:        map[string][]string{},
 		DefaultRequestObjectSigningAlgValuesSupported: map[string]string{},
 		AuthorizationEndpoints:                        map[string]string{},
+		PushedAuthorizationRequestEndpoints:           map[string]string{},
 		Issuers:                                       map[string]string{},
 		ResponseTypesSupported:                        []string{},
 		AcrValuesSupported:                            []string{},
 	}
 
-	configGetter := authentication.NewOpenIdConfigGetter()
+	configGetter := authentication.DefaultOpenIdConfigGetter
 	for discoveryItemIndex, discoveryItem := range discoveryModel.DiscoveryModel.DiscoveryItems {
-		key := fmt.Sprintf("schema_version=%s", discoveryItem.APISpecification.SchemaVersion)
+		key := discoveryItemKeyPrefix + discoveryItem.APISpecification.SchemaVersion
 
 		url := discoveryItem.OpenidConfigurationURI
 		ctxLogger.WithFields(logrus.Fields{
@@ -94,15 +97,24 @@ func (d discoveryHandlers) setDiscoveryModelHandler(c echo.Context) error {
 			}).Error("Error on /.well-known/openid-configuration")
 			failures = append(failures, newOpenidConfigurationURIFailure(discoveryItemIndex, e))
 		} else {
-			var SupportedRequestSignAlgValues = []string{"PS256", "RS256", "NONE"}
+			var SupportedRequestSignAlgValues = []string{"PS256", "RS256", "ES256", "EdDSA", "NONE"}
 			requestObjectSigningAlgValuesSupported := sets.InsensitiveIntersection(config.RequestObjectSigningAlgValuesSupported, SupportedRequestSignAlgValues)
 			if len(requestObjectSigningAlgValuesSupported) == 0 {
 				return errors.New("no supported request object signing alg found")
 			}
 
+			journeyFrom(c).SetJWKSURI(config.JwksURI)
 			response.TokenEndpoints[key] = config.TokenEndpoint
 			response.AuthorizationEndpoints[key] = config.AuthorizationEndpoint
 			response.Issuers[key] = config.Issuer
+			parEndpoint, e := pushedAuthorizationRequestEndpoint(discoveryItem, config)
+			if e != nil {
+				failures = append(failures, discovery.ValidationFailure{
+					Key:   fmt.Sprintf("DiscoveryModel.DiscoveryItems[%d].PushedAuthorizationRequests", discoveryItemIndex),
+					Error: e.Error(),
+				})
+			}
+			response.PushedAuthorizationRequestEndpoints[key] = parEndpoint
 			response.TokenEndpointAuthMethods[key] = authentication.SuiteSupportedAuthMethodsMostSecureFirst()
 			response.DefaultTokenEndpointAuthMethod[key] = authentication.DefaultAuthMethod(config.TokenEndpointAuthMethodsSupported, d.logger)
 			response.RequestObjectSigningAlgValuesSupported[key] = requestObjectSigningAlgValuesSupported
@@ -126,3 +138,21 @@ func newOpenidConfigurationURIFailure(discoveryItemIndex int, err error) discove
 		Error: err.Error(),
 	}
 }
+
+// pushedAuthorizationRequestEndpoint returns the endpoint consent is pushed to for a discovery item, empty when
+// consent is requested with a front-channel request object
+func pushedAuthorizationRequestEndpoint(item discovery.ModelDiscoveryItem, config authentication.OpenIDConfiguration) (string, error) {
+	if item.PushedAuthorizationRequests == nil {
+		return config.PushedAuthorizationRequestEndpoint, nil
+	}
+	if !*item.PushedAuthorizationRequests {
+		if config.RequirePushedAuthorizationRequests {
+			return "", errors.New("pushed authorization requests are disabled but the openid configuration requires them")
+		}
+		return "", nil
+	}
+	if config.PushedAuthorizationRequestEndpoint == "" {
+		return "", errors.New("pushed authorization requests are enabled but the openid configuration has no pushed_authorization_request_endpoint")
+	}
+	return config.PushedAuthorizationRequestEndpoint, nil
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
+	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+)
+
+func TestPushedAuthorizationRequestEndpoint(t *testing.T) {
+	enabled, disabled := true, false
+	advertised := authentication.OpenIDConfiguration{PushedAuthorizationRequestEndpoint: "https://aspsp.example.com/par"}
+	required := authentication.OpenIDConfiguration{
+		PushedAuthorizationRequestEndpoint: "https://aspsp.example.com/par",
+		RequirePushedAuthorizationRequests: true,
+	}
+
+	testCases := []struct {
+		name     string
+		item     discovery.ModelDiscoveryItem
+		config   authentication.OpenIDConfiguration
+		endpoint string
+		err      string
+	}{
+		{name: "used when advertised", config: advertised, endpoint: "https://aspsp.example.com/par"},
+		{name: "not used when not advertised", config: authentication.OpenIDConfiguration{}},
+		{name: "disabled", item: discovery.ModelDiscoveryItem{PushedAuthorizationRequests: &disabled}, config: advertised},
+		{
+			name:   "disabled when required",
+			item:   discovery.ModelDiscoveryItem{PushedAuthorizationRequests: &disabled},
+			config: required,
+			err:    "pushed authorization requests are disabled but the openid configuration requires them",
+		},
+		{
+			name:   "enabled when not advertised",
+			item:   discovery.ModelDiscoveryItem{PushedAuthorizationRequests: &enabled},
+			config: authentication.OpenIDConfiguration{},
+			err:    "pushed authorization requests are enabled but the openid configuration has no pushed_authorization_request_endpoint",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoint, err := pushedAuthorizationRequestEndpoint(tc.item, tc.config)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.endpoint, endpoint)
+		})
+	}
+}
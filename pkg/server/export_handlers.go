@@ -2,6 +2,7 @@ package server
 
 import (
 	"bytes"
+	"io"
 	"net/http"
 
 	"github.com/pkg/errors"
@@ -17,17 +18,24 @@ import (
 // MIME types
 const (
 	MIMEApplicationZIP = "application/zip"
+	MIMETextHTML       = echo.MIMETextHTMLCharsetUTF8
 )
 
+// reportSigner - the key reports exported with `add_digital_signature` are signed with, reports cannot be signed when nil
+var reportSigner *report.Signer
+
+// SetReportSigner - sets the key reports exported with `add_digital_signature` are signed with
+func SetReportSigner(signer *report.Signer) {
+	reportSigner = signer
+}
+
 type exportHandlers struct {
-	journey Journey
-	logger  *logrus.Entry
+	logger *logrus.Entry
 }
 
-func newExportHandlers(journey Journey, logger *logrus.Entry) exportHandlers {
+func newExportHandlers(logger *logrus.Entry) exportHandlers {
 	return exportHandlers{
-		journey: journey,
-		logger:  logger.WithField("handler", "exportHandlers"),
+		logger: logger.WithField("handler", "exportHandlers"),
 	}
 }
 
@@ -45,13 +53,34 @@ func (h exportHandlers) postExport(c echo.Context) error {
 
 	logger.WithField("request", request).Info("Exporting ...")
 
-	results := h.journey.Results().AllResultsGrouped()
-	responseFields := h.journey.Results().ResponseFieldsJSON()
-	tokens := h.journey.Events().AllAcquiredAccessToken()
-	discovery, err := h.journey.DiscoveryModel()
+	buff := bytes.NewBuffer([]byte{})
+	if err := exportReport(journeyFrom(c), request, buff); err != nil {
+		return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
+	}
+
+	if request.ExportFormat() == models.ExportFormatHTML {
+		return c.Blob(http.StatusOK, MIMETextHTML, buff.Bytes())
+	}
+	if request.ExportFormat() == models.ExportFormatCoverage {
+		return c.JSONBlob(http.StatusOK, buff.Bytes())
+	}
 
+	// TODO(mbana): Might help to return these, if not remove in the future.
+	// name := "report.zip"
+	// dispositionType := "attachment"
+	// c.Response().Header().Set(HeaderContentDisposition, fmt.Sprintf("%s; filename=%q", dispositionType, name))
+	// c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="report.zip"`)
+	return c.Blob(http.StatusOK, MIMEApplicationZIP, buff.Bytes())
+}
+
+// exportReport - builds the report for the journey's current run and writes it to `writer` in the requested format.
+func exportReport(journey Journey, request models.ExportRequest, writer io.Writer) error {
+	results := journey.Results().AllResultsGrouped()
+	responseFields := journey.Results().ResponseFieldsJSON()
+	tokens := journey.Events().AllAcquiredAccessToken()
+	discovery, err := journey.DiscoveryModel()
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, NewErrorResponse(errors.Wrap(err, "exporting report-get journey discovery model")))
+		return errors.Wrap(err, "exporting report-get journey discovery model")
 	}
 
 	exportResults := models.ExportResults{
@@ -60,26 +89,28 @@ func (h exportHandlers) postExport(c echo.Context) error {
 		Results:          results,
 		Tokens:           tokens,
 		DiscoveryModel:   discovery,
-		TLSVersionResult: h.journey.TLSVersionResult(),
+		TLSVersionResult: journey.TLSVersionResult(),
 		ResponseFields:   responseFields,
 		JWSStatus:        model.JWSStatus(),
+		JWKSKeys:         journey.JWKSKeys(),
+		TLSAudits:        journey.TLSAudits(),
 	}
 
 	r, err := report.NewReport(exportResults, request.Environment)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
+		return err
 	}
 
-	buff := bytes.NewBuffer([]byte{})
-	exporter := report.NewZipExporter(r, buff)
-	if err := exporter.Export(); err != nil {
-		return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
+	exporter := report.NewZipExporter(r, writer)
+	if request.ExportFormat() == models.ExportFormatHTML {
+		exporter = report.NewHTMLExporter(r, writer)
+	} else if request.ExportFormat() == models.ExportFormatCoverage {
+		exporter = report.NewCoverageExporter(r, writer)
+	} else if request.AddDigitalSignature {
+		if reportSigner == nil {
+			return errors.New("add_digital_signature is set but no report signing key is configured")
+		}
+		exporter = report.NewSignedZipExporter(r, reportSigner, writer)
 	}
-
-	// TODO(mbana): Might help to return these, if not remove in the future.
-	// name := "report.zip"
-	// dispositionType := "attachment"
-	// c.Response().Header().Set(HeaderContentDisposition, fmt.Sprintf("%s; filename=%q", dispositionType, name))
-	// c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="report.zip"`)
-	return c.Blob(http.StatusOK, MIMEApplicationZIP, buff.Bytes())
+	return exporter.Export()
 }
@@ -3,11 +3,18 @@ package server
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
+	"math/big"
 	"net/http"
 	"testing"
 	"time"
 
+	"github.com/OpenBankingUK/conformance-suite/pkg/coverage"
 	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
 	discovery_mocks "github.com/OpenBankingUK/conformance-suite/pkg/discovery/mocks"
 	gmocks "github.com/OpenBankingUK/conformance-suite/pkg/generation"
@@ -114,6 +121,151 @@ func TestServerPostExport(t *testing.T) {
 	require.Equal(expected.JobTitle, actual.CertifiedBy.JobTitle)
 }
 
+func TestServerPostExportHTML(t *testing.T) {
+	require := test.NewRequire(t)
+
+	discoveryModel := &discovery.Model{}
+	validator := &discovery_mocks.Validator{}
+	validator.On("Validate", discoveryModel).Return(discovery.NoValidationFailures(), nil)
+	generator := &gmocks.MockGenerator{}
+	journey := NewJourney(nullLogger(), generator, validator, discovery.NewNullTLSValidator(), false)
+
+	failures, err := journey.SetDiscoveryModel(discoveryModel)
+	require.NoError(err)
+	require.Equal(discovery.NoValidationFailures(), failures)
+
+	server := NewServer(journey, nullLogger(), &version_mocks.Version{})
+	defer func() {
+		require.NoError(server.Shutdown(context.TODO()))
+	}()
+
+	requestJSON, err := json.Marshal(models.ExportRequest{
+		Environment:  "sandbox",
+		Implementer:  "implementer",
+		AuthorisedBy: "authorised_by",
+		JobTitle:     "job_title",
+		Products:     []string{"Business"},
+		Format:       models.ExportFormatHTML,
+	})
+	require.NoError(err)
+
+	code, body, headers := request(http.MethodPost, "/api/export", bytes.NewReader(requestJSON), server)
+
+	require.Equal(http.StatusOK, code, body.String())
+	require.Equal(MIMETextHTML, headers.Get(echo.HeaderContentType))
+	require.Contains(body.String(), "<!DOCTYPE html>")
+	require.Contains(body.String(), "<dt>Implementer</dt><dd>implementer</dd>")
+}
+
+func TestServerPostExportCoverage(t *testing.T) {
+	require := test.NewRequire(t)
+
+	discoveryModel := &discovery.Model{DiscoveryModel: discovery.ModelDiscovery{DiscoveryItems: []discovery.ModelDiscoveryItem{{
+		APISpecification: discovery.ModelAPISpecification{
+			Name:          "Account and Transaction API Specification",
+			Version:       "v3.1.10",
+			SchemaVersion: "https://raw.githubusercontent.com/OpenBankingUK/read-write-api-specs/v3.1.10/dist/openapi/account-info-openapi.json",
+		},
+		Endpoints: []discovery.ModelEndpoint{{Method: "GET", Path: "/accounts"}},
+	}}}}
+	validator := &discovery_mocks.Validator{}
+	validator.On("Validate", discoveryModel).Return(discovery.NoValidationFailures(), nil)
+	journey := NewJourney(nullLogger(), &gmocks.MockGenerator{}, validator, discovery.NewNullTLSValidator(), false)
+	_, err := journey.SetDiscoveryModel(discoveryModel)
+	require.NoError(err)
+
+	server := NewServer(journey, nullLogger(), &version_mocks.Version{})
+	defer func() {
+		require.NoError(server.Shutdown(context.TODO()))
+	}()
+
+	requestJSON, err := json.Marshal(models.ExportRequest{
+		Environment:  "sandbox",
+		Implementer:  "implementer",
+		AuthorisedBy: "authorised_by",
+		JobTitle:     "job_title",
+		Products:     []string{"Business"},
+		Format:       models.ExportFormatCoverage,
+	})
+	require.NoError(err)
+
+	code, body, headers := request(http.MethodPost, "/api/export", bytes.NewReader(requestJSON), server)
+
+	require.Equal(http.StatusOK, code, body.String())
+	require.Equal(echo.MIMEApplicationJSONCharsetUTF8, headers.Get(echo.HeaderContentType))
+	apiCoverage := []coverage.APICoverage{}
+	require.NoError(json.Unmarshal(body.Bytes(), &apiCoverage))
+	require.Len(apiCoverage, 1)
+	require.Equal("v3.1.10", apiCoverage[0].Version)
+	require.Equal(0, apiCoverage[0].OperationsHit.Exercised)
+	require.NotZero(apiCoverage[0].OperationsHit.Total)
+}
+
+func TestServerPostExportSigned(t *testing.T) {
+	require := test.NewRequire(t)
+
+	discoveryModel := &discovery.Model{}
+	validator := &discovery_mocks.Validator{}
+	validator.On("Validate", discoveryModel).Return(discovery.NoValidationFailures(), nil)
+	journey := NewJourney(nullLogger(), &gmocks.MockGenerator{}, validator, discovery.NewNullTLSValidator(), false)
+	_, err := journey.SetDiscoveryModel(discoveryModel)
+	require.NoError(err)
+
+	server := NewServer(journey, nullLogger(), &version_mocks.Version{})
+	defer func() {
+		require.NoError(server.Shutdown(context.TODO()))
+		SetReportSigner(nil)
+	}()
+
+	requestJSON, err := json.Marshal(models.ExportRequest{
+		Environment:         "sandbox",
+		Implementer:         "implementer",
+		AuthorisedBy:        "authorised_by",
+		JobTitle:            "job_title",
+		Products:            []string{"Business"},
+		AddDigitalSignature: true,
+	})
+	require.NoError(err)
+
+	code, body, _ := request(http.MethodPost, "/api/export", bytes.NewReader(requestJSON), server)
+	require.Equal(http.StatusBadRequest, code)
+	require.JSONEq(`{"error": "add_digital_signature is set but no report signing key is configured"}`, body.String())
+
+	SetReportSigner(testReportSigner(t))
+
+	code, body, _ = request(http.MethodPost, "/api/export", bytes.NewReader(requestJSON), server)
+	require.Equal(http.StatusOK, code, body.String())
+	archive := body.Bytes()
+	certificate, err := report.VerifyZip(bytes.NewReader(archive), nil)
+	require.NoError(err)
+	require.Equal("CN=Conformance Suite", certificate.Subject.String())
+	imported, err := report.NewZipImporter(bytes.NewReader(archive)).Import()
+	require.NoError(err)
+	require.Len(*imported.SignatureChain, 1)
+}
+
+// testReportSigner - a report signer with a self-signed "Conformance Suite" certificate
+func testReportSigner(t *testing.T) *report.Signer {
+	require := test.NewRequire(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Conformance Suite"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(err)
+	signer, err := report.NewSigner(
+		pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	)
+	require.NoError(err)
+	return signer
+}
+
 func TestServerPostExport_InvalidRequest(t *testing.T) {
 	require := test.NewRequire(t)
 
@@ -201,6 +353,19 @@ func TestServerPostExport_InvalidRequest(t *testing.T) {
 			},
 			err: `{"error":"products: pkg/server/models.ExportRequest: 'products' ([\"Business\" \"Invalid_Product\"]) invalid value provided (\"Invalid_Product\")."}`,
 		},
+		{
+			request: models.ExportRequest{
+				Environment:  "sandbox",
+				Implementer:  "implementer",
+				AuthorisedBy: "authorised_by",
+				JobTitle:     "job_title",
+				Products: []string{
+					"Business",
+				},
+				Format: "pdf",
+			},
+			err: `{"error":"format: must be a valid value."}`,
+		},
 	}
 
 	for _, testCase := range testCases {
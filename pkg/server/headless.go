@@ -0,0 +1,141 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
+	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
+	"github.com/OpenBankingUK/conformance-suite/pkg/server/models"
+)
+
+var errHeadlessTokensNotCollected = errors.New("headless run requires all tokens to be acquired without a PSU, use a discovery model with headless token acquisition")
+
+// HeadlessRunner - drives a `Journey` in-process through the same steps the web UI takes,
+// without a TLS server, a websocket or a browser.
+//
+// The steps are:
+// 1. SetDiscoveryModel - validate the discovery model and fetch each well-known openid configuration
+// 2. SetConfig - validate and set the global configuration
+// 3. TestCases - generate the test cases and acquire the tokens headlessly
+// 4. RunTests - run the test cases and wait until they have all completed
+// 5. Export - write the report ZIP archive
+type HeadlessRunner struct {
+	journey Journey
+	logger  *logrus.Entry
+}
+
+// NewHeadlessRunner - returns a `HeadlessRunner` for `journey`.
+func NewHeadlessRunner(journey Journey, logger *logrus.Entry) HeadlessRunner {
+	return HeadlessRunner{
+		journey: journey,
+		logger:  logger.WithField("module", "HeadlessRunner"),
+	}
+}
+
+// Run - runs the whole conformance journey and writes the exported report to `writer` in the requested format.
+// The results of all the test cases that were run are returned.
+func (r HeadlessRunner) Run(discoveryModel *discovery.Model, config *GlobalConfiguration, request models.ExportRequest, writer io.Writer) ([]results.TestCase, error) {
+	if err := request.Validate(); err != nil {
+		return nil, errors.Wrap(err, "headless run: invalid export request")
+	}
+
+	if err := r.setDiscoveryModel(discoveryModel); err != nil {
+		return nil, err
+	}
+
+	if err := r.setConfig(config); err != nil {
+		return nil, err
+	}
+
+	r.journey.NewDaemonController()
+	if _, err := r.journey.TestCases(); err != nil {
+		return nil, errors.Wrap(err, "headless run: generating test cases")
+	}
+
+	if !r.journey.AllTokenCollected() {
+		return nil, errHeadlessTokensNotCollected
+	}
+
+	if err := r.journey.RunTests(); err != nil {
+		return nil, errors.Wrap(err, "headless run: running test cases")
+	}
+	testCases := r.waitForResults()
+
+	if err := exportReport(r.journey, request, writer); err != nil {
+		return nil, errors.Wrap(err, "headless run: exporting report")
+	}
+
+	r.logger.WithField("results", len(testCases)).Info("headless run completed")
+	return testCases, nil
+}
+
+func (r HeadlessRunner) setDiscoveryModel(discoveryModel *discovery.Model) error {
+	failures, err := r.journey.SetDiscoveryModel(discoveryModel)
+	if err != nil {
+		return errors.Wrap(err, "headless run: setting discovery model")
+	}
+	if !failures.Empty() {
+		return newValidationFailuresError("headless run: invalid discovery model", failures)
+	}
+
+	failures = discovery.ValidationFailures{}
+	configGetter := authentication.DefaultOpenIdConfigGetter
+	for discoveryItemIndex, discoveryItem := range discoveryModel.DiscoveryModel.DiscoveryItems {
+		config, err := configGetter.Get(discoveryItem.OpenidConfigurationURI)
+		if err != nil {
+			failures = append(failures, newOpenidConfigurationURIFailure(discoveryItemIndex, err))
+			continue
+		}
+		r.journey.SetJWKSURI(config.JwksURI)
+	}
+	if !failures.Empty() {
+		return newValidationFailuresError("headless run: fetching openid configuration", failures)
+	}
+
+	return nil
+}
+
+func (r HeadlessRunner) setConfig(config *GlobalConfiguration) error {
+	if err := config.Validate(); err != nil {
+		return errors.Wrap(err, "headless run: invalid config")
+	}
+
+	journeyConfig, err := MakeJourneyConfig(config)
+	if err != nil {
+		return errors.Wrap(err, "headless run: invalid config")
+	}
+
+	return errors.Wrap(r.journey.SetConfig(journeyConfig), "headless run: setting config")
+}
+
+// waitForResults - drains the results channel until the run signals it has completed.
+// Results are accumulated by the daemon controller so they do not need to be kept here,
+// but the channel still needs a reader otherwise the runner would block once its buffer is full.
+func (r HeadlessRunner) waitForResults() []results.TestCase {
+	daemon := r.journey.Results()
+	for {
+		select {
+		case result := <-daemon.Results():
+			r.logger.WithFields(logrus.Fields{
+				"id":   result.Id,
+				"pass": result.Pass,
+			}).Debug("test case result")
+		case <-daemon.IsCompleted():
+			return daemon.AllResults()
+		}
+	}
+}
+
+func newValidationFailuresError(message string, failures discovery.ValidationFailures) error {
+	reasons := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		reasons = append(reasons, fmt.Sprintf("%s: %v", failure.Key, failure.Error))
+	}
+	return fmt.Errorf("%s: %s", message, strings.Join(reasons, "; "))
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
+	"github.com/OpenBankingUK/conformance-suite/pkg/server/models"
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+)
+
+func headlessExportRequest() models.ExportRequest {
+	return models.ExportRequest{
+		Environment:  "sandbox",
+		Implementer:  "implementer",
+		AuthorisedBy: "authorised_by",
+		JobTitle:     "job_title",
+		Products:     []string{"Business"},
+	}
+}
+
+func TestHeadlessRunnerRejectsInvalidExportRequest(t *testing.T) {
+	require := test.NewRequire(t)
+
+	journey := &MockJourney{}
+	runner := NewHeadlessRunner(journey, nullLogger())
+
+	testCases, err := runner.Run(&discovery.Model{}, &GlobalConfiguration{}, models.ExportRequest{}, &bytes.Buffer{})
+
+	require.Error(err)
+	require.Contains(err.Error(), "invalid export request")
+	require.Nil(testCases)
+	journey.AssertNotCalled(t, "SetDiscoveryModel", mock.Anything)
+}
+
+func TestHeadlessRunnerReturnsDiscoveryValidationFailures(t *testing.T) {
+	require := test.NewRequire(t)
+
+	discoveryModel := &discovery.Model{}
+	failures := discovery.ValidationFailures{
+		{Key: "DiscoveryModel.Name", Error: "Field 'Name' is required"},
+	}
+	journey := &MockJourney{}
+	journey.On("SetDiscoveryModel", discoveryModel).Return(failures, nil)
+	runner := NewHeadlessRunner(journey, nullLogger())
+
+	testCases, err := runner.Run(discoveryModel, &GlobalConfiguration{}, headlessExportRequest(), &bytes.Buffer{})
+
+	require.EqualError(err, "headless run: invalid discovery model: DiscoveryModel.Name: Field 'Name' is required")
+	require.Nil(testCases)
+	journey.AssertNotCalled(t, "TestCases")
+}
+
+func TestHeadlessRunnerWaitForResults(t *testing.T) {
+	require := test.NewRequire(t)
+
+	daemon := executors.NewBufferedDaemonController()
+	journey := &MockJourney{}
+	journey.On("Results").Return(daemon)
+	runner := NewHeadlessRunner(journey, nullLogger())
+
+	expected := []results.TestCase{
+		results.NewTestCaseResult("1", true, results.NoMetrics(), nil, "/accounts", "Account and Transaction API Specification", "v3.1", "", "", "200"),
+		results.NewTestCaseResult("2", false, results.NoMetrics(), nil, "/balances", "Account and Transaction API Specification", "v3.1", "", "", "400"),
+	}
+	go func() {
+		for _, result := range expected {
+			daemon.AddResult(result)
+		}
+		daemon.SetCompleted()
+	}()
+
+	require.Equal(expected, runner.waitForResults())
+}
@@ -1,81 +1,135 @@
-// This is still WORK IN PROGRESS. The handlers just return either an empty
-// `github.com/OpenBankingUK/conformance-suite/pkg/server/models.ImportReviewResponse` or
-//  `github.com/OpenBankingUK/conformance-suite/pkg/server/models.ImportRerunResponse` and do not do the
-// importing or review functionality. This will be implemented as we go along.
-
 package server
 
 import (
+	"bytes"
+	"crypto/x509"
+	"io/ioutil"
 	"net/http"
+	"os"
 
 	"github.com/labstack/echo"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	"github.com/OpenBankingUK/conformance-suite/pkg/report"
 	"github.com/OpenBankingUK/conformance-suite/pkg/server/models"
 )
 
 type importHandlers struct {
-	journey Journey
-	logger  *logrus.Entry
+	logger *logrus.Entry
 }
 
-func newImportHandlers(journey Journey, logger *logrus.Entry) importHandlers {
+func newImportHandlers(logger *logrus.Entry) importHandlers {
 	return importHandlers{
-		journey: journey,
-		logger:  logger.WithField("handler", "importHandlers"),
+		logger: logger.WithField("handler", "importHandlers"),
 	}
 }
 
 // postImportReview - `/api/import/review` POST.
+// Returns the report of the archive with whether its signature is valid, an archive whose files do not match
+// its `manifest.json` is rejected. An unsigned archive, or one not signed by the configured report signing
+// certificate, is reviewed with a signature error, as its `manifest.json` does not show it is unmodified.
 func (h importHandlers) postImportReview(c echo.Context) error {
 	logger := h.logger.WithField("function", "postImportReview")
 
-	request := models.ImportRequest{}
-	if err := c.Bind(&request); err != nil {
+	archive, err := h.doImport(c, logger)
+	if err != nil {
 		return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
 	}
 
-	if err := request.Validate(); err != nil {
-		return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
+	response := models.ImportReviewResponse{
+		Report: archive.ReportJSON(),
+		Signed: archive.Signed(),
 	}
-
-	if err := h.doImport(request, logger); err != nil {
-		return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
+	certificate, err := verifyImportSignature(archive)
+	if err != nil {
+		response.SignatureError = err.Error()
+	} else {
+		response.SignatureValid = true
+		response.SignedBy = certificate.Subject.String()
 	}
-
-	response := models.ImportReviewResponse{}
-	logger.Info("Imported")
+	logger.WithField("report.ID", archive.Report.ID).Info("Imported")
 
 	return c.JSON(http.StatusOK, response)
 }
 
 // postImportRerun - `/api/import/rerun` POST.
+// Restores the discovery model and manifests of the archive into the journey so the run can be repeated,
+// an archive whose files do not match its `manifest.json`, that is not signed by the configured report signing
+// certificate or with an invalid signature is rejected.
 func (h importHandlers) postImportRerun(c echo.Context) error {
 	logger := h.logger.WithField("function", "postImportRerun")
 
-	request := models.ImportRequest{}
-	if err := c.Bind(&request); err != nil {
+	archive, err := h.doImport(c, logger)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
+	}
+	if _, err := verifyImportSignature(archive); err != nil {
 		return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
 	}
 
-	if err := request.Validate(); err != nil {
+	dir, err := ioutil.TempDir("", "fcs-import-")
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, NewErrorResponse(err))
+	}
+	discoveryModel, err := archive.RestoreDiscovery(dir)
+	if err != nil {
+		removeImportDir(dir, logger)
 		return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
 	}
 
-	if err := h.doImport(request, logger); err != nil {
+	journey := journeyFrom(c)
+	failures, err := journey.SetDiscoveryModel(&discoveryModel)
+	if err != nil {
+		removeImportDir(dir, logger)
 		return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
 	}
+	if !failures.Empty() {
+		removeImportDir(dir, logger)
+		return c.JSON(http.StatusBadRequest, validationFailuresResponse{failures})
+	}
+	// the manifests of the previous import are no longer used by the discovery model
+	journey.SetImportDir(dir)
 
-	response := models.ImportRerunResponse{}
-	logger.Info("Imported")
+	response := models.ImportRerunResponse{
+		ReportID:       archive.Report.ID,
+		DiscoveryModel: discoveryModel,
+	}
+	logger.WithFields(logrus.Fields{"report.ID": archive.Report.ID, "dir": dir}).Info("Restored discovery model")
 
 	return c.JSON(http.StatusOK, response)
 }
 
-// nolint:unparam
-func (h importHandlers) doImport(request models.ImportRequest, logger *logrus.Entry) error {
-	// logger.WithField("request", request).Info("Importing ...")
-	logger.WithField("len(request.Report)", len(request.Report)).Info("Importing ...")
-	// TODO(mbana): Do something with `report.zip`
-	return nil
+// verifyImportSignature verifies the signature of an imported archive against the certificate of the configured
+// report signer: anyone can sign an edited archive with a self-signed `x5c` certificate of their own.
+func verifyImportSignature(archive report.Archive) (*x509.Certificate, error) {
+	if reportSigner == nil {
+		return nil, errors.New("report signature cannot be verified, no report signing certificate is configured")
+	}
+	return archive.VerifySignature(reportSigner.Certificate())
+}
+
+// removeImportDir removes the directory the manifests of an archive that was not rerun were restored to
+func removeImportDir(dir string, logger *logrus.Entry) {
+	if err := os.RemoveAll(dir); err != nil {
+		logger.WithError(err).WithField("dir", dir).Error("removing import directory")
+	}
+}
+
+// doImport binds and validates the request and reads its report ZIP archive
+func (h importHandlers) doImport(c echo.Context, logger *logrus.Entry) (report.Archive, error) {
+	request := models.ImportRequest{}
+	if err := c.Bind(&request); err != nil {
+		return report.Archive{}, err
+	}
+	if err := request.Validate(); err != nil {
+		return report.Archive{}, err
+	}
+
+	zipArchive, err := request.ZipArchive()
+	if err != nil {
+		return report.Archive{}, err
+	}
+	logger.WithField("len(zipArchive)", len(zipArchive)).Info("Importing ...")
+	return report.ReadZipArchive(bytes.NewReader(zipArchive))
 }
@@ -1,61 +1,224 @@
 package server
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/mock"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+	discovery_mocks "github.com/OpenBankingUK/conformance-suite/pkg/discovery/mocks"
+	gmocks "github.com/OpenBankingUK/conformance-suite/pkg/generation"
+	"github.com/OpenBankingUK/conformance-suite/pkg/report"
 	"github.com/OpenBankingUK/conformance-suite/pkg/server/models"
 	"github.com/OpenBankingUK/conformance-suite/pkg/test"
 	"github.com/OpenBankingUK/conformance-suite/pkg/version/mocks"
 )
 
+// importRequestJSON - an import request with `archive` as a base64 data URL, as the UI sends it
+func importRequestJSON(t *testing.T, archive []byte) []byte {
+	requestJSON, err := json.MarshalIndent(models.ImportRequest{
+		Report: "data:application/zip;base64," + base64.StdEncoding.EncodeToString(archive),
+	}, marshalIndentPrefix, marshalIndentindent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return requestJSON
+}
+
+// exportedReportZip - a report ZIP archive exported with a discovery model of one manifest, signed by `signer`
+// unless nil
+func exportedReportZip(t *testing.T, signer *report.Signer) []byte {
+	buff := &bytes.Buffer{}
+	toExport := report.Report{
+		ID:          "f47ac10b-58cc-4372-a567-0e02b2c3d479",
+		Status:      report.StatusComplete,
+		CertifiedBy: report.CertifiedBy{Environment: report.CertifiedByEnvironmentTesting},
+		Discovery: discovery.Model{
+			DiscoveryModel: discovery.ModelDiscovery{
+				DiscoveryItems: []discovery.ModelDiscoveryItem{
+					{APISpecification: discovery.ModelAPISpecification{Manifest: "file://manifests/ob_3.1_accounts_transactions_fca.json"}},
+				},
+			},
+		},
+	}
+	exporter := report.NewZipExporter(toExport, buff)
+	if signer != nil {
+		exporter = report.NewSignedZipExporter(toExport, signer, buff)
+	}
+	if err := exporter.Export(); err != nil {
+		t.Fatal(err)
+	}
+	return buff.Bytes()
+}
+
 func TestServerImportHandlersPostImportReview(t *testing.T) {
 	require := test.NewRequire(t)
 
 	server := NewServer(testJourney(), nullLogger(), &mocks.Version{})
 	defer func() {
 		require.NoError(server.Shutdown(context.TODO()))
+		SetReportSigner(nil)
 	}()
 	require.NotNil(server)
-
-	report, err := ioutil.ReadFile("./testdata/report.zip")
-	require.NoError(err)
-
-	importRequest := models.ImportRequest{
-		Report: string(report),
-	}
-	requestJSON, err := json.MarshalIndent(importRequest, marshalIndentPrefix, marshalIndentindent)
-	require.NoError(err)
-	require.NotNil(requestJSON)
+	signer := testReportSigner(t)
+	SetReportSigner(signer)
 
 	// make the request
-	code, body, headers := request(http.MethodPost, "/api/import/review", bytes.NewReader(requestJSON), server)
+	code, body, headers := request(http.MethodPost, "/api/import/review", bytes.NewReader(importRequestJSON(t, exportedReportZip(t, signer))), server)
 
 	// do assertions
+	require.Equal(http.StatusOK, code, body.String())
 	response := models.ImportReviewResponse{}
-	responseJSON, err := json.MarshalIndent(response, marshalIndentPrefix, marshalIndentindent)
-	require.NoError(err)
-	require.NotNil(responseJSON)
-
-	require.NotNil(body)
-	bodyExpected := string(responseJSON)
-	bodyActual := body.String()
-	require.JSONEq(bodyExpected, bodyActual)
+	require.NoError(json.Unmarshal(body.Bytes(), &response))
+	require.True(response.Signed)
+	require.True(response.SignatureValid)
+	require.Equal("CN=Conformance Suite", response.SignedBy)
+	imported := report.Report{}
+	require.NoError(json.Unmarshal(response.Report, &imported))
+	require.Equal("f47ac10b-58cc-4372-a567-0e02b2c3d479", imported.ID)
 
-	require.Equal(http.StatusOK, code)
 	// No gzip compression on this route
 	require.Equal(http.Header{
 		"Content-Type": []string{
 			"application/json; charset=UTF-8",
 		},
 	}, headers)
+
+	// an unsigned archive is reviewed, flagged as its manifest could have been rewritten
+	code, body, _ = request(http.MethodPost, "/api/import/review", bytes.NewReader(importRequestJSON(t, exportedReportZip(t, nil))), server)
+	require.Equal(http.StatusOK, code, body.String())
+	response = models.ImportReviewResponse{}
+	require.NoError(json.Unmarshal(body.Bytes(), &response))
+	require.False(response.Signed)
+	require.False(response.SignatureValid)
+	require.Equal("report is not signed, no signature.json in ZIP archive, so manifest.json does not show its files are unmodified", response.SignatureError)
+
+	// an archive signed by another key is reviewed, flagged as anyone can sign an edited archive
+	code, body, _ = request(http.MethodPost, "/api/import/review", bytes.NewReader(importRequestJSON(t, exportedReportZip(t, testReportSigner(t)))), server)
+	require.Equal(http.StatusOK, code, body.String())
+	response = models.ImportReviewResponse{}
+	require.NoError(json.Unmarshal(body.Bytes(), &response))
+	require.True(response.Signed)
+	require.False(response.SignatureValid)
+	require.Empty(response.SignedBy)
+	require.Equal(`report is signed by an untrusted certificate "CN=Conformance Suite", not by the trusted certificate "CN=Conformance Suite"`, response.SignatureError)
+
+	// a signature cannot be verified without a report signing certificate
+	SetReportSigner(nil)
+	code, body, _ = request(http.MethodPost, "/api/import/review", bytes.NewReader(importRequestJSON(t, exportedReportZip(t, signer))), server)
+	require.Equal(http.StatusOK, code, body.String())
+	response = models.ImportReviewResponse{}
+	require.NoError(json.Unmarshal(body.Bytes(), &response))
+	require.True(response.Signed)
+	require.False(response.SignatureValid)
+	require.Equal("report signature cannot be verified, no report signing certificate is configured", response.SignatureError)
+
+	// an archive exported before archives had a `manifest.json` cannot be checked
+	archive, err := ioutil.ReadFile("./testdata/report.zip")
+	require.NoError(err)
+	code, body, _ = request(http.MethodPost, "/api/import/review", bytes.NewReader(importRequestJSON(t, archive)), server)
+	require.Equal(http.StatusBadRequest, code)
+	require.JSONEq(`{"error": "zipImporter.Import: no manifest.json in ZIP archive"}`, body.String())
+
+	code, body, _ = request(http.MethodPost, "/api/import/review", strings.NewReader(`{"report": "not base64"}`), server)
+	require.Equal(http.StatusBadRequest, code)
+	require.Contains(body.String(), "report: not base64 encoded")
 }
 
 func TestServerImportHandlersPostImportRerun(t *testing.T) {
-	// TODO(mbana): need to implement rerun functionality and write tests.
-	t.Skip()
+	require := test.NewRequire(t)
+
+	validator := &discovery_mocks.Validator{}
+	validator.On("Validate", mock.Anything).Return(discovery.NoValidationFailures(), nil)
+	journey := NewJourney(nullLogger(), &gmocks.MockGenerator{}, validator, discovery.NewNullTLSValidator(), false)
+	server := NewServer(journey, nullLogger(), &mocks.Version{})
+	defer func() {
+		require.NoError(server.Shutdown(context.TODO()))
+		SetReportSigner(nil)
+	}()
+	signer := testReportSigner(t)
+	SetReportSigner(signer)
+
+	code, body, _ := request(http.MethodPost, "/api/import/rerun", bytes.NewReader(importRequestJSON(t, exportedReportZip(t, signer))), server)
+	require.Equal(http.StatusOK, code, body.String())
+	response := models.ImportRerunResponse{}
+	require.NoError(json.Unmarshal(body.Bytes(), &response))
+	require.Equal("f47ac10b-58cc-4372-a567-0e02b2c3d479", response.ReportID)
+
+	discoveryModel, err := journey.DiscoveryModel()
+	require.NoError(err)
+	require.Equal(response.DiscoveryModel, discoveryModel)
+	manifest := discoveryModel.DiscoveryModel.DiscoveryItems[0].APISpecification.Manifest
+	require.True(strings.HasSuffix(manifest, "/ob_3.1_accounts_transactions_fca.json"), manifest)
+	defer os.RemoveAll(filepath.Dir(strings.TrimPrefix(manifest, "file://")))
+	restored, err := ioutil.ReadFile(strings.TrimPrefix(manifest, "file://"))
+	require.NoError(err)
+	original, err := ioutil.ReadFile("../../manifests/ob_3.1_accounts_transactions_fca.json")
+	require.NoError(err)
+	require.Equal(original, restored)
+
+	// a rerun removes the manifests restored by the previous one
+	code, body, _ = request(http.MethodPost, "/api/import/rerun", bytes.NewReader(importRequestJSON(t, exportedReportZip(t, signer))), server)
+	require.Equal(http.StatusOK, code, body.String())
+	rerun := models.ImportRerunResponse{}
+	require.NoError(json.Unmarshal(body.Bytes(), &rerun))
+	rerunDir := filepath.Dir(strings.TrimPrefix(rerun.DiscoveryModel.DiscoveryModel.DiscoveryItems[0].APISpecification.Manifest, "file://"))
+	defer os.RemoveAll(rerunDir)
+	require.NotEqual(filepath.Dir(strings.TrimPrefix(manifest, "file://")), rerunDir)
+	_, err = os.Stat(strings.TrimPrefix(manifest, "file://"))
+	require.True(os.IsNotExist(err), err)
+	journey.SetImportDir("")
+	_, err = os.Stat(rerunDir)
+	require.True(os.IsNotExist(err), err)
+
+	// an unsigned archive is not rerun
+	code, body, _ = request(http.MethodPost, "/api/import/rerun", bytes.NewReader(importRequestJSON(t, exportedReportZip(t, nil))), server)
+	require.Equal(http.StatusBadRequest, code)
+	require.JSONEq(`{"error": "report is not signed, no signature.json in ZIP archive, so manifest.json does not show its files are unmodified"}`, body.String())
+
+	// an archive signed by another key is not rerun, its manifests could have been edited and re-signed
+	code, body, _ = request(http.MethodPost, "/api/import/rerun", bytes.NewReader(importRequestJSON(t, exportedReportZip(t, testReportSigner(t)))), server)
+	require.Equal(http.StatusBadRequest, code)
+	require.JSONEq(`{"error": "report is signed by an untrusted certificate \"CN=Conformance Suite\", not by the trusted certificate \"CN=Conformance Suite\""}`, body.String())
+
+	// nor is any archive without a report signing certificate to verify it against
+	SetReportSigner(nil)
+	code, body, _ = request(http.MethodPost, "/api/import/rerun", bytes.NewReader(importRequestJSON(t, exportedReportZip(t, signer))), server)
+	require.Equal(http.StatusBadRequest, code)
+	require.JSONEq(`{"error": "report signature cannot be verified, no report signing certificate is configured"}`, body.String())
+	SetReportSigner(signer)
+
+	// an archive with a modified file is not rerun
+	archive := exportedReportZip(t, signer)
+	zipReader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	require.NoError(err)
+	buff := &bytes.Buffer{}
+	zipWriter := zip.NewWriter(buff)
+	for _, file := range zipReader.File {
+		reader, err := file.Open()
+		require.NoError(err)
+		contents, err := ioutil.ReadAll(reader)
+		require.NoError(err)
+		if file.Name == "ob_3.1_accounts_transactions_fca.json" {
+			contents = append(contents, '\n')
+		}
+		writer, err := zipWriter.Create(file.Name)
+		require.NoError(err)
+		_, err = writer.Write(contents)
+		require.NoError(err)
+	}
+	require.NoError(zipWriter.Close())
+	code, body, _ = request(http.MethodPost, "/api/import/rerun", bytes.NewReader(importRequestJSON(t, buff.Bytes())), server)
+	require.Equal(http.StatusBadRequest, code)
+	require.Contains(body.String(), `ZIP archive does not match manifest.json: \"ob_3.1_accounts_transactions_fca.json\" is modified`)
 }
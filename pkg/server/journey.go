@@ -1,14 +1,20 @@
 package server
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/blang/semver/v4"
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"gopkg.in/resty.v1"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
 	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
@@ -17,6 +23,8 @@ import (
 	"github.com/OpenBankingUK/conformance-suite/pkg/generation"
 	"github.com/OpenBankingUK/conformance-suite/pkg/manifest"
 	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/permissions"
+	"github.com/OpenBankingUK/conformance-suite/pkg/runs"
 	"github.com/OpenBankingUK/conformance-suite/pkg/schemaprops"
 	"github.com/OpenBankingUK/conformance-suite/pkg/server/models"
 )
@@ -56,6 +64,11 @@ type Journey interface {
 	ConditionalProperties() []discovery.ConditionalAPIProperties
 	Events() events.Events
 	TLSVersionResult() map[string]*discovery.TLSValidationResult
+	TLSAudits() []discovery.TLSAudit
+	RunStore() runs.Store
+	SetJWKSURI(uri string)
+	SetImportDir(dir string)
+	JWKSKeys() []authentication.JWKSKey
 }
 
 // AppJourney - application controlled by this class
@@ -77,19 +90,31 @@ type AppJourney struct {
 	manifests             []manifest.Scripts
 	filteredManifests     manifest.Scripts
 	tlsValidator          discovery.TLSValidator
+	tlsAuditLock          *sync.Mutex // the audit runs in the background, see startTLSAudit
+	tlsAudits             []discovery.TLSAudit
+	tlsAuditDone          chan struct{}
 	conditionalProperties []discovery.ConditionalAPIProperties
 	dynamicResourceIDs    bool
+	runStore              runs.Store
+	tokenRefresher        *executors.TokenRefresher
+	jwksURI               string
+	jwksCache             *authentication.JWKSCache
+	propertyCollector     schemaprops.PropertyCollector
+	importDir             string
+	consentResolver       permissions.ResolverMode
+	httpClient            *resty.Client
 }
 
 // NewJourney creates an instance for a user journey
 func NewJourney(logger *logrus.Entry, generator generation.Generator,
 	validator discovery.Validator, tlsValidator discovery.TLSValidator,
 	dynamicResourceIDs bool) *AppJourney {
-	return &AppJourney{
+	journey := &AppJourney{
 		generator:             generator,
 		validator:             validator,
 		daemonController:      executors.NewBufferedDaemonController(),
 		journeyLock:           &sync.Mutex{},
+		tlsAuditLock:          &sync.Mutex{},
 		allCollected:          false,
 		testCasesRunGenerated: false,
 		context:               model.Context{},
@@ -99,7 +124,27 @@ func NewJourney(logger *logrus.Entry, generator generation.Generator,
 		manifests:             make([]manifest.Scripts, 0),
 		tlsValidator:          tlsValidator,
 		dynamicResourceIDs:    dynamicResourceIDs,
+		runStore:              runs.NewMemoryStore(),
+		tokenRefresher:        executors.NewTokenRefresher(),
+		jwksCache:             authentication.NewJWKSCache(),
+		propertyCollector:     schemaprops.MakeCollector(),
+		httpClient:            newHTTPClient(),
+	}
+	// ASPSPs are called with this journey's own client, so its transport certificate is not presented by another journey
+	journey.context.Put(model.CtxHTTPClient, journey.httpClient)
+	return journey
+}
+
+// newHTTPClient returns a client with the logging, debug, redirect policy and headers of resty's default client
+func newHTTPClient() *resty.Client {
+	client := resty.New()
+	client.Debug = resty.DefaultClient.Debug
+	client.Log = resty.DefaultClient.Log
+	client.GetClient().CheckRedirect = resty.DefaultClient.GetClient().CheckRedirect
+	for name, values := range resty.DefaultClient.Header {
+		client.Header[name] = append([]string{}, values...)
 	}
+	return client
 }
 
 // NewDaemonController - calls StopTestRun and then sets new daemonController
@@ -195,6 +240,73 @@ func (wj *AppJourney) TLSVersionResult() map[string]*discovery.TLSValidationResu
 	return tlsValidationResult
 }
 
+// TLSAudits - the TLS audits of the resource servers and the token endpoint started when test cases were
+// generated, waits for the audit to complete
+func (wj *AppJourney) TLSAudits() []discovery.TLSAudit {
+	wj.tlsAuditLock.Lock()
+	done := wj.tlsAuditDone
+	wj.tlsAuditLock.Unlock()
+	if done != nil {
+		<-done
+	}
+
+	wj.tlsAuditLock.Lock()
+	defer wj.tlsAuditLock.Unlock()
+	return wj.tlsAudits
+}
+
+// startTLSAudit audits the TLS of each resource server and of the token endpoint in the background, as an audit
+// makes dozens of handshakes per host, so test cases are generated without waiting for it
+func (wj *AppJourney) startTLSAudit(logger *logrus.Entry) {
+	uris := []string{}
+	for _, discoveryItem := range wj.validDiscoveryModel.DiscoveryModel.DiscoveryItems {
+		uris = append(uris, discoveryItem.ResourceBaseURI)
+	}
+	if wj.config.tokenEndpoint != "" {
+		uris = append(uris, wj.config.tokenEndpoint)
+	}
+
+	done := make(chan struct{})
+	wj.tlsAuditLock.Lock()
+	wj.tlsAudits = nil
+	wj.tlsAuditDone = done
+	wj.tlsAuditLock.Unlock()
+
+	tlsValidator := wj.tlsValidator
+	go func() {
+		audits := auditTLS(tlsValidator, uris, logger)
+		wj.tlsAuditLock.Lock()
+		// a later audit replaces this one
+		if wj.tlsAuditDone == done {
+			wj.tlsAudits = audits
+		}
+		wj.tlsAuditLock.Unlock()
+		close(done)
+	}()
+}
+
+// auditTLS audits the TLS of each of `uris` once per host
+func auditTLS(tlsValidator discovery.TLSValidator, uris []string, logger *logrus.Entry) []discovery.TLSAudit {
+	audits := []discovery.TLSAudit{}
+	audited := map[string]bool{}
+	for _, uri := range uris {
+		parsedURI, err := url.Parse(uri)
+		if err != nil || audited[parsedURI.Host] {
+			continue
+		}
+		audited[parsedURI.Host] = true
+
+		audit, err := tlsValidator.AuditTLS(uri)
+		if err != nil {
+			logger.WithError(err).WithField("uri", uri).Error("Error auditing TLS")
+			audit.URI = uri
+			audit.Error = err.Error()
+		}
+		audits = append(audits, audit)
+	}
+	return audits
+}
+
 // SetFilteredManifests -
 func (wj *AppJourney) SetFilteredManifests(fmfs manifest.Scripts) {
 	wj.filteredManifests = fmfs
@@ -227,12 +339,15 @@ func (wj *AppJourney) TestCases() (generation.SpecRun, error) {
 		return generation.SpecRun{}, errTestCasesGenerated
 	}
 
-	jwksURI := authentication.GetJWKSUri()
-	if jwksURI != "" { // STORE jwks_uri from well known endpoint in journey context
-		wj.context.PutString("jwks_uri", jwksURI)
+	if wj.jwksURI != "" { // STORE jwks_uri from well known endpoint in journey context
+		wj.context.PutString("jwks_uri", wj.jwksURI)
 	} else {
 		logrus.Warn("JWKS URI is empty")
 	}
+	// response fields are collected and signatures verified with this journey's own, see model.CtxPropertyCollector
+	wj.propertyCollector = schemaprops.MakeCollector()
+	wj.context.Put(model.CtxPropertyCollector, wj.propertyCollector)
+	wj.context.Put(model.CtxJWKSCache, wj.jwksCache)
 
 	if tlsCheck {
 		for k, discoveryItem := range wj.validDiscoveryModel.DiscoveryModel.DiscoveryItems {
@@ -247,6 +362,7 @@ func (wj *AppJourney) TestCases() (generation.SpecRun, error) {
 			wj.context.PutString(wj.tlsVersionCtxKey(discoveryItem.APISpecification.Name), tlsValidationResult.TLSVersion)
 			wj.context.Put(wj.tlsValidCtxKey(discoveryItem.APISpecification.Name), tlsValidationResult.Valid)
 		}
+		wj.startTLSAudit(logger)
 	} else {
 		logrus.Warn("TLS Check disabled")
 	}
@@ -272,7 +388,11 @@ func (wj *AppJourney) TestCases() (generation.SpecRun, error) {
 
 	logger.Debug("generator.GenerateManifestTests ...")
 	logrus.Tracef("conditionalProperties from journey config: %#v", wj.config.conditionalProperties)
-	wj.specRun, wj.filteredManifests, wj.permissions = wj.generator.GenerateManifestTests(wj.log, config, discovery, &wj.context, wj.config.conditionalProperties)
+	specRun, filteredManifests, requiredTokens, err := wj.generator.GenerateManifestTests(wj.log, config, discovery, &wj.context, wj.config.conditionalProperties)
+	if err != nil {
+		return generation.SpecRun{}, err
+	}
+	wj.specRun, wj.filteredManifests, wj.permissions = specRun, filteredManifests, requiredTokens
 
 	tests := 0
 	for _, sp := range wj.specRun.SpecTestCases {
@@ -292,8 +412,7 @@ func (wj *AppJourney) TestCases() (generation.SpecRun, error) {
 		}
 	}
 
-	collector := schemaprops.GetPropertyCollector()
-	collector.SetCollectorAPIDetails(schemaprops.ConsentGathering, "")
+	wj.propertyCollector.SetCollectorAPIDetails(schemaprops.ConsentGathering, "")
 
 	if discovery.TokenAcquisition == "psu" || discovery.TokenAcquisition == "mobile" { // Handle  PSU Consent
 		logger.WithFields(logrus.Fields{
@@ -431,7 +550,7 @@ func (wj *AppJourney) CollectToken(code, state, scope string) error {
 		return errTestCasesNotGenerated
 	}
 
-	accessToken, err := executors.ExchangeCodeForAccessToken(state, code, &wj.context)
+	accessToken, err := executors.ExchangeCodeForAccessToken(state, code, &wj.context, wj.tokenRefresher)
 	if err != nil {
 		logger.WithFields(logrus.Fields{
 			"err":         err,
@@ -537,13 +656,90 @@ func (wj *AppJourney) RunTests() error {
 		wj.dumpJSON(wj.specRun.SpecTestCases[k].TestCases)
 	}
 
+	// the keys signatures are verified with are reported from the JWKS fetched during the run
+	wj.jwksCache.Reset()
+
+	// the run is recorded against the discovery model and configuration it started with,
+	// they may be changed while it runs
+	wj.journeyLock.Lock()
+	var discoveryModel discovery.Model
+	if wj.validDiscoveryModel != nil {
+		discoveryModel = *wj.validDiscoveryModel
+	}
+	configHash, runStore := wj.config.configHash, wj.runStore
+	wj.journeyLock.Unlock()
+
 	runDefinition := wj.makeRunDefinition()
-	runner := executors.NewTestCaseRunner(wj.log, runDefinition, wj.daemonController)
+	recordRun := wj.recordRun(time.Now(), discoveryModel, configHash, runStore)
+	runner := executors.NewTestCaseRunner(wj.log, runDefinition, newRecordingDaemonController(wj.daemonController, recordRun))
 	wj.context.PutString(CtxPhase, "run")
 	err := runner.RunTestCases(&wj.context)
 	return err
 }
 
+// recordRun returns a function saving the run started at startedAt with discoveryModel and the configuration
+// of configHash to store once it has completed
+func (wj *AppJourney) recordRun(startedAt time.Time, discoveryModel discovery.Model, configHash string, store runs.Store) func(executors.DaemonController) {
+	return func(daemonController executors.DaemonController) {
+		logger := wj.log.WithField("function", "recordRun")
+		run := runs.NewRun(uuid.New().String(), startedAt, time.Now(), configHash, discoveryModel,
+			daemonController.AllResults(), daemonController.ResponseFieldsJSON())
+		if err := store.Save(run); err != nil {
+			logger.WithError(err).Error("saving run")
+			return
+		}
+		logger.WithField("run", run.ID).Info("run saved")
+	}
+}
+
+// SetRunStore - sets the store runs are saved to once completed, fcs_server sets the store of its `runs_db` flag.
+// Runs are kept in memory until a store is set.
+func (wj *AppJourney) SetRunStore(store runs.Store) {
+	wj.journeyLock.Lock()
+	defer wj.journeyLock.Unlock()
+	wj.runStore = store
+}
+
+// SetDefaultConsentResolver - sets how account test cases are grouped into consents when the configuration
+// does not select a consent resolver, greedy by default
+func (wj *AppJourney) SetDefaultConsentResolver(mode permissions.ResolverMode) {
+	wj.journeyLock.Lock()
+	defer wj.journeyLock.Unlock()
+	wj.consentResolver = mode
+}
+
+// RunStore - returns the store of completed runs
+func (wj *AppJourney) RunStore() runs.Store {
+	wj.journeyLock.Lock()
+	defer wj.journeyLock.Unlock()
+	return wj.runStore
+}
+
+// SetJWKSURI - sets the jwks_uri of the openid configuration response signatures are verified with
+func (wj *AppJourney) SetJWKSURI(uri string) {
+	wj.journeyLock.Lock()
+	defer wj.journeyLock.Unlock()
+	wj.jwksURI = uri
+}
+
+// SetImportDir - sets the directory the manifests of the last imported report archive were restored to, the
+// directory of the previous import is removed. An empty `dir` removes the directory of the last import.
+func (wj *AppJourney) SetImportDir(dir string) {
+	wj.journeyLock.Lock()
+	defer wj.journeyLock.Unlock()
+	if wj.importDir != "" && wj.importDir != dir {
+		if err := os.RemoveAll(wj.importDir); err != nil {
+			wj.log.WithError(err).WithField("dir", wj.importDir).Error("removing import directory")
+		}
+	}
+	wj.importDir = dir
+}
+
+// JWKSKeys - returns the keys seen in the JWKS fetched during the last run of this journey
+func (wj *AppJourney) JWKSKeys() []authentication.JWKSKey {
+	return wj.jwksCache.Keys()
+}
+
 // Results -
 func (wj *AppJourney) Results() executors.DaemonController {
 	return wj.daemonController
@@ -557,6 +753,7 @@ func (wj *AppJourney) StopTestRun() {
 func (wj *AppJourney) createTokenCollector(consentIds executors.TokenConsentIDs) {
 	if len(consentIds) > 0 {
 		wj.collector = executors.NewTokenCollector(wj.log, consentIds, wj.doneCollectionCallback, wj.events)
+		wj.tokenRefresher = executors.NewTokenRefresher()
 		consentIdsToTestCaseRun(wj.log, consentIds, &wj.specRun)
 
 		wj.allCollected = false
@@ -582,18 +779,33 @@ func (wj *AppJourney) makeGeneratorConfig() generation.GeneratorConfig {
 		AuthorizationEndpoint: wj.config.authorizationEndpoint,
 		RedirectURL:           wj.config.redirectURL,
 		ResourceIDs:           wj.config.resourceIDs,
+		ConsentResolver:       wj.consentResolverMode(),
 	}
 }
 
 func (wj *AppJourney) makeRunDefinition() executors.RunDefinition {
 	return executors.RunDefinition{
-		DiscoModel:    wj.validDiscoveryModel,
-		SpecRun:       wj.specRun,
-		SigningCert:   wj.config.certificateSigning,
-		TransportCert: wj.config.certificateTransport,
+		DiscoModel:      wj.validDiscoveryModel,
+		SpecRun:         wj.specRun,
+		SigningCert:     wj.config.certificateSigning,
+		TransportCert:   wj.config.certificateTransport,
+		Workers:         wj.config.testCaseWorkers,
+		RetryPolicy:     wj.config.retryPolicy,
+		Refresher:       wj.tokenRefresher,
+		Events:          wj.events,
+		ConsentResolver: wj.consentResolverMode(),
+		HTTPClient:      wj.httpClient,
 	}
 }
 
+// consentResolverMode - the consent resolver of the configuration, the journey's default when not configured
+func (wj *AppJourney) consentResolverMode() permissions.ResolverMode {
+	if wj.config.consentResolver != "" {
+		return wj.config.consentResolver
+	}
+	return wj.consentResolver
+}
+
 // JourneyConfig main configuration variables
 type JourneyConfig struct {
 	certificateSigning            authentication.Certificate
@@ -607,6 +819,7 @@ type JourneyConfig struct {
 	ResponseType                  string
 	tokenEndpointAuthMethod       string
 	authorizationEndpoint         string
+	pushedAuthorizationEndpoints  map[string]string // by discovery item key, `schema_version=<schema version>`
 	resourceBaseURL               string
 	xXFAPIFinancialID             string
 	xXFAPICustomerIPAddress       string
@@ -630,6 +843,11 @@ type JourneyConfig struct {
 	conditionalProperties         []discovery.ConditionalAPIProperties
 	cbpiiDebtorAccount            discovery.CBPIIDebtorAccount
 	issuer                        string
+	testCaseWorkers               int
+	retryPolicy                   executors.RetryPolicy
+	clientAssertion               authentication.ClientAssertionClaims
+	consentResolver               permissions.ResolverMode
+	configHash                    string
 }
 
 // SetConfig -
@@ -639,6 +857,10 @@ func (wj *AppJourney) SetConfig(config JourneyConfig) error {
 
 	wj.config = config
 	wj.config.useDynamicResourceID = wj.dynamicResourceIDs // fed from environment variable 'dynres'=true/false
+	// Use the transport keys for MATLS as some endpoints require this
+	if config.certificateTransport != nil {
+		wj.httpClient.SetTLSClientConfig(&tls.Config{Certificates: []tls.Certificate{config.certificateTransport.TLSCert()}})
+	}
 	err := PutParametersToJourneyContext(wj.config, wj.context)
 	if err != nil {
 		return err
@@ -3,17 +3,21 @@ package server
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
 	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
 	"github.com/OpenBankingUK/conformance-suite/pkg/discovery/mocks"
 	"github.com/OpenBankingUK/conformance-suite/pkg/generation"
+	"github.com/OpenBankingUK/conformance-suite/pkg/manifest"
 	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/permissions"
 	"github.com/OpenBankingUK/conformance-suite/pkg/server/models"
 	"github.com/OpenBankingUK/conformance-suite/pkg/test"
 
 	gmocks "github.com/OpenBankingUK/conformance-suite/pkg/generation"
 	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -120,6 +124,69 @@ func TestJourneyRunTestCasesCantRunIfNoTestCases(t *testing.T) {
 	assert.EqualError(err, "error test cases not generated")
 }
 
+func TestJourneyTestCasesUseTheJourneysJWKSURIAndCollector(t *testing.T) {
+	require := test.NewRequire(t)
+
+	newJourneyWithJWKSURI := func(uri string) *AppJourney {
+		discoveryModel := &discovery.Model{}
+		validator := &mocks.Validator{}
+		validator.On("Validate", discoveryModel).Return(discovery.NoValidationFailures(), nil)
+		generator := &gmocks.MockGenerator{}
+		generator.On("GenerateManifestTests", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(generation.SpecRun{}, manifest.Scripts{}, map[string][]manifest.RequiredTokens{}, nil)
+		journey := NewJourney(nullLogger(), generator, validator, discovery.NewNullTLSValidator(), false)
+		_, err := journey.SetDiscoveryModel(discoveryModel)
+		require.NoError(err)
+		journey.SetJWKSURI(uri)
+		_, err = journey.TestCases()
+		require.Equal(errNoTestCases, err)
+		return journey
+	}
+
+	first := newJourneyWithJWKSURI("https://first.example.com/jwks")
+	second := newJourneyWithJWKSURI("https://second.example.com/jwks")
+
+	for _, journey := range []*AppJourney{first, second} {
+		jwksURI, err := journey.context.GetString("jwks_uri")
+		require.NoError(err)
+		require.Equal(journey.jwksURI, jwksURI)
+		require.Same(journey.propertyCollector, model.PropertyCollector(&journey.context))
+		cache, ok := journey.context.Get(model.CtxJWKSCache)
+		require.True(ok)
+		require.Same(journey.jwksCache, cache)
+	}
+	require.NotSame(first.propertyCollector, second.propertyCollector)
+	require.NotSame(first.jwksCache, second.jwksCache)
+	require.Empty(first.JWKSKeys())
+}
+
+func TestJourneyTestCasesUseTheConfiguredConsentResolver(t *testing.T) {
+	require := test.NewRequire(t)
+
+	generationErr := errors.New("no account consent satisfies test cases [t2], they include a permission they exclude")
+	testCases := func(configured permissions.ResolverMode, expected permissions.ResolverMode) error {
+		discoveryModel := &discovery.Model{}
+		validator := &mocks.Validator{}
+		validator.On("Validate", discoveryModel).Return(discovery.NoValidationFailures(), nil)
+		generator := &gmocks.MockGenerator{}
+		generator.On("GenerateManifestTests", mock.Anything, mock.MatchedBy(func(config generation.GeneratorConfig) bool {
+			return config.ConsentResolver == expected
+		}), mock.Anything, mock.Anything).
+			Return(generation.SpecRun{}, manifest.Scripts{}, map[string][]manifest.RequiredTokens{}, generationErr)
+		journey := NewJourney(nullLogger(), generator, validator, discovery.NewNullTLSValidator(), false)
+		journey.SetDefaultConsentResolver(permissions.ResolverModeHeuristic)
+		journey.config.consentResolver = configured
+		_, err := journey.SetDiscoveryModel(discoveryModel)
+		require.NoError(err)
+		_, err = journey.TestCases()
+		generator.AssertExpectations(t)
+		return err
+	}
+
+	require.Equal(generationErr, testCases("", permissions.ResolverModeHeuristic))
+	require.Equal(generationErr, testCases(permissions.ResolverModeExact, permissions.ResolverModeExact))
+}
+
 func TestJourneySetConfig(t *testing.T) {
 	require := test.NewRequire(t)
 
@@ -161,3 +228,45 @@ func TestJourneySetConfig(t *testing.T) {
 	require.NoError(journey.SetConfig(config))
 	require.Equal(config, journey.config)
 }
+
+// blockingTLSValidator - audits TLS once `release` is closed
+type blockingTLSValidator struct {
+	discovery.NullTLSValidator
+	release chan struct{}
+}
+
+func (v blockingTLSValidator) AuditTLS(uri string) (discovery.TLSAudit, error) {
+	<-v.release
+	return discovery.TLSAudit{URI: uri, Valid: true}, nil
+}
+
+func TestJourneyTLSAuditRunsInTheBackground(t *testing.T) {
+	require := test.NewRequire(t)
+
+	tlsValidator := blockingTLSValidator{release: make(chan struct{})}
+	journey := NewJourney(nullLogger(), &gmocks.MockGenerator{}, &mocks.Validator{}, tlsValidator, false)
+	journey.validDiscoveryModel = &discovery.Model{DiscoveryModel: discovery.ModelDiscovery{DiscoveryItems: []discovery.ModelDiscoveryItem{
+		{ResourceBaseURI: "https://bank.example.com/open-banking/v3.1/aisp"},
+		{ResourceBaseURI: "https://bank.example.com/open-banking/v3.1/pisp"},
+	}}}
+	journey.config.tokenEndpoint = "https://auth.example.com/token"
+
+	// starting the audit does not wait for its handshakes
+	journey.startTLSAudit(nullLogger())
+
+	audits := make(chan []discovery.TLSAudit)
+	go func() {
+		audits <- journey.TLSAudits()
+	}()
+	select {
+	case <-audits:
+		require.Fail("TLSAudits returned before the audit completed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(tlsValidator.release)
+	require.Equal([]discovery.TLSAudit{
+		{URI: "https://bank.example.com/open-banking/v3.1/aisp", Valid: true},
+		{URI: "https://auth.example.com/token", Valid: true},
+	}, <-audits)
+}
@@ -2,12 +2,14 @@
 
 package server
 
+import authentication "github.com/OpenBankingUK/conformance-suite/pkg/authentication"
 import discovery "github.com/OpenBankingUK/conformance-suite/pkg/discovery"
 import events "github.com/OpenBankingUK/conformance-suite/pkg/executors/events"
 import executors "github.com/OpenBankingUK/conformance-suite/pkg/executors"
 import generation "github.com/OpenBankingUK/conformance-suite/pkg/generation"
 import manifest "github.com/OpenBankingUK/conformance-suite/pkg/manifest"
 import mock "github.com/stretchr/testify/mock"
+import runs "github.com/OpenBankingUK/conformance-suite/pkg/runs"
 
 // MockJourney is an autogenerated mock type for the Journey type
 type MockJourney struct {
@@ -116,6 +118,22 @@ func (_m *MockJourney) FilteredManifests() (manifest.Scripts, error) {
 	return r0, r1
 }
 
+// JWKSKeys provides a mock function with given fields:
+func (_m *MockJourney) JWKSKeys() []authentication.JWKSKey {
+	ret := _m.Called()
+
+	var r0 []authentication.JWKSKey
+	if rf, ok := ret.Get(0).(func() []authentication.JWKSKey); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]authentication.JWKSKey)
+		}
+	}
+
+	return r0
+}
+
 // NewDaemonController provides a mock function with given fields:
 func (_m *MockJourney) NewDaemonController() {
 	_m.Called()
@@ -137,6 +155,22 @@ func (_m *MockJourney) Results() executors.DaemonController {
 	return r0
 }
 
+// RunStore provides a mock function with given fields:
+func (_m *MockJourney) RunStore() runs.Store {
+	ret := _m.Called()
+
+	var r0 runs.Store
+	if rf, ok := ret.Get(0).(func() runs.Store); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(runs.Store)
+		}
+	}
+
+	return r0
+}
+
 // RunTests provides a mock function with given fields:
 func (_m *MockJourney) RunTests() error {
 	ret := _m.Called()
@@ -193,11 +227,37 @@ func (_m *MockJourney) SetFilteredManifests(_a0 manifest.Scripts) {
 	_m.Called(_a0)
 }
 
+// SetImportDir provides a mock function with given fields: dir
+func (_m *MockJourney) SetImportDir(dir string) {
+	_m.Called(dir)
+}
+
+// SetJWKSURI provides a mock function with given fields: uri
+func (_m *MockJourney) SetJWKSURI(uri string) {
+	_m.Called(uri)
+}
+
 // StopTestRun provides a mock function with given fields:
 func (_m *MockJourney) StopTestRun() {
 	_m.Called()
 }
 
+// TLSAudits provides a mock function with given fields:
+func (_m *MockJourney) TLSAudits() []discovery.TLSAudit {
+	ret := _m.Called()
+
+	var r0 []discovery.TLSAudit
+	if rf, ok := ret.Get(0).(func() []discovery.TLSAudit); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]discovery.TLSAudit)
+		}
+	}
+
+	return r0
+}
+
 // TLSVersionResult provides a mock function with given fields:
 func (_m *MockJourney) TLSVersionResult() map[string]*discovery.TLSValidationResult {
 	ret := _m.Called()
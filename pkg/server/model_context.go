@@ -1,11 +1,17 @@
 package server
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
+	"github.com/OpenBankingUK/conformance-suite/pkg/manifest"
 	"github.com/OpenBankingUK/conformance-suite/pkg/model"
 	"github.com/OpenBankingUK/conformance-suite/pkg/version"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
-	"gopkg.in/resty.v1"
 )
 
 // Context Variables
@@ -51,6 +57,10 @@ const (
 	CtxPhase                               = "phase"
 	CtxDynamicResourceIDs                  = "dynamicResourceIDs"
 	CtxAcrValuesSupported                  = "acrValuesSupported"
+	CtxClientAssertionAudience             = "client_assertion_audience"
+	CtxClientAssertionLifetime             = "client_assertion_lifetime" // in seconds
+	CtxClientAssertionJTI                  = "client_assertion_jti"
+	CtxPushedAuthorizationEndpoint         = "pushed_authorization_request_endpoint" // followed by `_` and the specification type consented
 )
 
 // PutParametersToJourneyContext populates a JourneyContext with values from the config screen
@@ -95,6 +105,13 @@ func PutParametersToJourneyContext(config JourneyConfig, context model.Context)
 	context.PutString(CtxTransactionToDate, config.transactionToDate)
 	context.Put(CtxDynamicResourceIDs, config.useDynamicResourceID)
 	context.PutStringSlice(CtxAcrValuesSupported, config.AcrValuesSupported)
+	context.PutString(CtxClientAssertionAudience, config.clientAssertion.Audience)
+	context.PutString(CtxClientAssertionLifetime, strconv.Itoa(int(config.clientAssertion.Lifetime/time.Second)))
+	context.PutString(CtxClientAssertionJTI, config.clientAssertion.JTI)
+
+	if err := putPushedAuthorizationEndpoints(config.pushedAuthorizationEndpoints, context); err != nil {
+		return err
+	}
 
 	basicauth, err := authentication.CalculateClientSecretBasicToken(config.clientID, config.clientSecret)
 	if err != nil {
@@ -109,13 +126,32 @@ func PutParametersToJourneyContext(config JourneyConfig, context model.Context)
 		context.Delete(CtxStatementID)
 	}
 
+	httpClient := model.HTTPClient(&context)
 	_, ou, cn, err := config.certificateTransport.DN()
 	if err == nil && cn != "" && ou != "" {
-		resty.SetHeader("User-Agent", "OpenBankingFCS/"+version.NewGitHub("").GetHumanVersion()+"/"+ou+"/"+cn)
+		httpClient.SetHeader("User-Agent", "OpenBankingFCS/"+version.NewGitHub("").GetHumanVersion()+"/"+ou+"/"+cn)
 	} else {
-		resty.SetHeader("User-Agent", "OpenBankingFCS/"+version.NewGitHub("").GetHumanVersion())
+		httpClient.SetHeader("User-Agent", "OpenBankingFCS/"+version.NewGitHub("").GetHumanVersion())
 	}
 
 	logrus.Tracef("TokenEndpoint auth method %s", config.tokenEndpointAuthMethod)
 	return nil
 }
+
+// putPushedAuthorizationEndpoints puts the pushed authorization request endpoint of each discovery item under the
+// specification type it consents to, consent is acquired once per specification type so its items have to agree
+func putPushedAuthorizationEndpoints(endpoints map[string]string, context model.Context) error {
+	specTypes := map[string]string{}
+	for key, endpoint := range endpoints {
+		specType, err := manifest.GetSpecType(strings.TrimPrefix(key, discoveryItemKeyPrefix))
+		if err != nil {
+			return errors.Wrapf(err, "pushed authorization request endpoint of %s", key)
+		}
+		if other, ok := specTypes[specType]; ok && other != endpoint {
+			return fmt.Errorf("discovery items of the %s specification have different pushed authorization request endpoints", specType)
+		}
+		specTypes[specType] = endpoint
+		context.PutString(CtxPushedAuthorizationEndpoint+"_"+specType, endpoint)
+	}
+	return nil
+}
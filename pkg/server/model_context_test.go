@@ -0,0 +1,75 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+)
+
+const (
+	accountsDiscoveryItemKey = discoveryItemKeyPrefix + "https://raw.githubusercontent.com/OpenBankingUK/read-write-api-specs/v3.1.6/dist/swagger/account-info-swagger.json"
+	paymentsDiscoveryItemKey = discoveryItemKeyPrefix + "https://raw.githubusercontent.com/OpenBankingUK/read-write-api-specs/v3.1.6/dist/swagger/payment-initiation-swagger.json"
+)
+
+func TestPutPushedAuthorizationEndpointsByConsentedItem(t *testing.T) {
+	require := test.NewRequire(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"request_uri":"urn:ietf:params:oauth:request_uri:001","expires_in":90}`))
+	}))
+	defer server.Close()
+
+	ctx := model.Context{
+		"authorisation_endpoint":     "https://example.com/authorisation",
+		"token_endpoint_auth_method": "client_secret_basic",
+		"client_id":                  "8672384e-9a33-439f-8924-67bb14340d71",
+		"client_secret":              "client-secret",
+		"token_endpoint":             "https://example.com/token",
+	}
+	endpoints := map[string]string{
+		accountsDiscoveryItemKey: server.URL,
+		paymentsDiscoveryItemKey: "",
+	}
+	require.NoError(putPushedAuthorizationEndpoints(endpoints, ctx))
+
+	consentURL := func(specType string) string {
+		ctx.PutString("consent_spec_type", specType)
+		tc := model.TestCase{Input: model.Input{Endpoint: "/consent", Method: "GET",
+			Generation: map[string]string{"strategy": "consenturl"},
+			Claims: map[string]string{
+				"iss":          "8672384e-9a33-439f-8924-67bb14340d71",
+				"scope":        "openid " + specType,
+				"redirect_url": "https://test.example.co.uk/redir",
+				"responseType": "code",
+			}}}
+		_, err := tc.Prepare(&ctx)
+		require.NoError(err)
+		url, err := ctx.GetString("consent_url")
+		require.NoError(err)
+		return url
+	}
+
+	require.Equal("https://example.com/authorisation?client_id=8672384e-9a33-439f-8924-67bb14340d71&request_uri=urn%3Aietf%3Aparams%3Aoauth%3Arequest_uri%3A001", consentURL("accounts"))
+	paymentsURL := consentURL("payments")
+	require.False(strings.Contains(paymentsURL, "request_uri="))
+	require.True(strings.Contains(paymentsURL, "&request="))
+}
+
+func TestPutPushedAuthorizationEndpointsItemsOfASpecificationDisagree(t *testing.T) {
+	require := test.NewRequire(t)
+
+	endpoints := map[string]string{
+		accountsDiscoveryItemKey: "https://example.com/par",
+		discoveryItemKeyPrefix + "https://raw.githubusercontent.com/OpenBankingUK/read-write-api-specs/v3.1.8/dist/openapi/account-info-openapi.yaml": "",
+	}
+	err := putPushedAuthorizationEndpoints(endpoints, model.Context{})
+	require.EqualError(err, "discovery items of the accounts specification have different pushed authorization request endpoints")
+
+	err = putPushedAuthorizationEndpoints(map[string]string{"schema_version=unknown": ""}, model.Context{})
+	require.EqualError(err, "pushed authorization request endpoint of schema_version=unknown: Unknown specification:  `unknown`")
+}
@@ -3,12 +3,20 @@ package models
 import (
 	"fmt"
 
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
 	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
 	"github.com/OpenBankingUK/conformance-suite/pkg/executors/events"
 	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
 	validation "github.com/go-ozzo/ozzo-validation"
 )
 
+// Export formats of the report
+const (
+	ExportFormatZIP      = "zip"      // ZIP archive with the report, discovery model, response fields and manifests, every archive has the report.html page too
+	ExportFormatHTML     = "html"     // The report.html page on its own, readable in a browser
+	ExportFormatCoverage = "coverage" // JSON coverage of the spec of each API version by the run
+)
+
 // ExportRequest - Request to `/api/export`.
 type ExportRequest struct {
 	Environment         string   `json:"environment"`           // Environment used for testing
@@ -18,6 +26,15 @@ type ExportRequest struct {
 	Products            []string `json:"products"`              // Products tested, e.g., "Business, Personal, Cards"
 	HasAgreed           bool     `json:"has_agreed"`            // I agree
 	AddDigitalSignature bool     `json:"add_digital_signature"` // Sign this report
+	Format              string   `json:"format,omitempty"`      // Export format, `zip` when empty
+}
+
+// ExportFormat - the format the report is exported in
+func (e ExportRequest) ExportFormat() string {
+	if e.Format == "" {
+		return ExportFormatZIP
+	}
+	return e.Format
 }
 
 func (e *ExportRequest) requiresTCAgreement() bool {
@@ -34,6 +51,7 @@ func (e ExportRequest) Validate() error {
 		validation.Field(&e.AuthorisedBy, validation.Required),
 		validation.Field(&e.JobTitle, validation.Required),
 		validation.Field(&e.Products, validation.Required, validation.By(productsValuesValidator)),
+		validation.Field(&e.Format, validation.In(ExportFormatZIP, ExportFormatHTML, ExportFormatCoverage)),
 	}
 
 	if e.requiresTCAgreement() {
@@ -90,4 +108,6 @@ type ExportResults struct {
 	ResponseFields   string                                    `json:"-"`
 	TLSVersionResult map[string]*discovery.TLSValidationResult `json:"-"`
 	JWSStatus        string                                    `json:"jws_status"`
+	JWKSKeys         []authentication.JWKSKey                  `json:"-"`
+	TLSAudits        []discovery.TLSAudit                      `json:"-"`
 }
@@ -1,11 +1,19 @@
 package models
 
-import validation "github.com/go-ozzo/ozzo-validation"
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/pkg/errors"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+)
 
 // ImportRequest - Request to `/api/import/review` or `/api/import/rerun` POST.
-// TODO(mbana): Needs more work.
 type ImportRequest struct {
-	Report string `json:"report" form:"report"` // The exported report ZIP archive.
+	Report string `json:"report" form:"report"` // The exported report ZIP archive, base64 encoded or as a base64 data URL.
 }
 
 // Validate - used by github.com/go-ozzo/ozzo-validation to validate struct.
@@ -15,10 +23,35 @@ func (r ImportRequest) Validate() error {
 	)
 }
 
+// ZipArchive - decodes the report ZIP archive of the request, a data URL such as
+// `data:application/zip;base64,UEsDBBQ...` is decoded from its data.
+func (r ImportRequest) ZipArchive() ([]byte, error) {
+	encoded := r.Report
+	if strings.HasPrefix(encoded, "data:") {
+		separator := strings.Index(encoded, ";base64,")
+		if separator < 0 {
+			return nil, errors.New("report: data URL is not base64 encoded")
+		}
+		encoded = encoded[separator+len(";base64,"):]
+	}
+	archive, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "report: not base64 encoded")
+	}
+	return archive, nil
+}
+
 // ImportReviewResponse - Response to `/api/import/review` POST.
 type ImportReviewResponse struct {
+	Report         json.RawMessage `json:"report"`                   // The `report.json` of the archive
+	Signed         bool            `json:"signed"`                   // The archive has a `signature.json`
+	SignatureValid bool            `json:"signatureValid"`           // The signature verifies against the archive files
+	SignedBy       string          `json:"signedBy,omitempty"`       // Subject of the signing certificate
+	SignatureError string          `json:"signatureError,omitempty"` // Why the signature did not verify
 }
 
 // ImportRerunResponse - Response to `/api/import/rerun` POST.
 type ImportRerunResponse struct {
+	ReportID       string          `json:"reportId"`       // ID of the imported report
+	DiscoveryModel discovery.Model `json:"discoveryModel"` // Discovery model set in the journey, with its restored manifests
 }
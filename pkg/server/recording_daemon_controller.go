@@ -0,0 +1,25 @@
+package server
+
+import (
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors"
+)
+
+// recordingDaemonController - a daemon controller calling record with all the results of
+// the run before signalling the run has completed
+type recordingDaemonController struct {
+	executors.DaemonController
+	record func(executors.DaemonController)
+}
+
+func newRecordingDaemonController(daemonController executors.DaemonController, record func(executors.DaemonController)) recordingDaemonController {
+	return recordingDaemonController{
+		DaemonController: daemonController,
+		record:           record,
+	}
+}
+
+// SetCompleted - records the run then marks the tests as completed.
+func (d recordingDaemonController) SetCompleted() {
+	d.record(d.DaemonController)
+	d.DaemonController.SetCompleted()
+}
@@ -51,14 +51,12 @@ type AuthClaim struct {
 }
 
 type redirectHandlers struct {
-	journey Journey
-	logger  *logrus.Entry
+	logger *logrus.Entry
 }
 
-func newRedirectHandlers(journey Journey, logger *logrus.Entry) redirectHandlers {
+func newRedirectHandlers(logger *logrus.Entry) redirectHandlers {
 	return redirectHandlers{
-		journey: journey,
-		logger:  logger.WithField("module", "redirectHandlers"),
+		logger: logger.WithField("module", "redirectHandlers"),
 	}
 }
 
@@ -78,7 +76,7 @@ func (h redirectHandlers) postFragmentOKHandler(c echo.Context) error {
 	// (Nothing to validate)
 	if fragment.IDToken == "" {
 		if fragment.Code != "" {
-			err := h.handleCodeExchange(fragment.Code, fragment.State, fragment.Scope)
+			err := h.handleCodeExchange(journeyFrom(c), fragment.Code, fragment.State, fragment.Scope)
 			if err != nil {
 				resp := NewErrorResponse(errors.Wrap(err, "unable to handle redirect"))
 				return c.JSON(http.StatusBadRequest, resp)
@@ -88,7 +86,7 @@ func (h redirectHandlers) postFragmentOKHandler(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, errors.New("code not set"))
 	}
 
-	err := h.handleCodeExchange(fragment.Code, fragment.State, fragment.Scope)
+	err := h.handleCodeExchange(journeyFrom(c), fragment.Code, fragment.State, fragment.Scope)
 	if err != nil {
 		resp := NewErrorResponse(errors.Wrap(err, "unable to handle redirect"))
 		return c.JSON(http.StatusBadRequest, resp)
@@ -138,7 +136,7 @@ func (h redirectHandlers) postQueryOKHandler(c echo.Context) error {
 	// (Nothing to validate)
 	if query.IDToken == "" {
 		if query.Code != "" {
-			err := h.handleCodeExchange(query.Code, query.State, query.Scope)
+			err := h.handleCodeExchange(journeyFrom(c), query.Code, query.State, query.Scope)
 			if err != nil {
 				resp := NewErrorResponse(errors.Wrap(err, "unable to handle redirect"))
 				return c.JSON(http.StatusBadRequest, resp)
@@ -148,7 +146,7 @@ func (h redirectHandlers) postQueryOKHandler(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, errors.New("code not set"))
 	}
 
-	err := h.handleCodeExchange(query.Code, query.State, query.Scope)
+	err := h.handleCodeExchange(journeyFrom(c), query.Code, query.State, query.Scope)
 	if err != nil {
 		resp := NewErrorResponse(errors.Wrap(err, "unable to handle redirect"))
 		return c.JSON(http.StatusBadRequest, resp)
@@ -182,14 +180,14 @@ func (h redirectHandlers) postQueryOKHandler(c echo.Context) error {
 	// return c.JSON(http.StatusBadRequest, resp)
 }
 
-func (h redirectHandlers) handleCodeExchange(code string, state string, scope string) error {
+func (h redirectHandlers) handleCodeExchange(journey Journey, code string, state string, scope string) error {
 	h.logger.WithFields(logrus.Fields{
 		"function": "handleCodeExchange",
 		"code":     code,
 		"state":    state,
 		"scope":    scope,
-	}).Info("h.journey.CollectToken ...")
-	return h.journey.CollectToken(code, state, scope)
+	}).Info("journey.CollectToken ...")
+	return journey.CollectToken(code, state, scope)
 }
 
 // postErrorHandler - POST /api/redirect/error
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/report"
+)
+
+type reportHandlers struct {
+	logger *logrus.Entry
+}
+
+func newReportHandlers(logger *logrus.Entry) reportHandlers {
+	return reportHandlers{
+		logger: logger.WithField("handler", "reportHandlers"),
+	}
+}
+
+// postReportDiff - `/api/report/diff` POST, compares the exported reports uploaded as the `old` and `new` multipart files.
+func (h reportHandlers) postReportDiff(c echo.Context) error {
+	logger := h.logger.WithField("function", "postReportDiff")
+
+	oldReport, err := importFormReport(c, "old")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
+	}
+	newReport, err := importFormReport(c, "new")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
+	}
+
+	diff := report.NewDiff(oldReport, newReport, report.DefaultDiffOptions())
+	logger.WithFields(logrus.Fields{
+		"old":          oldReport.ID,
+		"new":          newReport.ID,
+		"newlyFailing": diff.NewlyFailing(),
+	}).Info("Compared reports")
+
+	return c.JSON(http.StatusOK, diff)
+}
+
+// importFormReport - imports the report ZIP archive uploaded as the multipart file `name`.
+func importFormReport(c echo.Context, name string) (report.Report, error) {
+	fileHeader, err := c.FormFile(name)
+	if err != nil {
+		return report.Report{}, errors.Wrapf(err, "reading %q report", name)
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return report.Report{}, errors.Wrapf(err, "opening %q report", name)
+	}
+	defer file.Close()
+
+	imported, err := report.NewZipImporter(file).Import()
+	if err != nil {
+		return report.Report{}, fmt.Errorf("importing %q report: %s", name, err)
+	}
+	return imported, nil
+}
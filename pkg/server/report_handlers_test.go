@@ -0,0 +1,87 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
+	"github.com/OpenBankingUK/conformance-suite/pkg/report"
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+	versionmock "github.com/OpenBankingUK/conformance-suite/pkg/version/mocks"
+)
+
+func TestServerReportDiff(t *testing.T) {
+	require := test.NewRequire(t)
+
+	server := NewServer(testJourney(), nullLogger(), &versionmock.Version{})
+	defer func() {
+		require.NoError(server.Shutdown(context.TODO()))
+	}()
+
+	oldReport := report.Report{ID: "old", APISpecification: []report.APISpecification{{Name: "Accounts", Version: "v3.1", Results: []results.TestCase{
+		{Id: "OB-301-ACC-001", Pass: true},
+	}}}}
+	newReport := report.Report{ID: "new", APISpecification: []report.APISpecification{{Name: "Accounts", Version: "v3.1", Results: []results.TestCase{
+		{Id: "OB-301-ACC-001", Pass: false, Fail: []string{"status code 500"}},
+	}}}}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for name, r := range map[string]report.Report{"old": oldReport, "new": newReport} {
+		part, err := writer.CreateFormFile(name, name+".zip")
+		require.NoError(err)
+		_, err = part.Write(reportZip(t, r))
+		require.NoError(err)
+	}
+	require.NoError(writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/report/diff", body)
+	req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	require.Equal(http.StatusOK, rec.Code, rec.Body.String())
+	diff := report.Diff{}
+	require.NoError(json.Unmarshal(rec.Body.Bytes(), &diff))
+	require.Equal("old", diff.OldReportID)
+	require.Len(diff.APIs, 1)
+	require.Equal([]string{"OB-301-ACC-001"}, diff.APIs[0].NewlyFailing)
+}
+
+func TestServerReportDiffMissingReport(t *testing.T) {
+	require := test.NewRequire(t)
+
+	server := NewServer(testJourney(), nullLogger(), &versionmock.Version{})
+	defer func() {
+		require.NoError(server.Shutdown(context.TODO()))
+	}()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	require.NoError(writer.Close())
+	req := httptest.NewRequest(http.MethodPost, "/api/report/diff", body)
+	req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	require.Equal(http.StatusBadRequest, rec.Code)
+	require.Contains(rec.Body.String(), `reading \"old\" report`)
+}
+
+func reportZip(t *testing.T, r report.Report) []byte {
+	require := test.NewRequire(t)
+
+	r.Status = report.StatusComplete
+	r.CertifiedBy.Environment = report.CertifiedByEnvironmentTesting
+
+	buff := &bytes.Buffer{}
+	require.NoError(report.NewZipExporter(r, buff).Export())
+	return buff.Bytes()
+}
@@ -24,14 +24,12 @@ const (
 )
 
 type runHandlers struct {
-	journey  Journey
 	upgrader *websocket.Upgrader
 	logger   *logrus.Entry
 }
 
-func newRunHandlers(journey Journey, upgrader *websocket.Upgrader, logger *logrus.Entry) runHandlers {
+func newRunHandlers(upgrader *websocket.Upgrader, logger *logrus.Entry) runHandlers {
 	return runHandlers{
-		journey:  journey,
 		upgrader: upgrader,
 		logger:   logger,
 	}
@@ -39,7 +37,7 @@ func newRunHandlers(journey Journey, upgrader *websocket.Upgrader, logger *logru
 
 // runStartPostHandler creates a new test run
 func (h runHandlers) runStartPostHandler(c echo.Context) error {
-	err := h.journey.RunTests()
+	err := journeyFrom(c).RunTests()
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
 	}
@@ -70,8 +68,9 @@ func (h runHandlers) listenResultWebSocket(c echo.Context) error {
 	logger.Debug("client connected")
 
 	pingTicker := time.NewTicker(pingFrequency)
-	daemon := h.journey.Results()
-	events := h.journey.Events()
+	journey := journeyFrom(c)
+	daemon := journey.Results()
+	events := journey.Events()
 	for {
 		if h.shouldStop(daemon, ws, logger) {
 			break
@@ -98,6 +97,10 @@ func (h runHandlers) listenResultWebSocket(c echo.Context) error {
 			if err := h.processAcquiredAllAccessTokensEvent(ws, logger, event, ok); err != nil {
 				break
 			}
+		case event, ok := <-events.RefreshedTokensChannel():
+			if err := h.processRefreshedAccessTokenEvent(ws, logger, event, ok); err != nil {
+				break
+			}
 		}
 	}
 
@@ -140,7 +143,7 @@ func (h runHandlers) doSendPingMessage(ws *websocket.Conn, logger *logrus.Entry)
 
 // stopHandler sends signal to stop running test
 func (h runHandlers) stopRunHandler(c echo.Context) error {
-	h.journey.StopTestRun()
+	journeyFrom(c).StopTestRun()
 	return nil
 }
 
@@ -225,6 +228,27 @@ func (h runHandlers) processAcquiredAllAccessTokensEvent(ws *websocket.Conn, log
 	return nil
 }
 
+func (h runHandlers) processRefreshedAccessTokenEvent(ws *websocket.Conn, logger *logrus.Entry, event events.RefreshedAccessToken, ok bool) error {
+	if !ok {
+		err := errors.New("error reading from events.RefreshedTokens channel")
+		logger.Error(err)
+		return err
+	}
+
+	wsEvent := newRefreshedAccessTokenWebSocketEvent(event)
+	logger.WithFields(logrus.Fields{
+		"wsEvent.Type":    wsEvent.Type,
+		"event.TokenName": event.TokenName,
+		"event.Reason":    event.Reason,
+	}).Info("sending event")
+	if err := ws.WriteJSON(wsEvent); err != nil {
+		logger.WithError(err).Error("[processRefreshedAccessTokenEvent] writing json to websocket")
+		return err
+	}
+
+	return nil
+}
+
 // StoppedEvent -
 type StoppedEvent struct {
 	Stopped bool `json:"stopped"`
@@ -237,15 +261,20 @@ func newStoppedEvent() StoppedEvent {
 }
 
 // TestCaseResultWebSocketEvent -
+// `API` and `APIVersion` are sent alongside `Test` as they are not part of the `results.TestCase` JSON.
 type TestCaseResultWebSocketEvent struct {
-	Type string           `json:"type"`
-	Test results.TestCase `json:"test"`
+	Type       string           `json:"type"`
+	API        string           `json:"api,omitempty"`
+	APIVersion string           `json:"apiVersion,omitempty"`
+	Test       results.TestCase `json:"test"`
 }
 
 func newTestCaseResultWebSocketEvent(testCaseResult results.TestCase) TestCaseResultWebSocketEvent {
 	return TestCaseResultWebSocketEvent{
-		Type: "ResultType_TestCaseResult",
-		Test: testCaseResult,
+		Type:       "ResultType_TestCaseResult",
+		API:        testCaseResult.API,
+		APIVersion: testCaseResult.APIVersion,
+		Test:       testCaseResult,
 	}
 }
 
@@ -287,3 +316,16 @@ func newAcquiredAllAccessTokensWebSocketEvent(event events.AcquiredAllAccessToke
 		Value: event,
 	}
 }
+
+// RefreshedAccessTokenWebSocketEvent -
+type RefreshedAccessTokenWebSocketEvent struct {
+	Type  string                      `json:"type"`
+	Value events.RefreshedAccessToken `json:"value"`
+}
+
+func newRefreshedAccessTokenWebSocketEvent(event events.RefreshedAccessToken) RefreshedAccessTokenWebSocketEvent {
+	return RefreshedAccessTokenWebSocketEvent{
+		Type:  "ResultType_RefreshedAccessToken",
+		Value: event,
+	}
+}
@@ -49,6 +49,8 @@ func TestServerRunHandlersnewTestCaseResultWebSocketEvent(t *testing.T) {
 		RefURI:     "https://openbanking.org.uk/ref/uri",
 		Endpoint:   "/foobar",
 		HttpStatus: "200",
+		API:        "Account and Transaction API Specification",
+		APIVersion: "v3.1",
 	}
 	wsEvent := newTestCaseResultWebSocketEvent(testCaseResult)
 
@@ -59,6 +61,8 @@ func TestServerRunHandlersnewTestCaseResultWebSocketEvent(t *testing.T) {
 	expected := `
 {
 	"type": "ResultType_TestCaseResult",
+	"api": "Account and Transaction API Specification",
+	"apiVersion": "v3.1",
     "test": {
         "id": "#t1025",
         "pass": true,
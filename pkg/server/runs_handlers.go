@@ -0,0 +1,44 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/runs"
+)
+
+type runsHandlers struct {
+	logger *logrus.Entry
+}
+
+func newRunsHandlers(logger *logrus.Entry) runsHandlers {
+	return runsHandlers{
+		logger: logger.WithField("handler", "runsHandlers"),
+	}
+}
+
+// listRunsHandler - returns the summaries of the stored runs, most recent first
+func (h runsHandlers) listRunsHandler(c echo.Context) error {
+	summaries, err := journeyFrom(c).RunStore().List()
+	if err != nil {
+		h.logger.WithError(err).Error("listing runs")
+		return c.JSON(http.StatusInternalServerError, NewErrorResponse(err))
+	}
+	return c.JSON(http.StatusOK, summaries)
+}
+
+// getRunHandler - returns a stored run with its discovery model and results
+func (h runsHandlers) getRunHandler(c echo.Context) error {
+	run, err := journeyFrom(c).RunStore().Get(c.Param("id"))
+	if errors.Cause(err) == runs.ErrNotFound {
+		return c.JSON(http.StatusNotFound, NewErrorResponse(err))
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("getting run")
+		return c.JSON(http.StatusInternalServerError, NewErrorResponse(err))
+	}
+	return c.JSON(http.StatusOK, run)
+}
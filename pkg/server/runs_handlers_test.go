@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
+	"github.com/OpenBankingUK/conformance-suite/pkg/runs"
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+	versionmock "github.com/OpenBankingUK/conformance-suite/pkg/version/mocks"
+)
+
+func TestServerRunsHandlers(t *testing.T) {
+	require := test.NewRequire(t)
+
+	journey := testJourney()
+	startedAt := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	testCases := []results.TestCase{
+		results.NewTestCaseResult("OB-301-ACC-120382", true, results.NoMetrics(), nil, "/accounts", "accounts", "v3.1", "", "", "200 OK"),
+	}
+	run := runs.NewRun("run-1", startedAt, startedAt.Add(time.Minute), "abc", discovery.Model{}, testCases, "")
+	require.NoError(journey.RunStore().Save(run))
+
+	server := NewServer(journey, nullLogger(), &versionmock.Version{})
+	defer func() {
+		require.NoError(server.Shutdown(context.TODO()))
+	}()
+
+	code, body, _ := request(http.MethodGet, "/api/runs", nil, server)
+	require.Equal(http.StatusOK, code)
+	require.JSONEq(`[{
+		"id": "run-1",
+		"startedAt": "2019-06-01T12:00:00Z",
+		"completedAt": "2019-06-01T12:01:00Z",
+		"configHash": "abc",
+		"discoveryName": "",
+		"total": 1,
+		"passed": 1,
+		"failed": 0,
+		"skipped": 0
+	}]`, body.String())
+
+	code, body, _ = request(http.MethodGet, "/api/runs/run-1", nil, server)
+	require.Equal(http.StatusOK, code)
+	require.Contains(body.String(), `"api":"accounts"`)
+	require.Contains(body.String(), `"id":"OB-301-ACC-120382"`)
+
+	code, body, _ = request(http.MethodGet, "/api/runs/run-2", nil, server)
+	require.Equal(http.StatusNotFound, code)
+	require.JSONEq(`{"error": "run not found"}`, body.String())
+}
+
+func TestRecordingDaemonControllerRecordsBeforeCompleting(t *testing.T) {
+	require := test.NewRequire(t)
+
+	journey := NewJourney(nullLogger(), nil, nil, discovery.NewNullTLSValidator(), false)
+	store := runs.NewMemoryStore()
+	discoveryModel := discovery.Model{DiscoveryModel: discovery.ModelDiscovery{Name: "ob-v3.1-ozone"}}
+	daemonController := newRecordingDaemonController(executors.NewBufferedDaemonController(), journey.recordRun(time.Now(), discoveryModel, "abc", store))
+	daemonController.AddResult(results.NewTestCaseResult("OB-301-ACC-120382", true, results.NoMetrics(), nil, "/accounts", "accounts", "v3.1", "", "", "200 OK"))
+
+	// the run is recorded with the configuration and store it started with
+	journey.config.configHash = "def"
+	journey.SetRunStore(runs.NewMemoryStore())
+	daemonController.SetCompleted()
+	<-daemonController.IsCompleted()
+
+	summaries, err := store.List()
+	require.NoError(err)
+	require.Len(summaries, 1)
+	require.Equal("abc", summaries[0].ConfigHash)
+	require.Equal("ob-v3.1-ozone", summaries[0].DiscoveryName)
+	require.Equal(1, summaries[0].Passed)
+	summaries, err = journey.RunStore().List()
+	require.NoError(err)
+	require.Empty(summaries)
+}
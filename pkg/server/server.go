@@ -23,8 +23,17 @@ type Server struct {
 	version    version.Checker
 }
 
-// NewServer returns new echo.Echo server.
+// NewServer returns new echo.Echo server, every request shares the same journey.
 func NewServer(journey Journey, logger *logrus.Entry, version version.Checker) *Server {
+	return newServer(singleJourneyMiddleware(journey), nil, logger, version)
+}
+
+// NewSessionServer returns new echo.Echo server, each session has its own journey.
+func NewSessionServer(sessions *Sessions, logger *logrus.Entry, version version.Checker) *Server {
+	return newServer(sessions.Middleware(), sessions, logger, version)
+}
+
+func newServer(journeyMiddleware echo.MiddlewareFunc, sessions *Sessions, logger *logrus.Entry, version version.Checker) *Server {
 	server := &Server{
 		Echo:    echo.New(),
 		logger:  logger,
@@ -59,12 +68,14 @@ func NewServer(journey Journey, logger *logrus.Entry, version version.Checker) *
 		Browse:  false,
 	}))
 
-	registerRoutes(journey, server, logger, version)
+	registerRoutes(journeyMiddleware, sessions, server, logger, version)
 
 	return server
 }
 
-func registerRoutes(journey Journey, server *Server, logger *logrus.Entry, version version.Checker) {
+// registerRoutes - routes using a journey are served with the journey the journeyMiddleware resolves,
+// the sessions routes are only registered when sessions is not nil.
+func registerRoutes(journeyMiddleware echo.MiddlewareFunc, sessions *Sessions, server *Server, logger *logrus.Entry, version version.Checker) {
 	// swagger ui endpoints
 	for path, handler := range swaggerHandlers(logger) {
 		server.GET(path, handler)
@@ -75,38 +86,54 @@ func registerRoutes(journey Journey, server *Server, logger *logrus.Entry, versi
 
 	api.GET("/ping", func(c echo.Context) error { return nil })
 
-	importHandlers := newImportHandlers(journey, logger)
-	api.POST("/import/review", importHandlers.postImportReview)
-	api.POST("/import/rerun", importHandlers.postImportRerun)
+	importHandlers := newImportHandlers(logger)
+	api.POST("/import/review", importHandlers.postImportReview, journeyMiddleware)
+	api.POST("/import/rerun", importHandlers.postImportRerun, journeyMiddleware)
 
-	configHandlers := newConfigHandlers(journey, logger)
+	configHandlers := newConfigHandlers(logger)
 	// endpoint to post global configuration
-	api.POST("/config/global", configHandlers.configGlobalPostHandler)
-	api.GET("/config/conditional-property", configHandlers.configConditionalPropertyHandler)
+	api.POST("/config/global", configHandlers.configGlobalPostHandler, journeyMiddleware)
+	api.POST("/config/check", configHandlers.configCheckPostHandler)
+	api.GET("/config/conditional-property", configHandlers.configConditionalPropertyHandler, journeyMiddleware)
 
 	// endpoints for discovery model
-	discoveryHandlers := newDiscoveryHandlers(journey, logger)
-	api.POST("/discovery-model", discoveryHandlers.setDiscoveryModelHandler)
+	discoveryHandlers := newDiscoveryHandlers(logger)
+	api.POST("/discovery-model", discoveryHandlers.setDiscoveryModelHandler, journeyMiddleware)
 
 	// endpoints for test cases
-	testCaseHandlers := newTestCaseHandlers(journey, NewWebSocketUpgrader(), logger)
-	api.GET("/test-cases", testCaseHandlers.testCasesHandler)
+	testCaseHandlers := newTestCaseHandlers(NewWebSocketUpgrader(), logger)
+	api.GET("/test-cases", testCaseHandlers.testCasesHandler, journeyMiddleware)
 
 	// endpoints for test runner
-	runHandlers := newRunHandlers(journey, NewWebSocketUpgrader(), logger)
-	api.POST("/run", runHandlers.runStartPostHandler)
-	api.GET("/run/ws", runHandlers.listenResultWebSocket)
-	api.DELETE("/run", runHandlers.stopRunHandler)
+	runHandlers := newRunHandlers(NewWebSocketUpgrader(), logger)
+	api.POST("/run", runHandlers.runStartPostHandler, journeyMiddleware)
+	api.GET("/run/ws", runHandlers.listenResultWebSocket, journeyMiddleware)
+	api.DELETE("/run", runHandlers.stopRunHandler, journeyMiddleware)
 
 	// endpoints for validating and storing the token retrieved in `/conformancesuite/callback`
 	// `pkg/server/assets/main.js` calls into this endpoint.
-	redirectHandlers := newRedirectHandlers(journey, logger)
-	api.POST("/redirect/fragment/ok", redirectHandlers.postFragmentOKHandler)
-	api.POST("/redirect/query/ok", redirectHandlers.postQueryOKHandler)
-	api.POST("/redirect/error", redirectHandlers.postErrorHandler)
-
-	exportHandlers := newExportHandlers(journey, logger)
-	api.POST("/export", exportHandlers.postExport)
+	redirectHandlers := newRedirectHandlers(logger)
+	api.POST("/redirect/fragment/ok", redirectHandlers.postFragmentOKHandler, journeyMiddleware)
+	api.POST("/redirect/query/ok", redirectHandlers.postQueryOKHandler, journeyMiddleware)
+	api.POST("/redirect/error", redirectHandlers.postErrorHandler, journeyMiddleware)
+
+	exportHandlers := newExportHandlers(logger)
+	api.POST("/export", exportHandlers.postExport, journeyMiddleware)
+
+	reportHandlers := newReportHandlers(logger)
+	api.POST("/report/diff", reportHandlers.postReportDiff)
+
+	// endpoints for browsing the history of runs
+	runsHandlers := newRunsHandlers(logger)
+	api.GET("/runs", runsHandlers.listRunsHandler, journeyMiddleware)
+	api.GET("/runs/:id", runsHandlers.getRunHandler, journeyMiddleware)
+
+	// endpoints for managing the sessions of a shared server
+	if sessions != nil {
+		sessionsHandlers := newSessionsHandlers(sessions, logger)
+		api.GET("/sessions", sessionsHandlers.listSessionsHandler, journeyMiddleware)
+		api.DELETE("/sessions/:handle", sessionsHandlers.deleteSessionHandler, journeyMiddleware)
+	}
 
 	// endpoints for utility function such as version/update checking.
 	utilityEndpoints := newUtilityEndpoints(version)
@@ -0,0 +1,274 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Session identification, the header takes precedence over the cookie
+const (
+	SessionCookieName = "fcs_session"
+	SessionHeaderName = "X-FCS-Session"
+	DefaultSessionTTL = 8 * time.Hour
+	// DefaultMaxSessions - the number of live sessions a shared server starts by default
+	DefaultMaxSessions = 100
+)
+
+const (
+	journeyContextKey  = "journey"
+	sessionsContextKey = "sessions"
+	sessionContextKey  = "session"
+)
+
+var (
+	errSessionNotFound  = errors.New("session not found")
+	errSessionForbidden = errors.New("a session can only be deleted by itself")
+	errTooManySessions  = errors.New("too many sessions, try again once an unused session has expired")
+)
+
+// Sessions - the journeys of the users of a shared server, one per session.
+// Sessions not used for longer than their time to live expire, no more than maxSessions are live at once.
+type Sessions struct {
+	newJourney  func() Journey
+	ttl         time.Duration
+	maxSessions int
+	sessions    map[string]*session
+	lock        *sync.Mutex
+	now         func() time.Time
+	logger      *logrus.Entry
+}
+
+// The ID of a session is the credential its requests present, the handle identifies it to others without granting access
+type session struct {
+	id           string
+	handle       string
+	created      time.Time
+	lastAccessed time.Time
+	journey      Journey
+}
+
+// SessionInfo - describes a session by its handle, never by its ID
+type SessionInfo struct {
+	Handle       string    `json:"handle"`
+	Created      time.Time `json:"created"`
+	LastAccessed time.Time `json:"lastAccessed"`
+	Expires      time.Time `json:"expires"`
+	Current      bool      `json:"current"`
+}
+
+// NewSessions creates sessions whose journeys are created with newJourney, a ttl of 0 uses DefaultSessionTTL
+// and a maxSessions of 0 DefaultMaxSessions
+func NewSessions(newJourney func() Journey, ttl time.Duration, maxSessions int, logger *logrus.Entry) *Sessions {
+	if ttl <= 0 {
+		ttl = DefaultSessionTTL
+	}
+	if maxSessions <= 0 {
+		maxSessions = DefaultMaxSessions
+	}
+	return &Sessions{
+		newJourney:  newJourney,
+		ttl:         ttl,
+		maxSessions: maxSessions,
+		sessions:    map[string]*session{},
+		lock:        &sync.Mutex{},
+		now:         time.Now,
+		logger:      logger.WithField("module", "sessions"),
+	}
+}
+
+// Create starts a new session and returns its ID, unless maxSessions sessions have not expired
+func (s *Sessions) Create() (string, error) {
+	s.expire()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(s.sessions) >= s.maxSessions {
+		return "", errTooManySessions
+	}
+	now := s.now()
+	id := uuid.New().String()
+	handle := uuid.New().String()
+	s.sessions[id] = &session{id: id, handle: handle, created: now, lastAccessed: now, journey: s.newJourney()}
+	s.logger.WithField("session", handle).Info("session created")
+	return id, nil
+}
+
+// Journey returns the journey of a session that has not expired and marks the session as used
+func (s *Sessions) Journey(id string) (Journey, bool) {
+	s.lock.Lock()
+	session, ok := s.sessions[id]
+	if !ok {
+		s.lock.Unlock()
+		return nil, false
+	}
+	now := s.now()
+	if s.expired(session, now) {
+		s.remove(session)
+		s.lock.Unlock()
+		s.stop(session)
+		return nil, false
+	}
+	session.lastAccessed = now
+	s.lock.Unlock()
+	return session.journey, true
+}
+
+// List returns the sessions that have not expired, most recently used first
+func (s *Sessions) List(currentID string) []SessionInfo {
+	s.expire()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	infos := make([]SessionInfo, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		infos = append(infos, SessionInfo{
+			Handle:       session.handle,
+			Created:      session.created,
+			LastAccessed: session.lastAccessed,
+			Expires:      session.lastAccessed.Add(s.ttl),
+			Current:      session.id == currentID,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].LastAccessed.After(infos[j].LastAccessed)
+	})
+	return infos
+}
+
+// Handle returns the handle of a session
+func (s *Sessions) Handle(id string) (string, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	session, ok := s.sessions[id]
+	if !ok {
+		return "", false
+	}
+	return session.handle, true
+}
+
+// Delete stops the test run of the session with `handle` and removes it, only the session `currentID` itself can
+func (s *Sessions) Delete(handle, currentID string) error {
+	s.lock.Lock()
+	for _, session := range s.sessions {
+		if session.handle != handle {
+			continue
+		}
+		if session.id != currentID {
+			s.lock.Unlock()
+			return errSessionForbidden
+		}
+		s.remove(session)
+		s.lock.Unlock()
+		s.stop(session)
+		return nil
+	}
+	s.lock.Unlock()
+	return errSessionNotFound
+}
+
+// Middleware resolves the session of each request from the session header or cookie,
+// starting a new session when the request has none or its session has expired.
+// A request that would start a session once maxSessions are live is rejected.
+func (s *Sessions) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			id := c.Request().Header.Get(SessionHeaderName)
+			if id == "" {
+				if cookie, err := c.Cookie(SessionCookieName); err == nil {
+					id = cookie.Value
+				}
+			}
+
+			journey, ok := s.Journey(id)
+			if !ok {
+				var err error
+				if id, err = s.Create(); err != nil {
+					return c.JSON(http.StatusServiceUnavailable, NewErrorResponse(err))
+				}
+				journey, _ = s.Journey(id)
+			}
+
+			c.SetCookie(&http.Cookie{
+				Name:     SessionCookieName,
+				Value:    id,
+				Path:     "/",
+				MaxAge:   int(s.ttl.Seconds()),
+				Secure:   true,
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+			c.Response().Header().Set(SessionHeaderName, id)
+			c.Set(sessionsContextKey, s)
+			c.Set(sessionContextKey, id)
+			c.Set(journeyContextKey, journey)
+			return next(c)
+		}
+	}
+}
+
+// expire removes the sessions that have not been used for longer than their time to live
+func (s *Sessions) expire() {
+	s.lock.Lock()
+	now := s.now()
+	expired := []*session{}
+	for _, session := range s.sessions {
+		if s.expired(session, now) {
+			s.remove(session)
+			expired = append(expired, session)
+		}
+	}
+	s.lock.Unlock()
+
+	// stopping a test run waits for it, so other sessions are not held up by the lock meanwhile
+	for _, session := range expired {
+		s.stop(session)
+	}
+}
+
+func (s *Sessions) expired(session *session, now time.Time) bool {
+	return now.Sub(session.lastAccessed) > s.ttl
+}
+
+// remove must be called with the lock held, the session is then stopped once the lock is released
+func (s *Sessions) remove(session *session) {
+	delete(s.sessions, session.id)
+	s.logger.WithField("session", session.handle).Info("session removed")
+}
+
+// stop stops the test run of a removed session and removes the manifests it imported, it must be called without the lock held
+func (s *Sessions) stop(session *session) {
+	session.journey.StopTestRun()
+	session.journey.SetImportDir("")
+}
+
+// singleJourneyMiddleware serves every request with the same journey
+func singleJourneyMiddleware(journey Journey) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(journeyContextKey, journey)
+			return next(c)
+		}
+	}
+}
+
+// journeyFrom returns the journey of the request's session
+func journeyFrom(c echo.Context) Journey {
+	return c.Get(journeyContextKey).(Journey)
+}
+
+// sessionsFrom returns the sessions and the request's session ID, false when the server has a single journey
+func sessionsFrom(c echo.Context) (*Sessions, string, bool) {
+	sessions, ok := c.Get(sessionsContextKey).(*Sessions)
+	if !ok {
+		return nil, "", false
+	}
+	id, _ := c.Get(sessionContextKey).(string)
+	return sessions, id, true
+}
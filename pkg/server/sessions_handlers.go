@@ -0,0 +1,40 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/sirupsen/logrus"
+)
+
+type sessionsHandlers struct {
+	sessions *Sessions
+	logger   *logrus.Entry
+}
+
+func newSessionsHandlers(sessions *Sessions, logger *logrus.Entry) sessionsHandlers {
+	return sessionsHandlers{
+		sessions: sessions,
+		logger:   logger.WithField("handler", "sessionsHandlers"),
+	}
+}
+
+// listSessionsHandler - GET /api/sessions, the sessions that have not expired, most recently used first
+func (h sessionsHandlers) listSessionsHandler(c echo.Context) error {
+	_, id, _ := sessionsFrom(c)
+	return c.JSON(http.StatusOK, h.sessions.List(id))
+}
+
+// deleteSessionHandler - DELETE /api/sessions/:handle, stops the test run of the requesting session and removes it
+func (h sessionsHandlers) deleteSessionHandler(c echo.Context) error {
+	_, id, _ := sessionsFrom(c)
+	handle := c.Param("handle")
+	if err := h.sessions.Delete(handle, id); err != nil {
+		if err == errSessionForbidden {
+			return c.JSON(http.StatusForbidden, NewErrorResponse(err))
+		}
+		return c.JSON(http.StatusNotFound, NewErrorResponse(err))
+	}
+	h.logger.WithField("session", handle).Info("session deleted")
+	return c.NoContent(http.StatusNoContent)
+}
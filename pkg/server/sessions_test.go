@@ -0,0 +1,281 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"gopkg.in/resty.v1"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+	discovery_mocks "github.com/OpenBankingUK/conformance-suite/pkg/discovery/mocks"
+	gmocks "github.com/OpenBankingUK/conformance-suite/pkg/generation"
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+	versionmock "github.com/OpenBankingUK/conformance-suite/pkg/version/mocks"
+)
+
+func TestSessionsCreateAndJourney(t *testing.T) {
+	require := test.NewRequire(t)
+
+	sessions := NewSessions(testJourney, time.Hour, 0, nullLogger())
+	first := createSession(t, sessions)
+	second := createSession(t, sessions)
+	require.NotEqual(first, second)
+
+	firstJourney, ok := sessions.Journey(first)
+	require.True(ok)
+	secondJourney, ok := sessions.Journey(second)
+	require.True(ok)
+	require.False(firstJourney == secondJourney)
+
+	again, ok := sessions.Journey(first)
+	require.True(ok)
+	require.True(firstJourney == again)
+
+	_, ok = sessions.Journey("unknown")
+	require.False(ok)
+}
+
+func TestSessionsExpire(t *testing.T) {
+	require := test.NewRequire(t)
+
+	now := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	sessions := NewSessions(testJourney, time.Hour, 0, nullLogger())
+	sessions.now = func() time.Time { return now }
+
+	used := createSession(t, sessions)
+	unused := createSession(t, sessions)
+
+	now = now.Add(45 * time.Minute)
+	_, ok := sessions.Journey(used)
+	require.True(ok)
+
+	now = now.Add(30 * time.Minute)
+	_, ok = sessions.Journey(unused)
+	require.False(ok)
+
+	infos := sessions.List(used)
+	require.Len(infos, 1)
+	handle, ok := sessions.Handle(used)
+	require.True(ok)
+	require.Equal(handle, infos[0].Handle)
+	require.NotEqual(used, handle)
+	require.True(infos[0].Current)
+	require.Equal(infos[0].LastAccessed.Add(time.Hour), infos[0].Expires)
+}
+
+func TestSessionsListMostRecentlyUsedFirst(t *testing.T) {
+	require := test.NewRequire(t)
+
+	now := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	sessions := NewSessions(testJourney, time.Hour, 0, nullLogger())
+	sessions.now = func() time.Time { return now }
+
+	first := createSession(t, sessions)
+	now = now.Add(time.Minute)
+	second := createSession(t, sessions)
+	now = now.Add(time.Minute)
+	_, ok := sessions.Journey(first)
+	require.True(ok)
+
+	infos := sessions.List(second)
+	require.Len(infos, 2)
+	firstHandle, _ := sessions.Handle(first)
+	secondHandle, _ := sessions.Handle(second)
+	require.Equal(firstHandle, infos[0].Handle)
+	require.False(infos[0].Current)
+	require.Equal(secondHandle, infos[1].Handle)
+	require.True(infos[1].Current)
+}
+
+func TestSessionsDelete(t *testing.T) {
+	require := test.NewRequire(t)
+
+	sessions := NewSessions(testJourney, time.Hour, 0, nullLogger())
+	id := createSession(t, sessions)
+	other := createSession(t, sessions)
+	handle, _ := sessions.Handle(id)
+
+	require.Equal(errSessionNotFound, sessions.Delete(id, id))
+	require.Equal(errSessionForbidden, sessions.Delete(handle, other))
+	require.NoError(sessions.Delete(handle, id))
+	_, ok := sessions.Journey(id)
+	require.False(ok)
+	require.Equal(errSessionNotFound, sessions.Delete(handle, id))
+	_, ok = sessions.Journey(other)
+	require.True(ok)
+}
+
+func TestSessionsMaxSessions(t *testing.T) {
+	require := test.NewRequire(t)
+
+	now := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	sessions := NewSessions(testJourney, time.Hour, 2, nullLogger())
+	sessions.now = func() time.Time { return now }
+
+	first := createSession(t, sessions)
+	now = now.Add(30 * time.Minute)
+	createSession(t, sessions)
+	_, err := sessions.Create()
+	require.Equal(errTooManySessions, err)
+
+	// an expired session makes room for another
+	now = now.Add(45 * time.Minute)
+	createSession(t, sessions)
+	_, ok := sessions.Journey(first)
+	require.False(ok)
+	require.Len(sessions.List(""), 2)
+}
+
+func TestSessionsJourneysHaveTheirOwnHTTPClient(t *testing.T) {
+	require := test.NewRequire(t)
+
+	newJourney := func() Journey {
+		return NewJourney(nullLogger(), &gmocks.MockGenerator{}, &discovery_mocks.Validator{}, discovery.NewNullTLSValidator(), false)
+	}
+	sessions := NewSessions(newJourney, time.Hour, 0, nullLogger())
+	first, _ := sessions.Journey(createSession(t, sessions))
+	second, _ := sessions.Journey(createSession(t, sessions))
+
+	firstClient := first.(*AppJourney).httpClient
+	secondClient := second.(*AppJourney).httpClient
+	require.NotNil(firstClient)
+	require.False(firstClient == secondClient)
+	require.False(firstClient == resty.DefaultClient)
+	ctxClient, ok := first.(*AppJourney).context.Get(model.CtxHTTPClient)
+	require.True(ok)
+	require.True(firstClient == ctxClient)
+}
+
+func TestSessionServerGivesEachClientItsOwnSession(t *testing.T) {
+	require := test.NewRequire(t)
+
+	sessions := NewSessions(testJourney, time.Hour, 0, nullLogger())
+	server := NewSessionServer(sessions, nullLogger(), &versionmock.Version{})
+	defer func() {
+		require.NoError(server.Shutdown(context.TODO()))
+	}()
+
+	code, _, headers := request(http.MethodGet, "/api/sessions", nil, server)
+	require.Equal(http.StatusOK, code)
+	first := headers.Get(SessionHeaderName)
+	require.NotEmpty(first)
+	cookie := (&http.Response{Header: headers}).Cookies()[0]
+	require.Equal(SessionCookieName, cookie.Name)
+	require.Equal(first, cookie.Value)
+	require.True(cookie.Secure)
+	require.True(cookie.HttpOnly)
+
+	_, _, headers = request(http.MethodGet, "/api/sessions", nil, server)
+	second := headers.Get(SessionHeaderName)
+	require.NotEqual(first, second)
+
+	// the cookie selects the session
+	req := httptest.NewRequest(http.MethodGet, "/api/sessions", nil)
+	req.AddCookie(&http.Cookie{Name: SessionCookieName, Value: first})
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	require.Equal(http.StatusOK, rec.Code)
+	require.Equal(first, rec.Header().Get(SessionHeaderName))
+	firstHandle, _ := sessions.Handle(first)
+	require.Contains(rec.Body.String(), `"handle":"`+firstHandle+`","created"`)
+	require.NotContains(rec.Body.String(), first)
+	require.NotContains(rec.Body.String(), second)
+
+	// the header takes precedence over the cookie
+	req = httptest.NewRequest(http.MethodGet, "/api/sessions", nil)
+	req.AddCookie(&http.Cookie{Name: SessionCookieName, Value: first})
+	req.Header.Set(SessionHeaderName, second)
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	require.Equal(second, rec.Header().Get(SessionHeaderName))
+
+	require.Len(sessions.List(""), 2)
+}
+
+func TestSessionServerRejectsSessionsBeyondMaxSessions(t *testing.T) {
+	require := test.NewRequire(t)
+
+	sessions := NewSessions(testJourney, time.Hour, 1, nullLogger())
+	server := NewSessionServer(sessions, nullLogger(), &versionmock.Version{})
+	defer func() {
+		require.NoError(server.Shutdown(context.TODO()))
+	}()
+
+	code, _, headers := request(http.MethodGet, "/api/sessions", nil, server)
+	require.Equal(http.StatusOK, code)
+	id := headers.Get(SessionHeaderName)
+
+	code, body, _ := request(http.MethodGet, "/api/sessions", nil, server)
+	require.Equal(http.StatusServiceUnavailable, code)
+	require.JSONEq(`{"error": "too many sessions, try again once an unused session has expired"}`, body.String())
+
+	// the live session is still served
+	req := httptest.NewRequest(http.MethodGet, "/api/sessions", nil)
+	req.Header.Set(SessionHeaderName, id)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	require.Equal(http.StatusOK, rec.Code)
+}
+
+// createSession starts a session and returns its ID
+func createSession(t *testing.T, sessions *Sessions) string {
+	id, err := sessions.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestSessionServerDeleteSession(t *testing.T) {
+	require := test.NewRequire(t)
+
+	sessions := NewSessions(testJourney, time.Hour, 0, nullLogger())
+	server := NewSessionServer(sessions, nullLogger(), &versionmock.Version{})
+	defer func() {
+		require.NoError(server.Shutdown(context.TODO()))
+	}()
+	id := createSession(t, sessions)
+	other := createSession(t, sessions)
+	handle, _ := sessions.Handle(id)
+
+	// another session cannot delete it
+	req := httptest.NewRequest(http.MethodDelete, "/api/sessions/"+handle, nil)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(SessionHeaderName, other)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	require.Equal(http.StatusForbidden, rec.Code)
+	require.JSONEq(`{"error": "a session can only be deleted by itself"}`, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/sessions/"+handle, nil)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(SessionHeaderName, id)
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	require.Equal(http.StatusNoContent, rec.Code)
+
+	_, ok := sessions.Journey(id)
+	require.False(ok)
+
+	code, body, _ := request(http.MethodDelete, "/api/sessions/"+handle, nil, server)
+	require.Equal(http.StatusNotFound, code)
+	require.JSONEq(`{"error": "session not found"}`, body.String())
+}
+
+func TestServerWithoutSessionsHasNoSessionsAPI(t *testing.T) {
+	require := test.NewRequire(t)
+
+	server := NewServer(testJourney(), nullLogger(), &versionmock.Version{})
+	defer func() {
+		require.NoError(server.Shutdown(context.TODO()))
+	}()
+
+	code, _, headers := request(http.MethodGet, "/api/sessions", nil, server)
+	require.NotEqual(http.StatusOK, code)
+	require.Empty(headers.Get(SessionHeaderName))
+}
@@ -67,6 +67,9 @@ export default {
         const authorizationEndpoint = _.first(_.values(response.authorization_endpoints));
         commit(types.SET_AUTHORIZATION_ENDPOINT, authorizationEndpoint);
 
+        // By discovery item, empty when its consent is requested with a front-channel request object.
+        commit(types.SET_PUSHED_AUTHORIZATION_REQUEST_ENDPOINTS, response.pushed_authorization_request_endpoints || {});
+
         const issuer = _.first(_.values(response.issuers));
         commit(types.SET_ISSUER, issuer);
 
@@ -117,6 +120,7 @@ export default {
         'token_endpoint_auth_method',
         'request_object_signing_alg',
         'authorization_endpoint',
+        'pushed_authorization_request_endpoints',
         'resource_base_url',
         'x_fapi_financial_id',
         'send_x_fapi_customer_ip_address',
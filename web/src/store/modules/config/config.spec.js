@@ -61,6 +61,7 @@ describe('web/src/store/modules/config', () => {
         token_endpoint_auth_method: 'client_secret_basic',
         request_object_signing_alg: '',
         authorization_endpoint: '',
+        pushed_authorization_request_endpoints: {},
         resource_base_url: '',
         x_fapi_financial_id: '',
         send_x_fapi_customer_ip_address: false,
@@ -157,6 +158,7 @@ describe('web/src/store/modules/config', () => {
         token_endpoint_auth_method: 'client_secret_basic',
         request_object_signing_alg: '',
         authorization_endpoint: '',
+        pushed_authorization_request_endpoints: {},
         resource_base_url: '',
         x_fapi_financial_id: '',
         send_x_fapi_customer_ip_address: false,
@@ -213,6 +215,7 @@ describe('web/src/store/modules/config', () => {
         token_endpoint_auth_method: 'client_secret_basic',
         request_object_signing_alg: '',
         authorization_endpoint: '',
+        pushed_authorization_request_endpoints: {},
         resource_base_url: '',
         x_fapi_financial_id: '',
         send_x_fapi_customer_ip_address: false,
@@ -268,6 +271,7 @@ describe('web/src/store/modules/config', () => {
         token_endpoint_auth_method: 'client_secret_basic',
         request_object_signing_alg: '',
         authorization_endpoint: '',
+        pushed_authorization_request_endpoints: {},
         resource_base_url: '',
         x_fapi_financial_id: '',
         send_x_fapi_customer_ip_address: false,
@@ -323,6 +327,7 @@ describe('web/src/store/modules/config', () => {
         token_endpoint_auth_method: 'client_secret_basic',
         request_object_signing_alg: '',
         authorization_endpoint: '',
+        pushed_authorization_request_endpoints: {},
         resource_base_url: '',
         x_fapi_financial_id: '',
         send_x_fapi_customer_ip_address: false,
@@ -381,6 +386,7 @@ describe('web/src/store/modules/config', () => {
         token_endpoint_auth_method: 'client_secret_basic',
         request_object_signing_alg: '',
         authorization_endpoint: 'https://modelobankauth2018.o3bank.co.uk:4101/auth',
+        pushed_authorization_request_endpoints: {},
         resource_base_url: '',
         x_fapi_financial_id: '',
         send_x_fapi_customer_ip_address: false,
@@ -436,6 +442,7 @@ describe('web/src/store/modules/config', () => {
         token_endpoint_auth_method: 'client_secret_basic',
         request_object_signing_alg: '',
         authorization_endpoint: 'https://modelobankauth2018.o3bank.co.uk:4101/auth',
+        pushed_authorization_request_endpoints: {},
         resource_base_url: 'https://ob19-rs1.o3bank.co.uk:4501',
         x_fapi_financial_id: '',
         send_x_fapi_customer_ip_address: false,
@@ -491,6 +498,7 @@ describe('web/src/store/modules/config', () => {
         token_endpoint_auth_method: 'client_secret_basic',
         request_object_signing_alg: '',
         authorization_endpoint: 'https://modelobankauth2018.o3bank.co.uk:4101/auth',
+        pushed_authorization_request_endpoints: {},
         resource_base_url: 'https://ob19-rs1.o3bank.co.uk:4501',
         x_fapi_financial_id: '0015800001041RHAAY',
         send_x_fapi_customer_ip_address: false,
@@ -546,6 +554,7 @@ describe('web/src/store/modules/config', () => {
         token_endpoint_auth_method: 'client_secret_basic',
         request_object_signing_alg: '',
         authorization_endpoint: 'https://modelobankauth2018.o3bank.co.uk:4101/auth',
+        pushed_authorization_request_endpoints: {},
         resource_base_url: 'https://ob19-rs1.o3bank.co.uk:4501',
         x_fapi_financial_id: '0015800001041RHAAY',
         send_x_fapi_customer_ip_address: false,
@@ -601,6 +610,7 @@ describe('web/src/store/modules/config', () => {
         token_endpoint_auth_method: 'client_secret_basic',
         request_object_signing_alg: '',
         authorization_endpoint: 'https://modelobankauth2018.o3bank.co.uk:4101/auth',
+        pushed_authorization_request_endpoints: {},
         resource_base_url: 'https://ob19-rs1.o3bank.co.uk:4501',
         x_fapi_financial_id: '0015800001041RHAAY',
         send_x_fapi_customer_ip_address: false,
@@ -656,6 +666,7 @@ describe('web/src/store/modules/config', () => {
         token_endpoint_auth_method: 'client_secret_basic',
         request_object_signing_alg: '',
         authorization_endpoint: 'https://modelobankauth2018.o3bank.co.uk:4101/auth',
+        pushed_authorization_request_endpoints: {},
         resource_base_url: 'https://ob19-rs1.o3bank.co.uk:4501',
         x_fapi_financial_id: '0015800001041RHAAY',
         send_x_fapi_customer_ip_address: false,
@@ -717,6 +728,7 @@ describe('web/src/store/modules/config', () => {
         token_endpoint_auth_method: 'client_secret_basic',
         request_object_signing_alg: '',
         authorization_endpoint: '',
+        pushed_authorization_request_endpoints: {},
         resource_base_url: '',
         x_fapi_financial_id: '',
         send_x_fapi_customer_ip_address: false,
@@ -774,6 +786,7 @@ describe('web/src/store/modules/config', () => {
         token_endpoint_auth_method: 'client_secret_basic',
         request_object_signing_alg: '',
         authorization_endpoint: '',
+        pushed_authorization_request_endpoints: {},
         resource_base_url: '',
         x_fapi_financial_id: '',
         send_x_fapi_customer_ip_address: false,
@@ -831,6 +844,7 @@ describe('web/src/store/modules/config', () => {
         token_endpoint_auth_method: 'client_secret_basic',
         request_object_signing_alg: '',
         authorization_endpoint: '',
+        pushed_authorization_request_endpoints: {},
         resource_base_url: '',
         x_fapi_financial_id: '',
         send_x_fapi_customer_ip_address: false,
@@ -889,6 +903,7 @@ describe('web/src/store/modules/config', () => {
         token_endpoint_auth_method: 'client_secret_basic',
         request_object_signing_alg: '',
         authorization_endpoint: '',
+        pushed_authorization_request_endpoints: {},
         resource_base_url: '',
         x_fapi_financial_id: '',
         send_x_fapi_customer_ip_address: false,
@@ -952,6 +967,7 @@ describe('web/src/store/modules/config', () => {
           token_endpoint_auth_method: 'client_secret_basic',
           request_object_signing_alg: '',
           authorization_endpoint: '',
+          pushed_authorization_request_endpoints: {},
           resource_base_url: '',
           x_fapi_financial_id: '',
           send_x_fapi_customer_ip_address: false,
@@ -1049,6 +1065,7 @@ describe('web/src/store/modules/config', () => {
           token_endpoint_auth_method: 'client_secret_basic',
           request_object_signing_alg: '',
           authorization_endpoint: 'https://modelobankauth2018.o3bank.co.uk:4101/auth_1',
+          pushed_authorization_request_endpoints: {},
           resource_base_url: '',
           x_fapi_financial_id: '',
           send_x_fapi_customer_ip_address: false,
@@ -82,6 +82,9 @@ export const mutations = {
   [mutationTypes.SET_AUTHORIZATION_ENDPOINT](state, value) {
     state.configuration.authorization_endpoint = value;
   },
+  [mutationTypes.SET_PUSHED_AUTHORIZATION_REQUEST_ENDPOINTS](state, value) {
+    state.configuration.pushed_authorization_request_endpoints = value;
+  },
   [mutationTypes.SET_RESOURCE_BASE_URL](state, value) {
     state.configuration.resource_base_url = value;
   },
@@ -256,6 +259,7 @@ export const state = {
     token_endpoint_auth_method: 'client_secret_basic',
     request_object_signing_alg: '',
     authorization_endpoint: '',
+    pushed_authorization_request_endpoints: {},
     resource_base_url: '',
     x_fapi_financial_id: '',
     send_x_fapi_customer_ip_address: false,
@@ -22,6 +22,7 @@ export const SET_TOKEN_ENDPOINT_AUTH_METHODS = 'SET_TOKEN_ENDPOINT_AUTH_METHODS'
 export const SET_REQUEST_OBJECT_SIGNING_ALG_VALUES_SUPPORTED = 'SET_REQUEST_OBJECT_SIGNING_ALG_VALUES_SUPPORTED';
 export const SET_REQUEST_OBJECT_SIGNING_ALG = 'SET_REQUEST_OBJECT_SIGNING_ALG';
 export const SET_AUTHORIZATION_ENDPOINT = 'SET_AUTHORIZATION_ENDPOINT';
+export const SET_PUSHED_AUTHORIZATION_REQUEST_ENDPOINTS = 'SET_PUSHED_AUTHORIZATION_REQUEST_ENDPOINTS';
 export const SET_RESOURCE_BASE_URL = 'SET_RESOURCE_BASE_URL';
 export const SET_X_FAPI_FINANCIAL_ID = 'SET_X_FAPI_FINANCIAL_ID';
 export const SET_SEND_X_FAPI_CUSTOMER_IP_ADDRESS = 'SET_SEND_X_FAPI_CUSTOMER_IP_ADDRESS';
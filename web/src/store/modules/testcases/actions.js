@@ -107,6 +107,8 @@ export default {
           commit(types.ADD_TOKEN_ACQUIRED, update);
         } else if (_.has(update, 'type') && update.type === 'ResultType_AcquiredAllAccessTokens') {
           commit(types.SET_ALL_TOKENS_ACQUIRED);
+        } else if (_.has(update, 'type') && update.type === 'ResultType_RefreshedAccessToken') {
+          // do nothing, kept with the other websocket messages
         } else if (_.has(update, 'stopped') && update.stopped) {
           // do nothing
         } else {